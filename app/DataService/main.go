@@ -40,5 +40,8 @@ func main() {
 		log.Print(newErr.Error())
 		panic(newErr)
 	}
-	server.Run()
+	err = server.Run()
+	if err != nil {
+		log.Fatalf("DataServer stopped with error. Err:%s", err)
+	}
 }
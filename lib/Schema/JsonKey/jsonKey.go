@@ -27,27 +27,52 @@ package JsonKey
 
 const (
 	AdditionalProperties = "additionalProperties"
+	AllOf                = "allOf"
+	AppendOnly           = "appendOnly"
 	ArchivedSchemaIdDiv  = "__"
 	Array                = "array"
+	Boolean              = "boolean"
+	Const                = "const"
 	ContentMediaType     = "contentMediaType"
+	ContentTypeDiv       = "|"
+	Date                 = "date"
+	DateTime             = "date-time"
+	Default              = "default"
 	Definitions          = "definitions"
+	Deprecated           = "deprecated"
+	Discriminator        = "discriminator"
+	Enum                 = "enum"
+	Examples             = "examples"
+	Extends              = "extends"
+	Format               = "format"
 	DefinitionPrefix     = "#/definitions/"
 	DocRoot              = "#"
+	If                   = "if"
 	IndexTemplate        = "indexTemplate"
 	Inventory            = "inventory"
 	Items                = "items"
 	Key                  = "key"
 	Name                 = "name"
 	Map                  = "map"
+	Mapping              = "mapping"
+	Number               = "number"
 	Object               = "object"
+	OneOf                = "oneOf"
 	Properties           = "properties"
+	PropertyName         = "propertyName"
 	Ref                  = "$ref"
 	Required             = "required"
+	Rules                = "rules"
 	Schema               = "schema"
+	Sensitive            = "sensitive"
 	String               = "string"
+	Then                 = "then"
+	Ttl                  = "ttl"
+	TtlAttr              = "ttlAttr"
 	Integer              = "integer"
 	Type                 = "type"
 	Version              = "version"
+	WarnRules            = "warnRules"
 )
 
 var InvalidTypeChars = []string{
@@ -57,6 +82,7 @@ var InvalidTypeChars = []string{
 	"[",
 	"]",
 	"?",
+	"|",
 }
 
 var InvalidKeyChars = []string{
@@ -0,0 +1,340 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package OpenApi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+)
+
+const (
+	Version           = "3.1.0"
+	ComponentPrefix   = "#/components/schemas/"
+	ErrorComponent    = "HttpError"
+	RecordSuffix      = ".record"
+	DefinitionSegment = ".definitions."
+	ContentJson       = "application/json"
+	ContentMergePatch = "application/merge-patch+json"
+)
+
+// keys of attribute definition that mean the same in OpenAPI schema object, copied as they are
+var passKeys = []string{
+	JsonKey.Const,
+	JsonKey.ContentMediaType,
+	JsonKey.Default,
+	JsonKey.Deprecated,
+	JsonKey.Enum,
+	JsonKey.Examples,
+	JsonKey.Format,
+	"description",
+	"maximum",
+	"maxItems",
+	"maxLength",
+	"minimum",
+	"minItems",
+	"minLength",
+	"pattern",
+	"uniqueItems",
+}
+
+// OpenAPI document of CRUD paths on each type of docs under basePath,
+// with data of each type, its definitions and its record as component schemas
+func Generate(title string, version string, basePath string, docs []*SchemaDoc.SchemaDoc) (map[string]interface{}, error) {
+	sorted := append([]*SchemaDoc.SchemaDoc{}, docs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Id < sorted[j].Id
+	})
+	basePath = strings.TrimRight("/"+strings.Trim(basePath, "/"), "/")
+	components := map[string]interface{}{
+		ErrorComponent: errorSchema(),
+	}
+	paths := map[string]interface{}{}
+	recordRefs := make([]interface{}, 0, len(sorted))
+	for _, doc := range sorted {
+		err := addComponents(doc, components)
+		if err != nil {
+			return nil, err
+		}
+		components[doc.Id+RecordSuffix] = recordSchema(doc)
+		recordRefs = append(recordRefs, componentRef(doc.Id+RecordSuffix))
+		paths[fmt.Sprintf("%s/%s", basePath, doc.Id)] = listPath(doc)
+		paths[fmt.Sprintf("%s/%s/{id}", basePath, doc.Id)] = recordPath(doc)
+	}
+	if len(recordRefs) > 0 {
+		paths[basePath+"/"] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary":     "add record of any type",
+				"operationId": "addRecord",
+				"requestBody": jsonBody(map[string]interface{}{JsonKey.OneOf: recordRefs}),
+				"responses": responses(http.StatusCreated, "id of added record", map[string]interface{}{
+					"text/plain": map[string]interface{}{"schema": map[string]interface{}{JsonKey.Type: JsonKey.String}},
+				}),
+			},
+		}
+	}
+	return map[string]interface{}{
+		"openapi": Version,
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": components,
+		},
+	}, nil
+}
+
+// name of component schema of doc, definitions are named after path to them like VirtualMachine.definitions.nic
+func ComponentName(doc *SchemaDoc.SchemaDoc) string {
+	if doc.Parent == nil {
+		return doc.Id
+	}
+	return ComponentName(doc.Parent) + DefinitionSegment + doc.Id
+}
+
+func componentRef(name string) map[string]interface{} {
+	return map[string]interface{}{JsonKey.Ref: ComponentPrefix + name}
+}
+
+// component schema of doc and of each of its definitions
+func addComponents(doc *SchemaDoc.SchemaDoc, components map[string]interface{}) error {
+	schema, err := DocSchema(doc)
+	if err != nil {
+		return err
+	}
+	components[ComponentName(doc)] = schema
+	defNames := make([]string, 0, len(doc.Definitions))
+	for name := range doc.Definitions {
+		defNames = append(defNames, name)
+	}
+	sort.Strings(defNames)
+	for _, name := range defNames {
+		err = addComponents(doc.Definitions[name], components)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenAPI schema object of data described by doc
+func DocSchema(doc *SchemaDoc.SchemaDoc) (map[string]interface{}, error) {
+	props := map[string]interface{}{}
+	for name, def := range doc.Properties() {
+		attrDef, ok := def.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid attr definition @path=[%s/%s]", doc.Path(), name)
+		}
+		attrSchema, err := AttrSchema(doc, attrDef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert attr @path=[%s/%s]. Error: %s", doc.Path(), name, err)
+		}
+		props[name] = attrSchema
+	}
+	schema := map[string]interface{}{
+		JsonKey.Type:       JsonKey.Object,
+		JsonKey.Properties: props,
+	}
+	if description, ok := doc.Data["description"]; ok {
+		schema["description"] = description
+	}
+	if required := doc.RequiredAttrs(); len(required) > 0 {
+		sort.Strings(required)
+		schema[JsonKey.Required] = required
+	}
+	return schema, nil
+}
+
+// OpenAPI schema object of attribute of doc. $ref to definition points at its component,
+// array keeps its items and map is object with additionalProperties of its items
+func AttrSchema(doc *SchemaDoc.SchemaDoc, attrDef map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := attrDef[JsonKey.Ref]; ok {
+		refName, err := SchemaDoc.ParseRefName(attrDef)
+		if err != nil {
+			return nil, err
+		}
+		refDoc, err := doc.GetDefinition(refName)
+		if err != nil {
+			return nil, err
+		}
+		if refDoc == nil {
+			return nil, fmt.Errorf("cannot find definition=[%s]", refName)
+		}
+		return componentRef(ComponentName(refDoc)), nil
+	}
+	schema := map[string]interface{}{}
+	for _, key := range passKeys {
+		if value, ok := attrDef[key]; ok {
+			schema[key] = value
+		}
+	}
+	attrType, _ := attrDef[JsonKey.Type].(string)
+	if attrType != "" {
+		schema[JsonKey.Type] = attrType
+	}
+	switch attrType {
+	case JsonKey.Array:
+		itemDef, ok := attrDef[JsonKey.Items].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("missing key=[%s] for %s", JsonKey.Items, JsonKey.Array)
+		}
+		itemSchema, err := AttrSchema(doc, itemDef)
+		if err != nil {
+			return nil, err
+		}
+		schema[JsonKey.Items] = itemSchema
+	case JsonKey.Object:
+		switch itemDef := attrDef[JsonKey.AdditionalProperties].(type) {
+		case bool:
+			schema[JsonKey.AdditionalProperties] = itemDef
+		case map[string]interface{}:
+			itemSchema, err := AttrSchema(doc, itemDef)
+			if err != nil {
+				return nil, err
+			}
+			schema[JsonKey.AdditionalProperties] = itemSchema
+		}
+	}
+	return schema, nil
+}
+
+// record of doc type as it is read and written, data in envelope of id, type and version
+func recordSchema(doc *SchemaDoc.SchemaDoc) map[string]interface{} {
+	stringSchema := map[string]interface{}{JsonKey.Type: JsonKey.String}
+	return map[string]interface{}{
+		JsonKey.Type: JsonKey.Object,
+		JsonKey.Properties: map[string]interface{}{
+			Record.DataId:   stringSchema,
+			Record.DataType: map[string]interface{}{JsonKey.Type: JsonKey.String, JsonKey.Const: doc.Id},
+			Record.Version:  stringSchema,
+			Record.Data:     componentRef(ComponentName(doc)),
+		},
+		JsonKey.Required: []string{Record.DataId, Record.DataType, Record.Version, Record.Data},
+	}
+}
+
+func errorSchema() map[string]interface{} {
+	return map[string]interface{}{
+		JsonKey.Type: JsonKey.Object,
+		JsonKey.Properties: map[string]interface{}{
+			"httpStatus": map[string]interface{}{JsonKey.Type: JsonKey.Integer},
+			"message":    map[string]interface{}{JsonKey.Type: JsonKey.Array, JsonKey.Items: map[string]interface{}{JsonKey.Type: JsonKey.String}},
+			"code":       map[string]interface{}{JsonKey.Type: JsonKey.Integer},
+			"context":    map[string]interface{}{JsonKey.Type: JsonKey.Array, JsonKey.Items: map[string]interface{}{JsonKey.Type: JsonKey.String}},
+			"payload":    map[string]interface{}{},
+		},
+	}
+}
+
+func jsonBody(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		JsonKey.Required: true,
+		"content":        jsonContent(schema),
+	}
+}
+
+// response of status with content, other status answers HttpError
+func responses(status int, description string, content map[string]interface{}) map[string]interface{} {
+	response := map[string]interface{}{"description": description}
+	if content != nil {
+		response["content"] = content
+	}
+	return map[string]interface{}{
+		strconv.Itoa(status): response,
+		"default": map[string]interface{}{
+			"description": "error",
+			"content":     jsonContent(componentRef(ErrorComponent)),
+		},
+	}
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		ContentJson: map[string]interface{}{"schema": schema},
+	}
+}
+
+func listPath(doc *SchemaDoc.SchemaDoc) map[string]interface{} {
+	return map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     fmt.Sprintf("list ids of %s", doc.Id),
+			"operationId": fmt.Sprintf("list%s", doc.Id),
+			"responses": responses(http.StatusOK, fmt.Sprintf("ids of %s", doc.Id), jsonContent(map[string]interface{}{
+				JsonKey.Type:  JsonKey.Array,
+				JsonKey.Items: map[string]interface{}{JsonKey.Type: JsonKey.String},
+			})),
+		},
+	}
+}
+
+func recordPath(doc *SchemaDoc.SchemaDoc) map[string]interface{} {
+	record := componentRef(doc.Id + RecordSuffix)
+	return map[string]interface{}{
+		"parameters": []interface{}{
+			map[string]interface{}{
+				"name":           "id",
+				"in":             "path",
+				JsonKey.Required: true,
+				"schema":         map[string]interface{}{JsonKey.Type: JsonKey.String},
+			},
+		},
+		"get": map[string]interface{}{
+			"summary":     fmt.Sprintf("get record of %s", doc.Id),
+			"operationId": fmt.Sprintf("get%s", doc.Id),
+			"responses":   responses(http.StatusOK, fmt.Sprintf("record of %s", doc.Id), jsonContent(record)),
+		},
+		"put": map[string]interface{}{
+			"summary":     fmt.Sprintf("add or replace record of %s", doc.Id),
+			"operationId": fmt.Sprintf("set%s", doc.Id),
+			"requestBody": jsonBody(record),
+			"responses":   responses(http.StatusCreated, "id of written record", nil),
+		},
+		"patch": map[string]interface{}{
+			"summary":     fmt.Sprintf("merge data into record of %s", doc.Id),
+			"operationId": fmt.Sprintf("patch%s", doc.Id),
+			"requestBody": map[string]interface{}{
+				JsonKey.Required: true,
+				"content": map[string]interface{}{
+					ContentMergePatch: map[string]interface{}{"schema": map[string]interface{}{JsonKey.Type: JsonKey.Object}},
+				},
+			},
+			"responses": responses(http.StatusAccepted, "patched record", jsonContent(record)),
+		},
+		"delete": map[string]interface{}{
+			"summary":     fmt.Sprintf("delete record of %s", doc.Id),
+			"operationId": fmt.Sprintf("delete%s", doc.Id),
+			"responses":   responses(http.StatusAccepted, "record deleted", nil),
+		},
+	}
+}
@@ -26,23 +26,33 @@ This copyright notice and license applies to all files in this directory or sub-
 package Record
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
 	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Json"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
 const (
-	Data      = "data"
-	DataId    = "__id"
-	DataType  = "__type"
-	KeyRecord = "record"
-	NotRecord = "No-Record-Framework"
-	Version   = "__ver"
-	Schema    = `{
+	Created    = "__created"
+	CreatedBy  = "__createdBy"
+	Data       = "data"
+	DataId     = "__id"
+	DataType   = "__type"
+	ExpireAt   = "__expireAt"
+	KeyRecord  = "record"
+	Modified   = "__modified"
+	ModifiedBy = "__modifiedBy"
+	NotRecord  = "No-Record-Framework"
+	Ttl        = "__ttl"
+	Version    = "__ver"
+	Schema     = `{
 		"__id": "record",
 		"__type": "schema",
 		"__ver": "0.0.1",
@@ -69,10 +79,17 @@ const (
 )
 
 type Record struct {
-	Id      string                 `json:"__id"`
-	Type    string                 `json:"__type"`
-	Version string                 `json:"__ver"`
-	Data    map[string]interface{} `json:"data"`
+	Id         string                 `json:"__id"`
+	Type       string                 `json:"__type"`
+	Version    string                 `json:"__ver"`
+	Created    string                 `json:"__created,omitempty"` // UTC time of first write in RFC3339Nano
+	CreatedBy  string                 `json:"__createdBy,omitempty"`
+	ModifiedBy string                 `json:"__modifiedBy,omitempty"`
+	Modified   string                 `json:"__modified,omitempty"` // UTC time of last write in RFC3339Nano
+	ExpireAt   string                 `json:"__expireAt,omitempty"`
+	Ttl        int                    `json:"__ttl,omitempty"`
+	Tenant     string                 `json:"-"` // tenant of the write, selects schema overlay on validation
+	Data       map[string]interface{} `json:"data"`
 }
 
 func IsRecord(data map[string]interface{}) bool {
@@ -94,6 +111,27 @@ func NewRecord(dataType string, typeVersion string, dataId string, data map[stri
 	return &record
 }
 
+// time of first write, zero time when record is not written yet
+func (rec *Record) CreatedTime() (time.Time, error) {
+	return parseStamp(Created, rec.Created)
+}
+
+// time of last write, zero time when record is not written yet
+func (rec *Record) ModifiedTime() (time.Time, error) {
+	return parseStamp(Modified, rec.Modified)
+}
+
+func parseStamp(key string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	stamp, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time [%s]=[%s]. Error: %s", key, value, err)
+	}
+	return stamp, nil
+}
+
 func ParseVersion(version string) ([]int, error) {
 	verList := []int{}
 	idx := 0
@@ -194,3 +232,18 @@ func (rec *Record) Map() map[string]interface{} {
 	json.Unmarshal([]byte(*rec.Raw()), &data)
 	return data
 }
+
+// serialize record with keys sorted recursively, equal records always give identical bytes
+func (rec *Record) CanonicalJSON() ([]byte, error) {
+	return Json.Canonical(rec)
+}
+
+// sha256 hex of canonical bytes of the record, for ETag and change detection
+func (rec *Record) Hash() (string, error) {
+	raw, err := rec.CanonicalJSON()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
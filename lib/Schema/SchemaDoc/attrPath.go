@@ -0,0 +1,104 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Util"
+)
+
+// check attribute path like obj/attr or list[key]/attr is defined by the schema, without any data.
+// check stops at a ref, rest of path belongs to schema of the ref target
+func (d *SchemaDoc) ValidatePath(dataPath string) error {
+	if dataPath == "" {
+		return nil
+	}
+	step, nextPath := Util.ParsePath(dataPath)
+	attrName, key, err := Util.ParseArrayPath(step)
+	if err != nil {
+		return err
+	}
+	attrDef, ok := d.Properties()[attrName].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("attribute [%s] is not defined. @[path]=[%s]", attrName, d.Path())
+	}
+	isCollection := attrDef[JsonKey.Type] == JsonKey.Array || IsMap(attrDef)
+	if key != "" && !isCollection {
+		return fmt.Errorf("attribute [%s] is not %s or %s, cannot take key [%s]. @[path]=[%s]", attrName, JsonKey.Array, JsonKey.Map, key, d.Path())
+	}
+	if nextPath == "" {
+		return nil
+	}
+	if isCollection && key == "" {
+		return fmt.Errorf("missing key of [%s] before [%s]. @[path]=[%s]", attrName, nextPath, d.Path())
+	}
+	if _, isRef := d.CmtRefs[attrName]; isRef {
+		return nil
+	}
+	subDoc, ok := d.SubDocs[attrName]
+	if !ok {
+		return fmt.Errorf("attribute [%s] has no attribute to walk into [%s]. @[path]=[%s]", attrName, nextPath, d.Path())
+	}
+	return subDoc.ValidatePath(nextPath)
+}
+
+const (
+	PathKeyTemplate = "{key}"
+	PathRefTemplate = "{ref:%s}"
+)
+
+// every attribute path the schema can address, like list[{key}]/attr.
+// path that crosses a ref ends with {ref:type}, which continues with path templates of the target type
+func (d *SchemaDoc) PathTemplates() []string {
+	pathList := d.pathTemplates("")
+	sort.Strings(pathList)
+	return pathList
+}
+
+func (d *SchemaDoc) pathTemplates(prefix string) []string {
+	pathList := []string{}
+	for attrName, def := range d.Properties() {
+		attrDef := def.(map[string]interface{})
+		attrPath := prefix + attrName
+		pathList = append(pathList, attrPath)
+		if attrDef[JsonKey.Type] == JsonKey.Array || IsMap(attrDef) {
+			attrPath = fmt.Sprintf("%s[%s]", attrPath, PathKeyTemplate)
+			pathList = append(pathList, attrPath)
+		}
+		if ref, isRef := d.CmtRefs[attrName]; isRef {
+			pathList = append(pathList, fmt.Sprintf("%s/"+PathRefTemplate, attrPath, strings.Join(ref.ContentTypes, JsonKey.ContentTypeDiv)))
+			continue
+		}
+		if subDoc, ok := d.SubDocs[attrName]; ok {
+			pathList = append(pathList, subDoc.pathTemplates(attrPath+"/")...)
+		}
+	}
+	return pathList
+}
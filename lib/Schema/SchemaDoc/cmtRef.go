@@ -0,0 +1,79 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Util"
+)
+
+// polymorphic ref lists more than one target type in contentMediaType, e.g. inventory/typeA|typeB
+func (r *CMTDocRef) IsPolymorphic() bool {
+	return len(r.ContentTypes) > 1
+}
+
+func (r *CMTDocRef) Allows(dataType string) bool {
+	for _, contentType := range r.ContentTypes {
+		if contentType == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// parse ref value into target type and id. polymorphic ref value is {type}/{id}
+func (r *CMTDocRef) Target(value string) (string, string, error) {
+	dataType, dataId, _, err := r.TargetPath(value)
+	return dataType, dataId, err
+}
+
+// parse ref value into target type, id and attribute path inside target record.
+// value is {id}[/{path}], polymorphic ref value is {type}/{id}[/{path}]
+func (r *CMTDocRef) TargetPath(value string) (string, string, string, error) {
+	if !r.IsPolymorphic() {
+		dataId, dataPath := Util.ParsePath(value)
+		return r.ContentType, dataId, dataPath, nil
+	}
+	dataType, idPath := Util.ParsePath(value)
+	dataId, dataPath := Util.ParsePath(idPath)
+	if dataType == "" || dataId == "" {
+		return "", "", "", fmt.Errorf("invalid polymorphic ref value=[%s], expect {type}/{id}", value)
+	}
+	if !r.Allows(dataType) {
+		return "", "", "", fmt.Errorf("ref type=[%s] of value=[%s] not in allowed types %s", dataType, value, r.ContentTypes)
+	}
+	return dataType, dataId, dataPath, nil
+}
+
+// build ref value that points to the given record
+func (r *CMTDocRef) Value(dataType string, dataId string) string {
+	if !r.IsPolymorphic() {
+		return dataId
+	}
+	return strings.Join([]string{dataType, dataId}, "/")
+}
@@ -0,0 +1,223 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+)
+
+// records of one type distinguished by value of a string attribute, like kind.
+// each value maps to a definition that declares the extra attributes of that kind
+//
+//	"discriminator": {
+//		"propertyName": "kind",
+//		"mapping": {"server": "#/definitions/server", "switch": "switch"}
+//	}
+type Discriminator struct {
+	Attr    string
+	Mapping map[string]*SchemaDoc // value of Attr -> doc merged from this doc and definition of the kind
+}
+
+func (d *SchemaDoc) processDiscriminator() error {
+	for _, defDoc := range d.Definitions {
+		err := defDoc.processDiscriminator()
+		if err != nil {
+			return err
+		}
+	}
+	discData, ok := d.Data[JsonKey.Discriminator]
+	if !ok {
+		return nil
+	}
+	discMap, ok := discData.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("[%s] is not an object @[path]=[%s]", JsonKey.Discriminator, d.Path())
+	}
+	attr, _ := discMap[JsonKey.PropertyName].(string)
+	if attr == "" {
+		return fmt.Errorf("missing [%s] in [%s] @[path]=[%s]", JsonKey.PropertyName, JsonKey.Discriminator, d.Path())
+	}
+	attrDef, ok := d.Properties()[attr].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("[%s]=[%s] not defined in [%s] @[path]=[%s]", JsonKey.PropertyName, attr, JsonKey.Properties, d.Path())
+	}
+	if attrDef[JsonKey.Type] != JsonKey.String {
+		return fmt.Errorf("[%s]=[%s] is not a [%s] attribute @[path]=[%s]", JsonKey.PropertyName, attr, JsonKey.String, d.Path())
+	}
+	mapping, ok := discMap[JsonKey.Mapping].(map[string]interface{})
+	if !ok || len(mapping) == 0 {
+		return fmt.Errorf("missing [%s] in [%s] @[path]=[%s]", JsonKey.Mapping, JsonKey.Discriminator, d.Path())
+	}
+	discriminator := Discriminator{
+		Attr:    attr,
+		Mapping: make(map[string]*SchemaDoc, len(mapping)),
+	}
+	valueList := make([]string, 0, len(mapping))
+	for value := range mapping {
+		valueList = append(valueList, value)
+	}
+	// sorted so compiled schema is stable
+	sort.Strings(valueList)
+	conditions, _ := d.Data[JsonKey.AllOf].([]interface{})
+	for _, value := range valueList {
+		defName, ok := mapping[value].(string)
+		if !ok {
+			return fmt.Errorf("[%s] of [%s] is not a string @[path]=[%s/%s]", value, JsonKey.Mapping, d.Path(), JsonKey.Discriminator)
+		}
+		defName = strings.TrimPrefix(defName, JsonKey.DefinitionPrefix)
+		kindDoc, err := d.GetDefinition(defName)
+		if err != nil || kindDoc == nil {
+			return fmt.Errorf("definition [%s] of [%s]=[%s] not found @[path]=[%s/%s]", defName, attr, value, d.Path(), JsonKey.Discriminator)
+		}
+		merged, err := d.mergeKind(kindDoc)
+		if err != nil {
+			return fmt.Errorf("failed to merge kind [%s]=[%s]. Error: %s", attr, value, err)
+		}
+		discriminator.Mapping[value] = merged
+		// let JSON schema validation check attributes of the kind when value matches
+		then := map[string]interface{}{
+			JsonKey.Properties: kindDoc.Properties(),
+		}
+		if required, ok := kindDoc.Data[JsonKey.Required]; ok {
+			then[JsonKey.Required] = required
+		}
+		conditions = append(conditions, map[string]interface{}{
+			JsonKey.If: map[string]interface{}{
+				JsonKey.Properties: map[string]interface{}{
+					attr: map[string]interface{}{
+						JsonKey.Const: value,
+					},
+				},
+				JsonKey.Required: []interface{}{attr},
+			},
+			JsonKey.Then: then,
+		})
+	}
+	d.Data[JsonKey.AllOf] = conditions
+	d.Discriminator = &discriminator
+	return nil
+}
+
+// doc with attributes, sub docs, refs and rules of both d and kind
+func (d *SchemaDoc) mergeKind(kind *SchemaDoc) (*SchemaDoc, error) {
+	merged := *d
+	merged.Discriminator = nil
+	merged.Data = make(map[string]interface{}, len(d.Data))
+	for key, value := range d.Data {
+		merged.Data[key] = value
+	}
+	properties := make(map[string]interface{}, len(d.Properties())+len(kind.Properties()))
+	for attr, attrDef := range d.Properties() {
+		properties[attr] = attrDef
+	}
+	for attr, attrDef := range kind.Properties() {
+		if _, ok := properties[attr]; ok {
+			return nil, fmt.Errorf("attr [%s] of [%s] already defined @[path]=[%s]", attr, kind.Path(), d.Path())
+		}
+		properties[attr] = attrDef
+	}
+	merged.Data[JsonKey.Properties] = properties
+	baseRequired, _ := d.Data[JsonKey.Required].([]interface{})
+	kindRequired, _ := kind.Data[JsonKey.Required].([]interface{})
+	required := make([]interface{}, 0, len(baseRequired)+len(kindRequired))
+	merged.Data[JsonKey.Required] = append(append(required, baseRequired...), kindRequired...)
+	merged.RAW = make(map[string]interface{}, len(d.RAW))
+	for key, value := range d.RAW {
+		merged.RAW[key] = value
+	}
+	rawProperties := map[string]interface{}{}
+	for _, raw := range []map[string]interface{}{d.RAW, kind.RAW} {
+		if rawMap, ok := raw[JsonKey.Properties].(map[string]interface{}); ok {
+			for attr, attrDef := range rawMap {
+				rawProperties[attr] = attrDef
+			}
+		}
+	}
+	merged.RAW[JsonKey.Properties] = rawProperties
+	merged.SubDocs = mergeDocMap(d.SubDocs, kind.SubDocs)
+	merged.CmtRefs = make(map[string]*CMTDocRef, len(d.CmtRefs)+len(kind.CmtRefs))
+	for _, refs := range []map[string]*CMTDocRef{d.CmtRefs, kind.CmtRefs} {
+		for attr, ref := range refs {
+			merged.CmtRefs[attr] = ref
+		}
+	}
+	merged.Rules = append(append([]*Rule{}, d.Rules...), kind.Rules...)
+	merged.WarnRules = append(append([]*Rule{}, d.WarnRules...), kind.WarnRules...)
+	return &merged, nil
+}
+
+func mergeDocMap(base map[string]*SchemaDoc, kind map[string]*SchemaDoc) map[string]*SchemaDoc {
+	merged := make(map[string]*SchemaDoc, len(base)+len(kind))
+	for _, docMap := range []map[string]*SchemaDoc{base, kind} {
+		for attr, doc := range docMap {
+			merged[attr] = doc
+		}
+	}
+	return merged
+}
+
+// doc that describes data, selected by value of discriminator attribute.
+// d itself when it has no discriminator or data does not carry the attribute
+func (d *SchemaDoc) KindDoc(data map[string]interface{}) (*SchemaDoc, error) {
+	if d.Discriminator == nil {
+		return d, nil
+	}
+	value, ok := data[d.Discriminator.Attr]
+	if !ok || value == nil {
+		return d, nil
+	}
+	valueStr, _ := value.(string)
+	kindDoc, ok := d.Discriminator.Mapping[valueStr]
+	if !ok {
+		valueList := make([]string, 0, len(d.Discriminator.Mapping))
+		for kind := range d.Discriminator.Mapping {
+			valueList = append(valueList, kind)
+		}
+		sort.Strings(valueList)
+		return nil, fmt.Errorf("no sub-schema for [%s]=[%v], expect one of %v", d.Discriminator.Attr, value, valueList)
+	}
+	return kindDoc, nil
+}
+
+// same as KindDoc for walks on stored data, falls back to d when data is not an object or kind is unknown
+func (d *SchemaDoc) DocOf(data interface{}) *SchemaDoc {
+	if d == nil || d.Discriminator == nil {
+		return d
+	}
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return d
+	}
+	kindDoc, err := d.KindDoc(dataMap)
+	if err != nil {
+		return d
+	}
+	return kindDoc
+}
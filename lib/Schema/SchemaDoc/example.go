@@ -0,0 +1,137 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+)
+
+// examples are declared as strings on property, parsed here into the type of the property.
+// example that cannot be parsed, or not allowed by [enum], is left out with a warning instead of failing the schema
+func parseExamples(data map[string]interface{}, docPath string) (map[string][]interface{}, []string) {
+	examples := map[string][]interface{}{}
+	warnings := []string{}
+	properties, ok := data[JsonKey.Properties].(map[string]interface{})
+	if !ok {
+		return examples, warnings
+	}
+	for attr, prop := range properties {
+		attrDef, ok := prop.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		exampleList, ok := attrDef[JsonKey.Examples].([]interface{})
+		if !ok {
+			continue
+		}
+		valueList := make([]interface{}, 0, len(exampleList))
+		for idx, example := range exampleList {
+			exampleStr, ok := example.(string)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("example @[%d] is not a string, path=[%s/%s]", idx, docPath, attr))
+				continue
+			}
+			value, err := ParseExample(attrDef, exampleStr)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("invalid example [%s], Error: %s, path=[%s/%s]", exampleStr, err, docPath, attr))
+				continue
+			}
+			valueList = append(valueList, value)
+		}
+		examples[attr] = valueList
+	}
+	sort.Strings(warnings)
+	return examples, warnings
+}
+
+func ParseExample(attrDef map[string]interface{}, example string) (interface{}, error) {
+	attrType, _ := attrDef[JsonKey.Type].(string)
+	var value interface{}
+	switch attrType {
+	case JsonKey.String:
+		value = example
+	case JsonKey.Integer:
+		intValue, err := strconv.ParseInt(example, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("not an %s", attrType)
+		}
+		value = float64(intValue)
+	case JsonKey.Number:
+		numValue, err := strconv.ParseFloat(example, 64)
+		if err != nil {
+			return nil, fmt.Errorf("not a %s", attrType)
+		}
+		value = numValue
+	case JsonKey.Boolean:
+		boolValue, err := strconv.ParseBool(example)
+		if err != nil {
+			return nil, fmt.Errorf("not a %s", attrType)
+		}
+		value = boolValue
+	case JsonKey.Array:
+		listValue := []interface{}{}
+		err := json.Unmarshal([]byte(example), &listValue)
+		if err != nil {
+			return nil, fmt.Errorf("not a JSON %s", attrType)
+		}
+		value = listValue
+	default:
+		mapValue := map[string]interface{}{}
+		err := json.Unmarshal([]byte(example), &mapValue)
+		if err != nil {
+			return nil, fmt.Errorf("not a JSON %s", JsonKey.Object)
+		}
+		value = mapValue
+	}
+	if enum, ok := attrDef[JsonKey.Enum].([]interface{}); ok {
+		for _, allowed := range enum {
+			if allowed == value {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("not in %s %v", JsonKey.Enum, enum)
+	}
+	return value, nil
+}
+
+// warnings of doc and all its definitions
+func (d *SchemaDoc) AllWarnings() []string {
+	warnings := append([]string{}, d.Warnings...)
+	defNames := make([]string, 0, len(d.Definitions))
+	for name := range d.Definitions {
+		defNames = append(defNames, name)
+	}
+	sort.Strings(defNames)
+	for _, name := range defNames {
+		warnings = append(warnings, d.Definitions[name].AllWarnings()...)
+	}
+	return warnings
+}
@@ -0,0 +1,161 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+)
+
+const FlatColumnDiv = "."
+
+// flatten record data into column of dotted path to scalar value, like obj.attr or list[key].attr.
+// item of array or map of object takes its key in column, item of simple array takes its value
+func (d *SchemaDoc) FlattenRecord(data map[string]interface{}) (map[string]interface{}, error) {
+	row := map[string]interface{}{}
+	err := d.flatten(data, "", row)
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (d *SchemaDoc) flatten(data map[string]interface{}, prefix string, row map[string]interface{}) error {
+	doc, err := d.KindDoc(data)
+	if err != nil {
+		return fmt.Errorf("failed to flatten [%s]. Error: %s", prefix, err)
+	}
+	props := doc.Properties()
+	for attrName, value := range data {
+		attrDef, ok := props[attrName].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("attribute [%s] is not defined. @[path]=[%s]", attrName, doc.Path())
+		}
+		column := prefix + attrName
+		if value == nil {
+			row[column] = nil
+			continue
+		}
+		subDoc, hasDoc := doc.SubDocs[attrName]
+		switch attrDef[JsonKey.Type] {
+		case JsonKey.Array:
+			itemList, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("attribute [%s] is not %s. @[path]=[%s]", attrName, JsonKey.Array, doc.Path())
+			}
+			for idx, item := range itemList {
+				itemData, isObj := item.(map[string]interface{})
+				if !isObj || !hasDoc {
+					flattenFree(item, fmt.Sprintf("%s[%v]", column, item), row)
+					continue
+				}
+				key, err := subDoc.DocOf(itemData).BuildKey(itemData)
+				if err != nil {
+					return fmt.Errorf("failed to build key of [%s][%d]. @[path]=[%s], Error: %s", attrName, idx, doc.Path(), err)
+				}
+				err = subDoc.flatten(itemData, fmt.Sprintf("%s[%s]%s", column, key, FlatColumnDiv), row)
+				if err != nil {
+					return err
+				}
+			}
+		case JsonKey.Object:
+			objData, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("attribute [%s] is not %s. @[path]=[%s]", attrName, JsonKey.Object, doc.Path())
+			}
+			if !IsMap(attrDef) {
+				if !hasDoc {
+					flattenFree(objData, column, row)
+					continue
+				}
+				err := subDoc.flatten(objData, column+FlatColumnDiv, row)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			for key, item := range objData {
+				itemColumn := fmt.Sprintf("%s[%s]", column, key)
+				itemData, isObj := item.(map[string]interface{})
+				if !isObj || !hasDoc {
+					flattenFree(item, itemColumn, row)
+					continue
+				}
+				err := subDoc.flatten(itemData, itemColumn+FlatColumnDiv, row)
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			row[column] = value
+		}
+	}
+	return nil
+}
+
+// value without schema, like free form object, flattened by its own keys and index
+func flattenFree(value interface{}, column string, row map[string]interface{}) {
+	switch data := value.(type) {
+	case map[string]interface{}:
+		for key, item := range data {
+			flattenFree(item, column+FlatColumnDiv+key, row)
+		}
+	case []interface{}:
+		for idx, item := range data {
+			flattenFree(item, fmt.Sprintf("%s[%d]", column, idx), row)
+		}
+	default:
+		row[column] = value
+	}
+}
+
+// columns every record of the schema can have, scalar attributes reached through objects but not through array or map
+func (d *SchemaDoc) FlatColumns() []string {
+	columns := d.flatColumns("")
+	sort.Strings(columns)
+	return columns
+}
+
+func (d *SchemaDoc) flatColumns(prefix string) []string {
+	columns := []string{}
+	for attrName, def := range d.Properties() {
+		attrDef := def.(map[string]interface{})
+		column := prefix + attrName
+		switch attrDef[JsonKey.Type] {
+		case JsonKey.Array:
+			continue
+		case JsonKey.Object:
+			if subDoc, ok := d.SubDocs[attrName]; ok && !IsMap(attrDef) && !d.IsAncestor(subDoc.Id) {
+				columns = append(columns, subDoc.flatColumns(column+FlatColumnDiv)...)
+			}
+			continue
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
@@ -0,0 +1,287 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cross field rule, like [startPort <= endPort and protocol != "udp"].
+// comparisons are joined by [and]/[or], [and] binds tighter than [or].
+// each side of a comparison is a sibling attribute name or a literal of number, "string", true or false.
+// comparison with missing attribute is skipped as satisfied, use required to enforce existence.
+type Rule struct {
+	Expr string
+	any  [][]*ruleCompare
+}
+
+type ruleCompare struct {
+	left  ruleOperand
+	op    string
+	right ruleOperand
+}
+
+type ruleOperand struct {
+	attr    string
+	literal interface{}
+}
+
+var ruleOps = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+func ParseRule(expr string) (*Rule, error) {
+	tokens, err := ruleTokens(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule [%s], Error: %s", expr, err)
+	}
+	rule := Rule{
+		Expr: expr,
+		any:  [][]*ruleCompare{},
+	}
+	all := []*ruleCompare{}
+	for idx := 0; idx < len(tokens); {
+		if idx+3 > len(tokens) {
+			return nil, fmt.Errorf("invalid rule [%s], incomplete comparison", expr)
+		}
+		compare, err := newRuleCompare(tokens[idx], tokens[idx+1], tokens[idx+2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule [%s], Error: %s", expr, err)
+		}
+		all = append(all, compare)
+		idx += 3
+		if idx == len(tokens) {
+			break
+		}
+		switch tokens[idx] {
+		case "and":
+		case "or":
+			rule.any = append(rule.any, all)
+			all = []*ruleCompare{}
+		default:
+			return nil, fmt.Errorf("invalid rule [%s], expect [and/or] got [%s]", expr, tokens[idx])
+		}
+		idx++
+		if idx == len(tokens) {
+			return nil, fmt.Errorf("invalid rule [%s], missing comparison after [%s]", expr, tokens[idx-1])
+		}
+	}
+	if len(all) == 0 {
+		return nil, fmt.Errorf("invalid rule [%s], no comparison", expr)
+	}
+	rule.any = append(rule.any, all)
+	return &rule, nil
+}
+
+func ruleTokens(expr string) ([]string, error) {
+	tokens := []string{}
+	for idx := 0; idx < len(expr); {
+		c := expr[idx]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			idx++
+		case c == '"':
+			end := idx + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("string literal not closed")
+			}
+			tokens = append(tokens, expr[idx:end+1])
+			idx = end + 1
+		case strings.ContainsRune("<>=!", rune(c)):
+			end := idx + 1
+			if end < len(expr) && expr[end] == '=' {
+				end++
+			}
+			tokens = append(tokens, expr[idx:end])
+			idx = end
+		default:
+			end := idx
+			for end < len(expr) && !strings.ContainsRune(" \t\n\"<>=!", rune(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, expr[idx:end])
+			idx = end
+		}
+	}
+	return tokens, nil
+}
+
+func newRuleCompare(left string, op string, right string) (*ruleCompare, error) {
+	validOp := false
+	for _, ruleOp := range ruleOps {
+		if op == ruleOp {
+			validOp = true
+			break
+		}
+	}
+	if !validOp {
+		return nil, fmt.Errorf("unknown operator [%s], expect one of %s", op, ruleOps)
+	}
+	leftOperand, err := newRuleOperand(left)
+	if err != nil {
+		return nil, err
+	}
+	rightOperand, err := newRuleOperand(right)
+	if err != nil {
+		return nil, err
+	}
+	return &ruleCompare{
+		left:  leftOperand,
+		op:    op,
+		right: rightOperand,
+	}, nil
+}
+
+func newRuleOperand(token string) (ruleOperand, error) {
+	switch {
+	case strings.HasPrefix(token, "\""):
+		var value string
+		err := json.Unmarshal([]byte(token), &value)
+		if err != nil {
+			return ruleOperand{}, fmt.Errorf("invalid string literal [%s]", token)
+		}
+		return ruleOperand{literal: value}, nil
+	case token == "true" || token == "false":
+		return ruleOperand{literal: token == "true"}, nil
+	case token == "and" || token == "or":
+		return ruleOperand{}, fmt.Errorf("unexpected keyword [%s]", token)
+	}
+	if number, err := strconv.ParseFloat(token, 64); err == nil {
+		return ruleOperand{literal: number}, nil
+	}
+	return ruleOperand{attr: token}, nil
+}
+
+func (o ruleOperand) value(data map[string]interface{}) (interface{}, bool) {
+	if o.attr == "" {
+		return o.literal, true
+	}
+	value, ok := data[o.attr]
+	if !ok || value == nil {
+		return nil, false
+	}
+	if number, ok := value.(int); ok {
+		return float64(number), true
+	}
+	return value, true
+}
+
+// attribute names referred by the rule
+func (r *Rule) Attrs() []string {
+	attrs := []string{}
+	for _, all := range r.any {
+		for _, compare := range all {
+			for _, operand := range []ruleOperand{compare.left, compare.right} {
+				if operand.attr != "" {
+					attrs = append(attrs, operand.attr)
+				}
+			}
+		}
+	}
+	return attrs
+}
+
+func (r *Rule) Evaluate(data map[string]interface{}) (bool, error) {
+	for _, all := range r.any {
+		match := true
+		for _, compare := range all {
+			ok, err := compare.evaluate(data)
+			if err != nil {
+				return false, fmt.Errorf("failed to evaluate rule [%s], Error: %s", r.Expr, err)
+			}
+			if !ok {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *ruleCompare) evaluate(data map[string]interface{}) (bool, error) {
+	left, ok := c.left.value(data)
+	if !ok {
+		return true, nil
+	}
+	right, ok := c.right.value(data)
+	if !ok {
+		return true, nil
+	}
+	switch leftValue := left.(type) {
+	case float64:
+		rightValue, ok := right.(float64)
+		if !ok {
+			return false, fmt.Errorf("cannot compare number [%v] with [%v]", left, right)
+		}
+		return compareOrdered(leftValue, rightValue, c.op), nil
+	case string:
+		rightValue, ok := right.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare string [%v] with [%v]", left, right)
+		}
+		return compareOrdered(leftValue, rightValue, c.op), nil
+	case bool:
+		rightValue, ok := right.(bool)
+		if !ok {
+			return false, fmt.Errorf("cannot compare bool [%v] with [%v]", left, right)
+		}
+		switch c.op {
+		case "==":
+			return leftValue == rightValue, nil
+		case "!=":
+			return leftValue != rightValue, nil
+		}
+		return false, fmt.Errorf("operator [%s] not supported on bool", c.op)
+	}
+	return false, fmt.Errorf("value [%v] is not comparable", left)
+}
+
+func compareOrdered[T float64 | string](left T, right T, op string) bool {
+	switch op {
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	case ">=":
+		return left >= right
+	case "==":
+		return left == right
+	default:
+		return left != right
+	}
+}
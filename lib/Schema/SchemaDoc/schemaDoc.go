@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"path"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -40,15 +41,23 @@ import (
 )
 
 type SchemaDoc struct {
-	Id          string
-	Version     string
-	Parent      *SchemaDoc
-	KeyTemplate *Template.StrTemp
-	Data        map[string]interface{}
-	Definitions map[string]*SchemaDoc
-	CmtRefs     map[string]*CMTDocRef
-	SubDocs     map[string]*SchemaDoc
-	RAW         map[string]interface{}
+	Id            string
+	Version       string
+	Parent        *SchemaDoc
+	KeyTemplate   *Template.StrTemp
+	Data          map[string]interface{}
+	Definitions   map[string]*SchemaDoc
+	CmtRefs       map[string]*CMTDocRef
+	SubDocs       map[string]*SchemaDoc
+	ExternalRefs  map[string]string
+	Rules         []*Rule
+	WarnRules     []*Rule
+	Ttl           int
+	TtlAttr       string
+	Examples      map[string][]interface{}
+	Discriminator *Discriminator
+	Warnings      []string
+	RAW           map[string]interface{}
 }
 
 type CMTDocRef struct {
@@ -56,6 +65,7 @@ type CMTDocRef struct {
 	Name          string
 	CmtType       string
 	ContentType   string
+	ContentTypes  []string
 	IndexTemplate string
 }
 
@@ -98,14 +108,30 @@ func create(data map[string]interface{}, id string, parent *SchemaDoc) (*SchemaD
 		return nil, fmt.Errorf("invalid template=[%s], Error:%s", keyTemplate, err)
 	}
 	doc := SchemaDoc{
-		Id:          id,
-		Version:     version,
-		Parent:      parent,
-		Data:        data,
-		KeyTemplate: template,
-		CmtRefs:     map[string]*CMTDocRef{},
-		SubDocs:     map[string]*SchemaDoc{},
+		Id:           id,
+		Version:      version,
+		Parent:       parent,
+		Data:         data,
+		KeyTemplate:  template,
+		CmtRefs:      map[string]*CMTDocRef{},
+		SubDocs:      map[string]*SchemaDoc{},
+		ExternalRefs: map[string]string{},
+	}
+	rules, err := parseRules(data, JsonKey.Rules, fmt.Sprintf("%s/%s", parentPath, id))
+	if err != nil {
+		return nil, err
+	}
+	doc.Rules = rules
+	warnRules, err := parseRules(data, JsonKey.WarnRules, fmt.Sprintf("%s/%s", parentPath, id))
+	if err != nil {
+		return nil, err
 	}
+	doc.WarnRules = warnRules
+	doc.Ttl, doc.TtlAttr, err = parseTtl(data, fmt.Sprintf("%s/%s", parentPath, id))
+	if err != nil {
+		return nil, err
+	}
+	doc.Examples, doc.Warnings = parseExamples(data, fmt.Sprintf("%s/%s", parentPath, id))
 	if parent == nil {
 		rawDataIface, err := Json.Copy(data)
 		if err != nil {
@@ -142,6 +168,46 @@ func create(data map[string]interface{}, id string, parent *SchemaDoc) (*SchemaD
 	return &doc, nil
 }
 
+func parseRules(data map[string]interface{}, ruleKey string, docPath string) ([]*Rule, error) {
+	ruleList, ok := data[ruleKey].([]interface{})
+	if !ok {
+		return []*Rule{}, nil
+	}
+	properties := data[JsonKey.Properties].(map[string]interface{})
+	rules := make([]*Rule, 0, len(ruleList))
+	for idx, item := range ruleList {
+		expr, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("rule @[%d] is not a string, path=[%s/%s]", idx, docPath, ruleKey)
+		}
+		rule, err := ParseRule(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s, path=[%s/%s]", err, docPath, ruleKey)
+		}
+		for _, attr := range rule.Attrs() {
+			if _, ok := properties[attr]; !ok {
+				return nil, fmt.Errorf("rule [%s] use undefined attr [%s], path=[%s/%s]", expr, attr, docPath, ruleKey)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// check cross field rules of doc against data on the same level
+func (d *SchemaDoc) ValidateRules(data map[string]interface{}) error {
+	for _, rule := range d.Rules {
+		ok, err := rule.Evaluate(data)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("rule [%s] violated", rule.Expr)
+		}
+	}
+	return nil
+}
+
 func FromString(data string) (*SchemaDoc, error) {
 	dataObj := map[string]interface{}{}
 	err := json.Unmarshal([]byte(data), &dataObj)
@@ -165,6 +231,10 @@ func New(data map[string]interface{}) (*SchemaDoc, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = doc.processDiscriminator()
+	if err != nil {
+		return nil, fmt.Errorf("failed @processDiscriminator, Err:\n%s", err)
+	}
 	return doc, nil
 }
 
@@ -220,6 +290,17 @@ func (d *SchemaDoc) Properties() map[string]interface{} {
 	return d.Data[JsonKey.Properties].(map[string]interface{})
 }
 
+// sorted names of required attributes, available after preprocess
+func (d *SchemaDoc) RequiredAttrs() []string {
+	requiredList, _ := d.Data[JsonKey.Required].([]interface{})
+	attrList := make([]string, 0, len(requiredList))
+	for _, attr := range requiredList {
+		attrList = append(attrList, attr.(string))
+	}
+	sort.Strings(attrList)
+	return attrList
+}
+
 func (d *SchemaDoc) preprocess() error {
 	err := d.processRequired()
 	if err != nil {
@@ -237,6 +318,14 @@ func (d *SchemaDoc) preprocess() error {
 	if err != nil {
 		return fmt.Errorf("preprocess failed @processInvRefs, [path]=[%s], Error:%s", d.Path(), err)
 	}
+	err = d.validateAppendOnly()
+	if err != nil {
+		return err
+	}
+	err = d.validateDefaults()
+	if err != nil {
+		return err
+	}
 	err = d.validateKeyAttrs()
 	if err != nil {
 		return fmt.Errorf("validate Key Attributes failed. [path]=[%s] Error: %s", d.Path(), err)
@@ -252,6 +341,37 @@ func (d *SchemaDoc) preprocess() error {
 	return nil
 }
 
+// default is declared as string like examples and must parse into the type of the prop
+func (d *SchemaDoc) validateDefaults() error {
+	for attr, prop := range d.Data[JsonKey.Properties].(map[string]interface{}) {
+		propDef := prop.(map[string]interface{})
+		defValue, ok := propDef[JsonKey.Default]
+		if !ok {
+			continue
+		}
+		defStr, ok := defValue.(string)
+		if !ok {
+			return fmt.Errorf("[%s] must be a string, attr=[%s] @path=[%s]", JsonKey.Default, attr, d.Path())
+		}
+		_, err := ParseExample(propDef, defStr)
+		if err != nil {
+			return fmt.Errorf("invalid [%s]=[%s], Error: %s, attr=[%s] @path=[%s]", JsonKey.Default, defStr, err, attr, d.Path())
+		}
+	}
+	return nil
+}
+
+// appendOnly only makes sense on array, items can only be added after the stored ones
+func (d *SchemaDoc) validateAppendOnly() error {
+	for attr, prop := range d.Data[JsonKey.Properties].(map[string]interface{}) {
+		propDef := prop.(map[string]interface{})
+		if appendOnly, _ := propDef[JsonKey.AppendOnly].(bool); appendOnly && propDef[JsonKey.Type] != JsonKey.Array {
+			return fmt.Errorf("[%s] only works on type [%s], attr=[%s] is [%v] @path=[%s]", JsonKey.AppendOnly, JsonKey.Array, attr, propDef[JsonKey.Type], d.Path())
+		}
+	}
+	return nil
+}
+
 func (d *SchemaDoc) processAttrInvalidKeyChar() error {
 	propPath := path.Join(d.Path(), JsonKey.Properties)
 	propMap := d.Data[JsonKey.Properties].(map[string]interface{})
@@ -462,10 +582,24 @@ func (d *SchemaDoc) getCmtRef(pname string, prop map[string]interface{}) error {
 			Name:          pname,
 			CmtType:       JsonKey.Inventory,
 			ContentType:   dataType,
+			ContentTypes:  []string{dataType},
 			IndexTemplate: "",
 		}
+		if strings.Contains(dataType, JsonKey.ContentTypeDiv) {
+			// polymorphic ref, value carries the target type as {type}/{id}
+			ref.ContentType = ""
+			ref.ContentTypes = strings.Split(dataType, JsonKey.ContentTypeDiv)
+			for _, contentType := range ref.ContentTypes {
+				if contentType == "" {
+					return fmt.Errorf("[%s]=[%s] has empty type. @[path]=[%s/%s]", JsonKey.ContentMediaType, cmt, d.Path(), pname)
+				}
+			}
+		}
 		idxTemp, ok := prop[JsonKey.IndexTemplate]
 		if ok {
+			if ref.IsPolymorphic() {
+				return fmt.Errorf("[%s] not supported on polymorphic ref [%s]=[%s]. @[path]=[%s/%s]", JsonKey.IndexTemplate, JsonKey.ContentMediaType, cmt, d.Path(), pname)
+			}
 			ref.IndexTemplate = idxTemp.(string)
 		}
 		d.CmtRefs[ref.Name] = &ref
@@ -475,6 +609,11 @@ func (d *SchemaDoc) getCmtRef(pname string, prop map[string]interface{}) error {
 	}
 }
 
+// ref into another schema document, as {schemaId} or {schemaId}#/definitions/{name}
+func IsExternalRef(ref string) bool {
+	return ref != "" && !strings.HasPrefix(ref, JsonKey.DocRoot)
+}
+
 func ParseRefName(prop map[string]interface{}) (string, error) {
 	ref, ok := prop[JsonKey.Ref].(string)
 	if !ok {
@@ -492,6 +631,11 @@ func ParseRefName(prop map[string]interface{}) (string, error) {
 
 // get referenced sub definition
 func (d *SchemaDoc) getRefDoc(pname string, prop map[string]interface{}) error {
+	if ref, ok := prop[JsonKey.Ref].(string); ok && IsExternalRef(ref) {
+		// no sub doc until ref is resolved by Schema.ResolveExternalRefs
+		d.ExternalRefs[pname] = ref
+		return nil
+	}
 	refType, err := ParseRefName(prop)
 	if err != nil {
 		return fmt.Errorf("failed to parse ref. @path=[%s/%s/%s], Error: %s", d.Path(), pname, JsonKey.Ref, err)
@@ -587,6 +731,20 @@ func IsMap(attrDef map[string]interface{}) bool {
 	return true
 }
 
+// allowed values declared by [enum] of attribute, array attribute use [enum] of its items
+func AttrEnum(attrDef map[string]interface{}) ([]interface{}, bool) {
+	if enum, ok := attrDef[JsonKey.Enum].([]interface{}); ok {
+		return enum, true
+	}
+	if attrDef[JsonKey.Type] == JsonKey.Array {
+		if itemDef, ok := attrDef[JsonKey.Items].(map[string]interface{}); ok {
+			enum, ok := itemDef[JsonKey.Enum].([]interface{})
+			return enum, ok
+		}
+	}
+	return nil, false
+}
+
 func IsCmtRef(attrDef map[string]interface{}) bool {
 	if attrDef[JsonKey.Type] != JsonKey.String {
 		return false
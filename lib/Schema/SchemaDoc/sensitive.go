@@ -0,0 +1,58 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+)
+
+// names of attributes with sensitive=true in schema, its definitions and sub docs.
+// value of such attribute must not show up in logs
+func (d *SchemaDoc) SensitiveAttrs() map[string]bool {
+	names := map[string]bool{}
+	d.collectSensitive(names, map[*SchemaDoc]bool{})
+	return names
+}
+
+func (d *SchemaDoc) collectSensitive(names map[string]bool, visited map[*SchemaDoc]bool) {
+	if visited[d] {
+		return
+	}
+	visited[d] = true
+	properties, _ := d.Data[JsonKey.Properties].(map[string]interface{})
+	for attr, prop := range properties {
+		propDef, _ := prop.(map[string]interface{})
+		if sensitive, _ := propDef[JsonKey.Sensitive].(bool); sensitive {
+			names[attr] = true
+		}
+	}
+	for _, subDoc := range d.SubDocs {
+		subDoc.collectSensitive(names, visited)
+	}
+	for _, defDoc := range d.Definitions {
+		defDoc.collectSensitive(names, visited)
+	}
+}
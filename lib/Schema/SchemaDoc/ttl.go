@@ -0,0 +1,75 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"fmt"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+)
+
+// [ttl] is fixed lifetime in seconds of every record of the type.
+// [ttlAttr] names an integer attribute that carries lifetime of each record, falls back to [ttl] when not set.
+func parseTtl(data map[string]interface{}, docPath string) (int, string, error) {
+	ttl := 0
+	if ttlValue, ok := data[JsonKey.Ttl]; ok {
+		ttlNum, ok := ttlValue.(float64)
+		if !ok || ttlNum < 0 || ttlNum != float64(int(ttlNum)) {
+			return 0, "", fmt.Errorf("invalid [%s]=[%v], expect non-negative integer, path=[%s]", JsonKey.Ttl, ttlValue, docPath)
+		}
+		ttl = int(ttlNum)
+	}
+	ttlAttr, ok := data[JsonKey.TtlAttr].(string)
+	if !ok || ttlAttr == "" {
+		return ttl, "", nil
+	}
+	properties := data[JsonKey.Properties].(map[string]interface{})
+	attrDef, ok := properties[ttlAttr].(map[string]interface{})
+	if !ok {
+		return 0, "", fmt.Errorf("[%s]=[%s] is not a defined attr, path=[%s]", JsonKey.TtlAttr, ttlAttr, docPath)
+	}
+	if attrDef[JsonKey.Type] != JsonKey.Integer {
+		return 0, "", fmt.Errorf("[%s]=[%s] is not an attr of type [%s], path=[%s]", JsonKey.TtlAttr, ttlAttr, JsonKey.Integer, docPath)
+	}
+	return ttl, ttlAttr, nil
+}
+
+// lifetime in seconds of record data, 0 means record never expires
+func (d *SchemaDoc) RecordTtl(data map[string]interface{}) int {
+	if d.TtlAttr != "" {
+		switch ttl := data[d.TtlAttr].(type) {
+		case float64:
+			if ttl > 0 {
+				return int(ttl)
+			}
+		case int:
+			if ttl > 0 {
+				return ttl
+			}
+		}
+	}
+	return d.Ttl
+}
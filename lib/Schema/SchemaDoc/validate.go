@@ -0,0 +1,181 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+)
+
+// result of checking data against doc.
+// errors should block the write, warnings are advisory and only reported back
+type ValidateResult struct {
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+func (r *ValidateResult) Ok() bool {
+	return len(r.Errors) == 0
+}
+
+// check rules and warnRules of doc and sub docs against data.
+// value of attribute marked as deprecated and attribute not defined in properties are reported as warning
+func (d *SchemaDoc) Validate(data map[string]interface{}, dataPath string) *ValidateResult {
+	result := &ValidateResult{
+		Errors:   []string{},
+		Warnings: []string{},
+	}
+	d.validate(data, dataPath, result)
+	return result
+}
+
+func (d *SchemaDoc) validate(data map[string]interface{}, dataPath string, result *ValidateResult) {
+	d = d.DocOf(data)
+	for _, rule := range d.Rules {
+		ok, err := rule.Evaluate(data)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s @path=[%s]", err, dataPath))
+			continue
+		}
+		if !ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("rule [%s] violated @path=[%s]", rule.Expr, dataPath))
+		}
+	}
+	for _, rule := range d.WarnRules {
+		ok, err := rule.Evaluate(data)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s @path=[%s]", err, dataPath))
+			continue
+		}
+		if !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("rule [%s] not met @path=[%s]", rule.Expr, dataPath))
+		}
+	}
+	properties := d.Properties()
+	// attr not in properties is kept and reported, unless schema sets additionalProperties=false to reject it
+	if allowed, ok := d.Data[JsonKey.AdditionalProperties].(bool); !ok || allowed {
+		unknownList := []string{}
+		for attr := range data {
+			if _, ok := properties[attr]; !ok {
+				unknownList = append(unknownList, attr)
+			}
+		}
+		sort.Strings(unknownList)
+		for _, attr := range unknownList {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("attr [%s] is not defined in schema [%s] @path=[%s/%s]", attr, d.Id, dataPath, attr))
+		}
+	}
+	// sorted so warnings come back in stable order
+	attrList := make([]string, 0, len(properties))
+	for attr := range properties {
+		attrList = append(attrList, attr)
+	}
+	sort.Strings(attrList)
+	for _, attr := range attrList {
+		value, ok := data[attr]
+		if !ok || value == nil {
+			continue
+		}
+		attrDef := properties[attr].(map[string]interface{})
+		attrPath := fmt.Sprintf("%s/%s", dataPath, attr)
+		if deprecated, _ := attrDef[JsonKey.Deprecated].(bool); deprecated {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("attr [%s] is deprecated @path=[%s]", attr, attrPath))
+		}
+		subDoc, ok := d.SubDocs[attr]
+		if !ok {
+			continue
+		}
+		switch attrValue := value.(type) {
+		case []interface{}:
+			for idx, item := range attrValue {
+				if itemData, ok := item.(map[string]interface{}); ok {
+					subDoc.validate(itemData, fmt.Sprintf("%s[%d]", attrPath, idx), result)
+				}
+			}
+		case map[string]interface{}:
+			if !IsMap(attrDef) {
+				subDoc.validate(attrValue, attrPath, result)
+				continue
+			}
+			for key, item := range attrValue {
+				if itemData, ok := item.(map[string]interface{}); ok {
+					subDoc.validate(itemData, fmt.Sprintf("%s[%s]", attrPath, key), result)
+				}
+			}
+		}
+	}
+}
+
+// paths of required attributes missing from data, objects under it are checked with their own doc.
+// all of them are returned so write can report every missing attr at once
+func (d *SchemaDoc) MissingRequired(data map[string]interface{}, dataPath string) []string {
+	d = d.DocOf(data)
+	missingList := []string{}
+	for _, attr := range d.RequiredAttrs() {
+		if _, ok := data[attr]; !ok {
+			missingList = append(missingList, fmt.Sprintf("%s/%s", dataPath, attr))
+		}
+	}
+	properties := d.Properties()
+	attrList := make([]string, 0, len(properties))
+	for attr := range properties {
+		attrList = append(attrList, attr)
+	}
+	sort.Strings(attrList)
+	for _, attr := range attrList {
+		subDoc, ok := d.SubDocs[attr]
+		if !ok {
+			continue
+		}
+		attrPath := fmt.Sprintf("%s/%s", dataPath, attr)
+		switch attrValue := data[attr].(type) {
+		case []interface{}:
+			for idx, item := range attrValue {
+				if itemData, ok := item.(map[string]interface{}); ok {
+					missingList = append(missingList, subDoc.MissingRequired(itemData, fmt.Sprintf("%s[%d]", attrPath, idx))...)
+				}
+			}
+		case map[string]interface{}:
+			if !IsMap(properties[attr].(map[string]interface{})) {
+				missingList = append(missingList, subDoc.MissingRequired(attrValue, attrPath)...)
+				continue
+			}
+			keyList := make([]string, 0, len(attrValue))
+			for key := range attrValue {
+				keyList = append(keyList, key)
+			}
+			sort.Strings(keyList)
+			for _, key := range keyList {
+				if itemData, ok := attrValue[key].(map[string]interface{}); ok {
+					missingList = append(missingList, subDoc.MissingRequired(itemData, fmt.Sprintf("%s[%s]", attrPath, key))...)
+				}
+			}
+		}
+	}
+	return missingList
+}
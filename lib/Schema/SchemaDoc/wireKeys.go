@@ -0,0 +1,99 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaDoc
+
+// copy of data with names of attributes declared in schema renamed by wireKey, like stored name into casing of client.
+// with toStored, data carries names in casing of client and they are renamed back to declared names.
+// keys of map items and attributes not declared are kept as they are
+func (d *SchemaDoc) WireKeys(data map[string]interface{}, wireKey func(string) string, toStored bool) map[string]interface{} {
+	doc := d
+	if d.Discriminator != nil {
+		attr := d.Discriminator.Attr
+		if toStored {
+			attr = wireKey(attr)
+		}
+		kind, _ := data[attr].(string)
+		if kindDoc, ok := d.Discriminator.Mapping[kind]; ok {
+			doc = kindDoc
+		}
+	}
+	props := doc.Properties()
+	names := make(map[string]string, len(props))
+	for attrName := range props {
+		if toStored {
+			names[wireKey(attrName)] = attrName
+			continue
+		}
+		names[attrName] = wireKey(attrName)
+	}
+	result := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		newKey, ok := names[key]
+		if !ok {
+			result[key] = value
+			continue
+		}
+		attrName := key
+		if toStored {
+			attrName = newKey
+		}
+		result[newKey] = doc.wireValue(attrName, value, wireKey, toStored)
+	}
+	return result
+}
+
+// value of attribute with keys of objects under it renamed, keys of map items are kept
+func (d *SchemaDoc) wireValue(attrName string, value interface{}, wireKey func(string) string, toStored bool) interface{} {
+	subDoc, ok := d.SubDocs[attrName]
+	if !ok {
+		return value
+	}
+	switch data := value.(type) {
+	case []interface{}:
+		result := make([]interface{}, 0, len(data))
+		for _, item := range data {
+			if itemData, ok := item.(map[string]interface{}); ok {
+				item = subDoc.WireKeys(itemData, wireKey, toStored)
+			}
+			result = append(result, item)
+		}
+		return result
+	case map[string]interface{}:
+		attrDef, _ := d.Properties()[attrName].(map[string]interface{})
+		if !IsMap(attrDef) {
+			return subDoc.WireKeys(data, wireKey, toStored)
+		}
+		result := make(map[string]interface{}, len(data))
+		for key, item := range data {
+			if itemData, ok := item.(map[string]interface{}); ok {
+				item = subDoc.WireKeys(itemData, wireKey, toStored)
+			}
+			result[key] = item
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,84 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Schema
+
+import (
+	"fmt"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util/Json"
+)
+
+// array attribute with appendOnly=true keeps stored items as they are, new items can only be added at the end.
+// before is stored data, nil when record is new. attr inside object is checked as well
+func ValidateAppendOnly(schema *SchemaDoc.SchemaDoc, before map[string]interface{}, after map[string]interface{}, dataPath string) error {
+	if before == nil {
+		return nil
+	}
+	schema = schema.DocOf(after)
+	properties := schema.Data[JsonKey.Properties].(map[string]interface{})
+	for attr, prop := range properties {
+		attrDef := prop.(map[string]interface{})
+		attrPath := fmt.Sprintf("%s/%s", dataPath, attr)
+		if appendOnly, _ := attrDef[JsonKey.AppendOnly].(bool); appendOnly {
+			err := validateAppended(before[attr], after[attr], attrPath)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if attrDef[JsonKey.Type] != JsonKey.Object || SchemaDoc.IsMap(attrDef) {
+			continue
+		}
+		beforeObj, _ := before[attr].(map[string]interface{})
+		afterObj, ok := after[attr].(map[string]interface{})
+		if beforeObj == nil || !ok {
+			continue
+		}
+		err := ValidateAppendOnly(schema.SubDocs[attr], beforeObj, afterObj, attrPath)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateAppended(before interface{}, after interface{}, dataPath string) error {
+	beforeList, _ := before.([]interface{})
+	afterList, _ := after.([]interface{})
+	if len(afterList) < len(beforeList) {
+		return fmt.Errorf("[%s] attr can not remove items, [%d] stored, got [%d] @path=[%s]", JsonKey.AppendOnly, len(beforeList), len(afterList), dataPath)
+	}
+	for idx, item := range beforeList {
+		beforeStr, _ := Json.Canonical(item)
+		afterStr, _ := Json.Canonical(afterList[idx])
+		if string(beforeStr) != string(afterStr) {
+			return fmt.Errorf("[%s] attr can only add items at the end, stored item changed @path=[%s[%d]]", JsonKey.AppendOnly, dataPath, idx)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+)
+
+// date layouts recognized when coerce string value of attribute with [format]=[date-time|date]
+var DateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.ANSIC,
+}
+
+// lenient mode for data from heterogeneous sources.
+// convert string encoded number/boolean into type defined in schema and normalize recognized dates,
+// data is changed in place. strict validation still happens on write after coerce.
+func CoerceData(schema *SchemaDoc.SchemaDoc, data map[string]interface{}, dataPath string) error {
+	properties := schema.Data[JsonKey.Properties].(map[string]interface{})
+	for attr, value := range data {
+		attrDef, ok := properties[attr].(map[string]interface{})
+		if !ok || value == nil {
+			continue
+		}
+		newValue, err := coerceValue(schema.SubDocs[attr], attrDef, value, fmt.Sprintf("%s/%s", dataPath, attr))
+		if err != nil {
+			return err
+		}
+		data[attr] = newValue
+	}
+	return nil
+}
+
+func coerceValue(doc *SchemaDoc.SchemaDoc, attrDef map[string]interface{}, value interface{}, dataPath string) (interface{}, error) {
+	attrType, _ := attrDef[JsonKey.Type].(string)
+	switch attrType {
+	case JsonKey.Array:
+		itemDef, ok := attrDef[JsonKey.Items].(map[string]interface{})
+		valueList, isList := value.([]interface{})
+		if !ok || !isList {
+			return value, nil
+		}
+		for idx, item := range valueList {
+			newItem, err := coerceValue(doc, itemDef, item, fmt.Sprintf("%s[%d]", dataPath, idx))
+			if err != nil {
+				return nil, err
+			}
+			valueList[idx] = newItem
+		}
+		return valueList, nil
+	case JsonKey.Object:
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		if SchemaDoc.IsMap(attrDef) {
+			itemDef := attrDef[JsonKey.AdditionalProperties].(map[string]interface{})
+			for key, item := range valueMap {
+				newItem, err := coerceValue(doc, itemDef, item, fmt.Sprintf("%s[%s]", dataPath, key))
+				if err != nil {
+					return nil, err
+				}
+				valueMap[key] = newItem
+			}
+			return valueMap, nil
+		}
+		if doc == nil {
+			return valueMap, nil
+		}
+		return valueMap, CoerceData(doc, valueMap, dataPath)
+	}
+	strValue, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	switch attrType {
+	case JsonKey.Integer:
+		intValue, err := strconv.ParseInt(strings.TrimSpace(strValue), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot coerce value [%s] to [%s] @path=[%s]", strValue, attrType, dataPath)
+		}
+		return float64(intValue), nil
+	case JsonKey.Number:
+		numValue, err := strconv.ParseFloat(strings.TrimSpace(strValue), 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot coerce value [%s] to [%s] @path=[%s]", strValue, attrType, dataPath)
+		}
+		return numValue, nil
+	case JsonKey.Boolean:
+		boolValue, err := strconv.ParseBool(strings.TrimSpace(strValue))
+		if err != nil {
+			return nil, fmt.Errorf("cannot coerce value [%s] to [%s] @path=[%s]", strValue, attrType, dataPath)
+		}
+		return boolValue, nil
+	case JsonKey.String:
+		format, _ := attrDef[JsonKey.Format].(string)
+		if format != JsonKey.DateTime && format != JsonKey.Date {
+			return strValue, nil
+		}
+		dateValue, err := parseDate(strings.TrimSpace(strValue))
+		if err != nil {
+			return nil, fmt.Errorf("cannot coerce value [%s] to [%s] @path=[%s]", strValue, format, dataPath)
+		}
+		if format == JsonKey.Date {
+			return dateValue.Format("2006-01-02"), nil
+		}
+		return dateValue.UTC().Format(time.RFC3339), nil
+	}
+	return value, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range DateLayouts {
+		dateValue, err := time.Parse(layout, value)
+		if err == nil {
+			return dateValue, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format")
+}
@@ -0,0 +1,284 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Schema
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util/Json"
+)
+
+// fetch data of schema document by its id or url
+type SchemaFetcher func(schemaId string) (map[string]interface{}, error)
+
+type cachedSchema struct {
+	data    map[string]interface{}
+	fetched time.Time
+}
+
+// keep fetched schema document, so each id or url is only fetched once until it expires.
+// ttl 0 keeps it forever, maxSize caps number of documents kept and drops the oldest, no cap when 0
+func CachedFetcher(fetch SchemaFetcher, ttl time.Duration, maxSize int) SchemaFetcher {
+	lock := sync.Mutex{}
+	cache := map[string]cachedSchema{}
+	return func(schemaId string) (map[string]interface{}, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		now := time.Now()
+		if entry, ok := cache[schemaId]; ok {
+			if ttl <= 0 || now.Sub(entry.fetched) < ttl {
+				return Json.CopyToMap(entry.data)
+			}
+			delete(cache, schemaId)
+		}
+		data, err := fetch(schemaId)
+		if err != nil {
+			return nil, err
+		}
+		if maxSize > 0 && len(cache) >= maxSize {
+			oldestId := ""
+			for id, entry := range cache {
+				if oldestId == "" || entry.fetched.Before(cache[oldestId].fetched) {
+					oldestId = id
+				}
+			}
+			delete(cache, oldestId)
+		}
+		cache[schemaId] = cachedSchema{data: data, fetched: now}
+		return Json.CopyToMap(data)
+	}
+}
+
+// split external ref into schema id and definition name, definition name is empty when ref the document root
+func ParseExternalRef(ref string) (string, string, error) {
+	if !SchemaDoc.IsExternalRef(ref) {
+		return "", "", fmt.Errorf("ref [%s] is not external", ref)
+	}
+	idx := strings.Index(ref, JsonKey.DocRoot)
+	if idx < 0 {
+		return ref, "", nil
+	}
+	schemaId, fragment := ref[:idx], ref[idx:]
+	if fragment == JsonKey.DocRoot {
+		return schemaId, "", nil
+	}
+	if !strings.HasPrefix(fragment, JsonKey.DefinitionPrefix) || len(fragment) == len(JsonKey.DefinitionPrefix) {
+		return "", "", fmt.Errorf("invalid external ref [%s], expect {schemaId}%s{name}", ref, JsonKey.DefinitionPrefix)
+	}
+	return schemaId, fragment[len(JsonKey.DefinitionPrefix):], nil
+}
+
+// all external refs in schema data
+func ExternalRefs(data interface{}) []string {
+	refs := []string{}
+	walkRefs(data, func(node map[string]interface{}, ref string) error {
+		if SchemaDoc.IsExternalRef(ref) {
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+	return refs
+}
+
+func walkRefs(data interface{}, visit func(node map[string]interface{}, ref string) error) error {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		if ref, ok := value[JsonKey.Ref].(string); ok {
+			err := visit(value, ref)
+			if err != nil {
+				return err
+			}
+		}
+		for _, item := range value {
+			err := walkRefs(item, visit)
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			err := walkRefs(item, visit)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copy of schema data with referenced external definitions copied into [definitions],
+// external refs are rewritten to local refs, so validation and walking see one self-contained document.
+// refs inside a copied definition are resolved against the document it comes from.
+// each external definition is copied once, ref back to one on the way is pointed at the same copy
+func ResolveExternalRefs(data map[string]interface{}, fetch SchemaFetcher) (map[string]interface{}, error) {
+	refs := ExternalRefs(data)
+	if len(refs) == 0 {
+		return data, nil
+	}
+	if fetch == nil {
+		return nil, fmt.Errorf("no schema fetcher to resolve external ref %s", refs)
+	}
+	resolved, err := Json.CopyToMap(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy schema data. Error: %s", err)
+	}
+	definitions, ok := resolved[JsonKey.Definitions].(map[string]interface{})
+	if !ok {
+		definitions = map[string]interface{}{}
+	}
+	selfId, _ := resolved[JsonKey.Name].(string)
+	r := refResolver{
+		fetch:   fetch,
+		selfId:  selfId,
+		local:   definitions,
+		docs:    map[string]map[string]interface{}{},
+		keys:    map[string]string{},
+		inlined: map[string]interface{}{},
+	}
+	err = r.walk(resolved, "")
+	if err != nil {
+		return nil, err
+	}
+	for key, def := range r.inlined {
+		definitions[key] = def
+	}
+	resolved[JsonKey.Definitions] = definitions
+	return resolved, nil
+}
+
+type refResolver struct {
+	fetch   SchemaFetcher
+	selfId  string
+	local   map[string]interface{}
+	docs    map[string]map[string]interface{}
+	keys    map[string]string
+	inlined map[string]interface{}
+}
+
+// rewrite refs in data, schemaId is the external document data comes from, empty for the schema itself
+func (r *refResolver) walk(data interface{}, schemaId string) error {
+	return walkRefs(data, func(node map[string]interface{}, ref string) error {
+		refId, defName, err := r.target(ref, schemaId)
+		if err != nil {
+			return err
+		}
+		if refId == "" {
+			return nil
+		}
+		if refId == r.selfId {
+			node[JsonKey.Ref] = JsonKey.DocRoot
+			if defName != "" {
+				node[JsonKey.Ref] = JsonKey.DefinitionPrefix + defName
+			}
+			return nil
+		}
+		key, err := r.inline(refId, defName)
+		if err != nil {
+			return err
+		}
+		node[JsonKey.Ref] = JsonKey.DefinitionPrefix + key
+		return nil
+	})
+}
+
+// document and definition ref points at, empty document when ref stays as it is
+func (r *refResolver) target(ref string, schemaId string) (string, string, error) {
+	if SchemaDoc.IsExternalRef(ref) {
+		return ParseExternalRef(ref)
+	}
+	if schemaId == "" {
+		return "", "", nil
+	}
+	if ref == JsonKey.DocRoot {
+		return schemaId, "", nil
+	}
+	if !strings.HasPrefix(ref, JsonKey.DefinitionPrefix) {
+		return "", "", fmt.Errorf("unknown ref value=[%s] in schema [%s]", ref, schemaId)
+	}
+	return schemaId, ref[len(JsonKey.DefinitionPrefix):], nil
+}
+
+// copy definition of external document into definitions, return its key
+func (r *refResolver) inline(schemaId string, defName string) (string, error) {
+	source := fmt.Sprintf("%s%s%s", schemaId, JsonKey.DocRoot, defName)
+	if key, ok := r.keys[source]; ok {
+		return key, nil
+	}
+	doc, err := r.load(schemaId)
+	if err != nil {
+		return "", err
+	}
+	key := defName
+	def := doc
+	if defName == "" {
+		key, _ = doc[JsonKey.Name].(string)
+		if key == "" {
+			return "", fmt.Errorf("missing [%s] of external schema [%s]", JsonKey.Name, schemaId)
+		}
+	} else {
+		docDefs, _ := doc[JsonKey.Definitions].(map[string]interface{})
+		def, _ = docDefs[defName].(map[string]interface{})
+		if def == nil {
+			return "", fmt.Errorf("cannot find definition=[%s] in external schema [%s]", defName, schemaId)
+		}
+	}
+	if _, ok := r.local[key]; ok {
+		return "", fmt.Errorf("definition [%s] from external schema [%s] conflicts with definition of same name", key, schemaId)
+	}
+	if _, ok := r.inlined[key]; ok {
+		return "", fmt.Errorf("definition [%s] from external schema [%s] conflicts with definition of same name from another schema", key, schemaId)
+	}
+	defCopy, err := Json.CopyToMap(def)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy definition [%s] of external schema [%s]. Error: %s", key, schemaId, err)
+	}
+	// definitions are referenced through the source document, only the ones in use get copied
+	delete(defCopy, JsonKey.Definitions)
+	r.keys[source] = key
+	r.inlined[key] = defCopy
+	err = r.walk(defCopy, schemaId)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve refs of [%s]. Error: %s", source, err)
+	}
+	return key, nil
+}
+
+func (r *refResolver) load(schemaId string) (map[string]interface{}, error) {
+	if doc, ok := r.docs[schemaId]; ok {
+		return doc, nil
+	}
+	doc, err := r.fetch(schemaId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch external schema [%s]. Error: %s", schemaId, err)
+	}
+	r.docs[schemaId] = doc
+	return doc, nil
+}
@@ -0,0 +1,112 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Schema
+
+import (
+	"fmt"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util/Json"
+)
+
+// copy of data in the shape schema describes, data itself is not changed.
+// missing attr with [default] gets the default parsed into its type, string values are coerced like CoerceData
+// and attr not in properties is dropped when schema sets additionalProperties=false.
+// value that can not be coerced is kept as it is
+func NormalizeData(schema *SchemaDoc.SchemaDoc, data map[string]interface{}, dataPath string) (map[string]interface{}, error) {
+	result, err := Json.CopyToMap(data)
+	if err != nil {
+		return nil, err
+	}
+	normalizeObject(schema, result, dataPath)
+	return result, nil
+}
+
+func normalizeObject(schema *SchemaDoc.SchemaDoc, data map[string]interface{}, dataPath string) {
+	schema = schema.DocOf(data)
+	properties := schema.Data[JsonKey.Properties].(map[string]interface{})
+	if additional, ok := schema.Data[JsonKey.AdditionalProperties].(bool); ok && !additional {
+		for attr := range data {
+			if _, ok := properties[attr]; !ok {
+				delete(data, attr)
+			}
+		}
+	}
+	for attr, prop := range properties {
+		attrDef := prop.(map[string]interface{})
+		value, ok := data[attr]
+		if !ok {
+			if defStr, hasDefault := attrDef[JsonKey.Default].(string); hasDefault {
+				defValue, err := SchemaDoc.ParseExample(attrDef, defStr)
+				if err == nil {
+					data[attr] = defValue
+				}
+			}
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		data[attr] = normalizeValue(schema.SubDocs[attr], attrDef, value, fmt.Sprintf("%s/%s", dataPath, attr))
+	}
+}
+
+func normalizeValue(doc *SchemaDoc.SchemaDoc, attrDef map[string]interface{}, value interface{}, dataPath string) interface{} {
+	switch attrDef[JsonKey.Type] {
+	case JsonKey.Array:
+		itemDef, ok := attrDef[JsonKey.Items].(map[string]interface{})
+		valueList, isList := value.([]interface{})
+		if !ok || !isList {
+			return value
+		}
+		for idx, item := range valueList {
+			valueList[idx] = normalizeValue(doc, itemDef, item, fmt.Sprintf("%s[%d]", dataPath, idx))
+		}
+		return valueList
+	case JsonKey.Object:
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		if SchemaDoc.IsMap(attrDef) {
+			itemDef := attrDef[JsonKey.AdditionalProperties].(map[string]interface{})
+			for key, item := range valueMap {
+				valueMap[key] = normalizeValue(doc, itemDef, item, fmt.Sprintf("%s[%s]", dataPath, key))
+			}
+			return valueMap
+		}
+		if doc != nil {
+			normalizeObject(doc, valueMap, dataPath)
+		}
+		return valueMap
+	}
+	newValue, err := coerceValue(doc, attrDef, value, dataPath)
+	if err != nil {
+		return value
+	}
+	return newValue
+}
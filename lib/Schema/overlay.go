@@ -0,0 +1,139 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Schema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Util/Json"
+)
+
+// attr definition keys an overlay cannot change, they define shape of data instead of constraint
+var overlayFixedKeys = []string{
+	JsonKey.Type,
+	JsonKey.Items,
+	JsonKey.AdditionalProperties,
+	JsonKey.Ref,
+	JsonKey.ContentMediaType,
+	JsonKey.IndexTemplate,
+	JsonKey.Format,
+}
+
+// schema of base merged with overlay, overlay can only tighten base:
+// add new properties and rules, make optional attr required, narrow enum, add constraint keywords like pattern.
+// overlay that loosens base is rejected.
+// Record stays the base record so records of base type validate against the result
+func (schema *SchemaOps) Overlay(overlay map[string]interface{}) (*SchemaOps, error) {
+	merged, err := Json.CopyToMap(schema.Schema.RAW)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy schema data of [%s]. Error: %s", schema.Schema.Id, err)
+	}
+	for key, value := range overlay {
+		switch key {
+		case JsonKey.Properties:
+			overlayProps, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid overlay [%s] of [%s], expect object", key, schema.Schema.Id)
+			}
+			err := overlayProperties(merged[JsonKey.Properties].(map[string]interface{}), overlayProps)
+			if err != nil {
+				return nil, fmt.Errorf("invalid overlay of [%s]. Error: %s", schema.Schema.Id, err)
+			}
+		case JsonKey.Rules, JsonKey.WarnRules:
+			overlayRules, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid overlay [%s] of [%s], expect array", key, schema.Schema.Id)
+			}
+			rules, _ := merged[key].([]interface{})
+			merged[key] = append(rules, overlayRules...)
+		default:
+			return nil, fmt.Errorf("overlay key [%s] of [%s] is not supported, expect [%s] or [%s]", key, schema.Schema.Id, JsonKey.Properties, JsonKey.Rules)
+		}
+	}
+	result := SchemaOps{
+		Record: schema.Record,
+	}
+	err = result.load(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load overlay of [%s]. Error: %s", schema.Schema.Id, err)
+	}
+	return &result, nil
+}
+
+func overlayProperties(properties map[string]interface{}, overlayProps map[string]interface{}) error {
+	for attr, value := range overlayProps {
+		overlayDef, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("definition of attr [%s] is not an object", attr)
+		}
+		baseDef, ok := properties[attr].(map[string]interface{})
+		if !ok {
+			properties[attr] = overlayDef
+			continue
+		}
+		err := checkTighten(attr, baseDef, overlayDef)
+		if err != nil {
+			return err
+		}
+		for key, value := range overlayDef {
+			baseDef[key] = value
+		}
+	}
+	return nil
+}
+
+func checkTighten(attr string, baseDef map[string]interface{}, overlayDef map[string]interface{}) error {
+	for _, key := range overlayFixedKeys {
+		value, ok := overlayDef[key]
+		if ok && !reflect.DeepEqual(value, baseDef[key]) {
+			return fmt.Errorf("attr [%s] cannot change [%s]", attr, key)
+		}
+	}
+	if required, ok := overlayDef[JsonKey.Required].(bool); ok && !required {
+		if baseRequired, ok := baseDef[JsonKey.Required].(bool); !ok || baseRequired {
+			return fmt.Errorf("attr [%s] is required in base, cannot make it optional", attr)
+		}
+	}
+	if enum, ok := overlayDef[JsonKey.Enum].([]interface{}); ok {
+		if baseEnum, ok := baseDef[JsonKey.Enum].([]interface{}); ok {
+			for _, item := range enum {
+				found := false
+				for _, baseItem := range baseEnum {
+					if reflect.DeepEqual(item, baseItem) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					return fmt.Errorf("attr [%s] cannot add [%v] to [%s] of base", attr, item, JsonKey.Enum)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -54,10 +54,15 @@ type SchemaOps struct {
 }
 
 func LoadSchemaOpsRecord(record *Record.Record) (*SchemaOps, error) {
+	return LoadSchemaOpsWithFetcher(record, nil)
+}
+
+// load schema record, external refs are resolved through fetch
+func LoadSchemaOpsWithFetcher(record *Record.Record, fetch SchemaFetcher) (*SchemaOps, error) {
 	recWithSchema := SchemaOps{
 		Record: record,
 	}
-	err := recWithSchema.init()
+	err := recWithSchema.init(fetch)
 	if err != nil {
 		return nil, fmt.Errorf("failed while init SchemaOps. Error:%s", err)
 	}
@@ -73,7 +78,7 @@ func LoadSchemaOpsData(dataType string, typeVer string, data map[string]interfac
 	return LoadSchemaOpsRecord(record)
 }
 
-func (schema *SchemaOps) init() error {
+func (schema *SchemaOps) init(fetch SchemaFetcher) error {
 	if schema.Record.Type != JsonKey.Schema {
 		return fmt.Errorf("schema record has wrong [%s], [%s]!=[%s]", Record.DataType, schema.Record.Id, JsonKey.Schema)
 	}
@@ -81,7 +86,15 @@ func (schema *SchemaOps) init() error {
 	if err != nil {
 		return fmt.Errorf("copy schema.Record.Data failed. Error: %s", err)
 	}
-	doc, err := SchemaDoc.New(schemaData.(map[string]interface{}))
+	resolved, err := ResolveExternalRefs(schemaData.(map[string]interface{}), fetch)
+	if err != nil {
+		return fmt.Errorf("failed to resolve external refs of [%s]. Error: %s", schema.Record.Id, err)
+	}
+	return schema.load(resolved)
+}
+
+func (schema *SchemaOps) load(schemaData map[string]interface{}) error {
+	doc, err := SchemaDoc.New(schemaData)
 	if err != nil {
 		return fmt.Errorf("failed to create Schema Doc, err: %s", err)
 	}
@@ -98,12 +111,118 @@ func (schema *SchemaOps) init() error {
 	return nil
 }
 
-func (schema *SchemaOps) ValidateRecord(record *Record.Record) error {
-	for _, c := range JsonKey.InvalidKeyChars {
-		if strings.Contains(record.Id, c) {
-			return fmt.Errorf("invalid char found in record id:[%s], please make sure not include following chars:\n%s", record.Id, JsonKey.InvalidKeyChars)
+// name of the base schema this schema extends, empty when not extending any
+func (schema *SchemaOps) BaseType() string {
+	baseType, ok := schema.Record.Data[JsonKey.Extends].(string)
+	if !ok {
+		return ""
+	}
+	return baseType
+}
+
+// merge properties and definitions of base schema into this schema.
+// attributes defined on this schema win over the ones inherited from base.
+// Record keeps the data as stored, Schema and Meta are rebuilt from merged data
+func (schema *SchemaOps) Extend(base *SchemaOps) error {
+	merged, err := Json.CopyToMap(schema.Schema.RAW)
+	if err != nil {
+		return fmt.Errorf("failed to copy schema data of [%s]. Error: %s", schema.Schema.Id, err)
+	}
+	baseData, err := Json.CopyToMap(base.Schema.RAW)
+	if err != nil {
+		return fmt.Errorf("failed to copy base schema data of [%s]. Error: %s", base.Schema.Id, err)
+	}
+	for _, field := range []string{JsonKey.Properties, JsonKey.Definitions} {
+		baseMap, ok := baseData[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fieldMap, ok := merged[field].(map[string]interface{})
+		if !ok {
+			fieldMap = map[string]interface{}{}
+			merged[field] = fieldMap
+		}
+		for key, value := range baseMap {
+			if _, ok := fieldMap[key]; !ok {
+				fieldMap[key] = value
+			}
+		}
+	}
+	for _, ruleKey := range []string{JsonKey.Rules, JsonKey.WarnRules} {
+		if baseRules, ok := baseData[ruleKey].([]interface{}); ok {
+			rules, _ := merged[ruleKey].([]interface{})
+			merged[ruleKey] = append(rules, baseRules...)
 		}
 	}
+	if _, ok := merged[JsonKey.Key]; !ok {
+		if baseKey, ok := baseData[JsonKey.Key]; ok {
+			merged[JsonKey.Key] = baseKey
+		}
+	}
+	err = schema.load(merged)
+	if err != nil {
+		return fmt.Errorf("failed to load schema [%s] extended from [%s]. Error: %s", schema.Schema.Id, base.Schema.Id, err)
+	}
+	return nil
+}
+
+// schema data that validation actually uses.
+// inherited attributes are merged and default [required]=true is set explicitly on each property,
+// examples are shown as values of the property type
+func (schema *SchemaOps) Effective() (map[string]interface{}, error) {
+	data, err := Json.CopyToMap(schema.Schema.RAW)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy schema data of [%s]. Error: %s", schema.Schema.Id, err)
+	}
+	applyDefaults(data)
+	applyExamples(schema.Schema, data)
+	return data, nil
+}
+
+func applyExamples(doc *SchemaDoc.SchemaDoc, docData map[string]interface{}) {
+	if properties, ok := docData[JsonKey.Properties].(map[string]interface{}); ok {
+		for attr, examples := range doc.Examples {
+			if propDef, ok := properties[attr].(map[string]interface{}); ok {
+				propDef[JsonKey.Examples] = examples
+			}
+		}
+	}
+	if definitions, ok := docData[JsonKey.Definitions].(map[string]interface{}); ok {
+		for name, def := range definitions {
+			defDoc, ok := doc.Definitions[name]
+			if !ok {
+				continue
+			}
+			if defData, ok := def.(map[string]interface{}); ok {
+				applyExamples(defDoc, defData)
+			}
+		}
+	}
+}
+
+func applyDefaults(docData map[string]interface{}) {
+	if properties, ok := docData[JsonKey.Properties].(map[string]interface{}); ok {
+		for _, prop := range properties {
+			propDef, ok := prop.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := propDef[JsonKey.Required]; !ok {
+				propDef[JsonKey.Required] = true
+			}
+		}
+	}
+	if definitions, ok := docData[JsonKey.Definitions].(map[string]interface{}); ok {
+		for _, def := range definitions {
+			if defData, ok := def.(map[string]interface{}); ok {
+				applyDefaults(defData)
+			}
+		}
+	}
+}
+
+// record id can have any char, id with / [ ] ? is percent-encoded in path, see Util.EscapeKey
+func (schema *SchemaOps) ValidateRecord(record *Record.Record) error {
 	if schema.Schema.Id != record.Type {
 		return fmt.Errorf("schema id and payload data type does not match, [%s]!=[%s]", schema.Record.Id, record.Type)
 	}
@@ -155,6 +274,14 @@ func (schema *SchemaOps) ValidateRecord(record *Record.Record) error {
 }
 
 func ValidateSchemaKeys(schema *SchemaDoc.SchemaDoc, data map[string]interface{}, dataPath string) error {
+	schema, err := schema.KindDoc(data)
+	if err != nil {
+		return fmt.Errorf("%s @path=[%s]", err, dataPath)
+	}
+	err = schema.ValidateRules(data)
+	if err != nil {
+		return fmt.Errorf("%s @path=[%s]", err, dataPath)
+	}
 	properties := schema.Data[JsonKey.Properties].(map[string]interface{})
 	for attr := range properties {
 		attrDef := properties[attr].(map[string]interface{})
@@ -254,6 +381,7 @@ func ValidateSchemaKeys(schema *SchemaDoc.SchemaDoc, data map[string]interface{}
 }
 
 func SetDataOnPath(schema *SchemaDoc.SchemaDoc, data map[string]interface{}, dataPath string, prevPath string, newData interface{}) *Http.HttpError {
+	schema = schema.DocOf(data)
 	attrPath, nextPath := Util.ParsePath(dataPath)
 	if nextPath == "" {
 		if newData == nil {
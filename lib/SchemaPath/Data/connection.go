@@ -26,6 +26,7 @@ This copyright notice and license applies to all files in this directory or sub-
 package Data
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -38,9 +39,31 @@ import (
 
 type RecordFunction func(dataType string, dataId string) (*Record.Record, *Http.HttpError)
 
+// records of dataType for the ids in one fetch, id that has no record is left out
+type RecordsFunction func(dataType string, dataIdList []string) ([]*Record.Record, *Http.HttpError)
+
+// dataType can carry version as [type/version] or archived [type__version]
+type SchemaFunction func(dataType string) (*SchemaDoc.SchemaDoc, *Http.HttpError)
+
+type ListFunction func(dataType string) ([]interface{}, *Http.HttpError)
+
+type PutFunction func(record *Record.Record) *Http.HttpError
+
+// RecordCache keeps record read by GetRecord by type and id, it is created on first read when nil.
+// SchemaCache keeps schema from GetSchema by data type when not nil, nil reads schema from FuncSchema every time.
+// caches can be shared between connections, call ClearCache after records or schemas are changed.
+// MaxRefDepth is how many refs a walk follows into another record, ref beyond it stays as its value. 0 means no limit.
+// FuncRecords is optional, with it walk over items of array or map fetches their ref targets in one call per target type
 type Connection struct {
-	FuncRecord RecordFunction
-	cache      map[string]TypeCache
+	FuncRecord  RecordFunction
+	FuncRecords RecordsFunction
+	FuncSchema  SchemaFunction
+	FuncList    ListFunction
+	FuncPut     PutFunction
+	RecordCache map[string]TypeCache
+	SchemaCache map[string]*SchemaDoc.SchemaDoc
+	MaxRefDepth int
+	ctx         context.Context
 }
 
 type TypeCache struct {
@@ -48,16 +71,21 @@ type TypeCache struct {
 	IdCache  map[string]interface{}
 }
 
-func (c *Connection) cacheData(dataType string, id string) (interface{}, *Http.HttpError) {
-	if c.cache == nil {
-		c.cache = map[string]TypeCache{}
+func (c *Connection) typeCache(dataType string) TypeCache {
+	if c.RecordCache == nil {
+		c.RecordCache = map[string]TypeCache{}
 	}
-	if _, ok := c.cache[dataType]; !ok {
-		c.cache[dataType] = TypeCache{
+	if _, ok := c.RecordCache[dataType]; !ok {
+		c.RecordCache[dataType] = TypeCache{
 			DataType: dataType,
 			IdCache:  make(map[string]interface{}),
 		}
 	}
+	return c.RecordCache[dataType]
+}
+
+func (c *Connection) cacheData(dataType string, id string) (interface{}, *Http.HttpError) {
+	cache := c.typeCache(dataType)
 	if dataType == JsonKey.Schema {
 		schemaId, schemaVer, ex := SchemaDoc.ParseDataType(id)
 		if ex != nil {
@@ -67,14 +95,9 @@ func (c *Connection) cacheData(dataType string, id string) (interface{}, *Http.H
 			id = SchemaDoc.ArchivedSchemaId(schemaId, schemaVer)
 		}
 	}
-	data, ok := c.cache[dataType].IdCache[id]
+	data, ok := cache.IdCache[id]
 	if ok {
-		dataCopy, ex := Json.Copy(data)
-		if ex != nil {
-			return nil, Http.WrapError(ex, "failed to copy cache data", http.StatusInternalServerError)
-		}
-		recordCopy, _ := Record.LoadMap(dataCopy.(map[string]interface{}))
-		return recordCopy, nil
+		return cachedRecord(data)
 	}
 	data, err := c.FuncRecord(dataType, id)
 	if err != nil {
@@ -84,14 +107,51 @@ func (c *Connection) cacheData(dataType string, id string) (interface{}, *Http.H
 	if ex != nil {
 		return nil, Http.WrapError(ex, "failed to copy cache data", http.StatusInternalServerError)
 	}
-	c.cache[dataType].IdCache[id] = dataCopy
+	cache.IdCache[id] = dataCopy
 	return data, err
 }
 
+func cachedRecord(data interface{}) (*Record.Record, *Http.HttpError) {
+	dataCopy, ex := Json.Copy(data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, "failed to copy cache data", http.StatusInternalServerError)
+	}
+	recordCopy, _ := Record.LoadMap(dataCopy.(map[string]interface{}))
+	return recordCopy, nil
+}
+
+// copy of connection that stops fetching record, schema and id list once ctx is done. caches are shared with c
+func (c *Connection) WithContext(ctx context.Context) *Connection {
+	if c.RecordCache == nil {
+		c.RecordCache = map[string]TypeCache{}
+	}
+	bound := *c
+	bound.ctx = ctx
+	return &bound
+}
+
+// context fetches of connection are bound to, background when not bound
+func (c *Connection) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c *Connection) checkContext(target string) *Http.HttpError {
+	if c.ctx == nil || c.ctx.Err() == nil {
+		return nil
+	}
+	return Http.WrapError(c.ctx.Err(), fmt.Sprintf("stopped before fetch of [%s]", target), http.StatusRequestTimeout)
+}
+
 func (c *Connection) GetRecord(dataType string, dataId string) (*Record.Record, *Http.HttpError) {
 	if c.FuncRecord == nil {
 		return nil, Http.NewHttpError("field funcRecord is nil", http.StatusInternalServerError)
 	}
+	if err := c.checkContext(fmt.Sprintf("%s/%s", dataType, dataId)); err != nil {
+		return nil, err
+	}
 	data, err := c.cacheData(dataType, dataId)
 	if err != nil {
 		return nil, err
@@ -102,3 +162,144 @@ func (c *Connection) GetRecord(dataType string, dataId string) (*Record.Record,
 	}
 	return record, nil
 }
+
+// records of dataType by id, id that has no record is left out. cached ones are not fetched again,
+// the rest are fetched by one call of FuncRecords and cached, or one GetRecord each when FuncRecords is nil
+func (c *Connection) GetRecords(dataType string, dataIdList []string) (map[string]*Record.Record, *Http.HttpError) {
+	result := make(map[string]*Record.Record, len(dataIdList))
+	if c.FuncRecords == nil {
+		for _, dataId := range dataIdList {
+			record, err := c.GetRecord(dataType, dataId)
+			if err != nil {
+				if err.Status == http.StatusNotFound {
+					continue
+				}
+				return nil, err
+			}
+			result[dataId] = record
+		}
+		return result, nil
+	}
+	cache := c.typeCache(dataType)
+	fetchList := []string{}
+	seen := map[string]bool{}
+	for _, dataId := range dataIdList {
+		if seen[dataId] {
+			continue
+		}
+		seen[dataId] = true
+		data, ok := cache.IdCache[dataId]
+		if !ok {
+			fetchList = append(fetchList, dataId)
+			continue
+		}
+		record, err := cachedRecord(data)
+		if err != nil {
+			return nil, err
+		}
+		result[dataId] = record
+	}
+	if len(fetchList) == 0 {
+		return result, nil
+	}
+	if err := c.checkContext(fmt.Sprintf("%s/%v", dataType, fetchList)); err != nil {
+		return nil, err
+	}
+	recordList, err := c.FuncRecords(dataType, fetchList)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range recordList {
+		dataCopy, ex := Json.Copy(record)
+		if ex != nil {
+			return nil, Http.WrapError(ex, "failed to copy cache data", http.StatusInternalServerError)
+		}
+		cache.IdCache[record.Id] = dataCopy
+		result[record.Id] = record
+	}
+	return result, nil
+}
+
+// get schema doc from FuncSchema when given, otherwise load it from schema record
+func (c *Connection) GetSchema(dataType string) (*SchemaDoc.SchemaDoc, *Http.HttpError) {
+	if schema, ok := c.SchemaCache[dataType]; ok {
+		return schema, nil
+	}
+	if err := c.checkContext(fmt.Sprintf("%s/%s", JsonKey.Schema, dataType)); err != nil {
+		return nil, err
+	}
+	var schema *SchemaDoc.SchemaDoc
+	var err *Http.HttpError
+	if c.FuncSchema != nil {
+		schema, err = c.FuncSchema(dataType)
+	} else {
+		schema, err = c.schemaFromRecord(dataType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.SchemaCache != nil {
+		c.SchemaCache[dataType] = schema
+	}
+	return schema, nil
+}
+
+// drop every cached record and schema, schema cache stays on when it was on
+func (c *Connection) ClearCache() {
+	c.RecordCache = nil
+	if c.SchemaCache != nil {
+		c.SchemaCache = map[string]*SchemaDoc.SchemaDoc{}
+	}
+}
+
+func (c *Connection) schemaFromRecord(dataType string) (*SchemaDoc.SchemaDoc, *Http.HttpError) {
+	schemaRecord, err := c.GetRecord(JsonKey.Schema, dataType)
+	if err != nil {
+		return nil, err
+	}
+	schema, ex := SchemaDoc.New(schemaRecord.Data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to create SchemaDoc of [%s]", dataType), http.StatusInternalServerError)
+	}
+	return schema, nil
+}
+
+// connection with its own record cache that also keeps every schema it loads,
+// for one batch of queries that read the same records and schemas over and over
+func (c *Connection) Batch() *Connection {
+	return &Connection{
+		FuncRecord:  c.FuncRecord,
+		FuncRecords: c.FuncRecords,
+		FuncSchema:  c.FuncSchema,
+		FuncList:    c.FuncList,
+		FuncPut:     c.FuncPut,
+		SchemaCache: map[string]*SchemaDoc.SchemaDoc{},
+		MaxRefDepth: c.MaxRefDepth,
+		ctx:         c.ctx,
+	}
+}
+
+func (c *Connection) ListIds(dataType string) ([]interface{}, *Http.HttpError) {
+	if c.FuncList == nil {
+		return nil, Http.NewHttpError("field FuncList is nil", http.StatusInternalServerError)
+	}
+	if err := c.checkContext(dataType); err != nil {
+		return nil, err
+	}
+	return c.FuncList(dataType)
+}
+
+// persist record and drop it from cache, so next GetRecord read the updated one
+func (c *Connection) PutRecord(record *Record.Record) *Http.HttpError {
+	if c.FuncPut == nil {
+		return Http.NewHttpError("field FuncPut is nil", http.StatusInternalServerError)
+	}
+	err := c.FuncPut(record)
+	if err != nil {
+		return err
+	}
+	if typeCache, ok := c.RecordCache[record.Type]; ok {
+		delete(typeCache.IdCache, record.Id)
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
 	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
@@ -49,6 +50,13 @@ type PathNode struct {
 	Prev     *PathNode
 	Next     []*PathNode
 	Data     interface{}
+	// keep item of array[*] that misses the rest of path as Missing, so value of items stays aligned to their position
+	AlignAll bool
+	Missing  bool
+	// ref not followed because walk reached MaxRefDepth of connection, Data stays the ref value
+	Unresolved bool
+	// type/id/path of refs being followed on root of walk, to catch ref path that leads back to itself
+	refChain []string
 }
 
 const (
@@ -111,7 +119,7 @@ func (p *PathNode) Sync() *Http.HttpError {
 		}
 		p.Schema = p.Prev.Schema
 		if attrType == JsonKey.Object && !SchemaDoc.IsMap(p.AttrDef) {
-			p.Schema = p.Prev.Schema.SubDocs[attrName]
+			p.Schema = p.Prev.Schema.SubDocs[attrName].DocOf(p.Data)
 		}
 	}
 	return nil
@@ -121,6 +129,29 @@ func (p *PathNode) IsRecord() bool {
 	return p.DataType != ""
 }
 
+// count of refs followed from root of walk to this node
+func (p *PathNode) RefDepth() int {
+	depth := 0
+	for node := p; node.Prev != nil; node = node.Prev {
+		if node.IsRecord() {
+			depth++
+		}
+	}
+	return depth
+}
+
+// count of refs resolved into another record under this node, each branch of the path counts its own hops
+func (p *PathNode) Hops() int {
+	hops := 0
+	for _, next := range p.Next {
+		if next.IsRecord() {
+			hops++
+		}
+		hops += next.Hops()
+	}
+	return hops
+}
+
 func (p *PathNode) syncFromConn() *Http.HttpError {
 	if p.DataId == "" {
 		return Http.NewHttpError(fmt.Sprintf("missing data Id in path @[%s]", p.FullPath()), http.StatusBadRequest)
@@ -130,15 +161,11 @@ func (p *PathNode) syncFromConn() *Http.HttpError {
 		return Http.WrapError(err, fmt.Sprintf("failed to get record @path=[%s]", p.FullPath()), http.StatusNotFound)
 	}
 	p.Data = record.Data
-	schemaRecord, err := p.Conn.GetRecord(JsonKey.Schema, fmt.Sprintf("%s/%s", record.Type, record.Version))
+	schema, err := p.Conn.GetSchema(fmt.Sprintf("%s/%s", record.Type, record.Version))
 	if err != nil {
-		return Http.WrapError(err, fmt.Sprintf("failed to get record @path=[%s]", p.FullPath()), err.Status)
+		return Http.WrapError(err, fmt.Sprintf("failed to get schema @path=[%s]", p.FullPath()), err.Status)
 	}
-	schema, ex := SchemaDoc.New(schemaRecord.Data)
-	if ex != nil {
-		return Http.WrapError(ex, fmt.Sprintf("failed to create SchemaDoc @path=[%s]", p.FullPath()), http.StatusInternalServerError)
-	}
-	p.Schema = schema
+	p.Schema = schema.DocOf(record.Data)
 	return nil
 }
 
@@ -198,8 +225,8 @@ func (p *PathNode) buildIdxNodes(idx string) *Http.HttpError {
 	if idx == "" {
 		return nil
 	}
-	if idx == All {
-		p.Idx = All
+	if idx == All || Util.IsSliceIdx(idx) {
+		p.Idx = idx
 	}
 	attrType := p.AttrDef[JsonKey.Type].(string)
 	var err *Http.HttpError
@@ -216,9 +243,15 @@ func (p *PathNode) buildIdxNodes(idx string) *Http.HttpError {
 	default:
 		return Http.NewHttpError(fmt.Sprintf("invalid schema type=[%s] for idx=[%s] @path=[%s]", attrType, p.Idx, p.FullPath()), http.StatusBadRequest)
 	}
-	if p.Idx != All && len(p.Next) == 0 {
+	if err != nil {
+		return err
+	}
+	if p.Idx != All && !Util.IsSliceIdx(p.Idx) && len(p.Next) == 0 {
 		return Http.NewHttpError(fmt.Sprintf("invalid idx, [%s] not found @path=[%s]", p.Idx, p.FullPath()), http.StatusNotFound)
 	}
+	err = p.prefetchRefs(p.Next, func(next *PathNode) (string, interface{}) {
+		return p.AttrName, next.Data
+	})
 	if err != nil {
 		return err
 	}
@@ -235,47 +268,95 @@ func (p *PathNode) BuildIdx(idx string) *Http.HttpError {
 	if idx == "" {
 		return nil
 	}
+	if p.Missing {
+		return nil
+	}
+	if len(p.Next) > 0 {
+		return p.buildNext(func(next *PathNode) *Http.HttpError {
+			return next.BuildIdx(idx)
+		})
+	}
+	return p.buildIdxNodes(idx)
+}
+
+// walk build into every next node, drop the ones that fail.
+// with AlignAll, item of array[*] missing the path stays as Missing node, unless no item has the path
+func (p *PathNode) buildNext(build func(next *PathNode) *Http.HttpError) *Http.HttpError {
 	nextList := []*PathNode{}
+	found := 0
 	var lastErr *Http.HttpError
-	if len(p.Next) > 0 {
-		for _, next := range p.Next {
-			err := next.BuildIdx(idx)
-			if err != nil {
-				lastErr = err
-				continue
-			}
+	for _, next := range p.Next {
+		if next.Missing {
 			nextList = append(nextList, next)
+			continue
 		}
-		p.Next = nextList
-		if len(p.Next) == 0 {
-			return lastErr
+		err := build(next)
+		if err != nil {
+			if err.Status == http.StatusLoopDetected {
+				return err
+			}
+			lastErr = err
+			if p.alignItems() && err.Status == http.StatusNotFound {
+				next.Missing = true
+				next.Next = []*PathNode{}
+				nextList = append(nextList, next)
+			}
+			continue
 		}
-		return nil
+		found++
+		nextList = append(nextList, next)
 	}
-	return p.buildIdxNodes(idx)
+	if found == 0 && lastErr != nil {
+		return lastErr
+	}
+	p.Next = nextList
+	return nil
+}
+
+func (p *PathNode) alignItems() bool {
+	if p.Idx != All || p.AttrDef == nil || p.AttrDef[JsonKey.Type] != JsonKey.Array {
+		return false
+	}
+	return p.root().AlignAll
+}
+
+func (p *PathNode) root() *PathNode {
+	root := p
+	for root.Prev != nil {
+		root = root.Prev
+	}
+	return root
 }
 
 func (p *PathNode) BuildPath(nextPath string) *Http.HttpError {
 	if nextPath == "" {
 		return nil
 	}
-	nextList := []*PathNode{}
-	var lastErr *Http.HttpError
+	if p.Missing {
+		return nil
+	}
 	if len(p.Next) > 0 {
-		for _, next := range p.Next {
-			err := next.BuildPath(nextPath)
-			if err != nil {
-				lastErr = err
-				continue
+		attrName, _, _ := Util.ParseArrayPath(nextPath)
+		err := p.prefetchRefs(p.Next, func(next *PathNode) (string, interface{}) {
+			if data, ok := next.Data.(map[string]interface{}); ok && len(next.Next) == 0 {
+				return attrName, data[attrName]
 			}
-			nextList = append(nextList, next)
-		}
-		p.Next = nextList
-		if len(p.Next) == 0 {
-			return lastErr
+			return "", nil
+		})
+		if err != nil {
+			return err
 		}
+		return p.buildNext(func(next *PathNode) *Http.HttpError {
+			return next.BuildPath(nextPath)
+		})
+	}
+	if Util.IsSliceIdx(p.Idx) {
+		// empty slice, nothing to walk in
 		return nil
 	}
+	if p.Unresolved {
+		return Http.NewHttpError(fmt.Sprintf("ref [%v] not followed, walk reached max ref depth [%d] @path=[%s]", p.Data, p.Conn.MaxRefDepth, p.FullPath()), http.StatusBadRequest)
+	}
 	if p.Schema == nil {
 		return Http.NewHttpError(fmt.Sprintf("cannot walk further with undefined attr=[%s] @path=[%s]", p.AttrName, p.FullPath()), http.StatusBadRequest)
 	}
@@ -292,7 +373,12 @@ func (p *PathNode) BuildPath(nextPath string) *Http.HttpError {
 			if attrIdx != "" {
 				return Http.NewHttpError(fmt.Sprintf("invalid path, missing array key @path=[%s]", p.FullPath()), http.StatusBadRequest)
 			}
-			idxErr := p.buildIdxNodes(attrName)
+			// segment after map is its key, percent-encoded like idx
+			mapKey, ex := Util.UnescapeKey(attrName)
+			if ex != nil {
+				return Http.WrapError(ex, fmt.Sprintf("failed to unescape map key=[%s] @path=[%s]", attrName, p.FullPath()), http.StatusBadRequest)
+			}
+			idxErr := p.buildIdxNodes(mapKey)
 			if idxErr != nil {
 				return idxErr
 			}
@@ -317,8 +403,19 @@ func (p *PathNode) buildArrayIdxNode(idx string) *Http.HttpError {
 	if !isArray {
 		return Http.NewHttpError(fmt.Sprintf("data cannot convert to array. @path=[%s]", p.FullPath()), http.StatusBadRequest)
 	}
+	// [-1] selects item by position from the end, last item is -1
+	position := -1
+	if pos, ok := Util.NegativeIdx(idx); ok {
+		position = len(arrayData) + pos
+		if position < 0 {
+			return Http.NewHttpError(fmt.Sprintf("invalid idx, [%s] out of range of array length [%d] @path=[%s]", idx, len(arrayData), p.FullPath()), http.StatusNotFound)
+		}
+	}
+	// [1:3] selects items by position in range, bounds are clamped to the array
+	sliceStart, sliceEnd, isSlice := Util.SliceRange(idx, len(arrayData))
+	itemKeys := make([]string, 0, len(arrayData))
+	hasKey := false
 	for i, item := range arrayData {
-		selected := false
 		var itemKey string
 		switch itemType {
 		case JsonKey.Object:
@@ -333,7 +430,22 @@ func (p *PathNode) buildArrayIdxNode(idx string) *Http.HttpError {
 		default:
 			itemKey = strconv.Itoa(i)
 		}
-		if idx != All && idx != itemKey {
+		itemKeys = append(itemKeys, itemKey)
+		hasKey = hasKey || itemKey == idx
+	}
+	// [attr=value] selects object items whose attr equals value, only when no item has the key
+	filterAttr, filterValue, isFilter := Util.FilterIdx(idx)
+	isFilter = isFilter && itemType == JsonKey.Object && !hasKey
+	for i, item := range arrayData {
+		if position >= 0 && i != position {
+			continue
+		}
+		if isSlice && (i < sliceStart || i >= sliceEnd) {
+			continue
+		}
+		selected := false
+		itemKey := itemKeys[i]
+		if position < 0 && !isSlice && idx != All && idx != itemKey && !(isFilter && matchFilter(item, filterAttr, filterValue)) {
 			continue
 		}
 		err := p.newIdxNode(itemKey, itemDef, item)
@@ -347,6 +459,18 @@ func (p *PathNode) buildArrayIdxNode(idx string) *Http.HttpError {
 	return nil
 }
 
+func matchFilter(item interface{}, attr string, value string) bool {
+	itemData, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	attrValue, ok := itemData[attr]
+	if !ok || attrValue == nil {
+		return false
+	}
+	return fmt.Sprint(attrValue) == value
+}
+
 func (p *PathNode) buildMapIdxNode(idx string) *Http.HttpError {
 	itemDef, ok := p.AttrDef[JsonKey.AdditionalProperties].(map[string]interface{})
 	if !ok {
@@ -379,24 +503,19 @@ func (p *PathNode) buildMapIdxNode(idx string) *Http.HttpError {
 }
 
 func (p *PathNode) buildCmtNode() *Http.HttpError {
-	attrType := p.AttrDef[JsonKey.Type].(string)
-	if attrType != JsonKey.String {
-		return nil
+	ref, err := p.CmtRef()
+	if err != nil || ref == nil {
+		return err
 	}
-	_, ok := p.AttrDef[JsonKey.ContentMediaType].(string)
-	if !ok {
+	if p.Conn.MaxRefDepth > 0 && p.RefDepth() >= p.Conn.MaxRefDepth {
+		p.Unresolved = true
 		return nil
 	}
-	attrName := p.AttrName
-	if attrName == "" {
-		attrName = p.Prev.AttrName
-	}
-	ref, ok := p.Schema.CmtRefs[attrName]
-	if !ok {
-		return Http.NewHttpError(fmt.Sprintf("failed to find Cmt @path=[%s]", p.FullPath()), http.StatusBadRequest)
+	dataType, dataId, refPath, ex := ref.TargetPath(p.Data.(string))
+	if ex != nil {
+		return Http.WrapError(ex, fmt.Sprintf("invalid ref @path=[%s]", p.FullPath()), http.StatusBadRequest)
 	}
-
-	cmtNode, err := newRecordNode(p.Conn, ref.ContentType, p.Data.(string), p)
+	cmtNode, err := newRecordNode(p.Conn, dataType, dataId, p)
 	if err != nil {
 		return err
 	}
@@ -404,6 +523,103 @@ func (p *PathNode) buildCmtNode() *Http.HttpError {
 	if err != nil {
 		return err
 	}
+	if refPath != "" {
+		err = cmtNode.followRefPath(refPath)
+		if err != nil {
+			return err
+		}
+	}
 	p.Next = append(p.Next, cmtNode)
 	return nil
 }
+
+// fetch targets of refs held by nodes in one call per target type, so following each ref reads from cache.
+// refValue gives attribute name and value of the ref on a node. nothing is done without FuncRecords of connection,
+// each ref is then fetched when it is followed. missing target is left for the walk to report
+func (p *PathNode) prefetchRefs(nodes []*PathNode, refValue func(node *PathNode) (string, interface{})) *Http.HttpError {
+	if p.Conn.FuncRecords == nil || len(nodes) < 2 {
+		return nil
+	}
+	if p.Conn.MaxRefDepth > 0 && p.RefDepth() >= p.Conn.MaxRefDepth {
+		return nil
+	}
+	typeList := []string{}
+	idMap := map[string][]string{}
+	for _, node := range nodes {
+		if node.Missing || node.Schema == nil {
+			continue
+		}
+		attrName, value := refValue(node)
+		ref, ok := node.Schema.CmtRefs[attrName]
+		if !ok {
+			continue
+		}
+		refStr, ok := value.(string)
+		if !ok || refStr == "" {
+			continue
+		}
+		dataType, dataId, _, ex := ref.TargetPath(refStr)
+		if ex != nil {
+			continue
+		}
+		if _, ok := idMap[dataType]; !ok {
+			typeList = append(typeList, dataType)
+		}
+		idMap[dataType] = append(idMap[dataType], dataId)
+	}
+	for _, dataType := range typeList {
+		_, err := p.Conn.GetRecords(dataType, idMap[dataType])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk into attribute path carried by ref value, like {id}/items[01]. ref met on the path is followed as well.
+// reaching a type/id/path already followed in this chain is a circular reference.
+// chain is kept only while it is followed, so the same record can be walked again on another branch or by later steps
+func (p *PathNode) followRefPath(refPath string) *Http.HttpError {
+	root := p.root()
+	hop := fmt.Sprintf("%s/%s/%s", p.DataType, p.DataId, refPath)
+	for idx, followed := range root.refChain {
+		if followed == hop {
+			cycle := append(append([]string{}, root.refChain[idx:]...), hop)
+			return Http.NewHttpError(fmt.Sprintf("circular reference detected [%s] @path=[%s]", strings.Join(cycle, " -> "), p.Prev.FullPath()), http.StatusLoopDetected)
+		}
+	}
+	root.refChain = append(root.refChain, hop)
+	defer func() {
+		root.refChain = root.refChain[:len(root.refChain)-1]
+	}()
+	for refPath != "" {
+		step, nextPath := Util.ParsePath(refPath)
+		err := p.BuildPath(step)
+		if err != nil {
+			return err
+		}
+		refPath = nextPath
+	}
+	return nil
+}
+
+// return CMT ref of current string node, nil when node is not a ref
+func (p *PathNode) CmtRef() (*SchemaDoc.CMTDocRef, *Http.HttpError) {
+	attrType := p.AttrDef[JsonKey.Type].(string)
+	if attrType != JsonKey.String {
+		return nil, nil
+	}
+	_, ok := p.AttrDef[JsonKey.ContentMediaType].(string)
+	if !ok {
+		return nil, nil
+	}
+	attrName := p.AttrName
+	if attrName == "" {
+		attrName = p.Prev.AttrName
+	}
+	ref, ok := p.Schema.CmtRefs[attrName]
+	if !ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("failed to find Cmt @path=[%s]", p.FullPath()), http.StatusBadRequest)
+	}
+	return ref, nil
+}
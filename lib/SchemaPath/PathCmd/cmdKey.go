@@ -26,13 +26,32 @@ This copyright notice and license applies to all files in this directory or sub-
 package PathCmd
 
 const (
-	ALL         = "*"
-	CmdPrefix   = "?"
-	CmdPathName = "?pathName" // get alias from database and use the stored path to query value
-	CmdFlat     = "?flat"     // return flat value at the last step
-	CmdFlatPath = "/$"
-	CmdIter     = "?iterator" // return path information when there is a * in the path
-	CmdRef      = "?ref"      // return reference key of ContentMediaType
-	CmdSchema   = "?schema"   // return schema at the last step
-	CmdValue    = "?value"    // return any value at the last step
+	ALL          = "*"
+	CmdPrefix    = "?"
+	MatchStrict  = "strict"
+	CmdAsMap     = "?asmap"    // return keyed array at the last step as map of item key to item
+	CmdAvg       = "?avg"      // return average of numbers at the last step, array without idx on the way is walked as [*]
+	CmdCount     = "?count"    // return item count of array or key count of map at the last step, null is 0
+	CmdPathName  = "?pathName" // get alias from database and use the stored path to query value
+	CmdEnum      = "?enum"     // return allowed values of attribute at the last step
+	CmdEq        = "?eq"       // ?eq={literal}, return true when value at the last step equals literal
+	CmdFirst     = "?first"    // ?first[=strict], return first item at the last step, nil or 404 when strict if nothing matches
+	CmdFlat      = "?flat"     // return flat value at the last step
+	CmdFlatPath  = "/$"
+	CmdHops      = "?hops"       // return count of refs resolved into another record to walk the path
+	CmdIter      = "?iterator"   // return path information when there is a * in the path
+	CmdKeys      = "?keys"       // return keys of map or item keys of keyed array at the last step
+	CmdLast      = "?last"       // ?last[=strict], return last item at the last step, nil or 404 when strict if nothing matches
+	CmdLen       = "?len"        // return character count of string, item count of array or key count of map at the last step
+	CmdMax       = "?max"        // return max of numbers at the last step, same walk as ?avg
+	CmdMediaType = "?mediatype"  // return contentMediaType declared on attribute at the last step, empty when not declared
+	CmdMin       = "?min"        // return min of numbers at the last step, same walk as ?avg
+	CmdNe        = "?ne"         // ?ne={literal}, return true when value at the last step not equals literal
+	CmdRecord    = "?record"     // return full envelope of the record that holds value at the last step, the ref target when path crossed or ends at a ref
+	CmdRef       = "?ref"        // return reference key of ContentMediaType
+	CmdRequired  = "?required"   // return required attribute names of object or item schema at the last step
+	CmdSchema    = "?schema"     // return schema at the last step
+	CmdSchemaRef = "?schema=ref" // return schema of ref target at the last step, oneOf candidates for polymorphic ref
+	CmdSum       = "?sum"        // return sum of numbers at the last step, same walk as ?avg
+	CmdValue     = "?value"      // return any value at the last step
 )
@@ -34,7 +34,7 @@ import (
 	"github.com/salesforce/UniTAO/lib/Util/Http"
 )
 
-var CmdList = []string{CmdRef, CmdFlat, CmdSchema, CmdValue, CmdIter, CmdPathName}
+var CmdList = []string{CmdRef, CmdFlat, CmdSchema, CmdValue, CmdIter, CmdPathName, CmdAsMap, CmdEnum, CmdEq, CmdNe, CmdSchemaRef, CmdLen, CmdFirst, CmdLast, CmdRequired, CmdRecord, CmdSum, CmdMin, CmdMax, CmdAvg, CmdHops, CmdMediaType, CmdCount, CmdKeys}
 
 func Parse(path string) (string, string, *Http.HttpError) {
 	if strings.HasSuffix(path, CmdFlatPath) {
@@ -66,8 +66,10 @@ func Validate(cmd string) *Http.HttpError {
 			return nil
 		}
 	}
-	if strings.HasPrefix(cmd, fmt.Sprintf("%s=", CmdPathName)) {
-		return nil
+	for _, c := range []string{CmdPathName, CmdEq, CmdNe, CmdFirst, CmdLast} {
+		if strings.HasPrefix(cmd, fmt.Sprintf("%s=", c)) {
+			return nil
+		}
 	}
 	e := Http.NewHttpError(fmt.Sprintf("unknown path cmd=[%s]", cmd), http.StatusBadRequest)
 	cmdListStr, _ := json.MarshalIndent(CmdList, "", "     ")
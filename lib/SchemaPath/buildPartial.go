@@ -0,0 +1,93 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// minimal nested object that holds value at path, like data/value/value1 with x gives {"data": {"value": {"value1": "x"}}}.
+// array item is built only from filter idx like items[name=a], which becomes the one item {"name": "a", ...}.
+// other idx can not tell array from map without schema, nor build key of item, so it is an error
+func BuildPartial(pathExpr string, value interface{}) (map[string]interface{}, error) {
+	if strings.Contains(pathExpr, PathCmd.CmdPrefix) {
+		return nil, Http.NewHttpError(fmt.Sprintf("path [%s] with cmd can not build partial record", pathExpr), http.StatusBadRequest)
+	}
+	partial := map[string]interface{}{}
+	current := partial
+	remainPath := pathExpr
+	for {
+		step, nextPath := Util.ParsePath(remainPath)
+		if step == "" {
+			return nil, Http.NewHttpError(fmt.Sprintf("invalid path [%s], empty step", pathExpr), http.StatusBadRequest)
+		}
+		attrName, idx, ex := Util.ParseArrayPath(step)
+		if ex != nil {
+			return nil, Http.WrapError(ex, fmt.Sprintf("failed to parse step [%s] of path [%s]", step, pathExpr), http.StatusBadRequest)
+		}
+		if idx == "" {
+			if nextPath == "" {
+				current[attrName] = value
+				return partial, nil
+			}
+			next := map[string]interface{}{}
+			current[attrName] = next
+			current = next
+			remainPath = nextPath
+			continue
+		}
+		keyAttr, keyValue, isFilter := Util.FilterIdx(idx)
+		if !isFilter || keyAttr == "" {
+			return nil, Http.NewHttpError(fmt.Sprintf("can not build item of [%s] from idx [%s] without schema, use [attr=value] to give key of item. path=[%s]", attrName, idx, pathExpr), http.StatusBadRequest)
+		}
+		item := map[string]interface{}{}
+		if nextPath == "" {
+			valueMap, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, Http.NewHttpError(fmt.Sprintf("value of item [%s] must be an object, got [%T]. path=[%s]", step, value, pathExpr), http.StatusBadRequest)
+			}
+			if itemKey, ok := valueMap[keyAttr]; ok && itemKey != keyValue {
+				return nil, Http.NewHttpError(fmt.Sprintf("value has [%s]=[%v], not match idx [%s]. path=[%s]", keyAttr, itemKey, idx, pathExpr), http.StatusBadRequest)
+			}
+			for key, attrValue := range valueMap {
+				item[key] = attrValue
+			}
+		}
+		item[keyAttr] = keyValue
+		current[attrName] = []interface{}{item}
+		if nextPath == "" {
+			return partial, nil
+		}
+		current = item
+		remainPath = nextPath
+	}
+}
@@ -0,0 +1,192 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// sum/min/max/avg of numbers at the end of path, like attrArray/someNumber?sum.
+// array or map without idx in the middle of path is walked as [*], array or map of numbers at the last step is aggregated by items.
+// null value and item missing the attribute are skipped. sum of nothing is 0, min/max/avg of nothing is nil
+type CmdQueryAggregate struct {
+	p     *Node.PathNode
+	cmd   string
+	empty bool
+}
+
+func NewAggregateQuery(conn *Data.Connection, dataType string, dataId string, path string, pathCmd string) (*CmdQueryAggregate, *Http.HttpError) {
+	node, err := Node.New(conn, dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	for path != "" {
+		stepPath, stepNext := Util.ParsePath(path)
+		err = node.BuildPath(stepPath)
+		if err != nil {
+			return nil, err
+		}
+		if stepNext != "" {
+			hasItems, err := walkAllItems(node)
+			if err != nil {
+				return nil, err
+			}
+			if !hasItems {
+				// every array on the way is empty, nothing to aggregate
+				return &CmdQueryAggregate{p: node, cmd: pathCmd, empty: true}, nil
+			}
+		}
+		path = stepNext
+	}
+	return &CmdQueryAggregate{
+		p:   node,
+		cmd: pathCmd,
+	}, nil
+}
+
+// build [*] on array or map at the end of path built so far that has no idx.
+// return false when nothing is left to walk further because all of them are empty
+func walkAllItems(node *Node.PathNode) (bool, *Http.HttpError) {
+	hasItems := false
+	for _, leaf := range valueNodes(node) {
+		if leaf.AttrName == "" || itemDef(leaf.AttrDef) == nil {
+			hasItems = true
+			continue
+		}
+		err := leaf.BuildIdx(Node.All)
+		if err != nil {
+			return false, err
+		}
+		if len(leaf.Next) > 0 {
+			hasItems = true
+		}
+	}
+	return hasItems, nil
+}
+
+// item definition of array or map, nil for other types
+func itemDef(attrDef map[string]interface{}) map[string]interface{} {
+	if attrDef == nil {
+		return nil
+	}
+	switch attrDef[JsonKey.Type] {
+	case JsonKey.Array, JsonKey.Map:
+		def, _ := attrDef[JsonKey.Items].(map[string]interface{})
+		return def
+	case JsonKey.Object:
+		def, _ := attrDef[JsonKey.AdditionalProperties].(map[string]interface{})
+		return def
+	}
+	return nil
+}
+
+func (c *CmdQueryAggregate) Name() string {
+	return c.cmd
+}
+
+func (c *CmdQueryAggregate) WalkValue() (interface{}, *Http.HttpError) {
+	leafList := valueNodes(c.p)
+	if c.empty {
+		leafList = nil
+	}
+	numbers := []float64{}
+	for _, leaf := range leafList {
+		if !isNumericDef(leaf.AttrDef) && !isNumericDef(itemDefOf(leaf)) {
+			return nil, Http.NewHttpError(fmt.Sprintf("[%s] only works on [%s] or [%s], @path=[%s]", c.cmd, JsonKey.Integer, JsonKey.Number, leaf.FullPath()), http.StatusBadRequest)
+		}
+		numbers = appendNumbers(numbers, leaf.Data)
+	}
+	if c.cmd == PathCmd.CmdSum {
+		sum := 0.0
+		for _, number := range numbers {
+			sum += number
+		}
+		return sum, nil
+	}
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+	result := numbers[0]
+	for _, number := range numbers[1:] {
+		switch c.cmd {
+		case PathCmd.CmdMin:
+			if number < result {
+				result = number
+			}
+		case PathCmd.CmdMax:
+			if number > result {
+				result = number
+			}
+		case PathCmd.CmdAvg:
+			result += number
+		}
+	}
+	if c.cmd == PathCmd.CmdAvg {
+		result = result / float64(len(numbers))
+	}
+	return result, nil
+}
+
+func itemDefOf(node *Node.PathNode) map[string]interface{} {
+	if node.AttrDef == nil || node.Idx != "" {
+		return nil
+	}
+	return itemDef(node.AttrDef)
+}
+
+func isNumericDef(attrDef map[string]interface{}) bool {
+	if attrDef == nil {
+		return false
+	}
+	attrType := attrDef[JsonKey.Type]
+	return attrType == JsonKey.Integer || attrType == JsonKey.Number
+}
+
+func appendNumbers(numbers []float64, data interface{}) []float64 {
+	switch value := data.(type) {
+	case float64:
+		numbers = append(numbers, value)
+	case int:
+		numbers = append(numbers, float64(value))
+	case []interface{}:
+		for _, item := range value {
+			numbers = appendNumbers(numbers, item)
+		}
+	case map[string]interface{}:
+		for _, item := range value {
+			numbers = appendNumbers(numbers, item)
+		}
+	}
+	return numbers
+}
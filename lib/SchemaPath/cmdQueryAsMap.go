@@ -0,0 +1,109 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+type CmdQueryAsMap struct {
+	p *Node.PathNode
+}
+
+func NewAsMapQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryAsMap, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQueryAsMap{
+		p: node,
+	}, nil
+}
+
+func (c *CmdQueryAsMap) Name() string {
+	return PathCmd.CmdAsMap
+}
+
+func (c *CmdQueryAsMap) WalkValue() (interface{}, *Http.HttpError) {
+	dataList, err := c.GetNodeValue(c.p)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataList) == 1 {
+		return dataList[0], nil
+	}
+	return dataList, nil
+}
+
+func (c *CmdQueryAsMap) GetNodeValue(node *Node.PathNode) ([]interface{}, *Http.HttpError) {
+	if len(node.Next) == 0 {
+		keyedMap, err := c.KeyedMap(node)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{keyedMap}, nil
+	}
+	dataList := []interface{}{}
+	for _, next := range node.Next {
+		result, err := c.GetNodeValue(next)
+		if err != nil {
+			return nil, err
+		}
+		dataList = append(dataList, result...)
+	}
+	return dataList, nil
+}
+
+// convert array of keyed object into map of {key: item}
+func (c *CmdQueryAsMap) KeyedMap(node *Node.PathNode) (map[string]interface{}, *Http.HttpError) {
+	if node.IsRecord() || node.AttrDef[JsonKey.Type].(string) != JsonKey.Array {
+		return nil, Http.NewHttpError(fmt.Sprintf("[%s] only works on array, @path=[%s]", PathCmd.CmdAsMap, node.FullPath()), http.StatusBadRequest)
+	}
+	itemDef := node.AttrDef[JsonKey.Items].(map[string]interface{})
+	itemDoc, ok := node.Schema.SubDocs[node.AttrName]
+	if itemDef[JsonKey.Type].(string) != JsonKey.Object || !ok || len(itemDoc.KeyTemplate.Vars) == 0 {
+		return nil, Http.NewHttpError(fmt.Sprintf("[%s] requires array items with key template, @path=[%s]", PathCmd.CmdAsMap, node.FullPath()), http.StatusBadRequest)
+	}
+	err := node.BuildIdx(Node.All)
+	if err != nil {
+		return nil, err
+	}
+	keyedMap := make(map[string]interface{}, len(node.Next))
+	for _, next := range node.Next {
+		if _, ok := keyedMap[next.Idx]; ok {
+			return nil, Http.NewHttpError(fmt.Sprintf("duplicate item key=[%s], @path=[%s]", next.Idx, node.FullPath()), http.StatusConflict)
+		}
+		keyedMap[next.Idx] = next.Data
+	}
+	return keyedMap, nil
+}
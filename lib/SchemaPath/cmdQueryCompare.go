@@ -0,0 +1,149 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// compare value at the end of path with literal, literal is converted to type of the attribute defined in schema.
+// path not exists is treated as value not equal to any literal, so ?eq returns false and ?ne returns true
+type CmdQueryCompare struct {
+	p       *Node.PathNode
+	cmd     string
+	literal string
+}
+
+func IsCmdCompare(cmd string) bool {
+	for _, c := range []string{PathCmd.CmdEq, PathCmd.CmdNe} {
+		if cmd == c || strings.HasPrefix(cmd, fmt.Sprintf("%s=", c)) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewCompareQuery(conn *Data.Connection, dataType string, dataId string, path string, pathCmd string) (*CmdQueryCompare, *Http.HttpError) {
+	if !IsCmdCompare(pathCmd) {
+		return nil, Http.NewHttpError(fmt.Sprintf("invalid pathCmd in Url, expect format [{path}%s={literal}] or [{path}%s={literal}]", PathCmd.CmdEq, PathCmd.CmdNe), http.StatusBadRequest)
+	}
+	cmd, literal, _ := strings.Cut(pathCmd, "=")
+	query := CmdQueryCompare{
+		cmd:     cmd,
+		literal: literal,
+	}
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		if err.Status != http.StatusNotFound {
+			return nil, err
+		}
+		return &query, nil
+	}
+	query.p = node
+	return &query, nil
+}
+
+func (c *CmdQueryCompare) Name() string {
+	return c.cmd
+}
+
+func (c *CmdQueryCompare) WalkValue() (interface{}, *Http.HttpError) {
+	equal := false
+	if c.p != nil {
+		leafList := leafNodes(c.p)
+		if len(leafList) != 1 {
+			return nil, Http.NewHttpError(fmt.Sprintf("[%s] expect path to 1 value, got [%d] @path=[%s]", c.cmd, len(leafList), c.p.FullPath()), http.StatusBadRequest)
+		}
+		isEqual, err := c.compare(leafList[0])
+		if err != nil {
+			return nil, err
+		}
+		equal = isEqual
+	}
+	if c.cmd == PathCmd.CmdNe {
+		return !equal, nil
+	}
+	return equal, nil
+}
+
+// nodes at the end of path, record nodes followed from a ref value are not walked into
+func leafNodes(node *Node.PathNode) []*Node.PathNode {
+	if len(node.Next) == 0 || (!node.IsRecord() && node.Next[0].IsRecord()) {
+		return []*Node.PathNode{node}
+	}
+	leafList := []*Node.PathNode{}
+	for _, next := range node.Next {
+		leafList = append(leafList, leafNodes(next)...)
+	}
+	return leafList
+}
+
+func (c *CmdQueryCompare) compare(node *Node.PathNode) (bool, *Http.HttpError) {
+	if node.Data == nil {
+		return false, nil
+	}
+	attrType := JsonKey.String
+	if node.AttrDef != nil {
+		attrType, _ = node.AttrDef[JsonKey.Type].(string)
+	}
+	if node.IsRecord() {
+		attrType = JsonKey.Object
+	}
+	switch attrType {
+	case JsonKey.String:
+		value, ok := node.Data.(string)
+		return ok && value == c.literal, nil
+	case JsonKey.Integer, JsonKey.Number:
+		literal, ex := strconv.ParseFloat(c.literal, 64)
+		if ex != nil {
+			return false, Http.NewHttpError(fmt.Sprintf("cannot convert literal [%s] to [%s] @path=[%s]", c.literal, attrType, node.FullPath()), http.StatusBadRequest)
+		}
+		switch value := node.Data.(type) {
+		case float64:
+			return value == literal, nil
+		case int:
+			return float64(value) == literal, nil
+		}
+		return false, nil
+	case JsonKey.Boolean:
+		literal, ex := strconv.ParseBool(c.literal)
+		if ex != nil {
+			return false, Http.NewHttpError(fmt.Sprintf("cannot convert literal [%s] to [%s] @path=[%s]", c.literal, attrType, node.FullPath()), http.StatusBadRequest)
+		}
+		value, ok := node.Data.(bool)
+		return ok && value == literal, nil
+	}
+	return false, Http.NewHttpError(fmt.Sprintf("[%s] not supported on type [%s] @path=[%s]", c.cmd, attrType, node.FullPath()), http.StatusBadRequest)
+}
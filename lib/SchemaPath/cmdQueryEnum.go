@@ -0,0 +1,88 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+type CmdQueryEnum struct {
+	p *Node.PathNode
+}
+
+func NewEnumQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryEnum, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQueryEnum{
+		p: node,
+	}, nil
+}
+
+func (c *CmdQueryEnum) Name() string {
+	return PathCmd.CmdEnum
+}
+
+func (c *CmdQueryEnum) WalkValue() (interface{}, *Http.HttpError) {
+	dataList, err := c.GetNodeEnum(c.p)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataList) == 1 {
+		return dataList[0], nil
+	}
+	return dataList, nil
+}
+
+func (c *CmdQueryEnum) GetNodeEnum(node *Node.PathNode) ([]interface{}, *Http.HttpError) {
+	if len(node.Next) > 0 {
+		enumList := []interface{}{}
+		for _, next := range node.Next {
+			valueList, err := c.GetNodeEnum(next)
+			if err != nil {
+				return nil, err
+			}
+			enumList = append(enumList, valueList...)
+		}
+		return enumList, nil
+	}
+	if node.IsRecord() || node.AttrDef == nil {
+		return nil, Http.NewHttpError(fmt.Sprintf("[%s] only works on defined attribute, @path=[%s]", PathCmd.CmdEnum, node.FullPath()), http.StatusBadRequest)
+	}
+	enum, ok := SchemaDoc.AttrEnum(node.AttrDef)
+	if !ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("attribute has no enum defined, @path=[%s]", node.FullPath()), http.StatusBadRequest)
+	}
+	return []interface{}{enum}, nil
+}
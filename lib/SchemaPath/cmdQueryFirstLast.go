@@ -0,0 +1,102 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// single first or last item at the end of path, like attrArray[key1=01]?first.
+// when nothing matches, return nil, or 404 with ?first=strict and ?last=strict
+type CmdQueryFirstLast struct {
+	p      *Node.PathNode
+	cmd    string
+	strict bool
+}
+
+func IsCmdFirstLast(cmd string) bool {
+	for _, c := range []string{PathCmd.CmdFirst, PathCmd.CmdLast} {
+		if cmd == c || strings.HasPrefix(cmd, fmt.Sprintf("%s=", c)) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewFirstLastQuery(conn *Data.Connection, dataType string, dataId string, path string, pathCmd string) (*CmdQueryFirstLast, *Http.HttpError) {
+	cmd, option, hasOption := strings.Cut(pathCmd, "=")
+	if !IsCmdFirstLast(pathCmd) || hasOption && option != PathCmd.MatchStrict {
+		return nil, Http.NewHttpError(fmt.Sprintf("invalid pathCmd in Url, expect [%s], [%s] with optional [=%s]", PathCmd.CmdFirst, PathCmd.CmdLast, PathCmd.MatchStrict), http.StatusBadRequest)
+	}
+	query := CmdQueryFirstLast{
+		cmd:    cmd,
+		strict: option == PathCmd.MatchStrict,
+	}
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		if err.Status != http.StatusNotFound || query.strict {
+			return nil, err
+		}
+		return &query, nil
+	}
+	query.p = node
+	return &query, nil
+}
+
+func (c *CmdQueryFirstLast) Name() string {
+	return c.cmd
+}
+
+func (c *CmdQueryFirstLast) WalkValue() (interface{}, *Http.HttpError) {
+	if c.p == nil {
+		return nil, nil
+	}
+	valueQuery := CmdQueryValue{p: c.p}
+	dataList := valueQuery.GetNodeValue(c.p)
+	if len(dataList) == 1 {
+		// matched items or array at the end of path come back wrapped as 1 list
+		if items, ok := dataList[0].([]interface{}); ok {
+			dataList = items
+		}
+	}
+	if len(dataList) == 0 {
+		if c.strict {
+			return nil, Http.NewHttpError(fmt.Sprintf("[%s] found no item @path=[%s]", c.cmd, c.p.FullPath()), http.StatusNotFound)
+		}
+		return nil, nil
+	}
+	if c.cmd == PathCmd.CmdLast {
+		return dataList[len(dataList)-1], nil
+	}
+	return dataList[0], nil
+}
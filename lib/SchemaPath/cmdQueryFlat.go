@@ -89,6 +89,10 @@ func (c *CmdQueryFlat) GetNodeValue(node *Node.PathNode) ([]interface{}, *Http.H
 }
 
 func (c *CmdQueryFlat) getSingleNodeValue(node *Node.PathNode) ([]interface{}, *Http.HttpError) {
+	if len(node.Next) == 0 && Util.IsSliceIdx(node.Idx) {
+		// empty slice flats to nothing, not the whole array
+		return []interface{}{}, nil
+	}
 	if node.IsRecord() {
 		flatObj, err := c.FlatObject(node)
 		if err != nil {
@@ -0,0 +1,57 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// number of refs resolved into another record while walking the path.
+// path with * counts hops of every branch, so it shows how expensive the path is
+type CmdQueryHops struct {
+	p *Node.PathNode
+}
+
+func NewHopsQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryHops, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQueryHops{
+		p: node,
+	}, nil
+}
+
+func (c *CmdQueryHops) Name() string {
+	return PathCmd.CmdHops
+}
+
+func (c *CmdQueryHops) WalkValue() (interface{}, *Http.HttpError) {
+	return c.p.Hops(), nil
+}
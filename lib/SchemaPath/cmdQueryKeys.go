@@ -0,0 +1,121 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// keys of collection at the end of path:
+// map gives its keys in sorted order, array of keyed object gives item keys in item order, null gives no key
+type CmdQueryKeys struct {
+	p *Node.PathNode
+}
+
+func NewKeysQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryKeys, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQueryKeys{
+		p: node,
+	}, nil
+}
+
+func (c *CmdQueryKeys) Name() string {
+	return PathCmd.CmdKeys
+}
+
+func (c *CmdQueryKeys) WalkValue() (interface{}, *Http.HttpError) {
+	leafList := leafNodes(c.p)
+	dataList := make([]interface{}, 0, len(leafList))
+	for _, leaf := range leafList {
+		keys, err := c.GetNodeKeys(leaf)
+		if err != nil {
+			return nil, err
+		}
+		dataList = append(dataList, keys)
+	}
+	if len(dataList) == 1 {
+		return dataList[0], nil
+	}
+	return dataList, nil
+}
+
+func (c *CmdQueryKeys) GetNodeKeys(node *Node.PathNode) ([]string, *Http.HttpError) {
+	if node.IsRecord() {
+		return nil, Http.NewHttpError(fmt.Sprintf("[%s] only available on map or keyed array, not on record, @path=[%s]", PathCmd.CmdKeys, node.FullPath()), http.StatusBadRequest)
+	}
+	attrType := ""
+	if node.AttrDef != nil {
+		attrType, _ = node.AttrDef[JsonKey.Type].(string)
+	}
+	switch value := node.Data.(type) {
+	case map[string]interface{}:
+		if SchemaDoc.IsMap(node.AttrDef) {
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			return keys, nil
+		}
+	case []interface{}:
+		return c.itemKeys(node)
+	case nil:
+		if attrType == JsonKey.Array || SchemaDoc.IsMap(node.AttrDef) {
+			return []string{}, nil
+		}
+	}
+	return nil, Http.NewHttpError(fmt.Sprintf("[%s] only available on map or keyed array, not on type [%s], @path=[%s]", PathCmd.CmdKeys, attrType, node.FullPath()), http.StatusBadRequest)
+}
+
+// keys built from key template of array items
+func (c *CmdQueryKeys) itemKeys(node *Node.PathNode) ([]string, *Http.HttpError) {
+	itemDef, _ := node.AttrDef[JsonKey.Items].(map[string]interface{})
+	itemDoc, ok := node.Schema.SubDocs[node.AttrName]
+	if itemDef[JsonKey.Type] != JsonKey.Object || !ok || len(itemDoc.KeyTemplate.Vars) == 0 {
+		return nil, Http.NewHttpError(fmt.Sprintf("[%s] requires array items with key template, @path=[%s]", PathCmd.CmdKeys, node.FullPath()), http.StatusBadRequest)
+	}
+	err := node.BuildIdx(Node.All)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(node.Next))
+	for _, next := range node.Next {
+		keys = append(keys, next.Idx)
+	}
+	return keys, nil
+}
@@ -0,0 +1,113 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// length of value at the end of path:
+// string is counted by characters, array by items, map by keys, null is 0.
+// object and other scalars have no length. ?count is the same except string has no length either
+type CmdQueryLen struct {
+	p   *Node.PathNode
+	cmd string
+}
+
+func NewLenQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryLen, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQueryLen{
+		p:   node,
+		cmd: PathCmd.CmdLen,
+	}, nil
+}
+
+// size of array or map at the end of path, without transferring it
+func NewCountQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryLen, *Http.HttpError) {
+	query, err := NewLenQuery(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	query.cmd = PathCmd.CmdCount
+	return query, nil
+}
+
+func (c *CmdQueryLen) Name() string {
+	return c.cmd
+}
+
+func (c *CmdQueryLen) WalkValue() (interface{}, *Http.HttpError) {
+	leafList := leafNodes(c.p)
+	dataList := make([]interface{}, 0, len(leafList))
+	for _, leaf := range leafList {
+		length, err := c.GetNodeLen(leaf)
+		if err != nil {
+			return nil, err
+		}
+		dataList = append(dataList, length)
+	}
+	if len(dataList) == 1 {
+		return dataList[0], nil
+	}
+	return dataList, nil
+}
+
+func (c *CmdQueryLen) GetNodeLen(node *Node.PathNode) (int, *Http.HttpError) {
+	if node.IsRecord() {
+		return 0, Http.NewHttpError(fmt.Sprintf("[%s] not supported on record, @path=[%s]", c.cmd, node.FullPath()), http.StatusBadRequest)
+	}
+	switch value := node.Data.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		if c.cmd == PathCmd.CmdLen {
+			return utf8.RuneCountInString(value), nil
+		}
+	case []interface{}:
+		return len(value), nil
+	case map[string]interface{}:
+		if node.AttrDef != nil && SchemaDoc.IsMap(node.AttrDef) {
+			return len(value), nil
+		}
+	}
+	attrType := ""
+	if node.AttrDef != nil {
+		attrType, _ = node.AttrDef[JsonKey.Type].(string)
+	}
+	return 0, Http.NewHttpError(fmt.Sprintf("[%s] not supported on type [%s], @path=[%s]", c.cmd, attrType, node.FullPath()), http.StatusBadRequest)
+}
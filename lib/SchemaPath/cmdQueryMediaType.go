@@ -0,0 +1,70 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// contentMediaType declared on attribute at the end of path, like inventory/{dataType} of a ref.
+// empty string for attribute without it and for record
+type CmdQueryMediaType struct {
+	p *Node.PathNode
+}
+
+func NewMediaTypeQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryMediaType, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQueryMediaType{
+		p: node,
+	}, nil
+}
+
+func (c *CmdQueryMediaType) Name() string {
+	return PathCmd.CmdMediaType
+}
+
+func (c *CmdQueryMediaType) WalkValue() (interface{}, *Http.HttpError) {
+	leafList := leafNodes(c.p)
+	dataList := make([]interface{}, 0, len(leafList))
+	for _, leaf := range leafList {
+		mediaType := ""
+		if !leaf.IsRecord() {
+			mediaType, _ = leaf.AttrDef[JsonKey.ContentMediaType].(string)
+		}
+		dataList = append(dataList, mediaType)
+	}
+	if len(dataList) == 1 {
+		return dataList[0], nil
+	}
+	return dataList, nil
+}
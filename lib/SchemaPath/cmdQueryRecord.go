@@ -0,0 +1,92 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// full record envelope that holds value at the end of path.
+// when path crossed a ref, it is the referenced record, otherwise the record path starts from
+type CmdQueryRecord struct {
+	p *Node.PathNode
+}
+
+func NewRecordQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryRecord, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQueryRecord{
+		p: node,
+	}, nil
+}
+
+func (c *CmdQueryRecord) Name() string {
+	return PathCmd.CmdRecord
+}
+
+func (c *CmdQueryRecord) WalkValue() (interface{}, *Http.HttpError) {
+	recordList := []interface{}{}
+	recordKeys := map[string]bool{}
+	for _, leaf := range valueNodes(c.p) {
+		recordNode := leaf
+		for !recordNode.IsRecord() {
+			recordNode = recordNode.Prev
+		}
+		recordKey := fmt.Sprintf("%s/%s", recordNode.DataType, recordNode.DataId)
+		if recordKeys[recordKey] {
+			continue
+		}
+		recordKeys[recordKey] = true
+		record, err := recordNode.Conn.GetRecord(recordNode.DataType, recordNode.DataId)
+		if err != nil {
+			return nil, err
+		}
+		recordList = append(recordList, record.Map())
+	}
+	if len(recordList) == 1 {
+		return recordList[0], nil
+	}
+	return recordList, nil
+}
+
+// nodes that hold value at the end of path, path ends at a ref resolves to the referenced record as value query does
+func valueNodes(node *Node.PathNode) []*Node.PathNode {
+	if len(node.Next) == 0 {
+		return []*Node.PathNode{node}
+	}
+	nodeList := []*Node.PathNode{}
+	for _, next := range node.Next {
+		nodeList = append(nodeList, valueNodes(next)...)
+	}
+	return nodeList
+}
@@ -0,0 +1,93 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// required attribute names of object at the end of path,
+// array or map of objects returns required attributes of its item schema
+type CmdQueryRequired struct {
+	p *Node.PathNode
+}
+
+func NewRequiredQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryRequired, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQueryRequired{
+		p: node,
+	}, nil
+}
+
+func (c *CmdQueryRequired) Name() string {
+	return PathCmd.CmdRequired
+}
+
+func (c *CmdQueryRequired) WalkValue() (interface{}, *Http.HttpError) {
+	dataList, err := c.GetNodeRequired(c.p)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataList) == 1 {
+		return dataList[0], nil
+	}
+	return dataList, nil
+}
+
+func (c *CmdQueryRequired) GetNodeRequired(node *Node.PathNode) ([]interface{}, *Http.HttpError) {
+	if len(node.Next) > 0 {
+		requiredList := []interface{}{}
+		for _, next := range node.Next {
+			valueList, err := c.GetNodeRequired(next)
+			if err != nil {
+				return nil, err
+			}
+			requiredList = append(requiredList, valueList...)
+		}
+		return requiredList, nil
+	}
+	if node.IsRecord() || node.AttrDef[JsonKey.Type].(string) == JsonKey.Object && !SchemaDoc.IsMap(node.AttrDef) {
+		return []interface{}{node.Schema.RequiredAttrs()}, nil
+	}
+	if node.Idx == "" || node.Idx == Node.All {
+		if itemDoc, ok := node.Schema.SubDocs[node.AttrName]; ok {
+			return []interface{}{itemDoc.RequiredAttrs()}, nil
+		}
+	}
+	attrType, _ := node.AttrDef[JsonKey.Type].(string)
+	return nil, Http.NewHttpError(fmt.Sprintf("[%s] not supported on type [%s], expect object or array/map of object, @path=[%s]", PathCmd.CmdRequired, attrType, node.FullPath()), http.StatusBadRequest)
+}
@@ -0,0 +1,114 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+type CmdQuerySchemaRef struct {
+	p *Node.PathNode
+}
+
+func NewSchemaRefQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQuerySchemaRef, *Http.HttpError) {
+	node, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return nil, err
+	}
+	return &CmdQuerySchemaRef{
+		p: node,
+	}, nil
+}
+
+func (c *CmdQuerySchemaRef) Name() string {
+	return PathCmd.CmdSchemaRef
+}
+
+func (c *CmdQuerySchemaRef) WalkValue() (interface{}, *Http.HttpError) {
+	dataList, err := c.GetNodeSchemaRef(c.p)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataList) == 1 {
+		return dataList[0], nil
+	}
+	return dataList, nil
+}
+
+func (c *CmdQuerySchemaRef) GetNodeSchemaRef(node *Node.PathNode) ([]interface{}, *Http.HttpError) {
+	if !node.IsRecord() && node.AttrDef != nil {
+		ref, err := node.CmtRef()
+		if err != nil {
+			return nil, err
+		}
+		if ref != nil {
+			// stop at the ref, do not walk into the target record
+			schema, err := c.refSchema(node.Conn, ref)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{schema}, nil
+		}
+	}
+	if len(node.Next) == 0 {
+		return nil, Http.NewHttpError(fmt.Sprintf("[%s] only works on [%s] ref, @path=[%s]", PathCmd.CmdSchemaRef, JsonKey.ContentMediaType, node.FullPath()), http.StatusBadRequest)
+	}
+	schemaList := []interface{}{}
+	for _, next := range node.Next {
+		valueList, err := c.GetNodeSchemaRef(next)
+		if err != nil {
+			return nil, err
+		}
+		schemaList = append(schemaList, valueList...)
+	}
+	return schemaList, nil
+}
+
+// single target type returns its schema, polymorphic ref returns oneOf all candidate schemas
+func (c *CmdQuerySchemaRef) refSchema(conn *Data.Connection, ref *SchemaDoc.CMTDocRef) (interface{}, *Http.HttpError) {
+	candidates := make([]interface{}, 0, len(ref.ContentTypes))
+	for _, contentType := range ref.ContentTypes {
+		schema, err := conn.GetSchema(contentType)
+		if err != nil {
+			return nil, Http.WrapError(err, fmt.Sprintf("failed to get schema of ref type [%s]", contentType), err.Status)
+		}
+		candidates = append(candidates, schema.RAW)
+	}
+	if !ref.IsPolymorphic() {
+		return candidates[0], nil
+	}
+	return map[string]interface{}{
+		JsonKey.OneOf: candidates,
+	}, nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
 	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
 	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util"
 	"github.com/salesforce/UniTAO/lib/Util/Http"
 )
 
@@ -39,7 +40,8 @@ type CmdQueryValue struct {
 }
 
 func NewValueQuery(conn *Data.Connection, dataType string, dataId string, path string) (*CmdQueryValue, *Http.HttpError) {
-	node, err := BuildNodePath(conn, dataType, dataId, path)
+	// item of array[*] without the rest of path gives nil at its position
+	node, err := buildNodePath(conn, dataType, dataId, path, true)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +71,12 @@ func (c *CmdQueryValue) WalkValue() (interface{}, *Http.HttpError) {
 }
 
 func (c *CmdQueryValue) GetNodeValue(node *Node.PathNode) []interface{} {
-	if len(node.Next) == 0 {
+	if node.Missing {
+		return []interface{}{nil}
+	}
+	// [*] and slice keep their items as one list, so nested [*] with one item does not collapse into its parent list
+	isList := node.Idx == Node.All || Util.IsSliceIdx(node.Idx)
+	if len(node.Next) == 0 && !isList {
 		return []interface{}{node.Data}
 	}
 	dataList := []interface{}{}
@@ -84,5 +91,8 @@ func (c *CmdQueryValue) GetNodeValue(node *Node.PathNode) []interface{} {
 			}
 		}
 	}
+	if isList {
+		return []interface{}{dataList}
+	}
 	return dataList
 }
@@ -0,0 +1,124 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// path after record id, like attrArray[*]/key1?len. parsed once and walked on every record of a type
+type CompiledPath struct {
+	Path string
+	Cmd  string
+}
+
+// value of path on one record, Id is empty when records of type cannot be listed
+type PathResult struct {
+	Id    string          `json:"id"`
+	Value interface{}     `json:"value,omitempty"`
+	Err   *Http.HttpError `json:"error,omitempty"`
+}
+
+// records of type cannot be listed, the stream has no more result after it
+func (r PathResult) StreamErr() error {
+	if r.Id == "" && r.Err != nil {
+		return r.Err
+	}
+	return nil
+}
+
+func Compile(path string) (*CompiledPath, *Http.HttpError) {
+	qPath, qCmd, err := PathCmd.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if IsCmdPathName(qCmd) {
+		return nil, Http.NewHttpError(fmt.Sprintf("[%s] walks stored path of record, cannot be compiled", PathCmd.CmdPathName), http.StatusBadRequest)
+	}
+	return &CompiledPath{
+		Path: qPath,
+		Cmd:  qCmd,
+	}, nil
+}
+
+func (p *CompiledPath) Walk(conn *Data.Connection, dataType string, dataId string) (interface{}, *Http.HttpError) {
+	return p.WalkContext(context.Background(), conn, dataType, dataId)
+}
+
+// walk path on one record, stop with error before next fetch once ctx is done
+func (p *CompiledPath) WalkContext(ctx context.Context, conn *Data.Connection, dataType string, dataId string) (interface{}, *Http.HttpError) {
+	dataPath := Util.EscapeKey(dataId)
+	if p.Path != "" {
+		dataPath = fmt.Sprintf("%s/%s", dataPath, p.Path)
+	}
+	query, err := CreateQueryContext(ctx, conn, dataType, fmt.Sprintf("%s%s", dataPath, p.Cmd))
+	if err != nil {
+		return nil, err
+	}
+	return query.WalkValue()
+}
+
+// walk path on every record of dataType in order of id and send result of each record to out as it goes.
+// out is closed when all records are walked
+func (p *CompiledPath) Stream(conn *Data.Connection, dataType string, out chan<- PathResult) {
+	p.StreamContext(context.Background(), conn, dataType, out)
+}
+
+// Stream that stops once ctx is done, last result has no id and carries the context error
+func (p *CompiledPath) StreamContext(ctx context.Context, conn *Data.Connection, dataType string, out chan<- PathResult) {
+	defer close(out)
+	conn = conn.WithContext(ctx)
+	idList, err := conn.ListIds(dataType)
+	if err != nil {
+		out <- PathResult{Err: err}
+		return
+	}
+	ids := make([]string, 0, len(idList))
+	for _, id := range idList {
+		ids = append(ids, id.(string))
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			out <- PathResult{Err: Http.WrapError(ctx.Err(), fmt.Sprintf("stopped before walk of [%s/%s]", dataType, id), http.StatusRequestTimeout)}
+			return
+		}
+		value, err := p.WalkContext(ctx, conn, dataType, id)
+		out <- PathResult{
+			Id:    id,
+			Value: value,
+			Err:   err,
+		}
+	}
+}
@@ -0,0 +1,266 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const (
+	DescribeItems  = "items"
+	DescribeSchema = "schema"
+	DescribeType   = "type"
+)
+
+// static state of a path walk, def is the processed definition at current step and raw is the one as declared
+type pathShape struct {
+	doc   *SchemaDoc.SchemaDoc
+	def   map[string]interface{}
+	raw   map[string]interface{}
+	multi bool
+}
+
+// type of result of path query like {id}/attr[*]/name?len, worked out from schemas only, no record is read.
+// id in path is not used. result is {"type": ...}, with "items" for array and "schema" when result is one attribute
+func DescribePath(conn *Data.Connection, dataType string, dataPath string) (map[string]interface{}, *Http.HttpError) {
+	qPath, qCmd, err := PathCmd.Parse(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	if IsCmdPathName(qCmd) {
+		return nil, Http.NewHttpError(fmt.Sprintf("cannot describe [%s], path is stored with data", PathCmd.CmdPathName), http.StatusBadRequest)
+	}
+	doc, err := conn.GetSchema(dataType)
+	if err != nil {
+		return nil, err
+	}
+	_, attrPath := Util.ParsePath(qPath)
+	shape := &pathShape{
+		doc: doc,
+		raw: doc.RAW,
+	}
+	err = shape.walk(conn, attrPath, isCmdAggregate(qCmd))
+	if err != nil {
+		return nil, Http.WrapError(err, fmt.Sprintf("invalid path [%s/%s]", dataType, dataPath), err.Status)
+	}
+	return shape.describeCmd(qCmd)
+}
+
+func isCmdAggregate(cmd string) bool {
+	switch cmd {
+	case PathCmd.CmdSum, PathCmd.CmdMin, PathCmd.CmdMax, PathCmd.CmdAvg:
+		return true
+	}
+	return false
+}
+
+// array without idx is walked as [*] when implicitAll, like aggregate commands do
+func (s *pathShape) walk(conn *Data.Connection, attrPath string, implicitAll bool) *Http.HttpError {
+	for attrPath != "" {
+		step, nextPath := Util.ParsePath(attrPath)
+		if s.doc == nil {
+			return Http.NewHttpError(fmt.Sprintf("cannot walk into [%s], no schema defined", step), http.StatusBadRequest)
+		}
+		attrName, key, ex := Util.ParseArrayPath(step)
+		if ex != nil {
+			return Http.WrapError(ex, fmt.Sprintf("failed to parse step [%s]", step), http.StatusBadRequest)
+		}
+		attrDef, ok := s.doc.Properties()[attrName].(map[string]interface{})
+		if !ok {
+			return Http.NewHttpError(fmt.Sprintf("attr [%s] not defined @[path]=[%s]", attrName, s.doc.Path()), http.StatusBadRequest)
+		}
+		rawProperties, _ := s.raw[JsonKey.Properties].(map[string]interface{})
+		s.raw, _ = rawProperties[attrName].(map[string]interface{})
+		s.def = attrDef
+		isArray := attrDef[JsonKey.Type] == JsonKey.Array
+		isMap := SchemaDoc.IsMap(attrDef)
+		if key == "" && isMap && nextPath != "" {
+			// map takes key as the next step, like mapAttr/key
+			key, nextPath = Util.ParsePath(nextPath)
+		}
+		if key == "" && isArray && nextPath != "" {
+			if !implicitAll {
+				return Http.NewHttpError(fmt.Sprintf("missing array idx of [%s] before [%s]", attrName, nextPath), http.StatusBadRequest)
+			}
+			key = Node.All
+		}
+		if key != "" {
+			if !isArray && !isMap {
+				return Http.NewHttpError(fmt.Sprintf("attr [%s] is not %s or %s, cannot take idx [%s]", attrName, JsonKey.Array, JsonKey.Map, key), http.StatusBadRequest)
+			}
+			s.selectItem(key, isArray)
+		}
+		if nextPath == "" {
+			return nil
+		}
+		if ref, isRef := s.doc.CmtRefs[attrName]; isRef {
+			if ref.IsPolymorphic() {
+				return Http.NewHttpError(fmt.Sprintf("cannot describe through polymorphic ref [%s], target type depends on data", attrName), http.StatusBadRequest)
+			}
+			targetDoc, err := conn.GetSchema(ref.ContentType)
+			if err != nil {
+				return Http.WrapError(err, fmt.Sprintf("failed to get schema of ref [%s]", attrName), err.Status)
+			}
+			s.doc = targetDoc
+			s.raw = targetDoc.RAW
+		} else {
+			s.doc = s.doc.SubDocs[attrName]
+			if s.doc != nil {
+				s.raw = s.doc.RAW
+			}
+		}
+		attrPath = nextPath
+	}
+	return nil
+}
+
+// narrow shape to item of array or map by idx. [*], [start:end] and [attr=value] keep a list of items
+func (s *pathShape) selectItem(key string, isArray bool) {
+	itemKey := JsonKey.AdditionalProperties
+	if isArray {
+		itemKey = JsonKey.Items
+	}
+	s.def, _ = s.def[itemKey].(map[string]interface{})
+	rawItemKey := itemKey
+	if !isArray {
+		// custom type map declares its item as items
+		rawItemKey = JsonKey.Items
+	}
+	s.raw, _ = s.raw[rawItemKey].(map[string]interface{})
+	if key == Node.All || (isArray && (Util.IsSliceIdx(key) || isFilterIdx(key))) {
+		s.multi = true
+	}
+}
+
+func (s *pathShape) value() map[string]interface{} {
+	if s.def == nil {
+		return map[string]interface{}{
+			DescribeType:   JsonKey.Object,
+			DescribeSchema: s.raw,
+		}
+	}
+	return describeDef(s.def, s.raw)
+}
+
+func describeDef(def map[string]interface{}, raw map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{
+		DescribeType:   def[JsonKey.Type],
+		DescribeSchema: raw,
+	}
+	if itemDef, ok := def[JsonKey.Items].(map[string]interface{}); ok && def[JsonKey.Type] == JsonKey.Array {
+		rawItem, _ := raw[JsonKey.Items].(map[string]interface{})
+		result[DescribeItems] = describeDef(itemDef, rawItem)
+	}
+	return result
+}
+
+func (s *pathShape) list(item map[string]interface{}) map[string]interface{} {
+	if !s.multi {
+		return item
+	}
+	return map[string]interface{}{
+		DescribeType:  JsonKey.Array,
+		DescribeItems: item,
+	}
+}
+
+func scalar(valueType string) map[string]interface{} {
+	return map[string]interface{}{
+		DescribeType: valueType,
+	}
+}
+
+func (s *pathShape) describeCmd(cmd string) (map[string]interface{}, *Http.HttpError) {
+	switch cmd {
+	case PathCmd.CmdSum, PathCmd.CmdMin, PathCmd.CmdMax, PathCmd.CmdAvg:
+		if !isNumeric(s.def) {
+			return nil, Http.NewHttpError(fmt.Sprintf("cmd [%s] only works on %s or %s", cmd, JsonKey.Number, JsonKey.Integer), http.StatusBadRequest)
+		}
+		return scalar(JsonKey.Number), nil
+	case PathCmd.CmdHops:
+		return scalar(JsonKey.Integer), nil
+	case PathCmd.CmdLen, PathCmd.CmdCount:
+		return s.list(scalar(JsonKey.Integer)), nil
+	case PathCmd.CmdMediaType, PathCmd.CmdRef:
+		return s.list(scalar(JsonKey.String)), nil
+	case PathCmd.CmdRequired, PathCmd.CmdKeys:
+		return s.list(map[string]interface{}{
+			DescribeType:  JsonKey.Array,
+			DescribeItems: scalar(JsonKey.String),
+		}), nil
+	case PathCmd.CmdEnum:
+		return map[string]interface{}{
+			DescribeType:  JsonKey.Array,
+			DescribeItems: s.value(),
+		}, nil
+	case PathCmd.CmdSchema, PathCmd.CmdSchemaRef, PathCmd.CmdRecord, PathCmd.CmdAsMap:
+		return s.list(scalar(JsonKey.Object)), nil
+	case PathCmd.CmdIter:
+		return map[string]interface{}{
+			DescribeType:  JsonKey.Array,
+			DescribeItems: scalar(JsonKey.Object),
+		}, nil
+	}
+	if IsCmdCompare(cmd) {
+		return scalar(JsonKey.Boolean), nil
+	}
+	if IsCmdFirstLast(cmd) {
+		if s.multi {
+			return s.value(), nil
+		}
+		value := s.value()
+		if item, ok := value[DescribeItems].(map[string]interface{}); ok {
+			return item, nil
+		}
+		return value, nil
+	}
+	return s.list(s.value()), nil
+}
+
+// number, integer or array of them
+func isNumeric(def map[string]interface{}) bool {
+	if def == nil {
+		return false
+	}
+	if itemDef, ok := def[JsonKey.Items].(map[string]interface{}); ok && def[JsonKey.Type] == JsonKey.Array {
+		def = itemDef
+	}
+	return def[JsonKey.Type] == JsonKey.Number || def[JsonKey.Type] == JsonKey.Integer
+}
+
+func isFilterIdx(key string) bool {
+	_, _, ok := Util.FilterIdx(key)
+	return ok
+}
@@ -0,0 +1,213 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const (
+	DiffAdded    = "added"
+	DiffRemoved  = "removed"
+	DiffRelation = "relationChanged" // value of a ref attribute changed, record points to different target
+	DiffType     = "typeChanged"     // attribute declared with different type in schema of the 2 records
+	DiffValue    = "valueChanged"
+)
+
+type DiffEntry struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type StructuredDiff struct {
+	DataType string      `json:"dataType"`
+	IdA      string      `json:"idA"`
+	IdB      string      `json:"idB"`
+	Entries  []DiffEntry `json:"entries"`
+}
+
+// diff record idB against idA of the same type, each difference is classified with schema of the records.
+// items of keyed array and map of objects are matched by key, other arrays are compared as a whole
+func DiffRecords(conn *Data.Connection, dataType string, idA string, idB string) (*StructuredDiff, *Http.HttpError) {
+	recordA, err := conn.GetRecord(dataType, idA)
+	if err != nil {
+		return nil, Http.WrapError(err, fmt.Sprintf("failed to get record [%s/%s]", dataType, idA), err.Status)
+	}
+	recordB, err := conn.GetRecord(dataType, idB)
+	if err != nil {
+		return nil, Http.WrapError(err, fmt.Sprintf("failed to get record [%s/%s]", dataType, idB), err.Status)
+	}
+	schemaA, err := conn.GetSchema(fmt.Sprintf("%s/%s", recordA.Type, recordA.Version))
+	if err != nil {
+		return nil, err
+	}
+	schemaB, err := conn.GetSchema(fmt.Sprintf("%s/%s", recordB.Type, recordB.Version))
+	if err != nil {
+		return nil, err
+	}
+	diff := StructuredDiff{
+		DataType: dataType,
+		IdA:      idA,
+		IdB:      idB,
+		Entries:  []DiffEntry{},
+	}
+	diff.Entries = diffDoc(schemaA, schemaB, recordA.Data, recordB.Data, "", diff.Entries)
+	return &diff, nil
+}
+
+func diffDoc(docA *SchemaDoc.SchemaDoc, docB *SchemaDoc.SchemaDoc, dataA map[string]interface{}, dataB map[string]interface{}, dataPath string, entries []DiffEntry) []DiffEntry {
+	attrSet := map[string]bool{}
+	for _, data := range []map[string]interface{}{dataA, dataB} {
+		for attr := range data {
+			attrSet[attr] = true
+		}
+	}
+	attrList := make([]string, 0, len(attrSet))
+	for attr := range attrSet {
+		attrList = append(attrList, attr)
+	}
+	sort.Strings(attrList)
+	for _, attr := range attrList {
+		attrPath := fmt.Sprintf("%s/%s", dataPath, attr)
+		valueA, okA := dataA[attr]
+		valueB, okB := dataB[attr]
+		defA := attrDef(docA, attr)
+		defB := attrDef(docB, attr)
+		_, isRef := docB.CmtRefs[attr]
+		if !okB {
+			_, isRef = docA.CmtRefs[attr]
+		}
+		switch {
+		case defA != nil && defB != nil && defA[JsonKey.Type] != defB[JsonKey.Type]:
+			entries = append(entries, DiffEntry{Path: attrPath, Kind: DiffType, Before: valueA, After: valueB})
+		case reflect.DeepEqual(valueA, valueB):
+			continue
+		case isRef:
+			entries = append(entries, DiffEntry{Path: attrPath, Kind: DiffRelation, Before: valueA, After: valueB})
+		case !okA:
+			entries = append(entries, DiffEntry{Path: attrPath, Kind: DiffAdded, After: valueB})
+		case !okB:
+			entries = append(entries, DiffEntry{Path: attrPath, Kind: DiffRemoved, Before: valueA})
+		default:
+			entries = diffAttr(docA, docB, attr, defB, valueA, valueB, attrPath, entries)
+		}
+	}
+	return entries
+}
+
+func diffAttr(docA *SchemaDoc.SchemaDoc, docB *SchemaDoc.SchemaDoc, attr string, def map[string]interface{}, valueA interface{}, valueB interface{}, attrPath string, entries []DiffEntry) []DiffEntry {
+	subA, okA := docA.SubDocs[attr]
+	subB, okB := docB.SubDocs[attr]
+	if !okA || !okB || def == nil {
+		return append(entries, DiffEntry{Path: attrPath, Kind: DiffValue, Before: valueA, After: valueB})
+	}
+	objA, isObjA := valueA.(map[string]interface{})
+	objB, isObjB := valueB.(map[string]interface{})
+	if isObjA && isObjB {
+		if !SchemaDoc.IsMap(def) {
+			return diffDoc(subA, subB, objA, objB, attrPath, entries)
+		}
+		return diffItems(subA, subB, objA, objB, attrPath, entries)
+	}
+	listA, isListA := valueA.([]interface{})
+	listB, isListB := valueB.([]interface{})
+	if isListA && isListB && len(subB.KeyTemplate.Vars) > 0 {
+		itemsA, errA := keyedItems(subA, listA)
+		itemsB, errB := keyedItems(subB, listB)
+		if errA == nil && errB == nil {
+			return diffItems(subA, subB, itemsA, itemsB, attrPath, entries)
+		}
+	}
+	return append(entries, DiffEntry{Path: attrPath, Kind: DiffValue, Before: valueA, After: valueB})
+}
+
+// diff object items matched by key
+func diffItems(docA *SchemaDoc.SchemaDoc, docB *SchemaDoc.SchemaDoc, itemsA map[string]interface{}, itemsB map[string]interface{}, attrPath string, entries []DiffEntry) []DiffEntry {
+	keySet := map[string]bool{}
+	for _, items := range []map[string]interface{}{itemsA, itemsB} {
+		for key := range items {
+			keySet[key] = true
+		}
+	}
+	keyList := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keyList = append(keyList, key)
+	}
+	sort.Strings(keyList)
+	for _, key := range keyList {
+		itemPath := fmt.Sprintf("%s[%s]", attrPath, key)
+		itemA, okA := itemsA[key]
+		itemB, okB := itemsB[key]
+		switch {
+		case !okA:
+			entries = append(entries, DiffEntry{Path: itemPath, Kind: DiffAdded, After: itemB})
+		case !okB:
+			entries = append(entries, DiffEntry{Path: itemPath, Kind: DiffRemoved, Before: itemA})
+		case reflect.DeepEqual(itemA, itemB):
+			continue
+		default:
+			objA, isObjA := itemA.(map[string]interface{})
+			objB, isObjB := itemB.(map[string]interface{})
+			if !isObjA || !isObjB {
+				entries = append(entries, DiffEntry{Path: itemPath, Kind: DiffValue, Before: itemA, After: itemB})
+				continue
+			}
+			entries = diffDoc(docA, docB, objA, objB, itemPath, entries)
+		}
+	}
+	return entries
+}
+
+func keyedItems(doc *SchemaDoc.SchemaDoc, itemList []interface{}) (map[string]interface{}, *Http.HttpError) {
+	items := make(map[string]interface{}, len(itemList))
+	for idx, item := range itemList {
+		itemData, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, Http.NewHttpError(fmt.Sprintf("item @[%d] is not an object", idx), http.StatusBadRequest)
+		}
+		key, ex := doc.BuildKey(itemData)
+		if ex != nil {
+			return nil, Http.WrapError(ex, fmt.Sprintf("failed to build key of item @[%d]", idx), http.StatusBadRequest)
+		}
+		items[key] = item
+	}
+	return items, nil
+}
+
+func attrDef(doc *SchemaDoc.SchemaDoc, attr string) map[string]interface{} {
+	def, _ := doc.Properties()[attr].(map[string]interface{})
+	return def
+}
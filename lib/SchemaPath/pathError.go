@@ -0,0 +1,63 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// segment of path where walk failed. index 0 is the record id, 1 is first attribute after it.
+// it is the Payload of the returned HttpError, so it goes out with the error and errors.As finds it
+type PathError struct {
+	Path    string          `json:"path"`
+	Segment string          `json:"segment"`
+	Index   int             `json:"index"`
+	Cause   *Http.HttpError `json:"-"`
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("walk failed at segment [%d]=[%s] of path [%s]. Error: %s", e.Index, e.Segment, e.Path, strings.Join(e.Cause.Message, " "))
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Cause
+}
+
+// copy of err with PathError as payload, status and message stay the same
+func WithPathError(err *Http.HttpError, path string, segment string, index int) *Http.HttpError {
+	cause := *err
+	wrapped := *err
+	wrapped.Payload = &PathError{
+		Path:    path,
+		Segment: segment,
+		Index:   index,
+		Cause:   &cause,
+	}
+	return &wrapped
+}
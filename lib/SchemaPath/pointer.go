@@ -0,0 +1,215 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// query record value with JSON Pointer (RFC 6901), like /itemArray/0/refIdx
+func NewFromPointer(conn *Data.Connection, dataType string, dataId string, pointer string) (*CmdQueryValue, *Http.HttpError) {
+	path, err := PointerToPath(conn, dataType, dataId, pointer)
+	if err != nil {
+		return nil, err
+	}
+	return NewValueQuery(conn, dataType, dataId, path)
+}
+
+// convert JSON Pointer on record into SchemaPath attr path.
+// array step need numeric idx, which selects item by position and is converted to item key,
+// key of object item is built from item key template, key of string item is the value itself,
+// other item types use the position as key.
+// map step use map key as is.
+func PointerToPath(conn *Data.Connection, dataType string, dataId string, pointer string) (string, *Http.HttpError) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", nil
+	}
+	record, err := conn.GetRecord(dataType, dataId)
+	if err != nil {
+		return "", err
+	}
+	doc, err := conn.GetSchema(fmt.Sprintf("%s/%s", record.Type, record.Version))
+	if err != nil {
+		return "", err
+	}
+	var data interface{} = record.Data
+	pathList := []string{}
+	for idx := 0; idx < len(tokens); idx++ {
+		attrName := tokens[idx]
+		prevPath := strings.Join(tokens[:idx], "/")
+		dataMap, ok := data.(map[string]interface{})
+		if !ok || doc == nil {
+			return "", Http.NewHttpError(fmt.Sprintf("cannot walk into [%s] @pointer=[/%s]", attrName, prevPath), http.StatusBadRequest)
+		}
+		attrDef, ok := doc.Properties()[attrName].(map[string]interface{})
+		if !ok {
+			return "", Http.NewHttpError(fmt.Sprintf("attr [%s] not defined @pointer=[/%s]", attrName, prevPath), http.StatusNotFound)
+		}
+		attrData, ok := dataMap[attrName]
+		if !ok {
+			return "", Http.NewHttpError(fmt.Sprintf("attr [%s] does not exists @pointer=[/%s]", attrName, prevPath), http.StatusNotFound)
+		}
+		if idx == len(tokens)-1 {
+			pathList = append(pathList, attrName)
+			break
+		}
+		switch attrDef[JsonKey.Type] {
+		case JsonKey.Array:
+			idx++
+			position, ex := strconv.Atoi(tokens[idx])
+			arrayData, _ := attrData.([]interface{})
+			if ex != nil || position < 0 || position >= len(arrayData) {
+				return "", Http.NewHttpError(fmt.Sprintf("invalid array idx [%s] @pointer=[/%s/%s]", tokens[idx], prevPath, attrName), http.StatusNotFound)
+			}
+			item := arrayData[position]
+			itemKey := strconv.Itoa(position)
+			switch attrDef[JsonKey.Items].(map[string]interface{})[JsonKey.Type] {
+			case JsonKey.Object:
+				key, ex := doc.SubDocs[attrName].BuildKey(item.(map[string]interface{}))
+				if ex != nil {
+					return "", Http.WrapError(ex, fmt.Sprintf("failed to build item key @pointer=[/%s/%s/%d]", prevPath, attrName, position), http.StatusInternalServerError)
+				}
+				itemKey = key
+			case JsonKey.String:
+				itemKey = item.(string)
+			}
+			pathList = append(pathList, fmt.Sprintf("%s[%s]", attrName, itemKey))
+			data = item
+			doc = doc.SubDocs[attrName]
+		case JsonKey.Object:
+			if !SchemaDoc.IsMap(attrDef) {
+				pathList = append(pathList, attrName)
+				data = attrData
+				doc = doc.SubDocs[attrName]
+				continue
+			}
+			idx++
+			mapData, _ := attrData.(map[string]interface{})
+			item, ok := mapData[tokens[idx]]
+			if !ok {
+				return "", Http.NewHttpError(fmt.Sprintf("map key [%s] does not exists @pointer=[/%s/%s]", tokens[idx], prevPath, attrName), http.StatusNotFound)
+			}
+			pathList = append(pathList, fmt.Sprintf("%s[%s]", attrName, tokens[idx]))
+			data = item
+			doc = doc.SubDocs[attrName]
+		default:
+			return "", Http.NewHttpError(fmt.Sprintf("attr [%s] of type [%s] cannot walk further @pointer=[/%s]", attrName, attrDef[JsonKey.Type], prevPath), http.StatusBadRequest)
+		}
+	}
+	return strings.Join(pathList, "/"), nil
+}
+
+// convert SchemaPath attr path on record into positional JSON Pointer, like attrArray[01_02]/key2 -> /attrArray/1/key2.
+// path has to end at a single value within the record, pointer cannot follow ref into another record.
+func PathToPointer(conn *Data.Connection, dataType string, dataId string, path string) (string, *Http.HttpError) {
+	root, err := BuildNodePath(conn, dataType, dataId, path)
+	if err != nil {
+		return "", err
+	}
+	leafList := leafNodes(root)
+	if len(leafList) != 1 {
+		return "", Http.NewHttpError(fmt.Sprintf("path [%s] resolves to [%d] values, pointer needs a single value", path, len(leafList)), http.StatusBadRequest)
+	}
+	leaf := leafList[0]
+	// ref value is followed into its record, which is walked further when path goes beyond the ref
+	for _, next := range leaf.Next {
+		if next.IsRecord() && len(next.Next) > 0 {
+			return "", Http.NewHttpError(fmt.Sprintf("path [%s] crossed ref into record [%s/%s], pointer cannot cross records", path, next.DataType, next.DataId), http.StatusBadRequest)
+		}
+	}
+	tokens := []string{}
+	for node := leaf; node != root; node = node.Prev {
+		token := node.AttrName
+		if node.AttrName == "" {
+			position, err := itemToken(node)
+			if err != nil {
+				return "", err
+			}
+			token = position
+		}
+		tokens = append([]string{token}, tokens...)
+	}
+	pointer := ""
+	for _, token := range tokens {
+		pointer = fmt.Sprintf("%s/%s", pointer, strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return pointer, nil
+}
+
+// token of item node, position of item for array and key for map
+func itemToken(node *Node.PathNode) (string, *Http.HttpError) {
+	parent := node.Prev
+	arrayData, ok := parent.Data.([]interface{})
+	if !ok {
+		return node.Idx, nil
+	}
+	itemType := parent.AttrDef[JsonKey.Items].(map[string]interface{})[JsonKey.Type]
+	for position, item := range arrayData {
+		itemKey := strconv.Itoa(position)
+		switch itemType {
+		case JsonKey.Object:
+			key, ex := parent.Schema.SubDocs[parent.AttrName].BuildKey(item.(map[string]interface{}))
+			if ex != nil {
+				return "", Http.WrapError(ex, fmt.Sprintf("failed to build item key @path=[%s[%d]]", parent.FullPath(), position), http.StatusInternalServerError)
+			}
+			itemKey = key
+		case JsonKey.String:
+			itemKey = item.(string)
+		}
+		if itemKey == node.Idx {
+			return strconv.Itoa(position), nil
+		}
+	}
+	return "", Http.NewHttpError(fmt.Sprintf("item [%s] not found @path=[%s]", node.Idx, parent.FullPath()), http.StatusNotFound)
+}
+
+// split JSON Pointer into reference tokens, with ~1 and ~0 unescaped
+func ParsePointer(pointer string) ([]string, *Http.HttpError) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, Http.NewHttpError(fmt.Sprintf("invalid JSON Pointer [%s], expect to start with /", pointer), http.StatusBadRequest)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for idx, token := range tokens {
+		tokens[idx] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
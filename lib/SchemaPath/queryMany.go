@@ -0,0 +1,54 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util"
+)
+
+// resolve every path like {type}/{id}/{attrPath}?cmd on one batch connection, so schema and record read by one path is not read again by the next.
+// result and error are at the same idx as the path, error is nil when path resolves
+func QueryMany(conn *Data.Connection, paths []string) ([]interface{}, []error) {
+	batch := conn.Batch()
+	results := make([]interface{}, len(paths))
+	errs := make([]error, len(paths))
+	for idx, path := range paths {
+		dataType, dataPath := Util.ParsePath(path)
+		query, err := CreateQuery(batch, dataType, dataPath)
+		if err != nil {
+			errs[idx] = err
+			continue
+		}
+		value, err := query.WalkValue()
+		if err != nil {
+			errs[idx] = err
+			continue
+		}
+		results[idx] = value
+	}
+	return results, errs
+}
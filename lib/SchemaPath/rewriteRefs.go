@@ -0,0 +1,261 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+type RecordRef struct {
+	DataType string `json:"dataType"`
+	DataId   string `json:"dataId"`
+	AttrPath string `json:"attrPath"`
+}
+
+// rewrite refs pointing at oldTarget [type/id] to newTarget [type/id].
+// referrer types are the ones whose schema has ref allowing target type, records of those types are scanned for the ref.
+// ref that carries attribute path after the id keeps its path.
+// when dryRun is true, affected refs are returned without persisting any change
+func RewriteRefs(conn *Data.Connection, oldTarget string, newTarget string, dryRun bool) ([]RecordRef, *Http.HttpError) {
+	targetType, oldId := Util.ParsePath(oldTarget)
+	newType, newId := Util.ParsePath(newTarget)
+	if targetType == "" || oldId == "" || newId == "" {
+		return nil, Http.NewHttpError(fmt.Sprintf("invalid target, expect [type/id]. old=[%s], new=[%s]", oldTarget, newTarget), http.StatusBadRequest)
+	}
+	if targetType != newType {
+		return nil, Http.NewHttpError(fmt.Sprintf("cannot rewrite ref across types [%s]->[%s]", targetType, newType), http.StatusBadRequest)
+	}
+	affected := []RecordRef{}
+	subTypes, err := ReferrerTypes(conn, targetType)
+	if err != nil {
+		return nil, err
+	}
+	for _, subType := range subTypes {
+		idList, err := conn.ListIds(subType)
+		if err != nil {
+			return nil, Http.WrapError(err, fmt.Sprintf("failed to list referrer type [%s]", subType), err.Status)
+		}
+		for _, id := range idList {
+			record, err := conn.GetRecord(subType, id.(string))
+			if err != nil {
+				return nil, err
+			}
+			schema, err := conn.GetSchema(fmt.Sprintf("%s/%s", record.Type, record.Version))
+			if err != nil {
+				return nil, err
+			}
+			pathList := rewriteDocRefs(schema, record.Data, targetType, func(ref *SchemaDoc.CMTDocRef, value string) (string, bool) {
+				dataType, dataId, dataPath, ex := ref.TargetPath(value)
+				if ex != nil || dataType != targetType || dataId != oldId {
+					return "", false
+				}
+				if dataPath == "" {
+					return ref.Value(targetType, newId), true
+				}
+				return fmt.Sprintf("%s/%s", ref.Value(targetType, newId), dataPath), true
+			}, "")
+			if len(pathList) == 0 {
+				continue
+			}
+			for _, attrPath := range pathList {
+				affected = append(affected, RecordRef{
+					DataType: record.Type,
+					DataId:   record.Id,
+					AttrPath: attrPath,
+				})
+			}
+			if dryRun {
+				continue
+			}
+			err = conn.PutRecord(record)
+			if err != nil {
+				return affected, Http.WrapError(err, fmt.Sprintf("failed to update refs of [%s/%s]", record.Type, record.Id), err.Status)
+			}
+		}
+	}
+	return affected, nil
+}
+
+// refs pointing at target [type/id], found from referrer types like RewriteRefs
+func FindRefs(conn *Data.Connection, target string) ([]RecordRef, *Http.HttpError) {
+	return RewriteRefs(conn, target, target, true)
+}
+
+// types with a schema, of any version, that has ref allowing targetType, sorted
+func ReferrerTypes(conn *Data.Connection, targetType string) ([]string, *Http.HttpError) {
+	idList, err := conn.ListIds(JsonKey.Schema)
+	if err != nil {
+		return nil, Http.WrapError(err, "failed to list schemas", err.Status)
+	}
+	found := map[string]bool{}
+	for _, item := range idList {
+		schemaId, _ := item.(string)
+		if schemaId == "" || schemaId == JsonKey.Schema {
+			continue
+		}
+		dataType, version := Util.ParseCustomPath(schemaId, JsonKey.ArchivedSchemaIdDiv)
+		if found[dataType] {
+			continue
+		}
+		schemaType := dataType
+		if version != "" {
+			schemaType = fmt.Sprintf("%s/%s", dataType, version)
+		}
+		schema, err := conn.GetSchema(schemaType)
+		if err != nil {
+			return nil, Http.WrapError(err, fmt.Sprintf("failed to load schema [%s] to find refs of [%s]", schemaId, targetType), err.Status)
+		}
+		if docAllows(schema, targetType, map[*SchemaDoc.SchemaDoc]bool{}) {
+			found[dataType] = true
+		}
+	}
+	typeList := make([]string, 0, len(found))
+	for dataType := range found {
+		typeList = append(typeList, dataType)
+	}
+	sort.Strings(typeList)
+	return typeList, nil
+}
+
+// doc, its sub docs or definitions have ref allowing targetType
+func docAllows(doc *SchemaDoc.SchemaDoc, targetType string, visited map[*SchemaDoc.SchemaDoc]bool) bool {
+	if doc == nil || visited[doc] {
+		return false
+	}
+	visited[doc] = true
+	for _, ref := range doc.CmtRefs {
+		if ref.Allows(targetType) {
+			return true
+		}
+	}
+	for _, subDoc := range doc.SubDocs {
+		if docAllows(subDoc, targetType, visited) {
+			return true
+		}
+	}
+	for _, defDoc := range doc.Definitions {
+		if docAllows(defDoc, targetType, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// rewrite values of polymorphic refs that carry oldType as {oldType}/{id} to {newType}/{id} in data of doc.
+// return attribute paths of refs rewritten
+func RetypeDocRefs(doc *SchemaDoc.SchemaDoc, data map[string]interface{}, oldType string, newType string) []string {
+	return rewriteDocRefs(doc, data, oldType, func(ref *SchemaDoc.CMTDocRef, value string) (string, bool) {
+		if !ref.IsPolymorphic() {
+			return "", false
+		}
+		dataType, idPath := Util.ParsePath(value)
+		if dataType != oldType {
+			return "", false
+		}
+		return fmt.Sprintf("%s/%s", newType, idPath), true
+	}, "")
+}
+
+// rewrite values of refs that allow targetType, when rewrite returns new value of it
+func rewriteDocRefs(doc *SchemaDoc.SchemaDoc, data map[string]interface{}, targetType string, rewrite func(ref *SchemaDoc.CMTDocRef, value string) (string, bool), dataPath string) []string {
+	pathList := []string{}
+	for attr, ref := range doc.CmtRefs {
+		if !ref.Allows(targetType) {
+			continue
+		}
+		attrPath := fmt.Sprintf("%s/%s", dataPath, attr)
+		switch value := data[attr].(type) {
+		case string:
+			if newRef, ok := rewrite(ref, value); ok {
+				data[attr] = newRef
+				pathList = append(pathList, attrPath)
+			}
+		case []interface{}:
+			for idx, item := range value {
+				itemStr, _ := item.(string)
+				if newRef, ok := rewrite(ref, itemStr); ok {
+					value[idx] = newRef
+					pathList = append(pathList, fmt.Sprintf("%s[%d]", attrPath, idx))
+				}
+			}
+		case map[string]interface{}:
+			for key, item := range value {
+				itemStr, _ := item.(string)
+				newRef, ok := rewrite(ref, itemStr)
+				if !ok {
+					continue
+				}
+				if key == itemStr {
+					// map of ref use ref as key
+					delete(value, key)
+					key = newRef
+				}
+				value[key] = newRef
+				pathList = append(pathList, fmt.Sprintf("%s[%s]", attrPath, key))
+			}
+		}
+	}
+	for attr, subDoc := range doc.SubDocs {
+		attrPath := fmt.Sprintf("%s/%s", dataPath, attr)
+		attrDef := doc.Properties()[attr].(map[string]interface{})
+		switch value := data[attr].(type) {
+		case []interface{}:
+			for idx, item := range value {
+				itemData, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				itemPath := fmt.Sprintf("%s[%d]", attrPath, idx)
+				if key, err := subDoc.BuildKey(itemData); err == nil && key != "" {
+					itemPath = fmt.Sprintf("%s[%s]", attrPath, key)
+				}
+				pathList = append(pathList, rewriteDocRefs(subDoc, itemData, targetType, rewrite, itemPath)...)
+			}
+		case map[string]interface{}:
+			if attrDef[JsonKey.Type] == JsonKey.Object && !SchemaDoc.IsMap(attrDef) {
+				pathList = append(pathList, rewriteDocRefs(subDoc, value, targetType, rewrite, attrPath)...)
+				continue
+			}
+			for key, item := range value {
+				itemData, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				pathList = append(pathList, rewriteDocRefs(subDoc, itemData, targetType, rewrite, fmt.Sprintf("%s[%s]", attrPath, key))...)
+			}
+		}
+	}
+	sort.Strings(pathList)
+	return pathList
+}
@@ -26,6 +26,10 @@ This copyright notice and license applies to all files in this directory or sub-
 package SchemaPath
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+
 	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
 	"github.com/salesforce/UniTAO/lib/SchemaPath/Node"
 	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
@@ -34,15 +38,24 @@ import (
 )
 
 func BuildNodePath(conn *Data.Connection, dataType string, dataId string, dataPath string) (*Node.PathNode, *Http.HttpError) {
+	return buildNodePath(conn, dataType, dataId, dataPath, false)
+}
+
+func buildNodePath(conn *Data.Connection, dataType string, dataId string, dataPath string, alignAll bool) (*Node.PathNode, *Http.HttpError) {
+	fullPath := dataId
+	if dataPath != "" {
+		fullPath = fmt.Sprintf("%s/%s", dataId, dataPath)
+	}
 	node, err := Node.New(conn, dataType, dataId)
 	if err != nil {
-		return nil, err
+		return nil, WithPathError(err, fullPath, dataId, 0)
 	}
-	for dataPath != "" {
+	node.AlignAll = alignAll
+	for idx := 1; dataPath != ""; idx++ {
 		stepPath, stepNext := Util.ParsePath(dataPath)
 		err = node.BuildPath(stepPath)
 		if err != nil {
-			return nil, err
+			return nil, WithPathError(err, fullPath, stepPath, idx)
 		}
 		dataPath = stepNext
 	}
@@ -50,11 +63,21 @@ func BuildNodePath(conn *Data.Connection, dataType string, dataId string, dataPa
 }
 
 func CreateQuery(conn *Data.Connection, dataType string, dataPath string) (PathCmd.QueryIface, *Http.HttpError) {
+	return CreateQueryContext(context.Background(), conn, dataType, dataPath)
+}
+
+// query bound to ctx, building it and walking its value stop with error before next fetch once ctx is done
+func CreateQueryContext(ctx context.Context, conn *Data.Connection, dataType string, dataPath string) (PathCmd.QueryIface, *Http.HttpError) {
+	conn = conn.WithContext(ctx)
 	qPath, qCmd, pErr := PathCmd.Parse(dataPath)
 	if pErr != nil {
 		return nil, pErr
 	}
 	dataId, nextPath := Util.ParsePath(qPath)
+	dataId, ex := Util.UnescapeKey(dataId)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to unescape record id of path [%s]", qPath), http.StatusBadRequest)
+	}
 	switch qCmd {
 	case PathCmd.CmdSchema:
 		return NewSchemaQuery(conn, dataType, dataId, nextPath)
@@ -64,10 +87,38 @@ func CreateQuery(conn *Data.Connection, dataType string, dataPath string) (PathC
 		return NewRefQuery(conn, dataType, dataId, nextPath)
 	case PathCmd.CmdIter:
 		return NewIteratorQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdAsMap:
+		return NewAsMapQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdEnum:
+		return NewEnumQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdLen:
+		return NewLenQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdCount:
+		return NewCountQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdKeys:
+		return NewKeysQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdSchemaRef:
+		return NewSchemaRefQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdRequired:
+		return NewRequiredQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdRecord:
+		return NewRecordQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdMediaType:
+		return NewMediaTypeQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdHops:
+		return NewHopsQuery(conn, dataType, dataId, nextPath)
+	case PathCmd.CmdSum, PathCmd.CmdMin, PathCmd.CmdMax, PathCmd.CmdAvg:
+		return NewAggregateQuery(conn, dataType, dataId, nextPath, qCmd)
 	default:
 		if IsCmdPathName(qCmd) {
 			return NewPathQuery(conn, dataType, qPath, qCmd)
 		}
+		if IsCmdCompare(qCmd) {
+			return NewCompareQuery(conn, dataType, dataId, nextPath, qCmd)
+		}
+		if IsCmdFirstLast(qCmd) {
+			return NewFirstLastQuery(conn, dataType, dataId, nextPath, qCmd)
+		}
 		return NewValueQuery(conn, dataType, dataId, nextPath)
 	}
 }
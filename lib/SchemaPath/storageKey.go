@@ -0,0 +1,52 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPath
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// key {type}/{id} that record of dataType with payload as its data would be stored under, id is built by key template of the schema,
+// so client can link to record before it is created. tenant only selects schema overlay on validation, it does not change the key
+func StorageKey(conn *Data.Connection, dataType string, payload map[string]interface{}) (string, *Http.HttpError) {
+	schema, err := conn.GetSchema(dataType)
+	if err != nil {
+		return "", err
+	}
+	doc := schema.DocOf(payload)
+	if len(doc.KeyTemplate.Vars) == 0 {
+		return "", Http.NewHttpError(fmt.Sprintf("schema of type [%s] has no key template, id of record is given by client", dataType), http.StatusBadRequest)
+	}
+	dataId, ex := doc.BuildKey(payload)
+	if ex != nil {
+		return "", Http.WrapError(ex, fmt.Sprintf("failed to build key of type [%s] from payload", dataType), http.StatusBadRequest)
+	}
+	return fmt.Sprintf("%s/%s", dataType, dataId), nil
+}
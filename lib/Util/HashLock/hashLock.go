@@ -74,7 +74,9 @@ func (l *HashLock) Aquire(key string, userId string) error {
 }
 
 func (l *HashLock) Release(key string, userId string) {
+	l.lock.Lock(10 * time.Second)
 	lc, ok := l.hash[key]
+	l.lock.Unlock()
 	if !ok {
 		l.log.Printf("HashLock: lock key [%s] does not exists", key)
 		return
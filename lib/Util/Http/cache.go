@@ -0,0 +1,99 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderCacheControl = "Cache-Control"
+	HeaderExpires      = "Expires"
+	HeaderPragma       = "Pragma"
+	CacheNoCache       = "no-cache"
+	CacheNoStore       = "no-store"
+)
+
+// cache policy of GET response. MaxAge in seconds makes response cacheable, NoStore forbids any cache
+type CachePolicy struct {
+	MaxAge  int  `json:"maxAge"`
+	NoStore bool `json:"noStore"`
+}
+
+// cache policy by data type, types not listed use Default.
+// no cache header is set when policy is empty
+type CacheConfig struct {
+	Default CachePolicy            `json:"default"`
+	Types   map[string]CachePolicy `json:"types"`
+}
+
+func (c CacheConfig) Policy(dataType string) CachePolicy {
+	if policy, ok := c.Types[dataType]; ok {
+		return policy
+	}
+	return c.Default
+}
+
+// Cache-Control and Expires headers of policy, empty when policy is not set
+func CacheHeaders(policy CachePolicy, now time.Time) map[string]string {
+	if policy.NoStore {
+		return map[string]string{
+			HeaderCacheControl: CacheNoStore,
+			HeaderExpires:      "0",
+		}
+	}
+	if policy.MaxAge > 0 {
+		return map[string]string{
+			HeaderCacheControl: fmt.Sprintf("public, max-age=%d", policy.MaxAge),
+			HeaderExpires:      now.Add(time.Duration(policy.MaxAge) * time.Second).UTC().Format(http.TimeFormat),
+		}
+	}
+	return map[string]string{}
+}
+
+// set cache headers of dataType on response
+func SetCacheHeaders(w http.ResponseWriter, httpCfg Config, dataType string) {
+	for key, value := range CacheHeaders(httpCfg.Cache.Policy(dataType), time.Now()) {
+		w.Header().Set(key, value)
+	}
+}
+
+// client asks to bypass caches by Cache-Control: no-cache or Pragma: no-cache
+func NoCache(r *http.Request) bool {
+	for _, key := range []string{HeaderCacheControl, HeaderPragma} {
+		for _, value := range r.Header.Values(key) {
+			for _, directive := range strings.Split(value, ",") {
+				if strings.EqualFold(strings.TrimSpace(directive), CacheNoCache) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
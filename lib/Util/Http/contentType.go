@@ -0,0 +1,72 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+const (
+	DefaultCharset   = "utf-8"
+	HeaderContent    = "Content-Type"
+	MediaCsv         = "text/csv"
+	MediaEventStream = "text/event-stream"
+	MediaJson        = "application/json"
+	MediaJsonApi     = "application/vnd.api+json"
+	MediaJsonLines   = "application/x-ndjson"
+	MediaJsonPatch   = "application/json-patch+json"
+	MediaMergePatch  = "application/merge-patch+json"
+	MediaText        = "text/plain"
+)
+
+// value of Content-Type header of mediaType with charset from httpCfg, default utf-8
+func ContentType(mediaType string, httpCfg Config) string {
+	charset := httpCfg.Charset
+	if charset == "" {
+		charset = DefaultCharset
+	}
+	return fmt.Sprintf("%s; charset=%s", mediaType, charset)
+}
+
+// Content-Type of JSON response, media type can be changed by ContentType in httpCfg, like application/vnd.api+json
+func JsonContentType(httpCfg Config) string {
+	mediaType := httpCfg.ContentType
+	if mediaType == "" {
+		mediaType = MediaJson
+	}
+	return ContentType(mediaType, httpCfg)
+}
+
+// media type of request body from Content-Type header without parameters like charset, empty when not set
+func RequestMediaType(r *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(HeaderContent))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
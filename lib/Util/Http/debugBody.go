@@ -0,0 +1,192 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	DebugBodyCapture    = 1 << 20 // bytes of body kept for logging, larger body is not logged
+	DebugBodyRedacted   = "***"
+	DefaultDebugBodyMax = 4096 // bytes of body in one log line
+)
+
+// log request and response bodies for debugging, off by default.
+// body longer than MaxSize is cut, attribute marked sensitive in schema is logged as ***
+type DebugBodyConfig struct {
+	Enabled bool `json:"enabled"`
+	MaxSize int  `json:"maxSize"`
+}
+
+// wrap handler to log bodies when DebugBody is enabled, otherwise next is returned as it is.
+// sensitive gives attribute names to redact, body not in JSON is not logged since it can not be redacted
+func DebugBodyHandler(next http.Handler, httpCfg Config, logger *log.Logger, sensitive func() map[string]bool) http.Handler {
+	cfg := httpCfg.DebugBody
+	if !cfg.Enabled {
+		return next
+	}
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultDebugBodyMax
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names := sensitive()
+		if r.Body != nil {
+			reqBody, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				ResponseJson(w, WrapError(err, "failed to read body from request", http.StatusBadRequest), http.StatusBadRequest, httpCfg)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+			if len(reqBody) > 0 {
+				logger.Printf("DEBUG request [%s %s] body: %s", r.Method, r.URL.Path, DebugBody(reqBody, r.URL.Path, names, maxSize))
+			}
+		}
+		recorder := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		logger.Printf("DEBUG response [%s %s] status [%d] body: %s", r.Method, r.URL.Path, recorder.status, DebugBody(recorder.body(), r.URL.Path, names, maxSize))
+	})
+}
+
+// body as it goes to log: sensitive attributes redacted, cut at maxSize.
+// JSON lines are redacted line by line, whole body is redacted when path goes into a sensitive attribute
+func DebugBody(body []byte, urlPath string, sensitive map[string]bool, maxSize int) string {
+	if body == nil {
+		return fmt.Sprintf("[over %d bytes, not logged]", DebugBodyCapture)
+	}
+	if len(body) == 0 {
+		return ""
+	}
+	for _, name := range strings.Split(urlPath, "/") {
+		if sensitive[name] {
+			return DebugBodyRedacted
+		}
+	}
+	text, ok := redactJson(body, sensitive)
+	if !ok {
+		lines := []string{}
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 0, len(body)), len(body))
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			line, ok := redactJson(scanner.Bytes(), sensitive)
+			if !ok {
+				return fmt.Sprintf("[%d bytes not in JSON, not logged]", len(body))
+			}
+			lines = append(lines, line)
+		}
+		text = strings.Join(lines, "\n")
+	}
+	if len(text) > maxSize {
+		return fmt.Sprintf("%s...[cut, %d bytes]", text[:maxSize], len(text))
+	}
+	return text
+}
+
+func redactJson(body []byte, sensitive map[string]bool) (string, bool) {
+	var data interface{}
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		return "", false
+	}
+	text, err := json.Marshal(redact(data, sensitive))
+	if err != nil {
+		return "", false
+	}
+	return string(text), true
+}
+
+func redact(data interface{}, sensitive map[string]bool) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			if sensitive[key] {
+				result[key] = DebugBodyRedacted
+				continue
+			}
+			result[key] = redact(item, sensitive)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for idx, item := range value {
+			result[idx] = redact(item, sensitive)
+		}
+		return result
+	default:
+		return data
+	}
+}
+
+// keep status and up to DebugBodyCapture bytes of response, stream still flushes through
+type bodyRecorder struct {
+	http.ResponseWriter
+	status   int
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (b *bodyRecorder) WriteHeader(status int) {
+	b.status = status
+	b.ResponseWriter.WriteHeader(status)
+}
+
+func (b *bodyRecorder) Write(data []byte) (int, error) {
+	if !b.overflow {
+		if b.buf.Len()+len(data) > DebugBodyCapture {
+			b.overflow = true
+			b.buf.Reset()
+		} else {
+			b.buf.Write(data)
+		}
+	}
+	return b.ResponseWriter.Write(data)
+}
+
+func (b *bodyRecorder) Flush() {
+	if flusher, ok := b.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// nil when body was over DebugBodyCapture
+func (b *bodyRecorder) body() []byte {
+	if b.overflow {
+		return nil
+	}
+	return b.buf.Bytes()
+}
@@ -41,6 +41,8 @@ type HttpError struct {
 	Code    int         `json:"code"`
 	Context []string    `json:"context"`
 	Payload interface{} `json:"payload"`
+	// set instead of detail when error detail level is safe
+	CorrelationId string `json:"correlationId,omitempty"`
 }
 
 func (e HttpError) Error() string {
@@ -59,6 +61,14 @@ func (e HttpError) Error() string {
 	return string(errTxtBytes)
 }
 
+// payload that is an error itself, like SchemaPath.PathError, so errors.As can reach it
+func (e *HttpError) Unwrap() error {
+	if err, ok := e.Payload.(error); ok {
+		return err
+	}
+	return nil
+}
+
 func (e *HttpError) AppendError(err error) {
 	if !IsHttpError(err) {
 		e.Context = append(e.Context, e.Error())
@@ -0,0 +1,65 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+)
+
+const (
+	ErrorDetailFull = "full" // return error as it is
+	ErrorDetailSafe = "safe" // return generic message and correlation id, detail only goes to log
+)
+
+// error that goes back to client according to httpCfg.ErrorDetail.
+// in safe mode full detail is logged with correlation id, so it can be found from the response
+func DetailError(err *HttpError, httpCfg Config) *HttpError {
+	if httpCfg.ErrorDetail != ErrorDetailSafe {
+		return err
+	}
+	correlationId := newCorrelationId()
+	log.Printf("error [%s]: %s", correlationId, err.Error())
+	message := http.StatusText(err.Status)
+	if message == "" {
+		message = "request failed"
+	}
+	return &HttpError{
+		Status:        err.Status,
+		Message:       []string{message},
+		Code:          err.Code,
+		Context:       []string{},
+		CorrelationId: correlationId,
+	}
+}
+
+func newCorrelationId() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
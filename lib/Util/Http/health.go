@@ -0,0 +1,74 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	StatusOk       = "ok"
+	StatusNotReady = "not ready"
+)
+
+// body of health and ready probes
+type HealthStatus struct {
+	Status  string   `json:"status"`
+	Version string   `json:"version"`
+	Uptime  int64    `json:"uptime"` // seconds since server started
+	Message []string `json:"message,omitempty"`
+}
+
+// liveness probe, always 200 while the process serves requests
+func HealthHandler(version string, started time.Time, httpCfg Config) http.Handler {
+	return ReadyHandler(version, started, nil, httpCfg)
+}
+
+// readiness probe, 200 when ready returns nil, otherwise 503 with error of ready in message.
+// nil ready is always ready
+func ReadyHandler(version string, started time.Time, ready func() *HttpError, httpCfg Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			ResponseJson(w, NewHttpError("method not allowed on probe", http.StatusMethodNotAllowed), http.StatusMethodNotAllowed, httpCfg)
+			return
+		}
+		status := HealthStatus{
+			Status:  StatusOk,
+			Version: version,
+			Uptime:  int64(time.Since(started) / time.Second),
+		}
+		code := http.StatusOK
+		if ready != nil {
+			if err := ready(); err != nil {
+				status.Status = StatusNotReady
+				status.Message = err.Message
+				code = http.StatusServiceUnavailable
+			}
+		}
+		ResponseJson(w, status, code, httpCfg)
+	})
+}
@@ -27,6 +27,7 @@ package Http
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -35,8 +36,11 @@ import (
 	"net/url"
 	"path"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/salesforce/UniTAO/lib/Util/Json"
 )
 
 var UpdateMethods = map[string]bool{
@@ -52,11 +56,25 @@ var SuccessCodes = map[int]bool{
 }
 
 type Config struct {
-	HttpType  string                 `json:"type"`
-	DnsName   string                 `json:"dns"`
-	Port      string                 `json:"port"`
-	Id        string                 `json:"id"`
-	HeaderCfg map[string]interface{} `json:"headers"`
+	HttpType    string                 `json:"type"`
+	DnsName     string                 `json:"dns"`
+	Port        string                 `json:"port"`
+	Id          string                 `json:"id"`
+	HeaderCfg   map[string]interface{} `json:"headers"`
+	Security    map[string]string      `json:"securityHeaders"`
+	StrictJson  bool                   `json:"strictJson"` // reject JSON body with duplicate keys instead of keeping the last value
+	Cache       CacheConfig            `json:"cache"`
+	ErrorDetail string                 `json:"errorDetail"` // full (default) or safe
+	KeyCase     string                 `json:"keyCase"`     // casing of record attributes on the wire, snake or camel. kept as stored when empty
+	ContentType string                 `json:"contentType"` // media type of JSON response, default application/json
+	Charset     string                 `json:"charset"`     // charset of every text response, default utf-8
+	BasePath    string                 `json:"basePath"`    // path prefix the service is reached under, like behind a proxy. used in Location of created record
+	Timeout     TimeoutConfig          `json:"timeout"`
+	Limit       LimitConfig            `json:"limit"`
+	DebugBody   DebugBodyConfig        `json:"debugBody"`
+	Metrics     MetricsConfig          `json:"metrics"`
+	CertFile    string                 `json:"certFile"` // PEM certificate, required when type is https
+	KeyFile     string                 `json:"keyFile"`  // PEM private key of certificate, required when type is https
 }
 
 func GetUrl(r *http.Request) (string, *HttpError) {
@@ -71,6 +89,12 @@ func GetUrl(r *http.Request) (string, *HttpError) {
 }
 
 func LoadRequest(r *http.Request) (interface{}, *HttpError) {
+	return LoadJsonRequest(r, Config{})
+}
+
+// load request body as JSON map, body not in JSON is returned as string.
+// with StrictJson in httpCfg, JSON body with duplicate keys is rejected
+func LoadJsonRequest(r *http.Request, httpCfg Config) (interface{}, *HttpError) {
 	reqBody, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return nil, WrapError(err, "failed to read body from request", http.StatusBadRequest)
@@ -84,10 +108,42 @@ func LoadRequest(r *http.Request) (interface{}, *HttpError) {
 		}
 		return strData, nil
 	}
+	if httpCfg.StrictJson {
+		err = Json.CheckDuplicateKeys(reqBody)
+		if err != nil {
+			return nil, WrapError(err, "invalid JSON body", http.StatusBadRequest)
+		}
+	}
+	return data, nil
+}
+
+// load request body as JSON array, like operations of JSON Patch. StrictJson in httpCfg applies as in LoadJsonRequest
+func LoadJsonListRequest(r *http.Request, httpCfg Config) ([]interface{}, *HttpError) {
+	reqBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, WrapError(err, "failed to read body from request", http.StatusBadRequest)
+	}
+	data := []interface{}{}
+	err = json.Unmarshal(reqBody, &data)
+	if err != nil {
+		return nil, WrapError(err, "request body is not JSON array", http.StatusBadRequest)
+	}
+	if httpCfg.StrictJson {
+		err = Json.CheckDuplicateKeys(reqBody)
+		if err != nil {
+			return nil, WrapError(err, "invalid JSON body", http.StatusBadRequest)
+		}
+	}
 	return data, nil
 }
 
 func ResponseJson(w http.ResponseWriter, data interface{}, status int, httpCfg Config) {
+	switch err := data.(type) {
+	case *HttpError:
+		data = DetailError(err, httpCfg)
+	case HttpError:
+		data = DetailError(&err, httpCfg)
+	}
 	jsonData, err := json.MarshalIndent(data, "", "    ")
 	if err != nil {
 		log.Fatal(err)
@@ -95,15 +151,65 @@ func ResponseJson(w http.ResponseWriter, data interface{}, status int, httpCfg C
 		return
 	}
 	jsonStr := fmt.Sprintf("%s\n", string(jsonData))
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(HeaderContent, JsonContentType(httpCfg))
 	Response(w, []byte(jsonStr), status, httpCfg)
 }
 
+// write each item from channel as one line of JSON and flush it, until channel is closed.
+// trailers tell client if the stream completed and how many lines it got, see StreamTrailers
+func ResponseJsonLines[T any](w http.ResponseWriter, items <-chan T, httpCfg Config) {
+	w.Header().Set(HeaderContent, ContentType(MediaJsonLines, httpCfg))
+	w.Header().Set(HeaderTrailer, strings.Join(StreamTrailers, ", "))
+	Response(w, nil, http.StatusOK, httpCfg)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	count := 0
+	var streamErr error
+	for item := range items {
+		if streamErr != nil {
+			// drain the rest so producer is not blocked
+			continue
+		}
+		if failed, ok := any(item).(StreamItem); ok {
+			streamErr = failed.StreamErr()
+		}
+		err := encoder.Encode(item)
+		if err != nil {
+			streamErr = err
+			continue
+		}
+		count++
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	status := StreamOk
+	if streamErr != nil {
+		status = StreamFailed
+		w.Header().Set(TrailerStreamError, streamErr.Error())
+	}
+	w.Header().Set(TrailerStreamStatus, status)
+	w.Header().Set(TrailerStreamCount, strconv.Itoa(count))
+}
+
 func ResponseText(w http.ResponseWriter, txt []byte, status int, httpCfg Config) {
-	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set(HeaderContent, ContentType(MediaText, httpCfg))
 	Response(w, txt, status, httpCfg)
 }
 
+// write rows as CSV, first row is the header
+func ResponseCsv(w http.ResponseWriter, rows [][]string, status int, httpCfg Config) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	err := writer.WriteAll(rows)
+	if err != nil {
+		ResponseErr(w, WrapError(err, "failed to write CSV", http.StatusInternalServerError), http.StatusInternalServerError, httpCfg)
+		return
+	}
+	w.Header().Set(HeaderContent, ContentType(MediaCsv, httpCfg))
+	Response(w, buf.Bytes(), status, httpCfg)
+}
+
 func Response(w http.ResponseWriter, txt []byte, status int, httpCfg Config) {
 	for key, value := range httpCfg.HeaderCfg {
 		switch reflect.TypeOf(value).Kind() {
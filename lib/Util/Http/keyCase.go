@@ -0,0 +1,86 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	KeyCaseCamel = "camel" // data stored in snake_case, client reads and writes camelCase
+	KeyCaseSnake = "snake" // data stored in camelCase, client reads and writes snake_case
+)
+
+// func that renames attribute from stored casing to casing of client, nil when KeyCase is not set.
+// it only knows casing of names, which names are attributes is up to the schema of record
+func WireKeyFunc(httpCfg Config) func(string) string {
+	switch httpCfg.KeyCase {
+	case KeyCaseSnake:
+		return ToSnake
+	case KeyCaseCamel:
+		return ToCamel
+	}
+	return nil
+}
+
+// fooBarBaz -> foo_bar_baz, run of upper case letters is one word, HTTPPort -> http_port, vmID -> vm_id
+func ToSnake(key string) string {
+	runes := []rune(key)
+	builder := strings.Builder{}
+	for idx, c := range runes {
+		if unicode.IsUpper(c) {
+			if idx > 0 && runes[idx-1] != '_' {
+				prevUpper := unicode.IsUpper(runes[idx-1])
+				nextLower := idx+1 < len(runes) && unicode.IsLower(runes[idx+1])
+				if !prevUpper || nextLower {
+					builder.WriteByte('_')
+				}
+			}
+			c = unicode.ToLower(c)
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String()
+}
+
+// foo_bar_baz -> fooBarBaz
+func ToCamel(key string) string {
+	builder := strings.Builder{}
+	upper := false
+	for idx, c := range key {
+		if c == '_' && idx > 0 {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String()
+}
@@ -0,0 +1,141 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimitQueueWait = 100 // milliseconds a request waits for a free slot
+	DefaultLimitRetry     = 1   // seconds in Retry-After of rejected request
+	HeaderRetryAfter      = "Retry-After"
+	PathHealth            = "/health"
+	PathReady             = "/ready"
+	PathHealthAlias       = "/_health"
+	PathReadyAlias        = "/_ready"
+	SuffixWatch           = "/_watch"
+)
+
+// path prefixes never limited by default, so health probes still get through when server is saturated
+var DefaultLimitExempt = []string{PathHealth, PathReady, PathHealthAlias, PathReadyAlias}
+
+// path suffixes never limited by default, long-lived watch stream would hold its slot as long as it is open
+var DefaultLimitStream = []string{SuffixWatch}
+
+// cap of requests served at the same time, MaxInFlight 0 means no limit.
+// request over the cap waits up to QueueWait milliseconds for a slot, 0 takes the default, negative does not wait.
+// request still without slot gets 503 with Retry-After of RetryAfter seconds
+type LimitConfig struct {
+	MaxInFlight int      `json:"maxInFlight"`
+	QueueWait   int      `json:"queueWait"`
+	RetryAfter  int      `json:"retryAfter"`
+	Exempt      []string `json:"exempt"` // path prefixes not limited, default health and ready endpoints
+	Stream      []string `json:"stream"` // path suffixes of long-lived streams not limited, default watch
+}
+
+// wrap handler to serve at most Limit.MaxInFlight requests at the same time.
+// long-lived streams in Limit.Stream are not counted
+func LimitHandler(next http.Handler, httpCfg Config) http.Handler {
+	cfg := httpCfg.Limit
+	if cfg.MaxInFlight <= 0 {
+		return next
+	}
+	queueWait := cfg.QueueWait
+	if queueWait == 0 {
+		queueWait = DefaultLimitQueueWait
+	}
+	retryAfter := cfg.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = DefaultLimitRetry
+	}
+	exempt := cfg.Exempt
+	if exempt == nil {
+		exempt = DefaultLimitExempt
+	}
+	stream := cfg.Stream
+	if stream == nil {
+		stream = DefaultLimitStream
+	}
+	slots := make(chan struct{}, cfg.MaxInFlight)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limitExempt(r.URL.Path, exempt) || limitStream(r.URL.Path, stream) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !acquireSlot(slots, queueWait, r) {
+			w.Header().Set(HeaderRetryAfter, strconv.Itoa(retryAfter))
+			err := NewHttpError(fmt.Sprintf("server is busy with [%d] requests, retry later", cfg.MaxInFlight), http.StatusServiceUnavailable)
+			ResponseJson(w, err, err.Status, httpCfg)
+			return
+		}
+		defer func() { <-slots }()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func acquireSlot(slots chan struct{}, queueWait int, r *http.Request) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+	if queueWait < 0 {
+		return false
+	}
+	timer := time.NewTimer(time.Duration(queueWait) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func limitExempt(path string, exempt []string) bool {
+	for _, prefix := range exempt {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func limitStream(path string, stream []string) bool {
+	for _, suffix := range stream {
+		if strings.HasSuffix(strings.TrimSuffix(path, "/"), suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	PathMetrics        = "/metrics"
+	PathMetricsAlias   = "/_metrics"
+	MetricsUnknown     = "unknown" // label of data type that is not known, keeps label values bounded
+	MetricsOther       = "OTHER"   // label of non standard method
+	ContentTypeMetrics = "text/plain; version=0.0.4"
+)
+
+// upper bounds in seconds of request duration histogram
+var DefaultMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var metricsMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// request metrics in Prometheus text format, off unless Enabled
+type MetricsConfig struct {
+	Enabled bool      `json:"enabled"`
+	Buckets []float64 `json:"buckets"` // upper bounds of duration histogram in seconds, empty takes the default
+}
+
+type metricsKey struct {
+	method   string
+	dataType string
+}
+
+type metricsSeries struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+	codes   map[int]uint64
+}
+
+// request count, duration histogram and response codes by method and data type
+type Metrics struct {
+	prefix  string
+	buckets []float64
+	lock    sync.Mutex
+	series  map[metricsKey]*metricsSeries
+}
+
+// metric names start with prefix, like unitao_data
+func NewMetrics(prefix string, cfg MetricsConfig) *Metrics {
+	buckets := append([]float64{}, cfg.Buckets...)
+	if len(buckets) == 0 {
+		buckets = append(buckets, DefaultMetricsBuckets...)
+	}
+	sort.Float64s(buckets)
+	return &Metrics{
+		prefix:  prefix,
+		buckets: buckets,
+		series:  map[metricsKey]*metricsSeries{},
+	}
+}
+
+func (m *Metrics) Observe(method string, dataType string, status int, duration time.Duration) {
+	if !metricsMethods[method] {
+		method = MetricsOther
+	}
+	key := metricsKey{method: method, dataType: dataType}
+	seconds := duration.Seconds()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	series, ok := m.series[key]
+	if !ok {
+		series = &metricsSeries{buckets: make([]uint64, len(m.buckets)), codes: map[int]uint64{}}
+		m.series[key] = series
+	}
+	series.count++
+	series.sum += seconds
+	for idx, bound := range m.buckets {
+		if seconds <= bound {
+			series.buckets[idx]++
+		}
+	}
+	series.codes[status]++
+}
+
+// write all metrics in Prometheus text format, series sorted by labels
+func (m *Metrics) Write(w io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	keys := make([]metricsKey, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].dataType < keys[j].dataType
+	})
+	name := m.prefix + "_requests_total"
+	fmt.Fprintf(w, "# HELP %s Requests served by method and data type.\n# TYPE %s counter\n", name, name)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, key.labels(), m.series[key].count)
+	}
+	name = m.prefix + "_request_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Duration of requests by method and data type.\n# TYPE %s histogram\n", name, name)
+	for _, key := range keys {
+		series := m.series[key]
+		for idx, bound := range m.buckets {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, key.labels(), strconv.FormatFloat(bound, 'g', -1, 64), series.buckets[idx])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, key.labels(), series.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, key.labels(), strconv.FormatFloat(series.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, key.labels(), series.count)
+	}
+	name = m.prefix + "_responses_total"
+	fmt.Fprintf(w, "# HELP %s Responses by method, data type and status code.\n# TYPE %s counter\n", name, name)
+	for _, key := range keys {
+		series := m.series[key]
+		codes := make([]int, 0, len(series.codes))
+		for code := range series.codes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "%s{%s,code=\"%d\"} %d\n", name, key.labels(), code, series.codes[code])
+		}
+	}
+}
+
+func (k metricsKey) labels() string {
+	return fmt.Sprintf("method=\"%s\",dataType=\"%s\"", escapeLabel(k.method), escapeLabel(k.dataType))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// serve metrics on GET
+func (m *Metrics) Handler(httpCfg Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			ResponseJson(w, NewHttpError("method not allowed on metrics", http.StatusMethodNotAllowed), http.StatusMethodNotAllowed, httpCfg)
+			return
+		}
+		w.Header().Set(HeaderContent, ContentTypeMetrics)
+		w.WriteHeader(http.StatusOK)
+		m.Write(w)
+	})
+}
+
+// wrap handler to observe every request in metrics. dataType gives label of request after it is served,
+// it should return MetricsUnknown for anything but known data types
+func MetricsHandler(next http.Handler, metrics *Metrics, dataType func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		metrics.Observe(r.Method, dataType(r), recorder.status, time.Since(start))
+	})
+}
+
+// keep status of response, stream still flushes through
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(data []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(data)
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
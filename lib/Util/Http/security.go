@@ -0,0 +1,76 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"net/http"
+)
+
+const (
+	HeaderContentTypeOptions = "X-Content-Type-Options"
+	HeaderFrameOptions       = "X-Frame-Options"
+	HeaderHsts               = "Strict-Transport-Security"
+	HeaderCsp                = "Content-Security-Policy"
+)
+
+// default security headers, can be overwritten in Config.Security.
+// set header value to empty string in config to disable it
+var DefaultSecurityHeaders = map[string]string{
+	HeaderContentTypeOptions: "nosniff",
+	HeaderFrameOptions:       "DENY",
+	HeaderHsts:               "max-age=31536000; includeSubDomains",
+	HeaderCsp:                "default-src 'none'; frame-ancestors 'none'",
+}
+
+// security headers to set on response, HSTS only applies to TLS connection
+func SecurityHeaders(httpCfg Config, isTls bool) map[string]string {
+	headers := make(map[string]string, len(DefaultSecurityHeaders))
+	for key, value := range DefaultSecurityHeaders {
+		headers[key] = value
+	}
+	for key, value := range httpCfg.Security {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+	if !isTls {
+		delete(headers, HeaderHsts)
+	}
+	for key, value := range headers {
+		if value == "" {
+			delete(headers, key)
+		}
+	}
+	return headers
+}
+
+// wrap handler to set security headers on all responses
+func SecurityHandler(next http.Handler, httpCfg Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for key, value := range SecurityHeaders(httpCfg, r.TLS != nil) {
+			w.Header().Set(key, value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,192 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// default timeouts in seconds.
+// write has no default limit, watch stream stays open as long as client listens
+const (
+	DefaultReadHeaderTimeout = 10
+	DefaultReadTimeout       = 30
+	DefaultWriteTimeout      = 0
+	DefaultIdleTimeout       = 120
+	DefaultShutdownTimeout   = 30
+)
+
+// type of http server in config, empty serves plain http
+const (
+	TypeHttp  = "http"
+	TypeHttps = "https"
+)
+
+// timeouts of http server in seconds. 0 takes the default, negative value means no limit
+type TimeoutConfig struct {
+	ReadHeader int `json:"readHeader"`
+	Read       int `json:"read"`
+	Write      int `json:"write"`
+	Idle       int `json:"idle"`
+	Shutdown   int `json:"shutdown"` // wait for in-flight requests on shutdown
+}
+
+func timeout(value int, defaultValue int) time.Duration {
+	if value == 0 {
+		value = defaultValue
+	}
+	if value < 0 {
+		return 0
+	}
+	return time.Duration(value) * time.Second
+}
+
+// http server listen on addr with timeouts from config
+func NewServer(addr string, handler http.Handler, cfg Config) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: timeout(cfg.Timeout.ReadHeader, DefaultReadHeaderTimeout),
+		ReadTimeout:       timeout(cfg.Timeout.Read, DefaultReadTimeout),
+		WriteTimeout:      timeout(cfg.Timeout.Write, DefaultWriteTimeout),
+		IdleTimeout:       timeout(cfg.Timeout.Idle, DefaultIdleTimeout),
+	}
+}
+
+// http server that stops on signal or Stop, in-flight requests get up to Timeout.Shutdown seconds to finish.
+// 0 takes the default, negative waits as long as requests run
+type GracefulServer struct {
+	Server          *http.Server
+	ShutdownTimeout time.Duration
+	CertFile        string // serve TLS when set, with KeyFile
+	KeyFile         string
+	stopOnce        sync.Once
+	stopped         chan struct{}
+	stopErr         error
+}
+
+// server with https type in cfg serves TLS with CertFile and KeyFile of cfg
+func NewGracefulServer(addr string, handler http.Handler, cfg Config) *GracefulServer {
+	srv := &GracefulServer{
+		Server:          NewServer(addr, handler, cfg),
+		ShutdownTimeout: timeout(cfg.Timeout.Shutdown, DefaultShutdownTimeout),
+		stopped:         make(chan struct{}),
+	}
+	if cfg.HttpType == TypeHttps {
+		srv.CertFile = cfg.CertFile
+		srv.KeyFile = cfg.KeyFile
+	}
+	return srv
+}
+
+// check http type of cfg is known, and with https the cert and key files load as a key pair
+func CheckServerConfig(cfg Config) error {
+	switch cfg.HttpType {
+	case "", TypeHttp:
+		return nil
+	case TypeHttps:
+		return checkKeyPair(cfg.CertFile, cfg.KeyFile)
+	}
+	return fmt.Errorf("unknown http type=[%s], expect [%s] or [%s]", cfg.HttpType, TypeHttp, TypeHttps)
+}
+
+func checkKeyPair(certFile string, keyFile string) error {
+	if certFile == "" || keyFile == "" {
+		return fmt.Errorf("https requires certFile and keyFile, got certFile=[%s], keyFile=[%s]", certFile, keyFile)
+	}
+	_, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certFile=[%s], keyFile=[%s]. Error: %s", certFile, keyFile, err)
+	}
+	return nil
+}
+
+// listen on Addr of server, see Serve. with CertFile the key pair is checked before listening
+func (s *GracefulServer) ListenAndServe(signals ...os.Signal) error {
+	if s.CertFile != "" {
+		err := checkKeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return err
+		}
+	}
+	listener, err := net.Listen("tcp", s.Server.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener, signals...)
+}
+
+// serve on listener until it fails, one of signals arrives or Stop is called.
+// return error of listener, or of shutdown when requests did not finish in time, nil on clean stop
+func (s *GracefulServer) Serve(listener net.Listener, signals ...os.Signal) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if s.CertFile != "" {
+			serveErr <- s.Server.ServeTLS(listener, s.CertFile, s.KeyFile)
+			return
+		}
+		serveErr <- s.Server.Serve(listener)
+	}()
+	sigCh := make(chan os.Signal, 1)
+	if len(signals) > 0 {
+		signal.Notify(sigCh, signals...)
+		defer signal.Stop(sigCh)
+	}
+	select {
+	case err := <-serveErr:
+		if err != http.ErrServerClosed {
+			return err
+		}
+		// Stop is called elsewhere, wait for it to drain requests
+		<-s.stopped
+		return s.stopErr
+	case <-sigCh:
+		ctx, cancel := context.WithCancel(context.Background())
+		if s.ShutdownTimeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), s.ShutdownTimeout)
+		}
+		defer cancel()
+		return s.Stop(ctx)
+	}
+}
+
+// stop accepting requests and wait for in-flight ones until ctx is done. safe to call more than once
+func (s *GracefulServer) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		s.stopErr = s.Server.Shutdown(ctx)
+		close(s.stopped)
+	})
+	<-s.stopped
+	return s.stopErr
+}
@@ -0,0 +1,44 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Http
+
+const (
+	HeaderTrailer       = "Trailer"
+	StreamFailed        = "failed"
+	StreamOk            = "ok"
+	TrailerStreamCount  = "X-Stream-Count"
+	TrailerStreamError  = "X-Stream-Error"
+	TrailerStreamStatus = "X-Stream-Status"
+)
+
+// trailers sent after json lines stream. status is ok or failed, count is lines written, error is set when failed
+var StreamTrailers = []string{TrailerStreamStatus, TrailerStreamCount, TrailerStreamError}
+
+// item of json lines stream that can tell source of items broke partway.
+// the item is still written, then stream stops and trailer reports failure
+type StreamItem interface {
+	StreamErr() error
+}
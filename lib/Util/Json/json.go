@@ -26,11 +26,13 @@ This copyright notice and license applies to all files in this directory or sub-
 package Json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"reflect"
+	"sort"
 )
 
 func LoadJsonFile(filePath string) (interface{}, error) {
@@ -101,3 +103,145 @@ func CopyTo(src interface{}, targetAddr interface{}) error {
 	}
 	return nil
 }
+
+// walk JSON data token by token and return error on the first object with duplicate key.
+// json.Unmarshal silently keeps the last value of duplicate keys
+func CheckDuplicateKeys(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return checkDuplicateValue(decoder, "")
+}
+
+func checkDuplicateValue(decoder *json.Decoder, dataPath string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+	switch delim {
+	case '{':
+		keys := map[string]bool{}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key := token.(string)
+			if keys[key] {
+				return fmt.Errorf("duplicate key [%s] @path=[%s]", key, dataPath)
+			}
+			keys[key] = true
+			err = checkDuplicateValue(decoder, fmt.Sprintf("%s/%s", dataPath, key))
+			if err != nil {
+				return err
+			}
+		}
+	case '[':
+		for idx := 0; decoder.More(); idx++ {
+			err := checkDuplicateValue(decoder, fmt.Sprintf("%s[%d]", dataPath, idx))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// consume closing delim
+	_, err = decoder.Token()
+	return err
+}
+
+// serialize data as compact JSON with keys of every object sorted, so equal data always give identical bytes.
+// use it for anything that hashes or compares serialized data
+func Canonical(data interface{}) ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := writeCanonical(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, data interface{}) error {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for idx, key := range keys {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			err := writeCanonical(buf, key)
+			if err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			err = writeCanonical(buf, value[key])
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for idx, item := range value {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			err := writeCanonical(buf, item)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case nil, bool, string, float64, json.Number:
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(raw)
+	default:
+		// typed struct/map/slice, bring it to generic JSON form first
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var generic interface{}
+		err = decoder.Decode(&generic)
+		if err != nil {
+			return err
+		}
+		return writeCanonical(buf, generic)
+	}
+	return nil
+}
+
+// apply patch on target as JSON Merge Patch, RFC 7386. null in patch removes the key,
+// object merges into object, anything else replaces target. target is not modified
+func MergePatch(target interface{}, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	result := map[string]interface{}{}
+	if targetMap, ok := target.(map[string]interface{}); ok {
+		for key, value := range targetMap {
+			result[key] = value
+		}
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = MergePatch(result[key], value)
+	}
+	return result
+}
@@ -0,0 +1,285 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package Json
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	PatchAdd     = "add"
+	PatchCopy    = "copy"
+	PatchMove    = "move"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchTest    = "test"
+)
+
+// returned by ApplyPatch when a test operation does not match
+var ErrPatchTest = errors.New("json patch test failed")
+
+// apply operations of JSON Patch, RFC 6902, in order on a copy of doc. paths are JSON Pointer, RFC 6901.
+// any failed operation fails the whole patch and doc stays as it is, failed test wraps ErrPatchTest
+func ApplyPatch(doc interface{}, ops []interface{}) (interface{}, error) {
+	result, err := Copy(doc)
+	if err != nil {
+		return nil, err
+	}
+	for idx, item := range ops {
+		op, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operation @[%d] is not JSON object", idx)
+		}
+		result, err = applyOp(result, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation @[%d] failed, Error: %w", idx, err)
+		}
+	}
+	return result, nil
+}
+
+func applyOp(doc interface{}, op map[string]interface{}) (interface{}, error) {
+	opName, _ := op["op"].(string)
+	path, ok := op["path"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing [path] of op [%s]", opName)
+	}
+	switch opName {
+	case PatchAdd, PatchReplace, PatchTest:
+		value, ok := op["value"]
+		if !ok {
+			return nil, fmt.Errorf("missing [value] of op [%s]", opName)
+		}
+		if opName == PatchAdd {
+			return pointerAdd(doc, path, value)
+		}
+		current, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if opName == PatchTest {
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w, value @[%s] is [%v], expect [%v]", ErrPatchTest, path, current, value)
+			}
+			return doc, nil
+		}
+		if path == "" {
+			return value, nil
+		}
+		doc, err = pointerRemove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, value)
+	case PatchRemove:
+		return pointerRemove(doc, path)
+	case PatchMove, PatchCopy:
+		from, ok := op["from"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing [from] of op [%s]", opName)
+		}
+		value, err := pointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if opName == PatchMove {
+			if strings.HasPrefix(path, from+"/") {
+				return nil, fmt.Errorf("can not move [%s] into its own child [%s]", from, path)
+			}
+			doc, err = pointerRemove(doc, from)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			value, err = Copy(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return pointerAdd(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown op [%s]", opName)
+	}
+}
+
+// split JSON Pointer into unescaped tokens, "" points to the whole doc
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer [%s], must start with /", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for idx, token := range tokens {
+		tokens[idx] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIdx(list []interface{}, token string, path string, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return len(list), nil
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index [%s] @[%s]", token, path)
+	}
+	limit := len(list)
+	if allowEnd {
+		limit++
+	}
+	if idx >= limit {
+		return 0, fmt.Errorf("array index [%s] out of range @[%s]", token, path)
+	}
+	return idx, nil
+}
+
+func pointerGet(doc interface{}, path string) (interface{}, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, token := range tokens {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			child, ok := value[token]
+			if !ok {
+				return nil, fmt.Errorf("no value @[%s]", path)
+			}
+			current = child
+		case []interface{}:
+			idx, err := arrayIdx(value, token, path, false)
+			if err != nil {
+				return nil, err
+			}
+			current = value[idx]
+		default:
+			return nil, fmt.Errorf("no value @[%s]", path)
+		}
+	}
+	return current, nil
+}
+
+// parent of path and last token of it
+func pointerParent(doc interface{}, path string) (interface{}, string, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(tokens) == 0 {
+		return nil, "", nil
+	}
+	parentPath := path[:strings.LastIndex(path, "/")]
+	parent, err := pointerGet(doc, parentPath)
+	if err != nil {
+		return nil, "", err
+	}
+	return parent, tokens[len(tokens)-1], nil
+}
+
+// set value at path and return the doc, array is changed in place of its parent
+func pointerSet(doc interface{}, path string, change func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	parent, token, err := pointerParent(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return change(nil, "")
+	}
+	newParent, err := change(parent, token)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := parent.([]interface{}); !ok {
+		return doc, nil
+	}
+	parentPath := path[:strings.LastIndex(path, "/")]
+	if parentPath == "" {
+		return newParent, nil
+	}
+	grand, grandToken, _ := pointerParent(doc, parentPath)
+	switch value := grand.(type) {
+	case map[string]interface{}:
+		value[grandToken] = newParent
+	case []interface{}:
+		idx, _ := arrayIdx(value, grandToken, parentPath, false)
+		value[idx] = newParent
+	}
+	return doc, nil
+}
+
+func pointerAdd(doc interface{}, path string, value interface{}) (interface{}, error) {
+	return pointerSet(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch container := parent.(type) {
+		case nil:
+			return value, nil
+		case map[string]interface{}:
+			container[token] = value
+			return container, nil
+		case []interface{}:
+			idx, err := arrayIdx(container, token, path, true)
+			if err != nil {
+				return nil, err
+			}
+			container = append(container, nil)
+			copy(container[idx+1:], container[idx:])
+			container[idx] = value
+			return container, nil
+		default:
+			return nil, fmt.Errorf("can not add @[%s], parent is not object or array", path)
+		}
+	})
+}
+
+func pointerRemove(doc interface{}, path string) (interface{}, error) {
+	if path == "" {
+		return nil, fmt.Errorf("can not remove the whole doc")
+	}
+	return pointerSet(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := container[token]; !ok {
+				return nil, fmt.Errorf("no value @[%s]", path)
+			}
+			delete(container, token)
+			return container, nil
+		case []interface{}:
+			idx, err := arrayIdx(container, token, path, false)
+			if err != nil {
+				return nil, err
+			}
+			return append(container[:idx], container[idx+1:]...), nil
+		default:
+			return nil, fmt.Errorf("no value @[%s]", path)
+		}
+	})
+}
@@ -75,7 +75,7 @@ func (c *ThreadCtrl) GetWorker(workerId string) *Worker {
 
 func (c *ThreadCtrl) RemoveWorker(workerId string) error {
 	c.lock.Lock()
-	defer c.lock.Lock()
+	defer c.lock.Unlock()
 	delete(c.workers, workerId)
 	return nil
 }
@@ -32,6 +32,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -69,13 +70,100 @@ func ParseArrayPath(path string) (string, string, error) {
 	if keyStr == "" {
 		return "", "", fmt.Errorf("invalid array path=[%s], key empty", path)
 	}
-	key, err := url.QueryUnescape(keyStr)
+	key, err := UnescapeKey(keyStr)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to unescape key=[%s], Error:%s", keyStr, err)
 	}
+	if key == "-0" {
+		return "", "", fmt.Errorf("invalid array path=[%s], negative idx counts from -1", path)
+	}
+	if filterAttr, _, ok := FilterIdx(key); ok && filterAttr == "" {
+		return "", "", fmt.Errorf("invalid array path=[%s], filter attribute empty", path)
+	}
 	return attrName, key, nil
 }
 
+// key with chars that split path, / [ ] ?, percent-encoded like a%2Fb, so it can be record id or idx in path.
+// % is encoded too, as UnescapeKey decodes it. + is encoded so key also survives decoder of query value
+func EscapeKey(key string) string {
+	return keyEscaper.Replace(key)
+}
+
+var keyEscaper = strings.NewReplacer("%", "%25", "+", "%2B", "/", "%2F", "[", "%5B", "]", "%5D", "?", "%3F")
+
+// key of record id, idx or map key in path decoded from percent-encoding, done after path is split and command after ? is cut off.
+// raw + stays +, it is not taken as space
+func UnescapeKey(key string) (string, error) {
+	return url.PathUnescape(key)
+}
+
+// attr and value of filter idx like key1=01, which selects object items whose attr equals value.
+// key of item takes precedence, idx is only a filter when no item has it as key
+func FilterIdx(key string) (string, string, bool) {
+	return strings.Cut(key, "=")
+}
+
+// position from the end of array for idx like -1, the last item. ok is false for any other key
+func NegativeIdx(key string) (int, bool) {
+	if len(key) < 2 || key[0] != '-' {
+		return 0, false
+	}
+	pos, err := strconv.Atoi(key)
+	if err != nil || pos >= 0 || strings.ContainsAny(key[1:], "+-") {
+		return 0, false
+	}
+	return pos, true
+}
+
+// range [start, end) of array with given length for idx like 1:3, 2: or :2.
+// negative bound counts from the end, bounds are clamped to the array. ok is false when key is not a slice
+func SliceRange(key string, length int) (int, int, bool) {
+	startStr, endStr, isSlice := strings.Cut(key, ":")
+	if !isSlice {
+		return 0, 0, false
+	}
+	start, ok := sliceBound(startStr, 0, length)
+	if !ok {
+		return 0, 0, false
+	}
+	end, ok := sliceBound(endStr, length, length)
+	if !ok {
+		return 0, 0, false
+	}
+	if end < start {
+		end = start
+	}
+	return start, end, true
+}
+
+func IsSliceIdx(key string) bool {
+	_, _, ok := SliceRange(key, 0)
+	return ok
+}
+
+func sliceBound(bound string, defaultValue int, length int) (int, bool) {
+	if bound == "" {
+		return defaultValue, true
+	}
+	if strings.HasPrefix(bound, "+") {
+		return 0, false
+	}
+	value, err := strconv.Atoi(bound)
+	if err != nil {
+		return 0, false
+	}
+	if value < 0 {
+		value += length
+	}
+	if value < 0 {
+		return 0, true
+	}
+	if value > length {
+		return length, true
+	}
+	return value, true
+}
+
 func IdxList(searchAry []interface{}) map[interface{}]int {
 	hash := map[interface{}]int{}
 	for idx, item := range searchAry {
@@ -120,8 +208,13 @@ func DeDupeList(itemList []interface{}) ([]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	// keep items in the order they first show up in the list
 	result := make([]interface{}, 0, len(searchMap))
-	for item := range searchMap {
+	for _, item := range itemList {
+		if _, ok := searchMap[item]; !ok {
+			continue
+		}
+		delete(searchMap, item)
 		result = append(result, item)
 	}
 	return result, nil
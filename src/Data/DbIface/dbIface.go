@@ -53,6 +53,27 @@ type Database interface {
 	Delete(table string, keys map[string]interface{}) error
 }
 
+// optional for Database that can count records of queryArgs without loading them
+type Counter interface {
+	Count(queryArgs map[string]interface{}) (int, error)
+}
+
+// optional for Database that keeps records as bytes, returns them as stored. nil when record not found
+type RawGetter interface {
+	GetRaw(queryArgs map[string]interface{}) ([]byte, error)
+}
+
+// optional for Database that indexes refs, returns [type/id] of records in table that ref record [dataType/dataId]
+type RefFinder interface {
+	FindRefs(table string, dataType string, dataId string) ([]string, error)
+}
+
+// optional for Database that can write in transaction. writes made with Database while fn runs are kept
+// only when fn returns nil, and are not seen by other readers before that
+type Transactor interface {
+	Transaction(fn func() error) error
+}
+
 // walk into data with dataPath
 // return last data layer that wrapping the attrbute
 // attribute path:
@@ -175,18 +175,48 @@ func (db *mongoDb) DeleteTable(name string) error {
 }
 
 func (db *mongoDb) Get(queryArgs map[string]interface{}) ([]map[string]interface{}, error) {
+	table, filter, err := db.queryFilter(queryArgs)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := table.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	result := []map[string]interface{}{}
+	err = cursor.All(context.TODO(), &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// count records match queryArgs on server side without loading them
+func (db *mongoDb) Count(queryArgs map[string]interface{}) (int, error) {
+	table, filter, err := db.queryFilter(queryArgs)
+	if err != nil {
+		return 0, err
+	}
+	count, err := table.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (db *mongoDb) queryFilter(queryArgs map[string]interface{}) (*mongo.Collection, bson.M, error) {
 	database := db.client.Database(db.config.Mongodb.Database)
 	tableName, ok := queryArgs[DbIface.Table].(string)
 	if !ok {
-		return nil, fmt.Errorf("missing parameter [%s] from queryArgs", DbIface.Table)
+		return nil, nil, fmt.Errorf("missing parameter [%s] from queryArgs", DbIface.Table)
 	}
 	table := database.Collection(tableName)
 	if table == nil {
-		return nil, fmt.Errorf("table [%s] does not exists", tableName)
+		return nil, nil, fmt.Errorf("table [%s] does not exists", tableName)
 	}
 	dataType, ok := queryArgs[Record.DataType].(string)
 	if !ok {
-		return nil, fmt.Errorf("missing parameter [%s] from queryArgs", Record.DataType)
+		return nil, nil, fmt.Errorf("missing parameter [%s] from queryArgs", Record.DataType)
 	}
 
 	filter := bson.M{
@@ -205,16 +235,7 @@ func (db *mongoDb) Get(queryArgs map[string]interface{}) ([]map[string]interface
 			},
 		}
 	}
-	cursor, err := table.Find(context.TODO(), filter)
-	if err != nil {
-		return nil, err
-	}
-	result := []map[string]interface{}{}
-	err = cursor.All(context.TODO(), &result)
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
+	return table, filter, nil
 }
 
 func (db *mongoDb) Create(tableName string, data interface{}) error {
@@ -93,6 +93,10 @@ func (tbl *Table) Get(id string) (map[string]interface{}, error) {
 	return nil, nil
 }
 
+func (tbl *Table) GetRaw(id string) ([]byte, error) {
+	return FileRecord.ReadRaw(tbl.FullPath, id)
+}
+
 func (tbl *Table) Put(id string, payload map[string]interface{}) error {
 	return FileRecord.Put(tbl.FullPath, id, payload)
 }
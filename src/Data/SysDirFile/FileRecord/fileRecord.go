@@ -103,6 +103,18 @@ func New(dirPath string, fileName string) (*Record, error) {
 	return &record, nil
 }
 
+// bytes of the file as it is on disk, nil when file does not exist
+func ReadRaw(dirPath string, fileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dirPath, fileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
 func Put(dirPath string, fileName string, data map[string]interface{}) error {
 	filePath := filepath.Join(dirPath, fileName)
 	file, err := json.MarshalIndent(data, "", " ")
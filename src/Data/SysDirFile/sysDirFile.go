@@ -134,6 +134,26 @@ func (db *Database) Get(queryArgs map[string]interface{}) ([]map[string]interfac
 	return result, nil
 }
 
+func (db *Database) GetRaw(queryArgs map[string]interface{}) ([]byte, error) {
+	tableName, ok := queryArgs[DbIface.Table].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing field [%s] from queryArgs", DbIface.Table)
+	}
+	dataId, ok := queryArgs[Record.DataId].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing field [%s] from queryArgs", Record.DataId)
+	}
+	table, err := db.GetTable(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table, Err: %s", err)
+	}
+	data, err := table.GetRaw(dataId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data [%s] from table [%s], Err:%s", dataId, tableName, err)
+	}
+	return data, nil
+}
+
 func (db *Database) refresh() []error {
 	errList := []error{}
 	for name, tbl := range db.tables {
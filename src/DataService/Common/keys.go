@@ -27,5 +27,63 @@ This copyright notice and license applies to all files in this directory or sub-
 package Common
 
 const (
-	KeyJournal = "journal"
+	DefaultActorHeader  = "X-Actor"
+	DefaultAnonymous    = "anonymous"
+	DefaultTenantHeader = "X-Tenant"
+	FormatCsv           = "csv"
+	FormatJsonApi       = "jsonapi"
+	HeaderAccept        = "Accept"
+	HeaderETag          = "ETag"
+	HeaderIfField       = "If-Field"
+	HeaderIfMatch       = "If-Match"
+	HeaderNextOffset    = "X-Next-Offset"
+	HeaderRefCheck      = "X-Ref-Check"
+	HeaderSnapshot      = "X-Snapshot"
+	HeaderTruncated     = "X-Truncated"
+	HeaderWarning       = "Warning"
+	KeyBatch            = "batch"
+	KeyBatchAlias       = "_batch"
+	KeyGet              = "get"
+	KeyHistory          = "_history"
+	KeyId               = "id"
+	KeyNewId            = "newId"
+	KeyNewType          = "newType"
+	KeyJournal          = "journal"
+	KeyRename           = "_rename"
+	KeyRetype           = "_retype"
+	KeyImport           = "_import"
+	KeyOpenApi          = "openapi.json"
+	KeyOpenApiAlias     = "_openapi"
+	KeyPath             = "_path"
+	KeySchemaErrors     = "_schemaErrors"
+	KeySummary          = "_summary"
+	KeyTouch            = "_touch"
+	KeyType             = "type"
+	KeyWatch            = "_watch"
+	QueryAtomic         = "atomic"
+	QueryCoerce         = "coerce"
+	QueryDescribe       = "describe"
+	QueryDryRun         = "dryRun"
+	QueryEffective      = "effective"
+	QueryExpand         = "expand"
+	QueryFields         = "fields"
+	QueryForce          = "force"
+	QueryFilter         = "filter"
+	QueryFormat         = "format"
+	QueryLimit          = "limit"
+	QueryNormalized     = "normalized"
+	QueryOffset         = "offset"
+	QueryVersion        = "version"
+	QueryVersions       = "versions"
+	QueryPath           = "path"
+	QueryRaw            = "raw"
+	QuerySnapshot       = "snapshot"
+	QueryWalk           = "walk"
+	ReadLenient         = "lenient"
+	ReadStrict          = "strict"
+	RefNone             = "none"
+	RefResolve          = "resolve"
+	RefStructure        = "structure"
+	QueryWorkers        = "workers"
+	QueryStopOnError    = "stopOnError"
 )
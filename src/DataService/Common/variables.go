@@ -34,6 +34,7 @@ import (
 
 var InternalTypes = map[string]interface{}{
 	KeyJournal:                true,
+	KeyHistory:                true,
 	CmtIndex.KeyCmtIdx:        true,
 	CmtIndex.KeyCmtSubscriber: true,
 	JsonKey.Schema:            true,
@@ -47,6 +47,18 @@ type Confuguration struct {
 	DataTable DataTableConfig         `json:"table"`
 	Http      Http.Config             `json:"http"`
 	Inv       InvConfig               `json:"inventory"`
+	Import    ImportConfig            `json:"import"`
+	Audit     AuditConfig             `json:"audit"`
+	Schema    SchemaConfig            `json:"schema"`
+	List      ListConfig              `json:"list"`
+	Replica   ReplicaConfig           `json:"replica"`
+	Expire    ExpireConfig            `json:"expire"`
+	Tenant    TenantConfig            `json:"tenant"`
+	Watch     WatchConfig             `json:"watch"`
+	Views     map[string]ViewConfig   `json:"views"`
+	History   HistoryConfig           `json:"history"`
+	Delete    DeleteConfig            `json:"delete"`
+	Batch     BatchConfig             `json:"batch"`
 }
 
 type DataTableConfig struct {
@@ -62,6 +74,134 @@ type InvConfig struct {
 	Url string `json:"url"`
 }
 
+type ImportConfig struct {
+	Workers int `json:"workers"`
+}
+
+// where to find who made the change, and what to record when nobody is identified
+type AuditConfig struct {
+	ActorHeader string `json:"actorHeader"`
+	Anonymous   string `json:"anonymous"`
+}
+
+// how records are checked on read, [lenient] or [strict]. records are not checked on read when empty.
+// how ref values are checked on write, [resolve] by default, [structure] when target record may not exist yet or [none].
+// X-Ref-Check header of a write request overrides it
+type SchemaConfig struct {
+	ReadPolicy string             `json:"readPolicy"`
+	RefCheck   string             `json:"refCheck"`
+	RefUrl     SchemaRefUrlConfig `json:"refUrl"`
+}
+
+// external $ref to url is only fetched when url starts with one of Allow prefixes, off when Allow is empty.
+// Timeout is seconds of each fetch, default 10. MaxSize is bytes of a fetched document, default 1MiB.
+// fetched document is kept CacheTtl seconds, default 300, and at most CacheSize documents, default 100
+type SchemaRefUrlConfig struct {
+	Allow     []string `json:"allow"`
+	Timeout   int      `json:"timeout"`
+	MaxSize   int64    `json:"maxSize"`
+	CacheTtl  int      `json:"cacheTtl"`
+	CacheSize int      `json:"cacheSize"`
+}
+
+// cap of items in one batch write or batch get request, default 1000
+type BatchConfig struct {
+	MaxSize int `json:"maxSize"`
+}
+
+// cap of ids returned by one list request, no cap when 0.
+// SnapshotTtl is seconds a snapshot of truncated list is kept for paging, default 300
+type ListConfig struct {
+	MaxListSize int `json:"maxListSize"`
+	SnapshotTtl int `json:"snapshotTtl"`
+}
+
+// read replicas of database, reads fail over to them when primary is unhealthy.
+// HealthCheck is seconds between backend health checks
+type ReplicaConfig struct {
+	Databases   []DbConfig.DatabaseConfig `json:"databases"`
+	HealthCheck int                       `json:"healthCheck"`
+}
+
+// seconds between sweeps purging expired records, no sweep when 0
+type ExpireConfig struct {
+	SweepInterval int `json:"sweepInterval"`
+}
+
+// header that identifies tenant of a request, and schema overlays of each tenant by data type
+type TenantConfig struct {
+	Header   string                                       `json:"header"`
+	Overlays map[string]map[string]map[string]interface{} `json:"overlays"`
+}
+
+// events buffered for each watch, watch that falls behind further is dropped
+type WatchConfig struct {
+	Buffer int `json:"buffer"`
+}
+
+// prior versions kept for each record, one state for each version the record moved away from. off when Depth is 0
+type HistoryConfig struct {
+	Depth int `json:"depth"`
+}
+
+// with CheckRefs, record that other records ref is not deleted unless forced
+type DeleteConfig struct {
+	CheckRefs bool `json:"checkRefs"`
+}
+
+// read-only view of base type, records that match filter rule like [status == "active"],
+// data projected to fields when fields are given
+type ViewConfig struct {
+	Type   string   `json:"type"`
+	Filter string   `json:"filter"`
+	Fields []string `json:"fields"`
+}
+
+// environment variables that override value read from config file when they are set and not empty,
+// flags of the server override them in turn
+func envOverrides(config *Confuguration) map[string]*string {
+	return map[string]*string{
+		"UNITAO_PORT":                  &config.Http.Port,
+		"UNITAO_HTTP_TYPE":             &config.Http.HttpType,
+		"UNITAO_HTTP_DNS":              &config.Http.DnsName,
+		"UNITAO_HTTP_CERT_FILE":        &config.Http.CertFile,
+		"UNITAO_HTTP_KEY_FILE":         &config.Http.KeyFile,
+		"UNITAO_DATA_TABLE":            &config.DataTable.Data,
+		"UNITAO_INVENTORY_URL":         &config.Inv.Url,
+		"UNITAO_DB_TYPE":               &config.Database.DbType,
+		"UNITAO_DYNAMODB_REGION":       &config.Database.Dynamodb.Region,
+		"UNITAO_DYNAMODB_ENDPOINT":     &config.Database.Dynamodb.EndPoint,
+		"UNITAO_DYNAMODB_ACCESS_KEY":   &config.Database.Dynamodb.AccessKey,
+		"UNITAO_DYNAMODB_SECRET_KEY":   &config.Database.Dynamodb.SecretKey,
+		"UNITAO_DYNAMODB_ACCESS_TOKEN": &config.Database.Dynamodb.AccessToken,
+		"UNITAO_MONGODB_ENDPOINT":      &config.Database.Mongodb.EndPoint,
+		"UNITAO_MONGODB_DATABASE":      &config.Database.Mongodb.Database,
+		"UNITAO_MONGODB_USER":          &config.Database.Mongodb.UserName,
+		"UNITAO_MONGODB_PASSWORD":      &config.Database.Mongodb.Password,
+		"UNITAO_SYSDIRFILE_PATH":       &config.Database.SysDirFile.Path,
+	}
+}
+
+// apply environment variables of envOverrides on config, unset ones leave config as it is
+func ApplyEnv(config *Confuguration) {
+	for name, field := range envOverrides(config) {
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			*field = value
+		}
+	}
+}
+
+// port to listen on by precedence of flag, config after env overrides, then default
+func (c *Confuguration) ListenPort(flagPort string, defaultPort string) string {
+	if flagPort != "" {
+		return flagPort
+	}
+	if c.Http.Port != "" {
+		return c.Http.Port
+	}
+	return defaultPort
+}
+
 func Read(configPath string, config *Confuguration) error {
 	jsonFile, err := os.Open(configPath)
 	if err != nil {
@@ -71,6 +211,7 @@ func Read(configPath string, config *Confuguration) error {
 	defer jsonFile.Close()
 	byteValue, _ := ioutil.ReadAll(jsonFile)
 	json.Unmarshal([]byte(byteValue), config)
+	ApplyEnv(config)
 	if config.DataTable.Data == "" {
 		return fmt.Errorf("missing field data in Config.DataTable")
 	}
@@ -0,0 +1,223 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"Data/DbIface"
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const DefaultBatchMaxSize = 1000
+
+// outcome of one record in batch, Status 201 when added, 200 when replaced
+type BatchResult struct {
+	Index    int             `json:"index"`
+	DataType string          `json:"dataType,omitempty"`
+	DataId   string          `json:"dataId,omitempty"`
+	Status   int             `json:"status"`
+	Error    *Http.HttpError `json:"error,omitempty"`
+}
+
+// write each record of list with Add when new or Set when it exists, headers apply to every write.
+// with atomic, batch is written in one transaction of DB, 501 when DB can not. item that is not record fails
+// whole batch before any write. batch stops at first failed write and none of it is kept, results of records
+// written and not tried are 424. changes of atomic batch are journaled only after it commits
+func (h *Handler) Batch(recordList []interface{}, headers map[string]interface{}, atomic bool) ([]BatchResult, *Http.HttpError) {
+	err := h.checkBatchSize(len(recordList))
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := h.DB.(DbIface.Transactor)
+	if atomic && !ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("atomic batch is not supported, database [%s] has no transaction", h.DB.Name()), http.StatusNotImplemented)
+	}
+	records := make([]*Record.Record, len(recordList))
+	loadErrs := make([]*Http.HttpError, len(recordList))
+	for idx, item := range recordList {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			loadErrs[idx] = Http.NewHttpError(fmt.Sprintf("batch item @[%d] is not JSON object", idx), http.StatusBadRequest)
+			continue
+		}
+		record, ex := Record.LoadMap(data)
+		if ex != nil {
+			loadErrs[idx] = Http.WrapError(ex, fmt.Sprintf("failed to load batch item @[%d] as Record", idx), http.StatusBadRequest)
+			continue
+		}
+		if atomic && record.Type == JsonKey.Schema {
+			return nil, Http.NewHttpError(fmt.Sprintf("batch item @[%d] is schema [%s], atomic batch can not write schema in transaction", idx, record.Id), http.StatusNotImplemented)
+		}
+		records[idx] = record
+	}
+	for _, err := range loadErrs {
+		if err != nil && atomic {
+			return nil, err
+		}
+	}
+	if !atomic {
+		results := make([]BatchResult, 0, len(recordList))
+		for idx, record := range records {
+			results = append(results, h.batchWrite(idx, record, loadErrs[idx], headers, h.AddJournal))
+		}
+		return results, nil
+	}
+	results := make([]BatchResult, 0, len(recordList))
+	changes := []batchChange{}
+	buffer := func(dataType string, dataId string, before map[string]interface{}, after map[string]interface{}) *Http.HttpError {
+		changes = append(changes, batchChange{dataType, dataId, before, after})
+		return nil
+	}
+	ex := tx.Transaction(func() error {
+		for idx, record := range records {
+			result := h.batchWrite(idx, record, nil, headers, buffer)
+			results = append(results, result)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+	if ex == nil {
+		if h.AddJournal != nil {
+			for _, change := range changes {
+				h.AddJournal(change.dataType, change.dataId, change.before, change.after)
+			}
+		}
+		return results, nil
+	}
+	if len(results) == 0 || results[len(results)-1].Error == nil {
+		return nil, Http.WrapError(ex, "failed to commit atomic batch", http.StatusInternalServerError)
+	}
+	failed := results[len(results)-1]
+	for idx := range results[:len(results)-1] {
+		results[idx].Status = http.StatusFailedDependency
+		results[idx].Error = Http.NewHttpError(fmt.Sprintf("not kept, batch failed @[%d]", failed.Index), http.StatusFailedDependency)
+	}
+	for idx := failed.Index + 1; idx < len(recordList); idx++ {
+		results = append(results, BatchResult{
+			Index:  idx,
+			Status: http.StatusFailedDependency,
+			Error:  Http.NewHttpError(fmt.Sprintf("not written, batch failed @[%d]", failed.Index), http.StatusFailedDependency),
+		})
+	}
+	return results, nil
+}
+
+// change made by atomic batch, journaled once batch commits
+type batchChange struct {
+	dataType string
+	dataId   string
+	before   map[string]interface{}
+	after    map[string]interface{}
+}
+
+// result of writing item idx of batch, loadErr when item failed to load as record
+func (h *Handler) batchWrite(idx int, record *Record.Record, loadErr *Http.HttpError, headers map[string]interface{}, journal JournalAdd) BatchResult {
+	result := BatchResult{Index: idx}
+	err := loadErr
+	if err == nil {
+		result.DataType = record.Type
+		result.DataId = record.Id
+		result.Status, err = h.writeBatchRecord(record, headers, journal)
+	}
+	if err != nil {
+		result.Status = err.Status
+		result.Error = err
+	}
+	return result
+}
+
+// records of keys like {"type": "doc", "id": "doc01"} in order of keys, nil for key that has no record.
+// each distinct key is read once
+func (h *Handler) BatchGet(keyList []interface{}) ([]interface{}, *Http.HttpError) {
+	err := h.checkBatchSize(len(keyList))
+	if err != nil {
+		return nil, err
+	}
+	idKeys := make([]string, len(keyList))
+	for idx, item := range keyList {
+		key, _ := item.(map[string]interface{})
+		dataType, _ := key[Common.KeyType].(string)
+		dataId, _ := key[Common.KeyId].(string)
+		if dataType == "" || dataId == "" || strings.ContainsAny(dataId, "/"+PathCmd.CmdPrefix) {
+			return nil, Http.NewHttpError(fmt.Sprintf("invalid batch key @[%d], expect format={\"%s\": \"{dataType}\", \"%s\": \"{dataId}\"}", idx, Common.KeyType, Common.KeyId), http.StatusBadRequest)
+		}
+		idKeys[idx] = fmt.Sprintf("%s/%s", dataType, dataId)
+	}
+	found := map[string]interface{}{}
+	results := make([]interface{}, len(keyList))
+	for idx, idKey := range idKeys {
+		data, ok := found[idKey]
+		if !ok {
+			dataType, dataId := Util.ParsePath(idKey)
+			data, err = h.Get(dataType, Util.EscapeKey(dataId))
+			if err != nil {
+				if err.Status != http.StatusNotFound {
+					return nil, err
+				}
+				data = nil
+			}
+			found[idKey] = data
+		}
+		results[idx] = data
+	}
+	return results, nil
+}
+
+func (h *Handler) checkBatchSize(count int) *Http.HttpError {
+	maxSize := h.Config.Batch.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultBatchMaxSize
+	}
+	if count > maxSize {
+		return Http.NewHttpError(fmt.Sprintf("batch of [%d] items exceeds max size [%d]", count, maxSize), http.StatusBadRequest)
+	}
+	return nil
+}
+
+// Add record when it is new, Set when it exists, change recorded with journal. returns status of the write
+func (h *Handler) writeBatchRecord(record *Record.Record, headers map[string]interface{}, journal JournalAdd) (int, *Http.HttpError) {
+	record.ModifiedBy = h.Actor(headers)
+	record.Tenant = h.Tenant(headers)
+	before, err := h.LocalData(record.Type, record.Id)
+	if err != nil && err.Status != http.StatusNotFound {
+		return 0, err
+	}
+	if before == nil {
+		return http.StatusCreated, h.addWith(record, headers, journal)
+	}
+	return http.StatusOK, h.setIf(record.Type, record.Id, record, headers, journal)
+}
@@ -0,0 +1,103 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// records flattened into CSV rows, see SchemaDoc.FlattenRecord. header is __id then sorted columns of the schema
+// and of the records, so same schema and records always give same header
+func (h *Handler) FlatTable(dataType string, idList []interface{}) ([][]string, *Http.HttpError) {
+	schema, err := h.LocalSchema(dataType, "")
+	if err != nil {
+		return nil, err
+	}
+	columnMap := map[string]bool{}
+	for _, column := range schema.Schema.FlatColumns() {
+		columnMap[column] = true
+	}
+	flatList := make([]map[string]interface{}, 0, len(idList))
+	for _, id := range idList {
+		dataId := fmt.Sprintf("%v", id)
+		data, err := h.LocalData(dataType, dataId)
+		if err != nil {
+			return nil, err
+		}
+		record, ex := Record.LoadMap(data)
+		if ex != nil {
+			return nil, Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+		}
+		recordSchema, err := h.LocalSchema(record.Type, record.Version)
+		if err != nil {
+			return nil, err
+		}
+		flat, ex := recordSchema.Schema.FlattenRecord(record.Data)
+		if ex != nil {
+			return nil, Http.WrapError(ex, fmt.Sprintf("failed to flatten record [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+		}
+		for column := range flat {
+			columnMap[column] = true
+		}
+		flat[Record.DataId] = record.Id
+		flatList = append(flatList, flat)
+	}
+	columns := make([]string, 0, len(columnMap))
+	for column := range columnMap {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	header := append([]string{Record.DataId}, columns...)
+	rows := make([][]string, 0, len(flatList)+1)
+	rows = append(rows, header)
+	for _, flat := range flatList {
+		row := make([]string, 0, len(header))
+		for _, column := range header {
+			row = append(row, csvCell(flat[column]))
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func csvCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"Data/DbIface"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// [type/id] of other records that ref [dataType/dataId], sorted.
+// database that implements DbIface.RefFinder is asked, otherwise records of types whose schema has ref to dataType are scanned
+func (h *Handler) InboundRefs(dataType string, dataId string) ([]string, *Http.HttpError) {
+	self := fmt.Sprintf("%s/%s", dataType, dataId)
+	found := map[string]bool{}
+	if finder, ok := h.DB.(DbIface.RefFinder); ok {
+		refList, ex := finder.FindRefs(h.Config.DataTable.Data, dataType, dataId)
+		if ex != nil {
+			return nil, Http.WrapError(ex, fmt.Sprintf("failed to find refs of [%s] in database", self), http.StatusInternalServerError)
+		}
+		for _, ref := range refList {
+			found[ref] = true
+		}
+	} else {
+		refList, err := SchemaPath.FindRefs(h.Connection(), self)
+		if err != nil {
+			return nil, Http.WrapError(err, fmt.Sprintf("failed to find refs of [%s]", self), err.Status)
+		}
+		for _, ref := range refList {
+			found[fmt.Sprintf("%s/%s", ref.DataType, ref.DataId)] = true
+		}
+	}
+	// record that refs itself does not block its delete
+	delete(found, self)
+	result := make([]string, 0, len(found))
+	for ref := range found {
+		result = append(result, ref)
+	}
+	sort.Strings(result)
+	return result, nil
+}
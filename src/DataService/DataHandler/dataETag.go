@@ -0,0 +1,81 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// strong ETag of record, quoted hash of its canonical JSON as stored.
+// [__ttl] is computed on read, so it is left out to keep ETag of read and write the same
+func RecordETag(record *Record.Record) (string, *Http.HttpError) {
+	stored := *record
+	stored.Ttl = 0
+	hash, ex := stored.Hash()
+	if ex != nil {
+		return "", Http.WrapError(ex, fmt.Sprintf("failed to hash record [%s/%s]", record.Type, record.Id), http.StatusInternalServerError)
+	}
+	return fmt.Sprintf("\"%s\"", hash), nil
+}
+
+// ETag of record in data, as returned by Get
+func DataETag(data map[string]interface{}) (string, *Http.HttpError) {
+	record, ex := Record.LoadMap(data)
+	if ex != nil {
+		return "", Http.WrapError(ex, "failed to load data as record", http.StatusInternalServerError)
+	}
+	return RecordETag(record)
+}
+
+// with If-Match in headers, current record must have one of the listed ETags, * matches any existing record.
+// record is nil when it does not exist yet. weak ETag never matches
+func matchETag(record *Record.Record, headers map[string]interface{}) *Http.HttpError {
+	ifMatch, ok := headers[strings.ToLower(Common.HeaderIfMatch)].(string)
+	if !ok {
+		return nil
+	}
+	if record == nil {
+		return Http.NewHttpError(fmt.Sprintf("record does not exist, does not match [%s]=[%s]", Common.HeaderIfMatch, ifMatch), http.StatusPreconditionFailed)
+	}
+	etag, err := RecordETag(record)
+	if err != nil {
+		return err
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return nil
+		}
+	}
+	return Http.NewHttpError(fmt.Sprintf("record [%s/%s] has [%s]=[%s], does not match [%s]=[%s]", record.Type, record.Id, Common.HeaderETag, etag, Common.HeaderIfMatch, ifMatch), http.StatusPreconditionFailed)
+}
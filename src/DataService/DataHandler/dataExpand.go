@@ -0,0 +1,202 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const (
+	ExpandPathDiv    = "."
+	ExpandFieldStart = "("
+	ExpandFieldEnd   = ")"
+	ExpandFieldDiv   = ","
+)
+
+// get record with ref attributes on given paths replaced by the referenced records.
+// each path is attribute names joined by [.], like itemArray.refIdx
+// path can end with fields in brackets to project inlined data of target, like itemArray.refIdx(attr01,attr02)
+func (h *Handler) GetExpanded(dataType string, dataId string, expandList []string) (map[string]interface{}, *Http.HttpError) {
+	data, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	record, ex := Record.LoadMap(data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+	}
+	schema, err := h.LocalSchema(record.Type, record.Version)
+	if err != nil {
+		return nil, err
+	}
+	for _, expandPath := range expandList {
+		attrList, fields, err := ParseExpandPath(expandPath)
+		if err != nil {
+			return nil, err
+		}
+		err = h.expandAttr(schema.Schema, record.Data, attrList, fields, fmt.Sprintf("%s/%s", dataType, dataId))
+		if err != nil {
+			return nil, Http.WrapError(err, fmt.Sprintf("failed to expand path [%s]", expandPath), err.Status)
+		}
+	}
+	return record.Map(), nil
+}
+
+// split expand path into attribute list and projected fields, fields is nil when not given
+func ParseExpandPath(expandPath string) ([]string, []string, *Http.HttpError) {
+	refPath, fieldStr, hasFields := strings.Cut(expandPath, ExpandFieldStart)
+	var fields []string
+	if hasFields {
+		if !strings.HasSuffix(fieldStr, ExpandFieldEnd) {
+			return nil, nil, Http.NewHttpError(fmt.Sprintf("invalid expand path [%s], missing [%s] at the end", expandPath, ExpandFieldEnd), http.StatusBadRequest)
+		}
+		fieldStr = strings.TrimSuffix(fieldStr, ExpandFieldEnd)
+		fields = []string{}
+		for _, field := range strings.Split(fieldStr, ExpandFieldDiv) {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				return nil, nil, Http.NewHttpError(fmt.Sprintf("invalid expand path [%s], empty field", expandPath), http.StatusBadRequest)
+			}
+			fields = append(fields, field)
+		}
+	}
+	attrList := strings.Split(refPath, ExpandPathDiv)
+	for _, attr := range attrList {
+		if attr == "" {
+			return nil, nil, Http.NewHttpError(fmt.Sprintf("invalid expand path [%s], empty attr", expandPath), http.StatusBadRequest)
+		}
+	}
+	return attrList, fields, nil
+}
+
+func (h *Handler) expandAttr(doc *SchemaDoc.SchemaDoc, data map[string]interface{}, attrList []string, fields []string, dataPath string) *Http.HttpError {
+	doc = doc.DocOf(data)
+	attrName := attrList[0]
+	attrPath := fmt.Sprintf("%s/%s", dataPath, attrName)
+	attrDef, ok := doc.Properties()[attrName].(map[string]interface{})
+	if !ok {
+		return Http.NewHttpError(fmt.Sprintf("attr [%s] not defined. @path=[%s]", attrName, dataPath), http.StatusBadRequest)
+	}
+	value, ok := data[attrName]
+	if !ok || value == nil {
+		return nil
+	}
+	if len(attrList) == 1 {
+		ref, ok := doc.CmtRefs[attrName]
+		if !ok {
+			return Http.NewHttpError(fmt.Sprintf("attr [%s] is not a [%s] ref to expand. @path=[%s]", attrName, JsonKey.ContentMediaType, dataPath), http.StatusBadRequest)
+		}
+		switch attrDef[JsonKey.Type] {
+		case JsonKey.Array:
+			refList := value.([]interface{})
+			expanded := make([]interface{}, 0, len(refList))
+			for _, refId := range refList {
+				refData, err := h.expandRef(ref, refId.(string), fields, attrPath)
+				if err != nil {
+					return err
+				}
+				expanded = append(expanded, refData)
+			}
+			data[attrName] = expanded
+		case JsonKey.Object:
+			refMap := value.(map[string]interface{})
+			expanded := make(map[string]interface{}, len(refMap))
+			for key, refId := range refMap {
+				refData, err := h.expandRef(ref, refId.(string), fields, fmt.Sprintf("%s[%s]", attrPath, key))
+				if err != nil {
+					return err
+				}
+				expanded[key] = refData
+			}
+			data[attrName] = expanded
+		default:
+			refData, err := h.expandRef(ref, value.(string), fields, attrPath)
+			if err != nil {
+				return err
+			}
+			data[attrName] = refData
+		}
+		return nil
+	}
+	subDoc, ok := doc.SubDocs[attrName]
+	if !ok {
+		return Http.NewHttpError(fmt.Sprintf("attr [%s] has no sub document to walk in. @path=[%s]", attrName, dataPath), http.StatusBadRequest)
+	}
+	switch attrDef[JsonKey.Type] {
+	case JsonKey.Array:
+		for idx, item := range value.([]interface{}) {
+			err := h.expandAttr(subDoc, item.(map[string]interface{}), attrList[1:], fields, fmt.Sprintf("%s[%d]", attrPath, idx))
+			if err != nil {
+				return err
+			}
+		}
+	case JsonKey.Object:
+		if !SchemaDoc.IsMap(attrDef) {
+			return h.expandAttr(subDoc, value.(map[string]interface{}), attrList[1:], fields, attrPath)
+		}
+		for key, item := range value.(map[string]interface{}) {
+			err := h.expandAttr(subDoc, item.(map[string]interface{}), attrList[1:], fields, fmt.Sprintf("%s[%s]", attrPath, key))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// inline target record of ref, data of target is projected to fields when fields is not nil
+func (h *Handler) expandRef(ref *SchemaDoc.CMTDocRef, refId string, fields []string, dataPath string) (map[string]interface{}, *Http.HttpError) {
+	dataType, dataId, ex := ref.Target(refId)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("invalid ref @path=[%s]", dataPath), http.StatusBadRequest)
+	}
+	record, err := h.Inventory.Get(dataType, dataId)
+	if err != nil {
+		return nil, Http.WrapError(err, fmt.Sprintf("failed to get ref [%s/%s] @path=[%s]", dataType, dataId, dataPath), err.Status)
+	}
+	if fields != nil {
+		record.Data = projectData(record.Data, fields)
+	}
+	return record.Map(), nil
+}
+
+// data with only given fields, fields not in data are skipped
+func projectData(data map[string]interface{}, fields []string) map[string]interface{} {
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := data[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected
+}
@@ -0,0 +1,163 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// stamp [__expireAt] of record by ttl declared in its schema.
+// every write renews expiry, record without ttl never expires
+func (h *Handler) stampExpire(record *Record.Record) {
+	record.Ttl = 0
+	record.ExpireAt = ""
+	if _, ok := Common.InternalTypes[record.Type]; ok {
+		return
+	}
+	schema, err := h.LocalSchema(record.Type, "")
+	if err != nil {
+		// missing schema is reported by validation of the record
+		return
+	}
+	ttl := schema.Schema.RecordTtl(record.Data)
+	if ttl > 0 {
+		record.ExpireAt = time.Now().Add(time.Duration(ttl) * time.Second).UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// seconds left before record data expires, -1 when it does not expire
+func remainingTtl(data map[string]interface{}) int {
+	expireStr, ok := data[Record.ExpireAt].(string)
+	if !ok || expireStr == "" {
+		return -1
+	}
+	expireAt, err := time.Parse(time.RFC3339Nano, expireStr)
+	if err != nil {
+		return -1
+	}
+	left := time.Until(expireAt).Seconds()
+	if left <= 0 {
+		return 0
+	}
+	return int(math.Ceil(left))
+}
+
+func isExpired(data map[string]interface{}) bool {
+	return remainingTtl(data) == 0
+}
+
+// purge expired records of dataType, return ids purged
+func (h *Handler) Sweep(dataType string) ([]string, *Http.HttpError) {
+	if _, ok := Common.InternalTypes[dataType]; ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("sweep on type[%s] is not allowed", dataType), http.StatusBadRequest)
+	}
+	_, err := h.LocalSchema(dataType, "")
+	if err != nil {
+		return nil, err
+	}
+	recordList, err := h.QueryDb(dataType, "")
+	if err != nil {
+		return nil, err
+	}
+	purged := []string{}
+	for _, data := range recordList {
+		if !isExpired(data) {
+			continue
+		}
+		dataId := data[Record.DataId].(string)
+		removed, err := h.purgeExpired(dataType, dataId)
+		if err != nil {
+			return purged, err
+		}
+		if removed {
+			purged = append(purged, dataId)
+		}
+	}
+	return purged, nil
+}
+
+// purge expired records of all types
+func (h *Handler) SweepAll() *Http.HttpError {
+	typeList, err := h.List(JsonKey.Schema)
+	if err != nil {
+		return err
+	}
+	for _, dataType := range typeList {
+		if _, ok := Common.InternalTypes[dataType.(string)]; ok {
+			continue
+		}
+		purged, err := h.Sweep(dataType.(string))
+		if err != nil {
+			h.Log(fmt.Sprintf("failed to sweep type [%s], Error: %s", dataType, err))
+			continue
+		}
+		if len(purged) > 0 {
+			h.Log(fmt.Sprintf("swept [%d] expired records of type [%s]", len(purged), dataType))
+		}
+	}
+	return nil
+}
+
+// sweep all types every interval seconds until Close is called
+func (h *Handler) RunSweep(interval int) {
+	for {
+		select {
+		case <-h.stop:
+			h.Log("stop sweep")
+			return
+		case <-time.After(time.Duration(interval) * time.Second):
+			h.SweepAll()
+		}
+	}
+}
+
+// record may be renewed after it is listed, so check again under lock
+func (h *Handler) purgeExpired(dataType string, dataId string) (bool, *Http.HttpError) {
+	idKey := fmt.Sprintf("%s/%s", dataType, dataId)
+	h.Lock.Aquire(idKey, "HandlerSweep")
+	defer h.Lock.Release(idKey, "HandlerSweep")
+	recordList, err := h.QueryDb(dataType, dataId)
+	if err != nil {
+		return false, err
+	}
+	if len(recordList) == 0 || !isExpired(recordList[0]) {
+		return false, nil
+	}
+	err = h.removeData(dataType, dataId, recordList[0], h.AddJournal)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,226 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"Data/DbIface"
+)
+
+const DefaultHealthCheck = 10
+
+type backend struct {
+	db      DbIface.Database
+	healthy bool
+}
+
+// database with a primary and read replicas.
+// writes always go to primary and fail when primary is unhealthy.
+// reads go to primary when it is healthy, otherwise fail over to the first healthy replica.
+// health of backends is only changed by health check, a failed read tries next backend without marking it
+type FailoverDb struct {
+	primary  *backend
+	replicas []*backend
+	interval int
+	lock     sync.RWMutex
+	stop     chan interface{}
+	stopOnce sync.Once
+	log      *log.Logger
+}
+
+func NewFailoverDb(primary DbIface.Database, replicas []DbIface.Database, interval int, logger *log.Logger) *FailoverDb {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if interval <= 0 {
+		interval = DefaultHealthCheck
+	}
+	db := FailoverDb{
+		primary:  &backend{db: primary, healthy: true},
+		replicas: make([]*backend, 0, len(replicas)),
+		interval: interval,
+		stop:     make(chan interface{}),
+		log:      logger,
+	}
+	for _, replica := range replicas {
+		db.replicas = append(db.replicas, &backend{db: replica, healthy: true})
+	}
+	return &db
+}
+
+func (f *FailoverDb) Log(message string) {
+	f.log.Printf("FailoverDb: %s", message)
+}
+
+// check health of all backends every interval seconds until Stop is called
+func (f *FailoverDb) Run() {
+	for {
+		select {
+		case <-f.stop:
+			f.Log("stop health check")
+			return
+		case <-time.After(time.Duration(f.interval) * time.Second):
+			f.CheckHealth()
+		}
+	}
+}
+
+// stop health check, safe to call more than once
+func (f *FailoverDb) Stop() {
+	f.stopOnce.Do(func() { close(f.stop) })
+}
+
+// ping every backend and record whether it is healthy
+func (f *FailoverDb) CheckHealth() {
+	for idx, b := range append([]*backend{f.primary}, f.replicas...) {
+		_, err := b.db.ListTable()
+		healthy := err == nil
+		if healthy != f.isHealthy(b) {
+			f.Log(fmt.Sprintf("backend[%d] %s changed healthy=[%t]", idx, b.db.Name(), healthy))
+		}
+		f.setHealthy(b, healthy)
+	}
+}
+
+func (f *FailoverDb) PrimaryHealthy() bool {
+	return f.isHealthy(f.primary)
+}
+
+func (f *FailoverDb) isHealthy(b *backend) bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return b.healthy
+}
+
+func (f *FailoverDb) setHealthy(b *backend, healthy bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	b.healthy = healthy
+}
+
+// primary first, then replicas, only healthy ones
+func (f *FailoverDb) readBackends() []*backend {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	result := []*backend{}
+	for _, b := range append([]*backend{f.primary}, f.replicas...) {
+		if b.healthy {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+func (f *FailoverDb) read(call func(db DbIface.Database) error) error {
+	var lastErr error
+	for _, b := range f.readBackends() {
+		err := call(b.db)
+		if err == nil {
+			return nil
+		}
+		f.Log(fmt.Sprintf("read on %s failed, try next backend. Error: %s", b.db.Name(), err))
+		lastErr = err
+	}
+	if lastErr == nil {
+		return fmt.Errorf("no healthy backend to read from")
+	}
+	return lastErr
+}
+
+func (f *FailoverDb) write(call func(db DbIface.Database) error) error {
+	if !f.PrimaryHealthy() {
+		return fmt.Errorf("primary backend %s is down, write rejected", f.primary.db.Name())
+	}
+	return call(f.primary.db)
+}
+
+func (f *FailoverDb) Name() string {
+	return f.primary.db.Name()
+}
+
+func (f *FailoverDb) ListTable() ([]interface{}, error) {
+	var result []interface{}
+	err := f.read(func(db DbIface.Database) error {
+		tables, err := db.ListTable()
+		result = tables
+		return err
+	})
+	return result, err
+}
+
+func (f *FailoverDb) CreateTable(name string, data map[string]interface{}) error {
+	return f.write(func(db DbIface.Database) error {
+		return db.CreateTable(name, data)
+	})
+}
+
+func (f *FailoverDb) DeleteTable(name string) error {
+	return f.write(func(db DbIface.Database) error {
+		return db.DeleteTable(name)
+	})
+}
+
+func (f *FailoverDb) Get(queryArgs map[string]interface{}) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	err := f.read(func(db DbIface.Database) error {
+		records, err := db.Get(queryArgs)
+		result = records
+		return err
+	})
+	return result, err
+}
+
+func (f *FailoverDb) Create(table string, data interface{}) error {
+	return f.write(func(db DbIface.Database) error {
+		return db.Create(table, data)
+	})
+}
+
+func (f *FailoverDb) Update(table string, keys map[string]interface{}, data interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := f.write(func(db DbIface.Database) error {
+		updated, err := db.Update(table, keys, data)
+		result = updated
+		return err
+	})
+	return result, err
+}
+
+func (f *FailoverDb) Replace(table string, keys map[string]interface{}, data interface{}) error {
+	return f.write(func(db DbIface.Database) error {
+		return db.Replace(table, keys, data)
+	})
+}
+
+func (f *FailoverDb) Delete(table string, keys map[string]interface{}) error {
+	return f.write(func(db DbIface.Database) error {
+		return db.Delete(table, keys)
+	})
+}
@@ -0,0 +1,111 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// filter of list in format {attrPath}={value}, attr path is dotted to reach into object like spec.owner.
+// value is compared as text, like If-Field on PATCH
+type AttrFilter struct {
+	Attrs []string
+	Value string
+}
+
+func ParseFilters(filters []string) ([]AttrFilter, *Http.HttpError) {
+	result := make([]AttrFilter, 0, len(filters))
+	for _, filter := range filters {
+		attrPath, value, ok := strings.Cut(filter, "=")
+		if !ok || attrPath == "" {
+			return nil, Http.NewHttpError(fmt.Sprintf("invalid filter [%s], expect format=[{attrPath}={value}]", filter), http.StatusBadRequest)
+		}
+		attrs := strings.Split(attrPath, ".")
+		for _, attr := range attrs {
+			if attr == "" {
+				return nil, Http.NewHttpError(fmt.Sprintf("invalid attr path [%s] of filter [%s]", attrPath, filter), http.StatusBadRequest)
+			}
+		}
+		result = append(result, AttrFilter{Attrs: attrs, Value: value})
+	}
+	return result, nil
+}
+
+// data matches when value at attr path is the filter value, missing attr does not match
+func (f AttrFilter) Match(data map[string]interface{}) bool {
+	var value interface{} = data
+	for _, attr := range f.Attrs {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		value = obj[attr]
+	}
+	return value != nil && fmt.Sprint(value) == f.Value
+}
+
+// ids of type whose data matches all filters
+func (h *Handler) ListWhere(dataType string, filters []string) ([]interface{}, *Http.HttpError) {
+	attrFilters, err := ParseFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	if dataType == "" || dataType == JsonKey.Schema {
+		return nil, Http.NewHttpError(fmt.Sprintf("filter is not supported on list of [%s]", JsonKey.Schema), http.StatusBadRequest)
+	}
+	_, err = h.LocalData(JsonKey.Schema, dataType)
+	if err != nil {
+		return nil, Http.WrapError(err, fmt.Sprintf("object of type “%s” does not exist", dataType), err.Status)
+	}
+	recordList, err := h.QueryDb(dataType, "")
+	if err != nil {
+		return nil, err
+	}
+	result := []interface{}{}
+	for _, record := range recordList {
+		if record[Record.DataId] == Record.KeyRecord || isExpired(record) {
+			continue
+		}
+		data, _ := record[Record.Data].(map[string]interface{})
+		matched := true
+		for _, filter := range attrFilters {
+			if !filter.Match(data) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result = append(result, record[Record.DataId].(string))
+		}
+	}
+	return result, nil
+}
@@ -26,12 +26,15 @@ This copyright notice and license applies to all files in this directory or sub-
 package DataHandler
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"path"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"Data/DbConfig"
 	"Data/DbIface"
@@ -56,13 +59,32 @@ import (
 type JournalAdd func(dataType string, dataId string, before map[string]interface{}, after map[string]interface{}) *Http.HttpError
 
 type Handler struct {
-	DB         DbIface.Database
+	DB DbIface.Database
+	// health check over replicas, nil without replica, stopped by Close
+	Failover   *FailoverDb
 	schemaMap  map[string]*Schema.SchemaOps
-	Config     Config.Confuguration
-	Lock       *HashLock.HashLock
-	Inventory  *DataServiceProxy
-	AddJournal JournalAdd
-	log        *log.Logger
+	schemaLock sync.RWMutex
+	// dataType -> error of schema that failed to load, guarded by schemaLock
+	failedSchemas map[string]string
+	Config        Config.Confuguration
+	Lock          *HashLock.HashLock
+	Inventory     *DataServiceProxy
+	AddJournal    JournalAdd
+	Watches       *WatchHub
+	// fetch schema document of external $ref, default to local schema store
+	FetchSchema Schema.SchemaFetcher
+	fetchUrl    Schema.SchemaFetcher
+	log         *log.Logger
+	// tenant -> dataType -> schema overlay
+	overlays    map[string]map[string]map[string]interface{}
+	overlayLock sync.RWMutex
+	views       map[string]*View
+	// snapshot token -> ids captured by first page of truncated list
+	snapshots    map[string]*listSnapshot
+	snapshotLock sync.Mutex
+	// closed by Close to stop background sweep
+	stop     chan interface{}
+	stopOnce sync.Once
 }
 
 func New(config Config.Confuguration, logger *log.Logger, connectDb func(db DbConfig.DatabaseConfig, logger *log.Logger) (DbIface.Database, error)) (*Handler, *Http.HttpError) {
@@ -73,14 +95,47 @@ func New(config Config.Confuguration, logger *log.Logger, connectDb func(db DbCo
 	if err != nil {
 		return nil, Http.WrapError(err, "failed to connect to Database", http.StatusInternalServerError)
 	}
+	var failover *FailoverDb
+	if len(config.Replica.Databases) > 0 {
+		replicas := make([]DbIface.Database, 0, len(config.Replica.Databases))
+		for idx, replicaConfig := range config.Replica.Databases {
+			replica, err := connectDb(replicaConfig, logger)
+			if err != nil {
+				return nil, Http.WrapError(err, fmt.Sprintf("failed to connect to replica Database[%d]", idx), http.StatusInternalServerError)
+			}
+			replicas = append(replicas, replica)
+		}
+		failover = NewFailoverDb(db, replicas, config.Replica.HealthCheck, logger)
+		go failover.Run()
+		db = failover
+	}
 	handler := Handler{
-		schemaMap: make(map[string]*Schema.SchemaOps),
-		DB:        db,
-		Config:    config,
-		Lock:      HashLock.NewHashLock(logger),
-		log:       logger,
+		Failover:      failover,
+		schemaMap:     make(map[string]*Schema.SchemaOps),
+		failedSchemas: map[string]string{},
+		DB:            db,
+		Config:        config,
+		Lock:          HashLock.NewHashLock(logger),
+		Watches:       NewWatchHub(config.Watch.Buffer),
+		log:           logger,
+		snapshots:     make(map[string]*listSnapshot),
+		stop:          make(chan interface{}),
 	}
 	handler.Inventory = CreateDsProxy(&handler)
+	handler.FetchSchema = handler.fetchSchema
+	handler.fetchUrl = handler.urlFetcher()
+	handler.CheckSchemas()
+	httpErr := handler.loadOverlays()
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	httpErr = handler.loadViews()
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	if config.Expire.SweepInterval > 0 {
+		go handler.RunSweep(config.Expire.SweepInterval)
+	}
 	return &handler, nil
 }
 
@@ -88,6 +143,23 @@ func (h *Handler) Log(message string) {
 	h.log.Printf("Handler: %s", message)
 }
 
+// stop background work of handler, safe to call more than once
+func (h *Handler) Close() {
+	h.stopOnce.Do(func() { close(h.stop) })
+	if h.Failover != nil {
+		h.Failover.Stop()
+	}
+}
+
+// trivial check that backend database responds, 503 when it does not
+func (h *Handler) Ping() *Http.HttpError {
+	_, err := h.DB.ListTable()
+	if err != nil {
+		return Http.WrapError(err, fmt.Sprintf("database %s does not respond", h.DB.Name()), http.StatusServiceUnavailable)
+	}
+	return nil
+}
+
 func (h *Handler) QueryDb(dataType string, dataId string) ([]map[string]interface{}, *Http.HttpError) {
 	args := make(map[string]interface{})
 	args[DbIface.Table] = h.Config.DataTable.Data
@@ -119,14 +191,55 @@ func (h *Handler) List(dataType string) ([]interface{}, *Http.HttpError) {
 	result := make([]interface{}, 0, len(recordList))
 	for _, record := range recordList {
 		// not record schema is only for schema.
-		if record[Record.DataId] != Record.KeyRecord {
+		if record[Record.DataId] != Record.KeyRecord && !isExpired(record) {
 			result = append(result, record[Record.DataId].(string))
 		}
 	}
 	return result, nil
 }
 
+// list ids of type from offset, capped by config MaxListSize.
+// return offset of next page when list is truncated, otherwise 0
+func (h *Handler) ListPage(dataType string, offset int) ([]interface{}, int, *Http.HttpError) {
+	if offset < 0 {
+		return nil, 0, Http.NewHttpError(fmt.Sprintf("invalid offset=[%d], expect non-negative integer", offset), http.StatusBadRequest)
+	}
+	idList, err := h.List(dataType)
+	if err != nil {
+		return nil, 0, err
+	}
+	maxSize := h.Config.List.MaxListSize
+	if maxSize <= 0 && offset == 0 {
+		return idList, 0, nil
+	}
+	// ids are sorted so offset is stable between requests
+	sort.Slice(idList, func(i, j int) bool {
+		return idList[i].(string) < idList[j].(string)
+	})
+	page, next := pageIds(idList, offset, maxSize)
+	return page, next, nil
+}
+
+// ids of sorted idList from offset, capped by maxSize when it is positive.
+// return offset of next page when page is truncated, otherwise 0
+func pageIds(idList []interface{}, offset int, maxSize int) ([]interface{}, int) {
+	if offset >= len(idList) {
+		return []interface{}{}, 0
+	}
+	idList = idList[offset:]
+	if maxSize <= 0 || len(idList) <= maxSize {
+		return idList, 0
+	}
+	return idList[:maxSize], offset + maxSize
+}
+
 func (h *Handler) Get(dataType string, idPath string) (interface{}, *Http.HttpError) {
+	return h.GetContext(context.Background(), dataType, idPath)
+}
+
+// Get with walk of path bound to ctx, walk stops before next fetch once ctx is done.
+// record id in idPath is encoded as path key, see Util.EscapeKey
+func (h *Handler) GetContext(ctx context.Context, dataType string, idPath string) (interface{}, *Http.HttpError) {
 	if dataType == JsonKey.Schema {
 		id, version, ex := SchemaDoc.ParseDataType(idPath)
 		if ex != nil {
@@ -139,7 +252,11 @@ func (h *Handler) Get(dataType string, idPath string) (interface{}, *Http.HttpEr
 		return schema.Record.Map(), nil
 	}
 	dataId, nextPath := Util.ParsePath(idPath)
-	isLocal, err := h.Inventory.IsLocal(dataType, dataId)
+	recordId, ex := Util.UnescapeKey(dataId)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to unescape record id of path [%s]", idPath), http.StatusBadRequest)
+	}
+	isLocal, err := h.Inventory.IsLocal(dataType, recordId)
 	if err != nil {
 		return nil, err
 	}
@@ -147,20 +264,137 @@ func (h *Handler) Get(dataType string, idPath string) (interface{}, *Http.HttpEr
 		return nil, Http.NewHttpError(fmt.Sprintf("data type [%s/%s] is not start from this DataService", dataType, idPath), http.StatusNotFound)
 	}
 	if nextPath == "" && !strings.Contains(dataId, PathCmd.CmdPrefix) {
-		return h.LocalData(dataType, dataId)
+		record, err := h.localRecord(dataType, recordId)
+		if err != nil {
+			return nil, err
+		}
+		return record.Map(), nil
 	}
-	return h.GetDataByPath(dataType, dataId, nextPath)
+	return h.GetDataByPathContext(ctx, dataType, dataId, nextPath)
 }
 
-func (h *Handler) GetDataByPath(dataType string, idPath string, nextPath string) (interface{}, *Http.HttpError) {
-	conn := SchemaPathData.Connection{
-		FuncRecord: h.Inventory.Get,
+// SchemaPath connection that walks local data and follows refs into other DataServices through inventory
+func (h *Handler) Connection() *SchemaPathData.Connection {
+	return &SchemaPathData.Connection{
+		FuncRecord: h.GetRecord,
+		FuncSchema: h.GetSchema,
+		FuncList:   h.Inventory.List,
+		FuncPut:    h.Inventory.Put,
+	}
+}
+
+// result type of path query on dataType, worked out from schemas without reading any record
+func (h *Handler) DescribePath(dataType string, dataPath string) (map[string]interface{}, *Http.HttpError) {
+	return SchemaPath.DescribePath(h.Connection(), dataType, dataPath)
+}
+
+func (h *Handler) GetRecord(dataType string, dataId string) (*Record.Record, *Http.HttpError) {
+	return h.Inventory.Get(dataType, dataId)
+}
+
+func (h *Handler) GetSchema(dataType string) (*SchemaDoc.SchemaDoc, *Http.HttpError) {
+	schemaId, schemaVer, ex := SchemaDoc.ParseDataType(dataType)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to parse schema type[%s]", dataType), http.StatusBadRequest)
+	}
+	isLocal, err := h.Inventory.IsLocal(JsonKey.Schema, schemaId)
+	if err != nil {
+		return nil, err
+	}
+	if isLocal {
+		schema, err := h.LocalSchema(schemaId, schemaVer)
+		if err != nil {
+			return nil, err
+		}
+		return schema.Schema, nil
+	}
+	record, err := h.Inventory.Get(JsonKey.Schema, dataType)
+	if err != nil {
+		return nil, err
+	}
+	schema, ex := SchemaDoc.New(record.Data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to create SchemaDoc of [%s]", dataType), http.StatusInternalServerError)
+	}
+	return schema, nil
+}
+
+func (h *Handler) localRecord(dataType string, dataId string) (*Record.Record, *Http.HttpError) {
+	if dataType == JsonKey.Schema {
+		schemaId, schemaVer := Util.ParsePath(dataId)
+		schema, err := h.LocalSchema(schemaId, schemaVer)
+		if err != nil {
+			h.Log(fmt.Sprintf("failed to get local schema [%s/%s]", schemaId, schemaVer))
+			return nil, err
+		}
+		return schema.Record, nil
+	}
+	data, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		h.Log(fmt.Sprintf("local GET failed. [%s/%s]", dataType, dataId))
+		h.Log(err.Error())
+		return nil, err
+	}
+	record, ex := Record.LoadMap(data)
+	if ex != nil {
+		h.Log(fmt.Sprintf("local data load as record failed. [%s/%s]", dataType, dataId))
+		h.Log(ex.Error())
+		return nil, Http.WrapError(ex, "failed to load data as Record", http.StatusInternalServerError)
+	}
+	err = h.ValidateRead(record)
+	if err != nil {
+		return nil, err
+	}
+	if ttl := remainingTtl(data); ttl > 0 {
+		record.Ttl = ttl
+	}
+	return record, nil
+}
+
+// check record on read according to [readPolicy], no check when policy is not set.
+// lenient: record is checked by schema of its own [__ver], so constraints added in later schema versions do not fail the read.
+// strict: record is checked by current schema.
+// writes always validate against current schema regardless of the policy.
+func (h *Handler) ValidateRead(record *Record.Record) *Http.HttpError {
+	if _, ok := Common.InternalTypes[record.Type]; ok {
+		return nil
+	}
+	var version string
+	switch h.Config.Schema.ReadPolicy {
+	case Common.ReadLenient:
+		version = record.Version
+	case Common.ReadStrict:
+		version = ""
+	default:
+		return nil
+	}
+	schema, err := h.LocalSchema(record.Type, version)
+	if err != nil {
+		if err.Status == http.StatusNotFound && version != "" {
+			h.Log(fmt.Sprintf("schema [%s] version [%s] no longer available, skip read validation of [%s]", record.Type, version, record.Id))
+			return nil
+		}
+		return err
 	}
+	ex := schema.ValidateRecord(record)
+	if ex != nil {
+		errMsg := fmt.Sprintf("record [%s/%s] of version [%s] failed validation with schema version [%s]", record.Type, record.Id, record.Version, schema.Schema.Version)
+		h.Log(errMsg)
+		return Http.WrapError(ex, errMsg, http.StatusUnprocessableEntity)
+	}
+	return nil
+}
+
+func (h *Handler) GetDataByPath(dataType string, idPath string, nextPath string) (interface{}, *Http.HttpError) {
+	return h.GetDataByPathContext(context.Background(), dataType, idPath, nextPath)
+}
+
+func (h *Handler) GetDataByPathContext(ctx context.Context, dataType string, idPath string, nextPath string) (interface{}, *Http.HttpError) {
 	dataPath := idPath
 	if nextPath != "" {
 		dataPath = fmt.Sprintf("%s/%s", idPath, nextPath)
 	}
-	query, err := SchemaPath.CreateQuery(&conn, dataType, dataPath)
+	query, err := SchemaPath.CreateQueryContext(ctx, h.Connection(), dataType, dataPath)
 	if err != nil {
 		return nil, err
 	}
@@ -185,11 +419,20 @@ func (h *Handler) LocalData(dataType string, dataId string) (map[string]interfac
 	if len(recordList) > 1 {
 		return nil, Http.NewHttpError(fmt.Sprintf("found [%d] record for [type/id]=[%s/%s]", len(recordList), dataType, dataId), http.StatusInternalServerError)
 	}
+	if isExpired(recordList[0]) {
+		return nil, Http.NewHttpError(fmt.Sprintf("object of type '%s' with id '%s' expired", dataType, dataId), http.StatusNotFound)
+	}
 	return recordList[0], nil
 }
 
 func (h *Handler) querySchema(dataType string) (*Schema.SchemaOps, *Http.HttpError) {
+	return h.loadSchema(dataType, map[string]bool{})
+}
+
+func (h *Handler) loadSchema(dataType string, chain map[string]bool) (*Schema.SchemaOps, *Http.HttpError) {
+	h.schemaLock.RLock()
 	schema, ok := h.schemaMap[dataType]
+	h.schemaLock.RUnlock()
 	if ok {
 		return schema, nil
 	}
@@ -202,20 +445,76 @@ func (h *Handler) querySchema(dataType string) (*Schema.SchemaOps, *Http.HttpErr
 		errMsg := fmt.Sprintf("failed to load schema record as record. [type]=[%s]", dataType)
 		h.Log(errMsg)
 		h.Log(e.Error())
-		return nil, Http.WrapError(e, errMsg, http.StatusInternalServerError)
+		return nil, h.schemaFailed(dataType, Http.WrapError(e, errMsg, http.StatusInternalServerError))
 	}
-	schema, e = Schema.LoadSchemaOpsRecord(record)
+	schema, e = Schema.LoadSchemaOpsWithFetcher(record, h.FetchSchema)
 	if e != nil {
 		errMsg := fmt.Sprintf("failed to load Schema Record as SchemaOpsRecord, [%s]=[%s]", Record.DataType, dataType)
 		h.Log(errMsg)
 		h.Log(e.Error())
-		return nil, Http.WrapError(e, errMsg, http.StatusInternalServerError)
+		return nil, h.schemaFailed(dataType, Http.WrapError(e, errMsg, http.StatusInternalServerError))
+	}
+	err = h.extendSchema(schema, chain)
+	if err != nil {
+		return nil, h.schemaFailed(dataType, err)
 	}
+	h.logSchemaWarnings(schema)
 	h.SetLocalSchema(dataType, schema)
 	return schema, nil
 }
 
+func (h *Handler) logSchemaWarnings(schema *Schema.SchemaOps) {
+	for _, warning := range schema.Schema.AllWarnings() {
+		h.Log(fmt.Sprintf("schema [%s] warning: %s", schema.Schema.Id, warning))
+	}
+}
+
+// merge base schema into schema when [extends] is defined. chain holds schemas on the way to detect loops
+func (h *Handler) extendSchema(schema *Schema.SchemaOps, chain map[string]bool) *Http.HttpError {
+	baseType := schema.BaseType()
+	if baseType == "" {
+		return nil
+	}
+	chain[schema.Schema.Id] = true
+	if _, ok := chain[baseType]; ok {
+		errMsg := fmt.Sprintf("circular [%s] found on schema [%s] -> [%s]", JsonKey.Extends, schema.Schema.Id, baseType)
+		h.Log(errMsg)
+		return Http.NewHttpError(errMsg, http.StatusBadRequest)
+	}
+	base, err := h.loadSchema(baseType, chain)
+	if err != nil {
+		return Http.WrapError(err, fmt.Sprintf("failed to load base schema [%s] of [%s]", baseType, schema.Schema.Id), err.Status)
+	}
+	e := schema.Extend(base)
+	if e != nil {
+		errMsg := fmt.Sprintf("failed to extend schema [%s] from [%s]", schema.Schema.Id, baseType)
+		h.Log(errMsg)
+		h.Log(e.Error())
+		return Http.WrapError(e, errMsg, http.StatusBadRequest)
+	}
+	return nil
+}
+
+func (h *Handler) EffectiveSchema(idPath string) (map[string]interface{}, *Http.HttpError) {
+	id, version, ex := SchemaDoc.ParseDataType(idPath)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to parse schema type[%s]", idPath), http.StatusBadRequest)
+	}
+	schema, err := h.LocalSchema(id, version)
+	if err != nil {
+		return nil, err
+	}
+	data, ex := schema.Effective()
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to get effective schema of [%s]", idPath), http.StatusInternalServerError)
+	}
+	return Record.NewRecord(schema.Record.Type, schema.Record.Version, schema.Record.Id, data).Map(), nil
+}
+
 func (h *Handler) SetLocalSchema(dataType string, schema *Schema.SchemaOps) {
+	h.schemaLock.Lock()
+	defer h.schemaLock.Unlock()
+	delete(h.failedSchemas, dataType)
 	if schema == nil {
 		delete(h.schemaMap, dataType)
 		return
@@ -223,6 +522,30 @@ func (h *Handler) SetLocalSchema(dataType string, schema *Schema.SchemaOps) {
 	h.schemaMap[dataType] = schema
 }
 
+// schema type or a type whose schema is loaded
+func (h *Handler) KnownType(dataType string) bool {
+	if dataType == JsonKey.Schema {
+		return true
+	}
+	h.schemaLock.RLock()
+	defer h.schemaLock.RUnlock()
+	_, ok := h.schemaMap[dataType]
+	return ok
+}
+
+// names of sensitive attributes over every loaded schema
+func (h *Handler) SensitiveAttrs() map[string]bool {
+	h.schemaLock.RLock()
+	defer h.schemaLock.RUnlock()
+	names := map[string]bool{}
+	for _, schema := range h.schemaMap {
+		for attr := range schema.Schema.SensitiveAttrs() {
+			names[attr] = true
+		}
+	}
+	return names
+}
+
 func (h *Handler) LocalSchema(dataType string, version string) (*Schema.SchemaOps, *Http.HttpError) {
 	schema, err := h.querySchema(dataType)
 	if err != nil {
@@ -257,6 +580,11 @@ func (h *Handler) LocalSchema(dataType string, version string) (*Schema.SchemaOp
 }
 
 func (h *Handler) Validate(record *Record.Record) *Http.HttpError {
+	return h.validate(record, nil)
+}
+
+// validate record with ref values checked by check, configured ref check when nil
+func (h *Handler) validate(record *Record.Record, check *refCheck) *Http.HttpError {
 	if record.Type == Record.KeyRecord {
 		errMsg := fmt.Sprintf("should not validate schema of %s", Record.KeyRecord)
 		h.Log(errMsg)
@@ -266,15 +594,40 @@ func (h *Handler) Validate(record *Record.Record) *Http.HttpError {
 	if err != nil {
 		return err
 	}
+	// overlay only tightens schema, so record of tenant is validated by merged schema alone
+	tenantSchema, err := h.tenantSchema(schema, record)
+	if err != nil {
+		return err
+	}
+	if tenantSchema != nil {
+		schema = tenantSchema
+	}
+	if record.Type != JsonKey.Schema {
+		missingList := schema.Schema.MissingRequired(record.Data, path.Join(record.Type, record.Id))
+		if len(missingList) > 0 {
+			errMsg := fmt.Sprintf("missing required attr %v", missingList)
+			h.Log(errMsg)
+			return Http.NewHttpError(errMsg, http.StatusBadRequest)
+		}
+	}
 	e := schema.ValidateRecord(record)
 	if e != nil {
 		errMsg := fmt.Sprintf("failed to validate payload against schema for type %s", record.Type)
+		if tenantSchema != nil {
+			errMsg = fmt.Sprintf("%s with overlay of tenant [%s]", errMsg, record.Tenant)
+		}
 		h.Log(errMsg)
 		h.Log(e.Error())
 		return Http.WrapError(e, errMsg, http.StatusBadRequest)
 	}
 	if record.Type != JsonKey.Schema {
-		err = h.ValidateDataRefs(schema.Schema, record.Data, path.Join(record.Type, record.Id))
+		if check == nil {
+			check, err = h.newRefCheck(nil)
+			if err != nil {
+				return err
+			}
+		}
+		err = h.validateDataRefs(check, schema.Schema, record.Data, path.Join(record.Type, record.Id))
 	} else {
 		err = h.validateCmtAutoIdxOnSchema(record)
 	}
@@ -285,7 +638,35 @@ func (h *Handler) Validate(record *Record.Record) *Http.HttpError {
 	return nil
 }
 
+// advisory findings of record against its schema, like deprecated attr or unmet warnRules.
+// they do not block the write
+func (h *Handler) Warnings(record *Record.Record) []string {
+	if record.Type == JsonKey.Schema || record.Type == Record.KeyRecord {
+		return []string{}
+	}
+	if _, ok := Common.InternalTypes[record.Type]; ok {
+		return []string{}
+	}
+	schema, err := h.LocalSchema(record.Type, record.Version)
+	if err != nil {
+		h.Log(fmt.Sprintf("failed to load schema to check warnings of [%s/%s]: %s", record.Type, record.Id, err))
+		return []string{}
+	}
+	return schema.Schema.Validate(record.Data, path.Join(record.Type, record.Id)).Warnings
+}
+
 func (h *Handler) ValidateDataRefs(doc *SchemaDoc.SchemaDoc, data map[string]interface{}, dataPath string) *Http.HttpError {
+	check, err := h.newRefCheck(nil)
+	if err != nil {
+		return err
+	}
+	return h.validateDataRefs(check, doc, data, dataPath)
+}
+
+func (h *Handler) validateDataRefs(check *refCheck, doc *SchemaDoc.SchemaDoc, data map[string]interface{}, dataPath string) *Http.HttpError {
+	if check.mode == Common.RefNone {
+		return nil
+	}
 	for attrName, def := range doc.Properties() {
 		value, ok := data[attrName]
 		if !ok {
@@ -301,14 +682,14 @@ func (h *Handler) ValidateDataRefs(doc *SchemaDoc.SchemaDoc, data map[string]int
 			if !isRef {
 				continue
 			}
-			err := h.validateCmtRefValue(ref, value.(string), attrPath)
+			err := h.validateCmtRefValue(check, ref, value.(string), attrPath)
 			if err != nil {
 				errList = append(errList, err)
 			}
 		case JsonKey.Object:
 			valueObj := value.(map[string]interface{})
 			if !SchemaDoc.IsMap(attrDef) {
-				return h.ValidateDataRefs(subDoc, valueObj, attrPath)
+				return h.validateDataRefs(check, subDoc, valueObj, attrPath)
 			}
 			if !isRef && !isSubDoc {
 				continue
@@ -316,14 +697,14 @@ func (h *Handler) ValidateDataRefs(doc *SchemaDoc.SchemaDoc, data map[string]int
 			for key, keyValue := range valueObj {
 				keyPath := fmt.Sprintf("%s[%s]", attrPath, key)
 				if isRef {
-					err := h.validateCmtRefValue(ref, keyValue.(string), keyPath)
+					err := h.validateCmtRefValue(check, ref, keyValue.(string), keyPath)
 					if err != nil {
 						errList = append(errList, err)
 					}
 					continue
 				}
 				if isSubDoc {
-					err := h.ValidateDataRefs(subDoc, keyValue.(map[string]interface{}), keyPath)
+					err := h.validateDataRefs(check, subDoc, keyValue.(map[string]interface{}), keyPath)
 					if err != nil {
 						errList = append(errList, err)
 					}
@@ -337,7 +718,7 @@ func (h *Handler) ValidateDataRefs(doc *SchemaDoc.SchemaDoc, data map[string]int
 			for _, item := range valueAry {
 				if isRef {
 					itemPath := fmt.Sprintf("%s[%s]", attrPath, item.(string))
-					err := h.validateCmtRefValue(ref, item.(string), itemPath)
+					err := h.validateCmtRefValue(check, ref, item.(string), itemPath)
 					if err != nil {
 						errList = append(errList, err)
 					}
@@ -346,7 +727,7 @@ func (h *Handler) ValidateDataRefs(doc *SchemaDoc.SchemaDoc, data map[string]int
 				if isSubDoc {
 					itemKey, _ := subDoc.BuildKey(item.(map[string]interface{}))
 					itemPath := fmt.Sprintf("%s[%s]", attrPath, itemKey)
-					err := h.ValidateDataRefs(subDoc, item.(map[string]interface{}), itemPath)
+					err := h.validateDataRefs(check, subDoc, item.(map[string]interface{}), itemPath)
 					if err != nil {
 						errList = append(errList, err)
 					}
@@ -368,21 +749,34 @@ func (h *Handler) ValidateDataRefs(doc *SchemaDoc.SchemaDoc, data map[string]int
 	return nil
 }
 
-func (h *Handler) validateCmtRefValue(ref *SchemaDoc.CMTDocRef, value string, dataPath string) *Http.HttpError {
+func (h *Handler) validateCmtRefValue(check *refCheck, ref *SchemaDoc.CMTDocRef, value string, dataPath string) *Http.HttpError {
 	if ref.CmtType != Schema.Inventory {
 		// ContentMediaType not start with inventory, we don't understand
 		return nil
 	}
-	if ref.ContentType == JsonKey.Schema {
+	dataType, dataId, refPath, ex := ref.TargetPath(value)
+	if ex != nil {
+		return Http.WrapError(ex, fmt.Sprintf("invalid reference %s:%s. @path=[%s]", ref.CmtType, strings.Join(ref.ContentTypes, JsonKey.ContentTypeDiv), dataPath), http.StatusBadRequest)
+	}
+	if dataType == JsonKey.Schema {
 		return Http.NewHttpError("should not refer to schema of schema as data type", http.StatusBadRequest)
 	}
-	cmtRecord, err := h.Inventory.Get(ref.ContentType, value)
+	if check.mode == Common.RefStructure {
+		return h.validateCmtRefStructure(check, ref, dataType, refPath, value, dataPath)
+	}
+	cmtRecord, err := check.conn.GetRecord(dataType, dataId)
 	if err != nil {
 		if err.Status == http.StatusNotFound {
-			return Http.NewHttpError(fmt.Sprintf("reference %s:%s with value=[%s] does not exists. @path=[%s]", ref.CmtType, ref.ContentType, value, dataPath), http.StatusBadRequest)
+			return Http.NewHttpError(fmt.Sprintf("reference %s:%s with value=[%s] does not exists. @path=[%s]", ref.CmtType, dataType, value, dataPath), http.StatusBadRequest)
 		}
 		return err
 	}
+	if refPath != "" {
+		_, err = check.walk(dataType, dataId, refPath)
+		if err != nil {
+			return Http.WrapError(err, fmt.Sprintf("reference %s:%s with value=[%s] does not resolve. @path=[%s]", ref.CmtType, dataType, value, dataPath), http.StatusBadRequest)
+		}
+	}
 	if ref.IndexTemplate != "" {
 		idxTemp, ex := Template.ParseStr(ref.IndexTemplate, "{", "}")
 		if ex != nil {
@@ -400,9 +794,31 @@ func (h *Handler) validateCmtRefValue(ref *SchemaDoc.CMTDocRef, value string, da
 	return nil
 }
 
+// check target type of ref is known and path in ref value is defined by its schema, target record is not required
+func (h *Handler) validateCmtRefStructure(check *refCheck, ref *SchemaDoc.CMTDocRef, dataType string, refPath string, value string, dataPath string) *Http.HttpError {
+	targetSchema, err := check.conn.GetSchema(dataType)
+	if err != nil {
+		if err.Status == http.StatusNotFound {
+			return Http.NewHttpError(fmt.Sprintf("reference %s:%s with value=[%s] has unknown type. @path=[%s]", ref.CmtType, dataType, value, dataPath), http.StatusBadRequest)
+		}
+		return err
+	}
+	ex := targetSchema.ValidatePath(refPath)
+	if ex != nil {
+		return Http.WrapError(ex, fmt.Sprintf("reference %s:%s with value=[%s] has invalid path. @path=[%s]", ref.CmtType, dataType, value, dataPath), http.StatusBadRequest)
+	}
+	return nil
+}
+
 func (h *Handler) validateCmtAutoIdxOnSchema(record *Record.Record) *Http.HttpError {
-	schemaData, _ := Json.CopyToMap(record.Data)
-	schema, _ := SchemaDoc.New(schemaData)
+	schemaData, ex := Schema.ResolveExternalRefs(record.Data, h.FetchSchema)
+	if ex != nil {
+		return Http.WrapError(ex, fmt.Sprintf("failed to resolve external refs of schema [%s]", record.Id), http.StatusBadRequest)
+	}
+	schema, ex := SchemaDoc.New(schemaData)
+	if ex != nil {
+		return Http.WrapError(ex, fmt.Sprintf("failed to load schema [%s]", record.Id), http.StatusBadRequest)
+	}
 	idxList := CmtIndex.FindAutoIndex(schema, "")
 	errList := make([]*Http.HttpError, 0, len(idxList))
 	for _, idx := range idxList {
@@ -433,8 +849,89 @@ func (h *Handler) validateCmtAutoIdx(idx *CmtIndex.AutoIndex) *Http.HttpError {
 	return idx.ValidateIndexTemplate(targetSchema)
 }
 
+// predicate like status=pending, or spec/status=pending for attribute inside object, that stored record must satisfy
+func matchFieldPredicate(record *Record.Record, predicate string) *Http.HttpError {
+	attrPath, expected, ok := strings.Cut(predicate, "=")
+	if !ok || attrPath == "" {
+		return Http.NewHttpError(fmt.Sprintf("invalid header [%s]=[%s], expect format=[{attrPath}={value}]", Common.HeaderIfField, predicate), http.StatusBadRequest)
+	}
+	var value interface{} = record.Data
+	for nextPath := attrPath; nextPath != ""; {
+		var attr string
+		attr, nextPath = Util.ParsePath(nextPath)
+		data, isObj := value.(map[string]interface{})
+		if !isObj {
+			value = nil
+			break
+		}
+		value = data[attr]
+	}
+	if value == nil || fmt.Sprint(value) != expected {
+		return Http.NewHttpError(fmt.Sprintf("record [%s/%s] has [%s]=[%v], does not match [%s]=[%s]", record.Type, record.Id, attrPath, value, Common.HeaderIfField, predicate), http.StatusPreconditionFailed)
+	}
+	return nil
+}
+
+// actor of the request from configured header, anonymous marker when header not found
+func (h *Handler) Actor(headers map[string]interface{}) string {
+	header := h.Config.Audit.ActorHeader
+	if header == "" {
+		header = Common.DefaultActorHeader
+	}
+	if actor, ok := headers[strings.ToLower(header)].(string); ok && actor != "" {
+		return actor
+	}
+	return h.anonymous()
+}
+
+func (h *Handler) anonymous() string {
+	if h.Config.Audit.Anonymous != "" {
+		return h.Config.Audit.Anonymous
+	}
+	return Common.DefaultAnonymous
+}
+
+// record.ModifiedBy carries the actor from caller, audit fields are always computed here
+func (h *Handler) stampAudit(record *Record.Record, before *Record.Record) {
+	actor := record.ModifiedBy
+	if actor == "" {
+		actor = h.anonymous()
+	}
+	now := modifiedNow()
+	record.CreatedBy = actor
+	if before != nil && before.CreatedBy != "" {
+		record.CreatedBy = before.CreatedBy
+	}
+	record.Created = now
+	if before != nil && before.Created != "" {
+		record.Created = before.Created
+	}
+	record.ModifiedBy = actor
+	record.Modified = now
+}
+
+func modifiedNow() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
 func (h *Handler) Add(record *Record.Record) *Http.HttpError {
-	err := h.Validate(record)
+	return h.AddWith(record, nil)
+}
+
+// Add with ref values checked as X-Ref-Check in headers asks
+func (h *Handler) AddWith(record *Record.Record, headers map[string]interface{}) *Http.HttpError {
+	return h.addWith(record, headers, h.AddJournal)
+}
+
+// AddWith that records change with journal
+func (h *Handler) addWith(record *Record.Record, headers map[string]interface{}, journal JournalAdd) *Http.HttpError {
+	check, err := h.newRefCheck(headers)
+	if err != nil {
+		return err
+	}
+	h.stampAudit(record, nil)
+	h.stampExpire(record)
+	err = h.validate(record, check)
 	if err != nil {
 		return err
 	}
@@ -442,6 +939,20 @@ func (h *Handler) Add(record *Record.Record) *Http.HttpError {
 	if schema.Schema.Version != record.Version {
 		return Http.NewHttpError(fmt.Sprintf("invalid schema version of [%s %s] not match current schema version[%s]", record.Type, record.Version, schema.Schema.Version), http.StatusBadRequest)
 	}
+	if record.Type == JsonKey.Schema {
+		if h.IsView(record.Id) {
+			return Http.NewHttpError(fmt.Sprintf("schema [%s] conflicts with view of same name", record.Id), http.StatusConflict)
+		}
+		newSchema, ex := Schema.LoadSchemaOpsWithFetcher(record, h.FetchSchema)
+		if ex != nil {
+			return Http.WrapError(ex, "failed to load request record as schema", http.StatusBadRequest)
+		}
+		err = h.extendSchema(newSchema, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		h.logSchemaWarnings(newSchema)
+	}
 	idKey := fmt.Sprintf("%s/%s", record.Type, record.Id)
 	h.Lock.Aquire(idKey, "HandlerAdd")
 	defer h.Lock.Release(idKey, "HandlerAdd")
@@ -451,6 +962,14 @@ func (h *Handler) Add(record *Record.Record) *Http.HttpError {
 		h.Log(fmt.Sprintf("HandlerAdd: query failed.[%s/%s]", record.Type, record.Id))
 		return err
 	}
+	if len(recordList) > 0 && isExpired(recordList[0]) {
+		h.Log(fmt.Sprintf("HandlerAdd: purge expired.[%s/%s]", record.Type, record.Id))
+		err = h.removeData(record.Type, record.Id, recordList[0], journal)
+		if err != nil {
+			return err
+		}
+		recordList = nil
+	}
 	if len(recordList) > 0 {
 		h.Log(fmt.Sprintf("HandlerAdd: already exists.[%s/%s]", record.Type, record.Id))
 		if record.Type != JsonKey.Schema {
@@ -464,19 +983,13 @@ func (h *Handler) Add(record *Record.Record) *Http.HttpError {
 			h.Log(fmt.Sprintf("HandlerAdd: [%s]", msg))
 			return Http.NewHttpError(msg, http.StatusBadRequest)
 		}
-		newSchema, ex := Schema.LoadSchemaOpsRecord(record)
+		newSchema, ex := Schema.LoadSchemaOpsWithFetcher(record, h.FetchSchema)
 		if ex != nil {
 			return Http.WrapError(ex, "failed to load new schema record as schema", http.StatusBadRequest)
 		}
 		h.archiveCurrentSchema(newSchema)
 	}
-	if record.Type == JsonKey.Schema {
-		_, ex := Schema.LoadSchemaOpsRecord(record)
-		if ex != nil {
-			return Http.WrapError(ex, "failed to load request record as schema", http.StatusBadRequest)
-		}
-	}
-	return h.addData(record)
+	return h.addData(record, journal)
 }
 
 func CompareVersion(currentVersion string, newVersion string) (int, *Http.HttpError) {
@@ -515,7 +1028,7 @@ func (h *Handler) archiveCurrentSchema(newSchema *Schema.SchemaOps) *Http.HttpEr
 	}
 	schema.Record.Id = SchemaDoc.ArchivedSchemaId(schema.Schema.Id, schema.Schema.Version)
 	h.Log(fmt.Sprintf("HandlerAdd: updating schema record [%s]", newSchema.Schema.Id))
-	err = h.updateRecord(JsonKey.Schema, schema.Schema.Id, schema.Record)
+	err = h.updateRecord(JsonKey.Schema, schema.Schema.Id, schema.Record, nil)
 	if err != nil {
 		return err
 	}
@@ -529,16 +1042,16 @@ func (h *Handler) archiveCurrentSchema(newSchema *Schema.SchemaOps) *Http.HttpEr
 	return nil
 }
 
-func (h *Handler) addData(record *Record.Record) *Http.HttpError {
+func (h *Handler) addData(record *Record.Record, journal JournalAdd) *Http.HttpError {
 	h.Log(fmt.Sprintf("HandlerAdd: add record [%s/%s]", record.Type, record.Id))
 	e := h.DB.Create(h.Config.DataTable.Data, record.Map())
 	if e != nil {
 		return Http.WrapError(e, fmt.Sprintf("failed to create record [{type}/{id}]=[%s]/%s", record.Type, record.Id), http.StatusInternalServerError)
 	}
 	h.Log(fmt.Sprintf("HandlerAdd: record added [%s/%s]", record.Type, record.Id))
-	if h.AddJournal != nil {
+	if journal != nil {
 		h.Log(fmt.Sprintf("HandlerAdd: add journal for new record [%s/%s]", record.Type, record.Id))
-		h.AddJournal(record.Type, record.Id, nil, record.Map())
+		journal(record.Type, record.Id, nil, record.Map())
 		// we should log err if failed to add Journal
 	}
 	return nil
@@ -574,8 +1087,8 @@ func (h *Handler) CompareRecords(before *Record.Record, after *Record.Record) (b
 	if ex != nil {
 		return false, ex
 	}
-	srcStr, _ := json.MarshalIndent(before.Data, "", "    ")
-	tgtStr, _ := json.MarshalIndent(after.Data, "", "    ")
+	srcStr, _ := Json.Canonical(before.Data)
+	tgtStr, _ := Json.Canonical(after.Data)
 	if string(srcStr) != string(tgtStr) {
 		return false, nil
 	}
@@ -583,6 +1096,16 @@ func (h *Handler) CompareRecords(before *Record.Record, after *Record.Record) (b
 }
 
 func (h *Handler) Set(dataType string, dataId string, record *Record.Record) *Http.HttpError {
+	return h.SetIf(dataType, dataId, record, nil)
+}
+
+// Set when preconditions in headers hold on the current record, like If-Match
+func (h *Handler) SetIf(dataType string, dataId string, record *Record.Record, headers map[string]interface{}) *Http.HttpError {
+	return h.setIf(dataType, dataId, record, headers, h.AddJournal)
+}
+
+// SetIf that records change with journal
+func (h *Handler) setIf(dataType string, dataId string, record *Record.Record, headers map[string]interface{}, journal JournalAdd) *Http.HttpError {
 	if _, ok := Common.InternalTypes[record.Type]; ok {
 		return Http.NewHttpError(fmt.Sprintf("method[%s] on type[%s] is not allowed", http.MethodPut, record.Type), http.StatusBadRequest)
 	}
@@ -610,23 +1133,41 @@ func (h *Handler) Set(dataType string, dataId string, record *Record.Record) *Ht
 		}
 		before = record
 	}
+	err = matchETag(before, headers)
+	if err != nil {
+		h.Log(err.Error())
+		return err
+	}
+	h.stampAudit(record, before)
+	h.stampExpire(record)
+	err = h.validateAppendOnly(before, record)
+	if err != nil {
+		h.Log(err.Error())
+		return err
+	}
 	isSame, err := h.CompareRecords(before, record)
 	if err != nil {
 		h.Log(fmt.Sprintf("failed to compare record, Error: %s", err))
 		return err
 	}
-	if !isSame {
+	// write of same data still renews expiry
+	if !isSame || record.ExpireAt != "" {
 		h.Log(fmt.Sprintf("brefore and current %s/%s different", dataType, dataId))
-		err = h.updateRecord(record.Type, record.Id, record)
+		err = h.keepVersion(before, record)
+		if err != nil {
+			h.Log(err.Error())
+			return err
+		}
+		err = h.updateRecord(record.Type, record.Id, record, headers)
 		if err != nil {
 			h.Log(fmt.Sprintf("failed to update record, Error: %s", err))
 			return err
 		}
-		if h.AddJournal != nil {
+		if journal != nil {
 			if before != nil {
-				h.AddJournal(record.Type, record.Id, before.Map(), record.Map())
+				journal(record.Type, record.Id, before.Map(), record.Map())
 			} else {
-				h.AddJournal(record.Type, record.Id, nil, record.Map())
+				journal(record.Type, record.Id, nil, record.Map())
 			}
 
 		}
@@ -635,8 +1176,29 @@ func (h *Handler) Set(dataType string, dataId string, record *Record.Record) *Ht
 	return nil
 }
 
-func (h *Handler) updateRecord(dataType string, dataId string, record *Record.Record) *Http.HttpError {
-	err := h.Validate(record)
+// stored items of appendOnly array can not be changed, removed or reordered by update
+func (h *Handler) validateAppendOnly(before *Record.Record, after *Record.Record) *Http.HttpError {
+	if before == nil || after.Type == JsonKey.Schema {
+		return nil
+	}
+	schema, err := h.LocalSchema(after.Type, after.Version)
+	if err != nil {
+		return err
+	}
+	ex := Schema.ValidateAppendOnly(schema.Schema, before.Data, after.Data, path.Join(after.Type, after.Id))
+	if ex != nil {
+		return Http.NewHttpError(ex.Error(), http.StatusBadRequest)
+	}
+	return nil
+}
+
+// write record validated with ref check asked by headers
+func (h *Handler) updateRecord(dataType string, dataId string, record *Record.Record, headers map[string]interface{}) *Http.HttpError {
+	check, err := h.newRefCheck(headers)
+	if err != nil {
+		return err
+	}
+	err = h.validate(record, check)
 	if err != nil {
 		return err
 	}
@@ -651,17 +1213,34 @@ func (h *Handler) updateRecord(dataType string, dataId string, record *Record.Re
 }
 
 func (h *Handler) Delete(dataType string, dataId string) *Http.HttpError {
+	return h.DeleteChecked(dataType, dataId, false)
+}
+
+// with Delete.CheckRefs in config, record that other records ref is only deleted when force is true,
+// otherwise 409 with [type/id] of referrers in payload
+func (h *Handler) DeleteChecked(dataType string, dataId string, force bool) *Http.HttpError {
 	if dataType == JsonKey.Schema {
 		err := h.deleteSchema(dataId)
 		if err != nil {
 			return err
 		}
-		delete(h.schemaMap, dataId)
+		h.SetLocalSchema(dataId, nil)
 	}
 	_, err := h.LocalSchema(dataType, "")
 	if err != nil {
 		return err
 	}
+	if h.Config.Delete.CheckRefs && !force && dataType != JsonKey.Schema {
+		refs, err := h.InboundRefs(dataType, dataId)
+		if err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			err = Http.NewHttpError(fmt.Sprintf("[%s/%s] is referenced by %d record(s), delete with query [%s] to remove it anyway", dataType, dataId, len(refs), Common.QueryForce), http.StatusConflict)
+			err.Payload = refs
+			return err
+		}
+	}
 	return h.deleteData(dataType, dataId)
 }
 
@@ -707,7 +1286,12 @@ func (h *Handler) deleteData(dataType string, dataId string) *Http.HttpError {
 	if len(recordList) == 0 {
 		return nil
 	}
-	beforeRec, e := Record.LoadMap(recordList[0])
+	return h.removeData(dataType, dataId, recordList[0], h.AddJournal)
+}
+
+// delete record without lock, caller holds lock of [type/id]
+func (h *Handler) removeData(dataType string, dataId string, data map[string]interface{}, journal JournalAdd) *Http.HttpError {
+	beforeRec, e := Record.LoadMap(data)
 	if e != nil {
 		return Http.WrapError(e, fmt.Sprintf("failed to load data as record.[type/id]=[%s/%s]", dataType, dataId), http.StatusInternalServerError)
 	}
@@ -719,14 +1303,22 @@ func (h *Handler) deleteData(dataType string, dataId string) *Http.HttpError {
 	if e != nil {
 		return Http.WrapError(e, fmt.Sprintf("failed to delete record [type/id]=[%s/%s]", dataType, dataId), http.StatusInternalServerError)
 	}
-	if h.AddJournal != nil {
-		h.AddJournal(dataType, dataId, beforeRec.Map(), nil)
+	err := h.dropHistory(dataType, dataId)
+	if err != nil {
+		h.Log(err.Error())
+	}
+	if journal != nil {
+		journal(dataType, dataId, beforeRec.Map(), nil)
 	}
 	return nil
 }
 
 func (h *Handler) Patch(dataType string, idPath string, headers map[string]interface{}, data interface{}) (map[string]interface{}, *Http.HttpError) {
 	dataId, nextPath := Util.ParsePath(idPath)
+	dataId, ex := Util.UnescapeKey(dataId)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to unescape record id of path [%s]", idPath), http.StatusBadRequest)
+	}
 	if dataId == "" {
 		errMsg := fmt.Sprintf("invalid path=[%s/%s], expect format=[{dataType}/{dataId}/{dataPath}]", dataType, dataId)
 		h.Log(errMsg)
@@ -769,6 +1361,19 @@ func (h *Handler) Patch(dataType string, idPath string, headers map[string]inter
 		}
 		h.Log("version match with header")
 	}
+	err = matchETag(patchRecord, headers)
+	if err != nil {
+		h.Log(err.Error())
+		return nil, err
+	}
+	if predicate, ok := headers[strings.ToLower(Common.HeaderIfField)].(string); ok {
+		h.Log(fmt.Sprintf("PATCH[%s/%s]: header field predicate [%s]", dataType, dataId, predicate))
+		err = matchFieldPredicate(patchRecord, predicate)
+		if err != nil {
+			h.Log(err.Error())
+			return nil, err
+		}
+	}
 	h.Log(fmt.Sprintf("Handler PATCH[%s/%s]: get version schema [%s]", dataType, dataId, patchRecord.Version))
 	schema, err := h.LocalSchema(dataType, patchRecord.Version)
 	if err != nil {
@@ -796,10 +1401,24 @@ func (h *Handler) Patch(dataType string, idPath string, headers map[string]inter
 	if err != nil {
 		return nil, Http.WrapError(err, "failed to compare version", http.StatusBadRequest)
 	}
+	patchRecord.ModifiedBy = h.Actor(headers)
+	patchRecord.Modified = modifiedNow()
+	patchRecord.Tenant = h.Tenant(headers)
+	h.stampExpire(patchRecord)
 	if verComp < 0 {
 		return nil, Http.NewHttpError(fmt.Sprintf("downgrade data format are not supported. version[%s] -> [%s]", before.Version, patchRecord.Version), http.StatusBadRequest)
 	}
-	err = h.updateRecord(before.Type, before.Id, patchRecord)
+	err = h.validateAppendOnly(&before, patchRecord)
+	if err != nil {
+		h.Log(err.Error())
+		return nil, err
+	}
+	err = h.keepVersion(&before, patchRecord)
+	if err != nil {
+		h.Log(err.Error())
+		return nil, err
+	}
+	err = h.updateRecord(before.Type, before.Id, patchRecord, headers)
 	if err != nil {
 		h.Log(err.Error())
 		return nil, err
@@ -833,6 +1452,8 @@ func patchRecordByPath(schema *SchemaDoc.SchemaDoc, record *Record.Record, nextP
 		record.Id = archiveId
 	case Record.DataType:
 		return Http.NewHttpError("Change on Record Data Type is not supported", http.StatusNotModified)
+	case Record.Created, Record.CreatedBy, Record.ModifiedBy, Record.Modified, Record.ExpireAt, Record.Ttl:
+		return Http.NewHttpError(fmt.Sprintf("[%s] is computed by server, patch not allowed", nextPath), http.StatusBadRequest)
 	case Record.Version:
 		if record.Version == newData.(string) {
 			return Http.NewHttpError(fmt.Sprintf("Type Version already updated. [%s]", record.Version), http.StatusNotModified)
@@ -0,0 +1,198 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const (
+	historyVersion = "0.0.1"
+	keyVersions    = "versions"
+)
+
+// version of record with time it was last written, Current is the stored record itself
+type VersionInfo struct {
+	Version  string `json:"version"`
+	Modified string `json:"modified"`
+	Current  bool   `json:"current"`
+}
+
+// prior states of [type/id] are kept in one record of type Common.KeyHistory under this id
+func historyId(dataType string, dataId string) string {
+	return fmt.Sprintf("%s:%s", dataType, dataId)
+}
+
+// version -> prior state of record in that version, empty when nothing is kept
+func (h *Handler) historyVersions(dataType string, dataId string) (map[string]interface{}, *Http.HttpError) {
+	recordList, err := h.QueryDb(Common.KeyHistory, historyId(dataType, dataId))
+	if err != nil {
+		return nil, err
+	}
+	if len(recordList) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	data, _ := recordList[0][Record.Data].(map[string]interface{})
+	versions, ok := data[keyVersions].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	return versions, nil
+}
+
+// when write moves record to another version, keep state before it as state of its old version.
+// only last state of each version is kept, oldest versions over History.Depth are dropped. off when Depth is 0.
+// caller holds lock of the record
+func (h *Handler) keepVersion(before *Record.Record, after *Record.Record) *Http.HttpError {
+	depth := h.Config.History.Depth
+	if depth <= 0 || before == nil || before.Version == after.Version {
+		return nil
+	}
+	versions, err := h.historyVersions(before.Type, before.Id)
+	if err != nil {
+		return err
+	}
+	versions[before.Version] = before.Map()
+	// state of version the record moves to is the record itself
+	delete(versions, after.Version)
+	verList := sortVersions(versions)
+	for len(verList) > depth {
+		delete(versions, verList[0])
+		verList = verList[1:]
+	}
+	history := Record.NewRecord(Common.KeyHistory, historyVersion, historyId(before.Type, before.Id), map[string]interface{}{
+		keyVersions: versions,
+	})
+	e := h.DB.Replace(h.Config.DataTable.Data, map[string]interface{}{
+		Record.DataType: history.Type,
+		Record.DataId:   history.Id,
+	}, history.Map())
+	if e != nil {
+		return Http.WrapError(e, fmt.Sprintf("failed to keep version [%s] of [%s/%s]", before.Version, before.Type, before.Id), http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// drop kept versions of deleted record, caller holds lock of the record
+func (h *Handler) dropHistory(dataType string, dataId string) *Http.HttpError {
+	recordList, err := h.QueryDb(Common.KeyHistory, historyId(dataType, dataId))
+	if err != nil || len(recordList) == 0 {
+		return err
+	}
+	e := h.DB.Delete(h.Config.DataTable.Data, map[string]interface{}{
+		Record.DataType: Common.KeyHistory,
+		Record.DataId:   historyId(dataType, dataId),
+	})
+	if e != nil {
+		return Http.WrapError(e, fmt.Sprintf("failed to drop history of [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// move kept versions of renamed record to its new id, caller holds lock of both ids
+func (h *Handler) moveHistory(dataType string, oldId string, newId string) *Http.HttpError {
+	versions, err := h.historyVersions(dataType, oldId)
+	if err != nil || len(versions) == 0 {
+		return err
+	}
+	for _, state := range versions {
+		if data, ok := state.(map[string]interface{}); ok {
+			data[Record.DataId] = newId
+		}
+	}
+	history := Record.NewRecord(Common.KeyHistory, historyVersion, historyId(dataType, newId), map[string]interface{}{
+		keyVersions: versions,
+	})
+	e := h.DB.Replace(h.Config.DataTable.Data, map[string]interface{}{
+		Record.DataType: history.Type,
+		Record.DataId:   history.Id,
+	}, history.Map())
+	if e != nil {
+		return Http.WrapError(e, fmt.Sprintf("failed to move history of [%s/%s] to [%s]", dataType, oldId, newId), http.StatusInternalServerError)
+	}
+	return h.dropHistory(dataType, oldId)
+}
+
+// versions of keys from oldest to newest
+func sortVersions(versions map[string]interface{}) []string {
+	verList := make([]string, 0, len(versions))
+	for version := range versions {
+		verList = append(verList, version)
+	}
+	sort.Slice(verList, func(i, j int) bool {
+		comp, _ := CompareVersion(verList[i], verList[j])
+		return comp > 0
+	})
+	return verList
+}
+
+// record of [type/id] as it was in version, current record when it has that version
+func (h *Handler) GetVersion(dataType string, dataId string, version string) (map[string]interface{}, *Http.HttpError) {
+	current, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	if current[Record.Version] == version {
+		return current, nil
+	}
+	versions, err := h.historyVersions(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := versions[version].(map[string]interface{})
+	if !ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("version [%s] of [%s/%s] is not kept", version, dataType, dataId), http.StatusNotFound)
+	}
+	return data, nil
+}
+
+// versions of [type/id] from oldest to current
+func (h *Handler) ListVersions(dataType string, dataId string) ([]VersionInfo, *Http.HttpError) {
+	current, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := h.historyVersions(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	currentVer, _ := current[Record.Version].(string)
+	versions[currentVer] = current
+	result := []VersionInfo{}
+	for _, version := range sortVersions(versions) {
+		data, _ := versions[version].(map[string]interface{})
+		modified, _ := data[Record.Modified].(string)
+		result = append(result, VersionInfo{Version: version, Modified: modified, Current: version == currentVer})
+	}
+	return result, nil
+}
@@ -0,0 +1,231 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/salesforce/UniTAO/lib/Schema"
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const (
+	DefaultImportWorkers = 4
+	MaxImportLineSize    = 16 * 1024 * 1024
+	importProgressStep   = 100
+)
+
+type ImportOptions struct {
+	Workers     int
+	StopOnError bool
+	Actor       string // recorded as creator of imported records
+	Tenant      string // tenant whose schema overlays apply to imported records
+	Coerce      bool   // convert string encoded values into schema types before validation
+}
+
+type ImportError struct {
+	Line     int             `json:"line"`
+	DataType string          `json:"dataType,omitempty"`
+	DataId   string          `json:"dataId,omitempty"`
+	Error    *Http.HttpError `json:"error"`
+}
+
+type ImportResult struct {
+	Total    int           `json:"total"`
+	Imported int           `json:"imported"`
+	Failed   int           `json:"failed"`
+	Stopped  bool          `json:"stopped"`
+	Errors   []ImportError `json:"errors"`
+}
+
+type importJob struct {
+	line   int
+	record *Record.Record
+}
+
+type importState struct {
+	lock    sync.Mutex
+	result  ImportResult
+	options ImportOptions
+}
+
+func (s *importState) done(h *Handler, job importJob, err *Http.HttpError) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err != nil {
+		s.result.Failed++
+		s.result.Errors = append(s.result.Errors, ImportError{
+			Line:     job.line,
+			DataType: job.record.Type,
+			DataId:   job.record.Id,
+			Error:    err,
+		})
+		if s.options.StopOnError {
+			s.result.Stopped = true
+		}
+	} else {
+		s.result.Imported++
+	}
+	finished := s.result.Imported + s.result.Failed
+	if finished%importProgressStep == 0 {
+		h.Log(fmt.Sprintf("Import: progress imported=[%d] failed=[%d]", s.result.Imported, s.result.Failed))
+	}
+}
+
+func (s *importState) parseFailed(line int, err *Http.HttpError) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.result.Total++
+	s.result.Failed++
+	s.result.Errors = append(s.result.Errors, ImportError{
+		Line:  line,
+		Error: err,
+	})
+	if s.options.StopOnError {
+		s.result.Stopped = true
+	}
+}
+
+func (s *importState) stopped() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.result.Stopped
+}
+
+// import NDJSON records, one record per line, with a bounded pool of workers.
+// schema records are added after all queued records finished, so data after it can use the new schema.
+// workers of config is default and max of workers in options
+func (h *Handler) Import(reader io.Reader, options ImportOptions) (*ImportResult, *Http.HttpError) {
+	maxWorkers := h.Config.Import.Workers
+	if maxWorkers <= 0 {
+		maxWorkers = DefaultImportWorkers
+	}
+	if options.Workers > maxWorkers {
+		return nil, Http.NewHttpError(fmt.Sprintf("import workers [%d] exceeds max [%d]", options.Workers, maxWorkers), http.StatusBadRequest)
+	}
+	if options.Workers <= 0 {
+		options.Workers = maxWorkers
+	}
+	state := importState{
+		options: options,
+		result: ImportResult{
+			Errors: []ImportError{},
+		},
+	}
+	jobs := make(chan importJob)
+	wg := sync.WaitGroup{}
+	pending := sync.WaitGroup{}
+	for i := 0; i < options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if state.stopped() {
+					pending.Done()
+					continue
+				}
+				state.done(h, job, h.Add(job.record))
+				pending.Done()
+			}
+		}()
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxImportLineSize)
+	lineNum := 0
+	for scanner.Scan() && !state.stopped() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		data := map[string]interface{}{}
+		ex := json.Unmarshal([]byte(line), &data)
+		if ex != nil {
+			state.parseFailed(lineNum, Http.WrapError(ex, "failed to parse line as JSON object", http.StatusBadRequest))
+			continue
+		}
+		record, ex := Record.LoadMap(data)
+		if ex != nil {
+			state.parseFailed(lineNum, Http.WrapError(ex, "failed to load line as Record", http.StatusBadRequest))
+			continue
+		}
+		record.ModifiedBy = options.Actor
+		record.Tenant = options.Tenant
+		if options.Coerce && record.Type != JsonKey.Schema {
+			err := h.coerceRecord(record)
+			if err != nil {
+				state.parseFailed(lineNum, err)
+				continue
+			}
+		}
+		state.lock.Lock()
+		state.result.Total++
+		state.lock.Unlock()
+		job := importJob{
+			line:   lineNum,
+			record: record,
+		}
+		if record.Type == JsonKey.Schema {
+			pending.Wait()
+			state.done(h, job, h.Add(record))
+			continue
+		}
+		pending.Add(1)
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+	sort.Slice(state.result.Errors, func(i, j int) bool {
+		return state.result.Errors[i].Line < state.result.Errors[j].Line
+	})
+	ex := scanner.Err()
+	if ex != nil {
+		return &state.result, Http.WrapError(ex, fmt.Sprintf("failed to read import data after line [%d]", lineNum), http.StatusBadRequest)
+	}
+	h.Log(fmt.Sprintf("Import: finished total=[%d] imported=[%d] failed=[%d]", state.result.Total, state.result.Imported, state.result.Failed))
+	return &state.result, nil
+}
+
+func (h *Handler) coerceRecord(record *Record.Record) *Http.HttpError {
+	schema, err := h.LocalSchema(record.Type, record.Version)
+	if err != nil {
+		return err
+	}
+	ex := Schema.CoerceData(schema.Schema, record.Data, fmt.Sprintf("%s/%s", record.Type, record.Id))
+	if ex != nil {
+		return Http.WrapError(ex, "failed to coerce record into schema types", http.StatusBadRequest)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const (
+	JsonApiAttributes    = "attributes"
+	JsonApiData          = "data"
+	JsonApiId            = "id"
+	JsonApiKey           = "key"
+	JsonApiLinks         = "links"
+	JsonApiMeta          = "meta"
+	JsonApiNext          = "next"
+	JsonApiRelationships = "relationships"
+	JsonApiSelf          = "self"
+	JsonApiType          = "type"
+)
+
+// record in JSON:API document, {"data": {type, id, attributes, relationships, meta}}.
+// attribute with contentMediaType ref goes to relationships, system fields like __ver go to meta
+func (h *Handler) GetJsonApi(dataType string, dataId string) (map[string]interface{}, *Http.HttpError) {
+	data, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	record, ex := Record.LoadMap(data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+	}
+	schema, err := h.LocalSchema(record.Type, record.Version)
+	if err != nil {
+		return nil, err
+	}
+	doc := schema.Schema.DocOf(record.Data)
+	attributes := map[string]interface{}{}
+	relationships := map[string]interface{}{}
+	for attrName, value := range record.Data {
+		ref, isRef := doc.CmtRefs[attrName]
+		if !isRef {
+			attributes[attrName] = value
+			continue
+		}
+		attrDef, _ := doc.Properties()[attrName].(map[string]interface{})
+		linkage, err := jsonApiLinkage(ref, attrDef, value)
+		if err != nil {
+			return nil, Http.WrapError(err, fmt.Sprintf("invalid ref @path=[%s/%s/%s]", dataType, dataId, attrName), err.Status)
+		}
+		relationships[attrName] = map[string]interface{}{
+			JsonApiData: linkage,
+		}
+	}
+	meta := record.Map()
+	delete(meta, Record.DataId)
+	delete(meta, Record.DataType)
+	delete(meta, Record.Data)
+	resource := map[string]interface{}{
+		JsonApiType:       record.Type,
+		JsonApiId:         record.Id,
+		JsonApiAttributes: attributes,
+		JsonApiMeta:       meta,
+		JsonApiLinks: map[string]interface{}{
+			JsonApiSelf: Http.ResourcePath(h.Config.Http, record.Type, record.Id),
+		},
+	}
+	if len(relationships) > 0 {
+		resource[JsonApiRelationships] = relationships
+	}
+	return map[string]interface{}{
+		JsonApiData: resource,
+	}, nil
+}
+
+// page of ids in JSON:API document, {"data": [{type, id}], "links": {self, next}}. next is only there when page is truncated
+func (h *Handler) JsonApiList(dataType string, idList []interface{}, next int) map[string]interface{} {
+	resources := make([]interface{}, 0, len(idList))
+	for _, id := range idList {
+		dataId := fmt.Sprintf("%v", id)
+		resources = append(resources, map[string]interface{}{
+			JsonApiType: dataType,
+			JsonApiId:   dataId,
+			JsonApiLinks: map[string]interface{}{
+				JsonApiSelf: Http.ResourcePath(h.Config.Http, dataType, dataId),
+			},
+		})
+	}
+	links := map[string]interface{}{
+		JsonApiSelf: Http.ResourcePath(h.Config.Http, dataType),
+	}
+	if next > 0 {
+		links[JsonApiNext] = fmt.Sprintf("%s?offset=%s", Http.ResourcePath(h.Config.Http, dataType), strconv.Itoa(next))
+	}
+	return map[string]interface{}{
+		JsonApiData:  resources,
+		JsonApiLinks: links,
+	}
+}
+
+// resource linkage of ref attribute, one identifier for single ref, list of identifiers for array or map.
+// identifier of map item keeps its key in meta, items are in order of key
+func jsonApiLinkage(ref *SchemaDoc.CMTDocRef, attrDef map[string]interface{}, value interface{}) (interface{}, *Http.HttpError) {
+	if value == nil {
+		return nil, nil
+	}
+	switch attrDef[JsonKey.Type] {
+	case JsonKey.Array:
+		refList := value.([]interface{})
+		linkage := make([]interface{}, 0, len(refList))
+		for _, refValue := range refList {
+			identifier, err := jsonApiIdentifier(ref, refValue)
+			if err != nil {
+				return nil, err
+			}
+			linkage = append(linkage, identifier)
+		}
+		return linkage, nil
+	case JsonKey.Map, JsonKey.Object:
+		refMap := value.(map[string]interface{})
+		keyList := make([]string, 0, len(refMap))
+		for key := range refMap {
+			keyList = append(keyList, key)
+		}
+		sort.Strings(keyList)
+		linkage := make([]interface{}, 0, len(refMap))
+		for _, key := range keyList {
+			identifier, err := jsonApiIdentifier(ref, refMap[key])
+			if err != nil {
+				return nil, err
+			}
+			identifier[JsonApiMeta] = map[string]interface{}{
+				JsonApiKey: key,
+			}
+			linkage = append(linkage, identifier)
+		}
+		return linkage, nil
+	default:
+		return jsonApiIdentifier(ref, value)
+	}
+}
+
+func jsonApiIdentifier(ref *SchemaDoc.CMTDocRef, value interface{}) (map[string]interface{}, *Http.HttpError) {
+	refValue, ok := value.(string)
+	if !ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("ref value [%v] is not a string", value), http.StatusInternalServerError)
+	}
+	dataType, dataId, ex := ref.Target(refValue)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to parse ref value [%s]", refValue), http.StatusInternalServerError)
+	}
+	return map[string]interface{}{
+		JsonApiType: dataType,
+		JsonApiId:   dataId,
+	}, nil
+}
@@ -0,0 +1,69 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+// record in data with names of attributes in its data renamed by wireKey, see SchemaDoc.WireKeys.
+// data that is not record of a type with schema is returned as it is, so is everything when wireKey is nil
+func (h *Handler) WireRecord(data interface{}, wireKey func(string) string, toStored bool) interface{} {
+	record, ok := data.(map[string]interface{})
+	if !ok || wireKey == nil {
+		return data
+	}
+	dataType, _ := record[Record.DataType].(string)
+	version, _ := record[Record.Version].(string)
+	recordData, ok := record[Record.Data].(map[string]interface{})
+	if !ok {
+		return data
+	}
+	result := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		result[key] = value
+	}
+	result[Record.Data] = h.WireData(dataType, version, recordData, wireKey, toStored)
+	return result
+}
+
+// data of record of dataType in version with names of attributes renamed by wireKey, current version when version is empty
+func (h *Handler) WireData(dataType string, version string, data map[string]interface{}, wireKey func(string) string, toStored bool) map[string]interface{} {
+	if wireKey == nil {
+		return data
+	}
+	if _, ok := Common.InternalTypes[dataType]; ok {
+		return data
+	}
+	schema, err := h.LocalSchema(dataType, version)
+	if err != nil {
+		// record of unknown type or version is rejected or reported by its own handling
+		return data
+	}
+	return schema.Schema.WireKeys(data, wireKey, toStored)
+}
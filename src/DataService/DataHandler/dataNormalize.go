@@ -0,0 +1,62 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/salesforce/UniTAO/lib/Schema"
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// record with data normalized to the schema of its version, see Schema.NormalizeData.
+// stored record stays as it is
+func (h *Handler) GetNormalized(dataType string, dataId string) (map[string]interface{}, *Http.HttpError) {
+	if dataType == JsonKey.Schema {
+		return nil, Http.NewHttpError(fmt.Sprintf("normalized view is not supported on type [%s]", dataType), http.StatusBadRequest)
+	}
+	data, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	record, ex := Record.LoadMap(data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to load data [%s/%s] as record", dataType, dataId), http.StatusInternalServerError)
+	}
+	schema, err := h.LocalSchema(record.Type, record.Version)
+	if err != nil {
+		return nil, err
+	}
+	normalized, ex := Schema.NormalizeData(schema.Schema, record.Data, fmt.Sprintf("%s/%s", record.Type, record.Id))
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to normalize [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+	}
+	record.Data = normalized
+	return record.Map(), nil
+}
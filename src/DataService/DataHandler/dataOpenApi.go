@@ -0,0 +1,72 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"net/http"
+	"strings"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/OpenApi"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const OpenApiTitle = "UniTAO DataService"
+
+// OpenAPI document of CRUD paths on each data type with loaded schema, described as of version of the service
+func (h *Handler) OpenApi(version string) (map[string]interface{}, *Http.HttpError) {
+	typeList, err := h.List(JsonKey.Schema)
+	if err != nil {
+		return nil, err
+	}
+	failed := h.FailedSchemas()
+	docs := make([]*SchemaDoc.SchemaDoc, 0, len(typeList))
+	for _, item := range typeList {
+		dataType := item.(string)
+		if dataType == JsonKey.Schema || strings.Contains(dataType, JsonKey.ArchivedSchemaIdDiv) {
+			continue
+		}
+		if _, ok := Common.InternalTypes[dataType]; ok {
+			continue
+		}
+		if _, ok := failed[dataType]; ok {
+			continue
+		}
+		schema, err := h.LocalSchema(dataType, "")
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, schema.Schema)
+	}
+	doc, ex := OpenApi.Generate(OpenApiTitle, version, h.Config.Http.BasePath, docs)
+	if ex != nil {
+		return nil, Http.WrapError(ex, "failed to generate OpenAPI document", http.StatusInternalServerError)
+	}
+	return doc, nil
+}
@@ -0,0 +1,134 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+	"github.com/salesforce/UniTAO/lib/Util/Json"
+)
+
+// merge patch into data of record as JSON Merge Patch, RFC 7386, and write it back.
+// null in patch removes the attribute. version in headers must match the record like PATCH
+func (h *Handler) MergePatch(dataType string, dataId string, headers map[string]interface{}, patch map[string]interface{}) (map[string]interface{}, *Http.HttpError) {
+	return h.patchData(dataType, dataId, headers, "merge patch", func(data map[string]interface{}) (interface{}, *Http.HttpError) {
+		return Json.MergePatch(data, patch), nil
+	})
+}
+
+// apply operations of JSON Patch, RFC 6902, on data of record and write it back.
+// any failed operation leaves record as it is, failed test operation gives 409
+func (h *Handler) JsonPatch(dataType string, dataId string, headers map[string]interface{}, ops []interface{}) (map[string]interface{}, *Http.HttpError) {
+	return h.patchData(dataType, dataId, headers, "json patch", func(data map[string]interface{}) (interface{}, *Http.HttpError) {
+		result, ex := Json.ApplyPatch(data, ops)
+		if errors.Is(ex, Json.ErrPatchTest) {
+			return nil, Http.WrapError(ex, fmt.Sprintf("json patch on [%s/%s] aborted", dataType, dataId), http.StatusConflict)
+		}
+		if ex != nil {
+			return nil, Http.WrapError(ex, fmt.Sprintf("failed to apply json patch on [%s/%s]", dataType, dataId), http.StatusBadRequest)
+		}
+		return result, nil
+	})
+}
+
+// replace data of record with what change makes of it under lock of the record, then validate and write it back
+func (h *Handler) patchData(dataType string, dataId string, headers map[string]interface{}, method string, change func(data map[string]interface{}) (interface{}, *Http.HttpError)) (map[string]interface{}, *Http.HttpError) {
+	if _, ok := Common.InternalTypes[dataType]; ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("%s on type[%s] is not allowed", method, dataType), http.StatusBadRequest)
+	}
+	_, err := h.LocalSchema(dataType, "")
+	if err != nil {
+		return nil, err
+	}
+	idKey := fmt.Sprintf("%s/%s", dataType, dataId)
+	h.Lock.Aquire(idKey, "HandlerPatchData")
+	defer h.Lock.Release(idKey, "HandlerPatchData")
+	data, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	before, ex := Record.LoadMap(data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to load data [%s/%s] as record", dataType, dataId), http.StatusInternalServerError)
+	}
+	record, _ := Record.LoadMap(data)
+	if version, ok := headers[JsonKey.Version]; ok && record.Version != version {
+		errMsg := fmt.Sprintf("current record:[%s/%s] version:[%s] does not match specified version:[%s]", dataType, dataId, record.Version, version)
+		return nil, Http.NewHttpError(errMsg, http.StatusNotModified)
+	}
+	err = matchETag(before, headers)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := change(record.Data)
+	if err != nil {
+		return nil, err
+	}
+	changedData, ok := changed.(map[string]interface{})
+	if !ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("%s on [%s/%s] does not leave data as JSON object", method, dataType, dataId), http.StatusBadRequest)
+	}
+	record.Data, ex = Json.CopyToMap(changedData)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to %s [%s/%s]", method, dataType, dataId), http.StatusInternalServerError)
+	}
+	isSame, err := h.CompareRecords(before, record)
+	if err != nil {
+		return nil, err
+	}
+	if isSame {
+		return record.Map(), nil
+	}
+	record.ModifiedBy = h.Actor(headers)
+	record.Tenant = h.Tenant(headers)
+	record.Modified = modifiedNow()
+	h.stampExpire(record)
+	err = h.validateAppendOnly(before, record)
+	if err != nil {
+		return nil, err
+	}
+	err = h.keepVersion(before, record)
+	if err != nil {
+		return nil, err
+	}
+	err = h.updateRecord(dataType, dataId, record, headers)
+	if err != nil {
+		return nil, err
+	}
+	h.Log(fmt.Sprintf("%s [%s/%s] complete", strings.ToUpper(method), dataType, dataId))
+	if h.AddJournal != nil {
+		h.AddJournal(dataType, dataId, before.Map(), record.Map())
+	}
+	return record.Map(), nil
+}
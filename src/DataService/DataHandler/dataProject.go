@@ -0,0 +1,214 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// projected attributes by name, nil subtree keeps the whole value of attribute
+type fieldTree map[string]fieldTree
+
+// get record with data cut down to given paths. each path is attribute names joined by [.], like itemArray.name
+// path that walks through a ref inlines the projected data of target record, like owner.name gives {"owner": {"name": ...}}
+// dangling ref on the path is projected as null
+func (h *Handler) GetProjected(dataType string, dataId string, fields []string) (map[string]interface{}, *Http.HttpError) {
+	tree, err := parseFieldTree(fields)
+	if err != nil {
+		return nil, err
+	}
+	data, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	record, ex := Record.LoadMap(data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+	}
+	schema, err := h.LocalSchema(record.Type, record.Version)
+	if err != nil {
+		return nil, err
+	}
+	projected, err := h.projectTree(schema.Schema, record.Data, tree, fmt.Sprintf("%s/%s", dataType, dataId))
+	if err != nil {
+		return nil, err
+	}
+	record.Data = projected
+	return record.Map(), nil
+}
+
+// merge field paths into one tree so each ref on the way is resolved once.
+// path that is a prefix of another keeps the whole value
+func parseFieldTree(fields []string) (fieldTree, *Http.HttpError) {
+	tree := fieldTree{}
+	for _, field := range fields {
+		attrList := strings.Split(field, ExpandPathDiv)
+		node := tree
+		for idx, attr := range attrList {
+			if attr == "" {
+				return nil, Http.NewHttpError(fmt.Sprintf("invalid field path [%s], empty attr", field), http.StatusBadRequest)
+			}
+			sub, ok := node[attr]
+			if ok && sub == nil {
+				break
+			}
+			if idx == len(attrList)-1 {
+				node[attr] = nil
+				break
+			}
+			if !ok {
+				sub = fieldTree{}
+				node[attr] = sub
+			}
+			node = sub
+		}
+	}
+	return tree, nil
+}
+
+func (h *Handler) projectTree(doc *SchemaDoc.SchemaDoc, data map[string]interface{}, tree fieldTree, dataPath string) (map[string]interface{}, *Http.HttpError) {
+	doc = doc.DocOf(data)
+	projected := make(map[string]interface{}, len(tree))
+	for attrName, subTree := range tree {
+		attrPath := fmt.Sprintf("%s/%s", dataPath, attrName)
+		attrDef, ok := doc.Properties()[attrName].(map[string]interface{})
+		if !ok {
+			return nil, Http.NewHttpError(fmt.Sprintf("attr [%s] not defined. @path=[%s]", attrName, dataPath), http.StatusBadRequest)
+		}
+		value, ok := data[attrName]
+		if !ok {
+			continue
+		}
+		if subTree == nil || value == nil {
+			projected[attrName] = value
+			continue
+		}
+		if ref, ok := doc.CmtRefs[attrName]; ok {
+			refValue, err := h.projectRefAttr(ref, attrDef, value, subTree, attrPath)
+			if err != nil {
+				return nil, err
+			}
+			projected[attrName] = refValue
+			continue
+		}
+		subDoc, ok := doc.SubDocs[attrName]
+		if !ok {
+			return nil, Http.NewHttpError(fmt.Sprintf("attr [%s] has no sub document to walk in. @path=[%s]", attrName, dataPath), http.StatusBadRequest)
+		}
+		switch attrDef[JsonKey.Type] {
+		case JsonKey.Array:
+			itemList := value.([]interface{})
+			projectedList := make([]interface{}, 0, len(itemList))
+			for idx, item := range itemList {
+				projectedItem, err := h.projectTree(subDoc, item.(map[string]interface{}), subTree, fmt.Sprintf("%s[%d]", attrPath, idx))
+				if err != nil {
+					return nil, err
+				}
+				projectedList = append(projectedList, projectedItem)
+			}
+			projected[attrName] = projectedList
+		case JsonKey.Object:
+			if !SchemaDoc.IsMap(attrDef) {
+				projectedItem, err := h.projectTree(subDoc, value.(map[string]interface{}), subTree, attrPath)
+				if err != nil {
+					return nil, err
+				}
+				projected[attrName] = projectedItem
+				continue
+			}
+			itemMap := value.(map[string]interface{})
+			projectedMap := make(map[string]interface{}, len(itemMap))
+			for key, item := range itemMap {
+				projectedItem, err := h.projectTree(subDoc, item.(map[string]interface{}), subTree, fmt.Sprintf("%s[%s]", attrPath, key))
+				if err != nil {
+					return nil, err
+				}
+				projectedMap[key] = projectedItem
+			}
+			projected[attrName] = projectedMap
+		}
+	}
+	return projected, nil
+}
+
+// project each ref value of attribute in the same shape as the attribute, single value, array or map
+func (h *Handler) projectRefAttr(ref *SchemaDoc.CMTDocRef, attrDef map[string]interface{}, value interface{}, tree fieldTree, attrPath string) (interface{}, *Http.HttpError) {
+	switch attrDef[JsonKey.Type] {
+	case JsonKey.Array:
+		refList := value.([]interface{})
+		projectedList := make([]interface{}, 0, len(refList))
+		for _, refId := range refList {
+			refData, err := h.projectRef(ref, refId.(string), tree, attrPath)
+			if err != nil {
+				return nil, err
+			}
+			projectedList = append(projectedList, refData)
+		}
+		return projectedList, nil
+	case JsonKey.Object:
+		refMap := value.(map[string]interface{})
+		projectedMap := make(map[string]interface{}, len(refMap))
+		for key, refId := range refMap {
+			refData, err := h.projectRef(ref, refId.(string), tree, fmt.Sprintf("%s[%s]", attrPath, key))
+			if err != nil {
+				return nil, err
+			}
+			projectedMap[key] = refData
+		}
+		return projectedMap, nil
+	default:
+		return h.projectRef(ref, value.(string), tree, attrPath)
+	}
+}
+
+// projected data of ref target, resolved through the same connection SchemaPath walks refs with.
+// nil when target does not exist
+func (h *Handler) projectRef(ref *SchemaDoc.CMTDocRef, refId string, tree fieldTree, dataPath string) (interface{}, *Http.HttpError) {
+	dataType, dataId, ex := ref.Target(refId)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("invalid ref @path=[%s]", dataPath), http.StatusBadRequest)
+	}
+	conn := h.Connection()
+	record, err := conn.FuncRecord(dataType, dataId)
+	if err != nil {
+		if err.Status == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, Http.WrapError(err, fmt.Sprintf("failed to get ref [%s/%s] @path=[%s]", dataType, dataId, dataPath), err.Status)
+	}
+	doc, err := conn.FuncSchema(record.Type)
+	if err != nil {
+		return nil, Http.WrapError(err, fmt.Sprintf("failed to get schema of ref [%s/%s] @path=[%s]", dataType, dataId, dataPath), err.Status)
+	}
+	return h.projectTree(doc, record.Data, tree, fmt.Sprintf("%s/%s", dataType, dataId))
+}
@@ -0,0 +1,64 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"Data/DbIface"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// record as stored in database, byte for byte, when database keeps the bytes.
+// otherwise record is serialized again and raw is false
+func (h *Handler) GetRaw(dataType string, dataId string) ([]byte, bool, *Http.HttpError) {
+	record, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, false, err
+	}
+	if getter, ok := h.DB.(DbIface.RawGetter); ok {
+		data, ex := getter.GetRaw(map[string]interface{}{
+			DbIface.Table:   h.Config.DataTable.Data,
+			Record.DataType: dataType,
+			Record.DataId:   dataId,
+		})
+		if ex != nil {
+			return nil, false, Http.WrapError(ex, fmt.Sprintf("failed to get raw data of [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+		}
+		if data != nil {
+			return data, true, nil
+		}
+	}
+	data, ex := json.Marshal(record)
+	if ex != nil {
+		return nil, false, Http.WrapError(ex, fmt.Sprintf("failed to serialize data of [%s/%s]", dataType, dataId), http.StatusInternalServerError)
+	}
+	return data, false, nil
+}
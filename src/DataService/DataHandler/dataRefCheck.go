@@ -0,0 +1,85 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	SchemaPathData "github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// how ref values of one write are checked. targets are fetched through one connection,
+// so record and schema referred by many values are loaded once
+type refCheck struct {
+	mode string
+	conn *SchemaPathData.Connection
+}
+
+// mode from X-Ref-Check header of the request, configured refCheck when header not found
+func (h *Handler) newRefCheck(headers map[string]interface{}) (*refCheck, *Http.HttpError) {
+	mode := h.Config.Schema.RefCheck
+	if value, ok := headers[strings.ToLower(Common.HeaderRefCheck)].(string); ok && value != "" {
+		mode = value
+	}
+	switch mode {
+	case "":
+		mode = Common.RefResolve
+	case Common.RefResolve, Common.RefStructure, Common.RefNone:
+	default:
+		return nil, Http.NewHttpError(fmt.Sprintf("invalid ref check [%s], expect one of [%s, %s, %s]", mode, Common.RefResolve, Common.RefStructure, Common.RefNone), http.StatusBadRequest)
+	}
+	conn := h.Connection()
+	conn.SchemaCache = map[string]*SchemaDoc.SchemaDoc{}
+	return &refCheck{
+		mode: mode,
+		conn: conn,
+	}, nil
+}
+
+// value at refPath of target record, walked through cached connection of the check
+func (c *refCheck) walk(dataType string, dataId string, refPath string) (interface{}, *Http.HttpError) {
+	dataPath := fmt.Sprintf("%s/%s", Util.EscapeKey(dataId), refPath)
+	query, err := SchemaPath.CreateQuery(c.conn, dataType, dataPath)
+	if err != nil {
+		return nil, err
+	}
+	result, err := query.WalkValue()
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, Http.NewHttpError(fmt.Sprintf("walk SchemaPath with no value.from [path]=[%s]", dataPath), http.StatusNotFound)
+	}
+	return result, nil
+}
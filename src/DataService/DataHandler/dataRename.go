@@ -0,0 +1,126 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"DataService/Common"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// move record of dataType from oldId to newId, then rewrite refs pointing at oldId to newId.
+// return refs rewritten in referrer records
+func (h *Handler) Rename(dataType string, oldId string, newId string) ([]SchemaPath.RecordRef, *Http.HttpError) {
+	if _, ok := Common.InternalTypes[dataType]; ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("rename on type[%s] is not allowed", dataType), http.StatusBadRequest)
+	}
+	if oldId == "" || newId == "" {
+		return nil, Http.NewHttpError(fmt.Sprintf("invalid rename [%s/%s]->[%s], id cannot be empty", dataType, oldId, newId), http.StatusBadRequest)
+	}
+	if oldId == newId {
+		return nil, Http.NewHttpError(fmt.Sprintf("new id is the same as current id [%s/%s]", dataType, oldId), http.StatusBadRequest)
+	}
+	_, err := h.LocalSchema(dataType, "")
+	if err != nil {
+		return nil, err
+	}
+	err = h.moveRecord(dataType, oldId, newId)
+	if err != nil {
+		return nil, err
+	}
+	h.Log(fmt.Sprintf("HandlerRename: rewrite refs [%s/%s]->[%s/%s]", dataType, oldId, dataType, newId))
+	refs, err := SchemaPath.RewriteRefs(h.Connection(), fmt.Sprintf("%s/%s", dataType, oldId), fmt.Sprintf("%s/%s", dataType, newId), false)
+	if err != nil {
+		return refs, Http.WrapError(err, fmt.Sprintf("record renamed to [%s/%s], failed to rewrite refs", dataType, newId), err.Status)
+	}
+	return refs, nil
+}
+
+func (h *Handler) moveRecord(dataType string, oldId string, newId string) *Http.HttpError {
+	// lock both ids in fixed order, so renames in opposite direction do not dead lock
+	idKeys := []string{fmt.Sprintf("%s/%s", dataType, oldId), fmt.Sprintf("%s/%s", dataType, newId)}
+	sort.Strings(idKeys)
+	for _, idKey := range idKeys {
+		h.Lock.Aquire(idKey, "HandlerRename")
+		defer h.Lock.Release(idKey, "HandlerRename")
+	}
+	data, err := h.LocalData(dataType, oldId)
+	if err != nil {
+		return err
+	}
+	recordList, err := h.QueryDb(dataType, newId)
+	if err != nil {
+		return err
+	}
+	if len(recordList) > 0 {
+		return Http.NewHttpError(fmt.Sprintf("data [type/id]=[%s/%s] already exists", dataType, newId), http.StatusConflict)
+	}
+	before, ex := Record.LoadMap(data)
+	if ex != nil {
+		return Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%s/%s]", dataType, oldId), http.StatusInternalServerError)
+	}
+	record, ex := Record.LoadMap(data)
+	if ex != nil {
+		return Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%s/%s]", dataType, oldId), http.StatusInternalServerError)
+	}
+	record.Id = newId
+	err = h.Validate(record)
+	if err != nil {
+		return err
+	}
+	h.Log(fmt.Sprintf("HandlerRename: create [%s/%s]", dataType, newId))
+	e := h.DB.Create(h.Config.DataTable.Data, record.Map())
+	if e != nil {
+		return Http.WrapError(e, fmt.Sprintf("failed to create record [%s/%s]", dataType, newId), http.StatusInternalServerError)
+	}
+	h.Log(fmt.Sprintf("HandlerRename: delete [%s/%s]", dataType, oldId))
+	e = h.DB.Delete(h.Config.DataTable.Data, map[string]interface{}{
+		Record.DataType: dataType,
+		Record.DataId:   oldId,
+	})
+	if e != nil {
+		h.Log(fmt.Sprintf("HandlerRename: failed to delete [%s/%s], roll back [%s/%s]", dataType, oldId, dataType, newId))
+		h.DB.Delete(h.Config.DataTable.Data, map[string]interface{}{
+			Record.DataType: dataType,
+			Record.DataId:   newId,
+		})
+		return Http.WrapError(e, fmt.Sprintf("failed to delete record [%s/%s]", dataType, oldId), http.StatusInternalServerError)
+	}
+	err = h.moveHistory(dataType, oldId, newId)
+	if err != nil {
+		h.Log(err.Error())
+	}
+	if h.AddJournal != nil {
+		h.AddJournal(dataType, newId, nil, record.Map())
+		h.AddJournal(dataType, oldId, before.Map(), nil)
+	}
+	return nil
+}
@@ -0,0 +1,447 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/CmtIndex"
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+	"github.com/salesforce/UniTAO/lib/Util/Json"
+)
+
+// what retype of a data type changes, or has changed when it is not a dry run
+type RetypeReport struct {
+	OldType    string                 `json:"oldType"`
+	NewType    string                 `json:"newType"`
+	DryRun     bool                   `json:"dryRun"`
+	Schemas    []string               `json:"schemas"`    // schema ids moved to new type, archived versions included
+	Records    []string               `json:"records"`    // ids of records moved to new type
+	RefSchemas []string               `json:"refSchemas"` // schemas that have ref or extends rewritten to new type
+	Refs       []SchemaPath.RecordRef `json:"refs"`       // polymorphic ref values rewritten to new type
+}
+
+// one write of retype, before is nil for create and after is nil for delete
+type retypeWrite struct {
+	before *Record.Record
+	after  *Record.Record
+}
+
+// move schema and all records of oldType to newType, and rewrite schemas and refs that point at oldType.
+// all changes are planned before any write, records written are locked until retype is done.
+// writes done are rolled back when one of them fails.
+// with dryRun, return the report of planned changes without any write
+func (h *Handler) Retype(oldType string, newType string, dryRun bool) (*RetypeReport, *Http.HttpError) {
+	for _, dataType := range []string{oldType, newType} {
+		if dataType == "" {
+			return nil, Http.NewHttpError(fmt.Sprintf("invalid retype [%s]->[%s], type cannot be empty", oldType, newType), http.StatusBadRequest)
+		}
+		if _, ok := Common.InternalTypes[dataType]; ok {
+			return nil, Http.NewHttpError(fmt.Sprintf("retype on type[%s] is not allowed", dataType), http.StatusBadRequest)
+		}
+	}
+	if oldType == newType {
+		return nil, Http.NewHttpError(fmt.Sprintf("new type is the same as current type [%s]", oldType), http.StatusBadRequest)
+	}
+	for _, c := range JsonKey.InvalidKeyChars {
+		if strings.Contains(newType, c) {
+			return nil, Http.NewHttpError(fmt.Sprintf("invalid char [%s] in new type [%s]", c, newType), http.StatusBadRequest)
+		}
+	}
+	if strings.Contains(newType, JsonKey.ArchivedSchemaIdDiv) {
+		return nil, Http.NewHttpError(fmt.Sprintf("invalid [%s] in new type [%s], it divides archived schema version", JsonKey.ArchivedSchemaIdDiv, newType), http.StatusBadRequest)
+	}
+	if h.IsView(newType) {
+		return nil, Http.NewHttpError(fmt.Sprintf("new type [%s] is a view", newType), http.StatusConflict)
+	}
+	for name, view := range h.views {
+		if view.Type == oldType {
+			return nil, Http.NewHttpError(fmt.Sprintf("view [%s] is based on type [%s], change config of views first", name, oldType), http.StatusConflict)
+		}
+	}
+	// lock both types in fixed order, so retypes in opposite direction do not dead lock
+	typeKeys := []string{fmt.Sprintf("%s/%s", JsonKey.Schema, oldType), fmt.Sprintf("%s/%s", JsonKey.Schema, newType)}
+	sort.Strings(typeKeys)
+	for _, typeKey := range typeKeys {
+		h.Lock.Aquire(typeKey, "HandlerRetype")
+		defer h.Lock.Release(typeKey, "HandlerRetype")
+	}
+	_, err := h.LocalSchema(oldType, "")
+	if err != nil {
+		return nil, err
+	}
+	schemaList, err := h.QueryDb(JsonKey.Schema, newType)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemaList) > 0 {
+		return nil, Http.NewHttpError(fmt.Sprintf("schema of new type [%s] already exists", newType), http.StatusConflict)
+	}
+	writes, report, err := h.planRetype(oldType, newType)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = dryRun
+	if dryRun {
+		return report, nil
+	}
+	// lock records the plan writes, then plan again with them locked so no write of them slips in before retype
+	keyList := retypeKeys(writes, typeKeys)
+	for _, idKey := range keyList {
+		h.Lock.Aquire(idKey, "HandlerRetype")
+		defer h.Lock.Release(idKey, "HandlerRetype")
+	}
+	writes, report, err = h.planRetype(oldType, newType)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Join(retypeKeys(writes, typeKeys), ",") != strings.Join(keyList, ",") {
+		return nil, Http.NewHttpError(fmt.Sprintf("records to retype [%s]->[%s] changed while it was planned, try again", oldType, newType), http.StatusConflict)
+	}
+	err = h.applyRetype(writes)
+	if err != nil {
+		return nil, err
+	}
+	for _, write := range writes {
+		if write.after != nil && write.after.Type == JsonKey.Schema {
+			h.SetLocalSchema(write.after.Id, nil)
+		}
+		if write.before != nil && write.before.Type == JsonKey.Schema {
+			h.SetLocalSchema(write.before.Id, nil)
+		}
+	}
+	return report, nil
+}
+
+func (h *Handler) planRetype(oldType string, newType string) ([]retypeWrite, *RetypeReport, *Http.HttpError) {
+	report := RetypeReport{
+		OldType:    oldType,
+		NewType:    newType,
+		Schemas:    []string{},
+		Records:    []string{},
+		RefSchemas: []string{},
+		Refs:       []SchemaPath.RecordRef{},
+	}
+	creates := []retypeWrite{}
+	updates := []retypeWrite{}
+	deletes := []retypeWrite{}
+	move := func(before *Record.Record, after *Record.Record) {
+		creates = append(creates, retypeWrite{after: after})
+		deletes = append(deletes, retypeWrite{before: before})
+	}
+	// schemas of old type move, other schemas with ref or extends to old type are rewritten
+	refTypes := map[string]bool{}
+	schemaList, err := h.QueryDb(JsonKey.Schema, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, data := range schemaList {
+		if data[Record.DataId] == Record.KeyRecord {
+			continue
+		}
+		before, after, err := copyRecord(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		schemaType, schemaVer := Util.ParseCustomPath(before.Id, JsonKey.ArchivedSchemaIdDiv)
+		if _, ok := Common.InternalTypes[schemaType]; ok {
+			continue
+		}
+		changed := retypeSchemaData(after.Data, oldType, newType)
+		if schemaType != oldType {
+			if changed {
+				updates = append(updates, retypeWrite{before: before, after: after})
+				report.RefSchemas = append(report.RefSchemas, before.Id)
+				refTypes[schemaType] = true
+			}
+			continue
+		}
+		after.Id = newType
+		if schemaVer != "" {
+			after.Id = SchemaDoc.ArchivedSchemaId(newType, schemaVer)
+		}
+		after.Data[JsonKey.Name] = newType
+		move(before, after)
+		report.Schemas = append(report.Schemas, before.Id)
+	}
+	// records of old type move, polymorphic refs in them to old type are rewritten as well
+	recordList, err := h.QueryDb(oldType, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, data := range recordList {
+		before, after, err := copyRecord(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		refs, err := h.retypeRecordRefs(after, oldType, newType)
+		if err != nil {
+			return nil, nil, err
+		}
+		after.Type = newType
+		move(before, after)
+		report.Records = append(report.Records, before.Id)
+		report.Refs = append(report.Refs, refs...)
+	}
+	refTypeList := make([]string, 0, len(refTypes))
+	for refType := range refTypes {
+		refTypeList = append(refTypeList, refType)
+	}
+	sort.Strings(refTypeList)
+	for _, refType := range refTypeList {
+		recordList, err := h.QueryDb(refType, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, data := range recordList {
+			before, after, err := copyRecord(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			refs, err := h.retypeRecordRefs(after, oldType, newType)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(refs) > 0 {
+				updates = append(updates, retypeWrite{before: before, after: after})
+				report.Refs = append(report.Refs, refs...)
+			}
+		}
+	}
+	// cmtIdx of old type moves, subscriber of old type in cmtIdx of other types is renamed
+	idxList, err := h.QueryDb(CmtIndex.KeyCmtIdx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, data := range idxList {
+		before, after, err := copyRecord(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		idx, ex := CmtIndex.LoadMap(after.Data)
+		if ex != nil {
+			return nil, nil, Http.WrapError(ex, fmt.Sprintf("failed to load %s/%s", CmtIndex.KeyCmtIdx, before.Id), http.StatusInternalServerError)
+		}
+		subscriber, subscribed := idx.Subscriber[oldType]
+		if subscribed {
+			delete(idx.Subscriber, oldType)
+			subscriber.DataType = newType
+			idx.Subscriber[newType] = subscriber
+		}
+		if before.Id == oldType {
+			idx.DataType = newType
+			after.Id = newType
+			after.Data = idx.Map()
+			move(before, after)
+			continue
+		}
+		after.Data = idx.Map()
+		if subscribed {
+			updates = append(updates, retypeWrite{before: before, after: after})
+		}
+	}
+	sort.Strings(report.Schemas)
+	sort.Strings(report.Records)
+	sort.Strings(report.RefSchemas)
+	writes := append(creates, updates...)
+	writes = append(writes, deletes...)
+	return writes, &report, nil
+}
+
+// rewrite polymorphic refs to oldType in record with schema of its version
+func (h *Handler) retypeRecordRefs(record *Record.Record, oldType string, newType string) ([]SchemaPath.RecordRef, *Http.HttpError) {
+	schema, err := h.LocalSchema(record.Type, record.Version)
+	if err != nil {
+		return nil, err
+	}
+	refs := []SchemaPath.RecordRef{}
+	for _, attrPath := range SchemaPath.RetypeDocRefs(schema.Schema, record.Data, oldType, newType) {
+		refs = append(refs, SchemaPath.RecordRef{
+			DataType: record.Type,
+			DataId:   record.Id,
+			AttrPath: attrPath,
+		})
+	}
+	return refs, nil
+}
+
+func (h *Handler) applyRetype(writes []retypeWrite) *Http.HttpError {
+	table := h.Config.DataTable.Data
+	for idx, write := range writes {
+		var e error
+		switch {
+		case write.before == nil:
+			h.Log(fmt.Sprintf("HandlerRetype: create [%s/%s]", write.after.Type, write.after.Id))
+			e = h.DB.Create(table, write.after.Map())
+		case write.after == nil:
+			h.Log(fmt.Sprintf("HandlerRetype: delete [%s/%s]", write.before.Type, write.before.Id))
+			e = h.DB.Delete(table, recordKeys(write.before))
+		default:
+			h.Log(fmt.Sprintf("HandlerRetype: update [%s/%s]", write.before.Type, write.before.Id))
+			e = h.DB.Replace(table, recordKeys(write.before), write.after.Map())
+		}
+		if e == nil {
+			continue
+		}
+		h.Log(fmt.Sprintf("HandlerRetype: failed at write [%d], roll back", idx))
+		undoErrs := []string{}
+		for undoIdx := idx - 1; undoIdx >= 0; undoIdx-- {
+			undo := writes[undoIdx]
+			var undoErr error
+			switch {
+			case undo.before == nil:
+				undoErr = h.DB.Delete(table, recordKeys(undo.after))
+			case undo.after == nil:
+				undoErr = h.DB.Create(table, undo.before.Map())
+			default:
+				undoErr = h.DB.Replace(table, recordKeys(undo.before), undo.before.Map())
+			}
+			if undoErr != nil {
+				h.Log(fmt.Sprintf("HandlerRetype: failed to roll back write [%d]. Error: %s", undoIdx, undoErr))
+				undoErrs = append(undoErrs, fmt.Sprintf("write[%d]: %s", undoIdx, undoErr))
+			}
+		}
+		if len(undoErrs) > 0 {
+			return Http.WrapError(e, fmt.Sprintf("failed to retype, roll back incomplete, failed to undo %s", undoErrs), http.StatusInternalServerError)
+		}
+		return Http.WrapError(e, "failed to retype, changes rolled back", http.StatusInternalServerError)
+	}
+	if h.AddJournal != nil {
+		for _, write := range writes {
+			switch {
+			case write.before == nil:
+				h.AddJournal(write.after.Type, write.after.Id, nil, write.after.Map())
+			case write.after == nil:
+				h.AddJournal(write.before.Type, write.before.Id, write.before.Map(), nil)
+			default:
+				h.AddJournal(write.before.Type, write.before.Id, write.before.Map(), write.after.Map())
+			}
+		}
+	}
+	return nil
+}
+
+// lock keys of records written by retype in fixed order, without keys in held
+func retypeKeys(writes []retypeWrite, held []string) []string {
+	found := map[string]bool{}
+	for _, idKey := range held {
+		found[idKey] = true
+	}
+	keyList := []string{}
+	for _, write := range writes {
+		for _, record := range []*Record.Record{write.before, write.after} {
+			if record == nil {
+				continue
+			}
+			idKey := fmt.Sprintf("%s/%s", record.Type, record.Id)
+			if !found[idKey] {
+				found[idKey] = true
+				keyList = append(keyList, idKey)
+			}
+		}
+	}
+	sort.Strings(keyList)
+	return keyList
+}
+
+func recordKeys(record *Record.Record) map[string]interface{} {
+	return map[string]interface{}{
+		Record.DataType: record.Type,
+		Record.DataId:   record.Id,
+	}
+}
+
+// record loaded from data twice, so the second one can be changed without touching the first
+func copyRecord(data map[string]interface{}) (*Record.Record, *Record.Record, *Http.HttpError) {
+	before, ex := Record.LoadMap(data)
+	if ex != nil {
+		return nil, nil, Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%v/%v]", data[Record.DataType], data[Record.DataId]), http.StatusInternalServerError)
+	}
+	dataCopy, ex := Json.Copy(data)
+	if ex != nil {
+		return nil, nil, Http.WrapError(ex, fmt.Sprintf("failed to copy record [%s/%s]", before.Type, before.Id), http.StatusInternalServerError)
+	}
+	after, ex := Record.LoadMap(dataCopy.(map[string]interface{}))
+	if ex != nil {
+		return nil, nil, Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%s/%s]", before.Type, before.Id), http.StatusInternalServerError)
+	}
+	return before, after, nil
+}
+
+// rewrite oldType in [contentMediaType] and [extends] of schema data, return true when anything changed
+func retypeSchemaData(data interface{}, oldType string, newType string) bool {
+	changed := false
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			switch key {
+			case JsonKey.ContentMediaType:
+				cmt, ok := item.(string)
+				if !ok {
+					continue
+				}
+				cmtType, typeStr := Util.ParsePath(cmt)
+				if cmtType != JsonKey.Inventory {
+					continue
+				}
+				typeList := strings.Split(typeStr, JsonKey.ContentTypeDiv)
+				for idx, dataType := range typeList {
+					if dataType == oldType {
+						typeList[idx] = newType
+						changed = true
+					}
+				}
+				value[key] = fmt.Sprintf("%s/%s", cmtType, strings.Join(typeList, JsonKey.ContentTypeDiv))
+			case JsonKey.Extends:
+				if item == oldType {
+					value[key] = newType
+					changed = true
+				}
+			default:
+				if retypeSchemaData(item, oldType, newType) {
+					changed = true
+				}
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if retypeSchemaData(item, oldType, newType) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
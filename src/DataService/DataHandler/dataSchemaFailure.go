@@ -0,0 +1,75 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// load every schema once, a schema that fails is logged and its type answers 503 until it is fixed
+func (h *Handler) CheckSchemas() {
+	typeList, err := h.List(JsonKey.Schema)
+	if err != nil {
+		h.Log(fmt.Sprintf("failed to list schemas, Error: %s", err))
+		return
+	}
+	for _, item := range typeList {
+		dataType := item.(string)
+		if _, ok := Common.InternalTypes[dataType]; ok {
+			continue
+		}
+		_, err := h.querySchema(dataType)
+		if err != nil {
+			h.Log(fmt.Sprintf("schema of type [%s] failed to load, skipped. Error: %s", dataType, err))
+		}
+	}
+}
+
+// copy of data types whose schema failed to load with the error of the last attempt
+func (h *Handler) FailedSchemas() map[string]string {
+	h.schemaLock.RLock()
+	defer h.schemaLock.RUnlock()
+	result := make(map[string]string, len(h.failedSchemas))
+	for dataType, errMsg := range h.failedSchemas {
+		result[dataType] = errMsg
+	}
+	return result
+}
+
+// record the failure and answer 503 so the broken type does not look like a server error
+func (h *Handler) schemaFailed(dataType string, err *Http.HttpError) *Http.HttpError {
+	h.schemaLock.Lock()
+	h.failedSchemas[dataType] = strings.Join(append(append([]string{}, err.Message...), err.Context...), " ")
+	h.schemaLock.Unlock()
+	return Http.WrapError(err, fmt.Sprintf("schema of type [%s] failed to load", dataType), http.StatusServiceUnavailable)
+}
@@ -0,0 +1,154 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema"
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+const (
+	DefaultRefUrlTimeout   = 10
+	DefaultRefUrlMaxSize   = 1 << 20
+	DefaultRefUrlCacheTtl  = 300
+	DefaultRefUrlCacheSize = 100
+)
+
+// schema document of external ref. url is fetched over http and kept,
+// anything else is id of schema in local schema store
+func (h *Handler) fetchSchema(schemaId string) (map[string]interface{}, error) {
+	if strings.HasPrefix(schemaId, "http://") || strings.HasPrefix(schemaId, "https://") {
+		if !h.urlAllowed(schemaId) {
+			return nil, fmt.Errorf("schema url [%s] is not allowed, see schema.refUrl.allow of config", schemaId)
+		}
+		return h.fetchUrl(schemaId)
+	}
+	data, err := h.LocalData(JsonKey.Schema, schemaId)
+	if err != nil {
+		return nil, err
+	}
+	schemaData, ok := data[Record.Data].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid schema record [%s], missing [%s]", schemaId, Record.Data)
+	}
+	return schemaData, nil
+}
+
+// url matches an allowed prefix with same scheme and host, path of url under path of prefix
+func (h *Handler) urlAllowed(schemaUrl string) bool {
+	target, err := url.Parse(schemaUrl)
+	if err != nil || target.User != nil {
+		return false
+	}
+	for _, prefix := range h.Config.Schema.RefUrl.Allow {
+		allowed, err := url.Parse(prefix)
+		if err != nil {
+			continue
+		}
+		if !strings.EqualFold(target.Scheme, allowed.Scheme) || !strings.EqualFold(target.Host, allowed.Host) {
+			continue
+		}
+		if strings.HasPrefix(target.Path, allowed.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch url with timeout and size limit, keep fetched documents in a bounded cache
+func (h *Handler) urlFetcher() Schema.SchemaFetcher {
+	cfg := h.Config.Schema.RefUrl
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRefUrlTimeout
+	}
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultRefUrlMaxSize
+	}
+	cacheTtl := cfg.CacheTtl
+	if cacheTtl <= 0 {
+		cacheTtl = DefaultRefUrlCacheTtl
+	}
+	cacheSize := cfg.CacheSize
+	if cacheSize <= 0 {
+		cacheSize = DefaultRefUrlCacheSize
+	}
+	client := &http.Client{
+		Timeout: time.Duration(timeout) * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !h.urlAllowed(req.URL.String()) {
+				return fmt.Errorf("redirect to [%s] is not allowed", req.URL)
+			}
+			return nil
+		},
+	}
+	fetch := func(schemaUrl string) (map[string]interface{}, error) {
+		return fetchUrlSchema(client, schemaUrl, maxSize)
+	}
+	return Schema.CachedFetcher(fetch, time.Duration(cacheTtl)*time.Second, cacheSize)
+}
+
+// url can serve schema record or schema data directly
+func fetchUrlSchema(client *http.Client, schemaUrl string, maxSize int64) (map[string]interface{}, error) {
+	response, err := client.Get(schemaUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema from url=[%s], Err:%s", schemaUrl, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("invalid response from url=[%s], status=[%d]", schemaUrl, response.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema from url=[%s], Err:%s", schemaUrl, err)
+	}
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("schema from url=[%s] is larger than %d bytes", schemaUrl, maxSize)
+	}
+	data := map[string]interface{}{}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema from url=[%s], expect object. Err:%s", schemaUrl, err)
+	}
+	if data[Record.DataType] == JsonKey.Schema {
+		schemaData, ok := data[Record.Data].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid schema record from url=[%s], missing [%s]", schemaUrl, Record.Data)
+		}
+		return schemaData, nil
+	}
+	return data, nil
+}
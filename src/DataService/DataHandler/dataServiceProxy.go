@@ -252,29 +252,8 @@ func (i *DataServiceProxy) Get(dataType string, dataId string) (*Record.Record,
 		return nil, err
 	}
 	if isLocal {
-		if dataType == JsonKey.Schema {
-			schemaId, schemaVer := Util.ParsePath(dataId)
-			schema, err := i.handler.LocalSchema(schemaId, schemaVer)
-			if err != nil {
-				i.Log(fmt.Sprintf("failed to get local schema [%s/%s]", schemaId, schemaVer))
-				return nil, err
-			}
-			return schema.Record, nil
-		}
 		i.Log(fmt.Sprintf("%s/%s is local data", dataType, dataId))
-		data, err := i.handler.LocalData(dataType, dataId)
-		if err != nil {
-			i.Log(fmt.Sprintf("local GET failed. [%s/%s]", dataType, dataId))
-			i.Log(err.Error())
-			return nil, err
-		}
-		record, ex := Record.LoadMap(data)
-		if ex != nil {
-			i.Log(fmt.Sprintf("local data load as record failed. [%s/%s]", dataType, dataId))
-			i.Log(ex.Error())
-			return nil, Http.WrapError(ex, "failed to load data as Record", http.StatusInternalServerError)
-		}
-		return record, nil
+		return i.handler.localRecord(dataType, dataId)
 	}
 	i.Log(fmt.Sprintf("%s/%s is not local data", dataType, dataId))
 	queryUrl, err := i.getIdUrl(dataType, dataId)
@@ -367,7 +346,7 @@ func (i *DataServiceProxy) Patch(dataType string, dataId string, dataPath string
 		return err
 	}
 	if isLocal {
-		idPath := fmt.Sprintf("%s/%s", dataId, dataPath)
+		idPath := fmt.Sprintf("%s/%s", Util.EscapeKey(dataId), dataPath)
 		_, err := i.handler.Patch(dataType, idPath, headers, data)
 		if err != nil {
 			return err
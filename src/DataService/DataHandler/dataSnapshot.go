@@ -0,0 +1,148 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const DefaultSnapshotTtl = 300
+
+// ids of a type captured when first page of its list is truncated.
+// pages with the token are cut from these ids, so writes after the capture do not shift them
+type listSnapshot struct {
+	dataType string
+	idList   []interface{}
+	expireAt time.Time
+}
+
+// one page of id list, Next is offset of the page after it, 0 when no more
+type IdPage struct {
+	Items []interface{} `json:"items"`
+	Total int           `json:"total"`
+	Next  int           `json:"next,omitempty"`
+}
+
+// page of ids as ListPage does, with a snapshot token to keep paging a stable view.
+// without token, first page that is truncated captures ids of the type and returns a new token.
+// with token, page is cut from ids captured by the token, records may have changed or gone since
+func (h *Handler) ListSnapshot(dataType string, token string, offset int) ([]interface{}, int, string, *Http.HttpError) {
+	page, token, err := h.ListRange(dataType, token, offset, 0, nil)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	return page.Items, page.Next, token, nil
+}
+
+// page of ids like ListSnapshot with at most limit ids, limit 0 means no limit of its own.
+// page is never longer than config MaxListSize. with filters only ids of ListWhere are paged,
+// snapshot captured then keeps the filtered ids
+func (h *Handler) ListRange(dataType string, token string, offset int, limit int, filters []string) (*IdPage, string, *Http.HttpError) {
+	if offset < 0 {
+		return nil, "", Http.NewHttpError(fmt.Sprintf("invalid offset=[%d], expect non-negative integer", offset), http.StatusBadRequest)
+	}
+	if limit < 0 {
+		return nil, "", Http.NewHttpError(fmt.Sprintf("invalid limit=[%d], expect non-negative integer", limit), http.StatusBadRequest)
+	}
+	pageSize := h.Config.List.MaxListSize
+	if limit > 0 && (pageSize <= 0 || limit < pageSize) {
+		pageSize = limit
+	}
+	var idList []interface{}
+	if token == "" {
+		list, err := h.List(dataType)
+		if len(filters) > 0 {
+			list, err = h.ListWhere(dataType, filters)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if offset == 0 && pageSize <= 0 {
+			return &IdPage{Items: list, Total: len(list)}, "", nil
+		}
+		// ids are sorted so offset is stable between requests
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].(string) < list[j].(string)
+		})
+		idList = list
+	} else {
+		snapshot, err := h.getSnapshot(token)
+		if err != nil {
+			return nil, "", err
+		}
+		if snapshot.dataType != dataType {
+			return nil, "", Http.NewHttpError(fmt.Sprintf("snapshot [%s] is not a list of type [%s]", token, dataType), http.StatusBadRequest)
+		}
+		idList = snapshot.idList
+	}
+	items, next := pageIds(idList, offset, pageSize)
+	if token == "" && offset == 0 && next > 0 {
+		token = h.captureSnapshot(dataType, idList)
+	}
+	return &IdPage{Items: items, Total: len(idList), Next: next}, token, nil
+}
+
+func (h *Handler) captureSnapshot(dataType string, idList []interface{}) string {
+	ttl := h.Config.List.SnapshotTtl
+	if ttl <= 0 {
+		ttl = DefaultSnapshotTtl
+	}
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	now := time.Now()
+	h.snapshotLock.Lock()
+	defer h.snapshotLock.Unlock()
+	for key, snapshot := range h.snapshots {
+		if now.After(snapshot.expireAt) {
+			delete(h.snapshots, key)
+		}
+	}
+	h.snapshots[token] = &listSnapshot{
+		dataType: dataType,
+		idList:   idList,
+		expireAt: now.Add(time.Duration(ttl) * time.Second),
+	}
+	return token
+}
+
+func (h *Handler) getSnapshot(token string) (*listSnapshot, *Http.HttpError) {
+	h.snapshotLock.Lock()
+	defer h.snapshotLock.Unlock()
+	snapshot, ok := h.snapshots[token]
+	if !ok || time.Now().After(snapshot.expireAt) {
+		delete(h.snapshots, token)
+		return nil, Http.NewHttpError(fmt.Sprintf("snapshot [%s] does not exist or expired, list again from first page", token), http.StatusGone)
+	}
+	return snapshot, nil
+}
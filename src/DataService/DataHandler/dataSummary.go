@@ -0,0 +1,96 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"Data/DbIface"
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// count of records of every data type that has a schema, internal types and failed schemas are not listed
+func (h *Handler) Summary() (map[string]int, *Http.HttpError) {
+	typeList, err := h.List(JsonKey.Schema)
+	if err != nil {
+		return nil, err
+	}
+	// schema failed to load is listed by FailedSchemas instead
+	failed := h.FailedSchemas()
+	summary := make(map[string]int, len(typeList))
+	for _, item := range typeList {
+		dataType := item.(string)
+		if _, ok := Common.InternalTypes[dataType]; ok {
+			continue
+		}
+		if _, ok := failed[dataType]; ok {
+			continue
+		}
+		count, err := h.countRecords(dataType)
+		if err != nil {
+			return nil, Http.WrapError(err, fmt.Sprintf("failed to count records of type [%s]", dataType), err.Status)
+		}
+		summary[dataType] = count
+	}
+	return summary, nil
+}
+
+// count with database when it supports it, records of type with ttl are loaded so expired ones are not counted
+func (h *Handler) countRecords(dataType string) (int, *Http.HttpError) {
+	counter, ok := h.DB.(DbIface.Counter)
+	if ok {
+		schema, err := h.LocalSchema(dataType, "")
+		if err != nil {
+			return 0, err
+		}
+		if schema.Schema.Ttl == 0 && schema.Schema.TtlAttr == "" {
+			count, ex := counter.Count(map[string]interface{}{
+				DbIface.Table:   h.Config.DataTable.Data,
+				Record.DataType: dataType,
+			})
+			if ex != nil {
+				return 0, Http.WrapError(ex, "failed to count records in database", http.StatusInternalServerError)
+			}
+			return count, nil
+		}
+	}
+	recordList, err := h.QueryDb(dataType, "")
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, data := range recordList {
+		if !isExpired(data) {
+			count++
+		}
+	}
+	return count, nil
+}
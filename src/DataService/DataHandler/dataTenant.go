@@ -0,0 +1,118 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// tenant of request from configured header, empty when request has no tenant
+func (h *Handler) Tenant(headers map[string]interface{}) string {
+	header := h.Config.Tenant.Header
+	if header == "" {
+		header = Common.DefaultTenantHeader
+	}
+	tenant, _ := headers[strings.ToLower(header)].(string)
+	return tenant
+}
+
+// set schema overlay of dataType for tenant, nil overlay removes it.
+// overlay that loosens current schema is rejected
+func (h *Handler) SetOverlay(tenant string, dataType string, overlay map[string]interface{}) *Http.HttpError {
+	if tenant == "" {
+		return Http.NewHttpError("tenant of schema overlay is empty", http.StatusBadRequest)
+	}
+	if _, ok := Common.InternalTypes[dataType]; ok {
+		return Http.NewHttpError(fmt.Sprintf("schema overlay on type[%s] is not allowed", dataType), http.StatusBadRequest)
+	}
+	h.overlayLock.Lock()
+	defer h.overlayLock.Unlock()
+	if overlay == nil {
+		delete(h.overlays[tenant], dataType)
+		return nil
+	}
+	schema, err := h.LocalSchema(dataType, "")
+	if err != nil {
+		return err
+	}
+	_, ex := schema.Overlay(overlay)
+	if ex != nil {
+		return Http.WrapError(ex, fmt.Sprintf("invalid schema overlay of tenant [%s] on type [%s]", tenant, dataType), http.StatusBadRequest)
+	}
+	if _, ok := h.overlays[tenant]; !ok {
+		h.overlays[tenant] = map[string]map[string]interface{}{}
+	}
+	h.overlays[tenant][dataType] = overlay
+	return nil
+}
+
+func (h *Handler) loadOverlays() *Http.HttpError {
+	h.overlays = map[string]map[string]map[string]interface{}{}
+	for tenant, typeOverlays := range h.Config.Tenant.Overlays {
+		for dataType, overlay := range typeOverlays {
+			err := h.SetOverlay(tenant, dataType, overlay)
+			if err == nil {
+				continue
+			}
+			if err.Status != http.StatusNotFound && err.Status != http.StatusServiceUnavailable {
+				return err
+			}
+			// schema may be added or fixed later, overlay is checked again on validation
+			h.Log(fmt.Sprintf("schema of overlay [%s/%s] not found or failed to load, keep it unchecked", tenant, dataType))
+			if _, ok := h.overlays[tenant]; !ok {
+				h.overlays[tenant] = map[string]map[string]interface{}{}
+			}
+			h.overlays[tenant][dataType] = overlay
+		}
+	}
+	return nil
+}
+
+// schema of record merged with overlay of record tenant, nil when there is no overlay
+func (h *Handler) tenantSchema(schema *Schema.SchemaOps, record *Record.Record) (*Schema.SchemaOps, *Http.HttpError) {
+	if record.Tenant == "" {
+		return nil, nil
+	}
+	h.overlayLock.RLock()
+	overlay, ok := h.overlays[record.Tenant][record.Type]
+	h.overlayLock.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	tenantSchema, ex := schema.Overlay(overlay)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("invalid schema overlay of tenant [%s] on type [%s]", record.Tenant, record.Type), http.StatusInternalServerError)
+	}
+	return tenantSchema, nil
+}
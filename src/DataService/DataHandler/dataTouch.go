@@ -0,0 +1,77 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// mark record as seen now without change on data, __modified and __modifiedBy are updated.
+// version in headers must match the record like PATCH
+func (h *Handler) Touch(dataType string, dataId string, headers map[string]interface{}) (map[string]interface{}, *Http.HttpError) {
+	if _, ok := Common.InternalTypes[dataType]; ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("touch on type[%s] is not allowed", dataType), http.StatusBadRequest)
+	}
+	_, err := h.LocalSchema(dataType, "")
+	if err != nil {
+		return nil, err
+	}
+	idKey := fmt.Sprintf("%s/%s", dataType, dataId)
+	h.Lock.Aquire(idKey, "HandlerTouch")
+	defer h.Lock.Release(idKey, "HandlerTouch")
+	data, err := h.LocalData(dataType, dataId)
+	if err != nil {
+		return nil, err
+	}
+	before, ex := Record.LoadMap(data)
+	if ex != nil {
+		return nil, Http.WrapError(ex, fmt.Sprintf("failed to load data [%s/%s] as record", dataType, dataId), http.StatusInternalServerError)
+	}
+	record, _ := Record.LoadMap(data)
+	if version, ok := headers[JsonKey.Version]; ok && record.Version != version {
+		errMsg := fmt.Sprintf("current record:[%s/%s] version:[%s] does not match specified version:[%s]", dataType, dataId, record.Version, version)
+		return nil, Http.NewHttpError(errMsg, http.StatusNotModified)
+	}
+	record.ModifiedBy = h.Actor(headers)
+	record.Tenant = h.Tenant(headers)
+	record.Modified = modifiedNow()
+	err = h.updateRecord(dataType, dataId, record, headers)
+	if err != nil {
+		return nil, err
+	}
+	h.Log(fmt.Sprintf("TOUCH [%s/%s] @[%s]", dataType, dataId, record.Modified))
+	if h.AddJournal != nil {
+		h.AddJournal(dataType, dataId, before.Map(), record.Map())
+	}
+	return record.Map(), nil
+}
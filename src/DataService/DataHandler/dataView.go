@@ -0,0 +1,162 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// read-only collection of records of base type that match filter, served as its own type
+type View struct {
+	Name   string
+	Type   string
+	Filter *SchemaDoc.Rule
+	Fields []string
+}
+
+func (h *Handler) loadViews() *Http.HttpError {
+	h.views = map[string]*View{}
+	for name, viewCfg := range h.Config.Views {
+		if _, ok := Common.InternalTypes[name]; ok {
+			return Http.NewHttpError(fmt.Sprintf("view [%s] conflicts with internal type", name), http.StatusBadRequest)
+		}
+		if viewCfg.Type == "" || viewCfg.Type == name {
+			return Http.NewHttpError(fmt.Sprintf("invalid base type [%s] of view [%s]", viewCfg.Type, name), http.StatusBadRequest)
+		}
+		if _, ok := h.Config.Views[viewCfg.Type]; ok {
+			return Http.NewHttpError(fmt.Sprintf("base type [%s] of view [%s] cannot be a view", viewCfg.Type, name), http.StatusBadRequest)
+		}
+		view := View{
+			Name:   name,
+			Type:   viewCfg.Type,
+			Fields: viewCfg.Fields,
+		}
+		if viewCfg.Filter != "" {
+			rule, ex := SchemaDoc.ParseRule(viewCfg.Filter)
+			if ex != nil {
+				return Http.WrapError(ex, fmt.Sprintf("invalid filter of view [%s]", name), http.StatusBadRequest)
+			}
+			view.Filter = rule
+		}
+		h.views[name] = &view
+	}
+	return nil
+}
+
+func (h *Handler) IsView(dataType string) bool {
+	_, ok := h.views[dataType]
+	return ok
+}
+
+// unlike schema rule, filter does not match record that misses attribute of filter
+func (v *View) Match(data map[string]interface{}) (bool, *Http.HttpError) {
+	if v.Filter == nil {
+		return true, nil
+	}
+	for _, attr := range v.Filter.Attrs() {
+		if _, ok := data[attr]; !ok {
+			return false, nil
+		}
+	}
+	ok, ex := v.Filter.Evaluate(data)
+	if ex != nil {
+		return false, Http.WrapError(ex, fmt.Sprintf("failed to evaluate filter of view [%s]", v.Name), http.StatusInternalServerError)
+	}
+	return ok, nil
+}
+
+// ids of base type records that match filter of view
+func (h *Handler) ListView(viewName string) ([]interface{}, *Http.HttpError) {
+	view, ok := h.views[viewName]
+	if !ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("view [%s] does not exist", viewName), http.StatusNotFound)
+	}
+	_, err := h.LocalData(JsonKey.Schema, view.Type)
+	if err != nil {
+		return nil, Http.WrapError(err, fmt.Sprintf("base type [%s] of view [%s] does not exist", view.Type, viewName), err.Status)
+	}
+	recordList, err := h.QueryDb(view.Type, "")
+	if err != nil {
+		return nil, err
+	}
+	idList := []string{}
+	for _, data := range recordList {
+		if isExpired(data) {
+			continue
+		}
+		record, ex := Record.LoadMap(data)
+		if ex != nil {
+			return nil, Http.WrapError(ex, fmt.Sprintf("failed to load data as record. [%s/%v]", view.Type, data[Record.DataId]), http.StatusInternalServerError)
+		}
+		match, err := view.Match(record.Data)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			idList = append(idList, record.Id)
+		}
+	}
+	sort.Strings(idList)
+	result := make([]interface{}, 0, len(idList))
+	for _, id := range idList {
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// record of base type through view, 404 when it does not match filter.
+// record carries name of view as its type
+func (h *Handler) GetView(viewName string, dataId string) (map[string]interface{}, *Http.HttpError) {
+	view, ok := h.views[viewName]
+	if !ok {
+		return nil, Http.NewHttpError(fmt.Sprintf("view [%s] does not exist", viewName), http.StatusNotFound)
+	}
+	record, err := h.localRecord(view.Type, dataId)
+	if err != nil {
+		return nil, err
+	}
+	match, err := view.Match(record.Data)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		return nil, Http.NewHttpError(fmt.Sprintf("object with id '%s' not found in view '%s'", dataId, viewName), http.StatusNotFound)
+	}
+	record.Type = viewName
+	if view.Fields != nil {
+		record.Data = projectData(record.Data, view.Fields)
+	}
+	return record.Map(), nil
+}
@@ -0,0 +1,213 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataHandler
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	SchemaPathData "github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+const DefaultWatchBuffer = 16
+
+// change of a watched record, Before and After are values at watched path, or whole record without path.
+// nil Before means record is created, nil After means record is deleted
+type WatchEvent struct {
+	DataType string      `json:"dataType"`
+	DataId   string      `json:"dataId"`
+	Path     string      `json:"path,omitempty"`
+	Before   interface{} `json:"before"`
+	After    interface{} `json:"after"`
+}
+
+// Events is closed when watch is removed, or when watcher falls behind and buffer is full
+type Watch struct {
+	DataType string
+	DataId   string
+	Path     string
+	Events   chan *WatchEvent
+}
+
+type WatchHub struct {
+	buffer  int
+	lock    sync.Mutex
+	watches map[string]map[*Watch]bool
+	closed  bool
+}
+
+func NewWatchHub(buffer int) *WatchHub {
+	if buffer <= 0 {
+		buffer = DefaultWatchBuffer
+	}
+	return &WatchHub{
+		buffer:  buffer,
+		watches: map[string]map[*Watch]bool{},
+	}
+}
+
+func (w *WatchHub) Watch(dataType string, dataId string, path string) *Watch {
+	watch := &Watch{
+		DataType: dataType,
+		DataId:   dataId,
+		Path:     path,
+		Events:   make(chan *WatchEvent, w.buffer),
+	}
+	idKey := fmt.Sprintf("%s/%s", dataType, dataId)
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed {
+		close(watch.Events)
+		return watch
+	}
+	if _, ok := w.watches[idKey]; !ok {
+		w.watches[idKey] = map[*Watch]bool{}
+	}
+	w.watches[idKey][watch] = true
+	return watch
+}
+
+func (w *WatchHub) Unwatch(watch *Watch) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.remove(watch)
+}
+
+// close Events of every watch so open streams end, watch added after Close is closed at once
+func (w *WatchHub) Close() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.closed = true
+	for _, watches := range w.watches {
+		for watch := range watches {
+			w.remove(watch)
+		}
+	}
+}
+
+func (w *WatchHub) Closed() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.closed
+}
+
+// caller holds lock
+func (w *WatchHub) remove(watch *Watch) {
+	idKey := fmt.Sprintf("%s/%s", watch.DataType, watch.DataId)
+	if _, ok := w.watches[idKey][watch]; !ok {
+		return
+	}
+	delete(w.watches[idKey], watch)
+	if len(w.watches[idKey]) == 0 {
+		delete(w.watches, idKey)
+	}
+	close(watch.Events)
+}
+
+func (w *WatchHub) watchList(dataType string, dataId string) []*Watch {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	watchList := make([]*Watch, 0, len(w.watches[fmt.Sprintf("%s/%s", dataType, dataId)]))
+	for watch := range w.watches[fmt.Sprintf("%s/%s", dataType, dataId)] {
+		watchList = append(watchList, watch)
+	}
+	return watchList
+}
+
+// send event without blocking writer, watch that cannot keep up is dropped
+func (w *WatchHub) send(watch *Watch, event *WatchEvent) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	idKey := fmt.Sprintf("%s/%s", watch.DataType, watch.DataId)
+	if _, ok := w.watches[idKey][watch]; !ok {
+		return true
+	}
+	select {
+	case watch.Events <- event:
+		return true
+	default:
+		w.remove(watch)
+		return false
+	}
+}
+
+// publish change of record to its watches, with same signature of AddJournal so it can be chained after journal
+func (h *Handler) Notify(dataType string, dataId string, before map[string]interface{}, after map[string]interface{}) {
+	for _, watch := range h.Watches.watchList(dataType, dataId) {
+		event := &WatchEvent{
+			DataType: dataType,
+			DataId:   dataId,
+			Path:     watch.Path,
+			Before:   h.watchValue(dataType, dataId, watch.Path, before),
+			After:    h.watchValue(dataType, dataId, watch.Path, after),
+		}
+		if watch.Path != "" && reflect.DeepEqual(event.Before, event.After) {
+			continue
+		}
+		if !h.Watches.send(watch, event) {
+			h.Log(fmt.Sprintf("watch of [%s/%s] fell behind, dropped", dataType, dataId))
+		}
+	}
+}
+
+// value at path of record snapshot, nil when record or path does not exist
+func (h *Handler) watchValue(dataType string, dataId string, path string, data map[string]interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	if path == "" {
+		return data
+	}
+	record, ex := Record.LoadMap(data)
+	if ex != nil {
+		return nil
+	}
+	conn := h.Connection()
+	conn.FuncRecord = func(recordType string, recordId string) (*Record.Record, *Http.HttpError) {
+		if recordType == dataType && recordId == dataId {
+			return record, nil
+		}
+		return h.GetRecord(recordType, recordId)
+	}
+	value, err := walkValue(conn, dataType, fmt.Sprintf("%s/%s", Util.EscapeKey(dataId), path))
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+func walkValue(conn *SchemaPathData.Connection, dataType string, dataPath string) (interface{}, *Http.HttpError) {
+	query, err := SchemaPath.CreateQuery(conn, dataType, dataPath)
+	if err != nil {
+		return nil, err
+	}
+	return query.WalkValue()
+}
@@ -27,27 +27,43 @@ package DataServer
 
 import (
 	"Data"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"DataService/Common"
 	"DataService/Config"
 	"DataService/DataHandler"
 	"DataService/DataJournal"
 
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
 	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	"github.com/salesforce/UniTAO/lib/SchemaPath/PathCmd"
 	"github.com/salesforce/UniTAO/lib/Util"
 	"github.com/salesforce/UniTAO/lib/Util/CustomLogger"
 	"github.com/salesforce/UniTAO/lib/Util/Http"
 	"github.com/salesforce/UniTAO/lib/Util/Thread"
 )
 
+// version of data server in health probes, set at build with -ldflags "-X DataService/DataServer.Version=<version>"
+var Version = "dev"
+
 const (
-	CONFIG       = "config"
-	PORT         = "port"
-	PORT_DEFAULT = "8010"
+	CONFIG         = "config"
+	PORT           = "port"
+	PORT_DEFAULT   = "8010"
+	JOURNAL_WORKER = "journalHandler"
 )
 
 type Server struct {
@@ -61,6 +77,9 @@ type Server struct {
 	BackendCtl     *Thread.ThreadCtrl
 	logPath        string
 	log            *log.Logger
+	httpServer     *Http.GracefulServer
+	started        time.Time
+	stopOnce       sync.Once
 }
 
 func New() (Server, error) {
@@ -69,6 +88,7 @@ func New() (Server, error) {
 		args:    make(map[string]string),
 		config:  Config.Confuguration{},
 		logPath: "",
+		started: time.Now(),
 	}
 	err := srv.init()
 	if err != nil {
@@ -77,59 +97,167 @@ func New() (Server, error) {
 	return srv, nil
 }
 
-func (srv *Server) Run() {
+// run data server until listen fails or SIGINT/SIGTERM arrives, then shut down gracefully
+func (srv *Server) Run() error {
 	logFile, logger, ex := CustomLogger.FileLoger(srv.logPath, srv.Id)
 	if ex != nil {
-		log.Fatalf("failed to create file logger[%s], Error: %s", srv.Id, ex)
+		return fmt.Errorf("failed to create file logger[%s], Error: %s", srv.Id, ex)
 	}
 	if logFile != nil {
 		defer logFile.Close()
 	}
 	srv.log = logger
-	if logFile != nil {
-		defer logFile.Close()
-	}
 	srv.BackendCtl = Thread.NewThreadController(srv.log)
 	handler, err := DataHandler.New(srv.config, srv.log, Data.ConnectDb)
 	if err != nil {
-		srv.log.Fatalf("failed to initialize data layer, Err:%s", err)
+		return fmt.Errorf("failed to initialize data layer, Err:%s", err)
 	}
 	srv.data = handler
+	defer handler.Close()
 	jLogFile, jLogger, ex := CustomLogger.FileLoger(srv.logPath, fmt.Sprintf("%s_Journal", srv.Id))
 	if ex != nil {
-		srv.log.Fatalf("failed to create file logger[%s_Journal], Error: %s", srv.Id, err)
+		return fmt.Errorf("failed to create file logger[%s_Journal], Error: %s", srv.Id, ex)
 	}
 	if jLogFile != nil {
 		defer jLogFile.Close()
 	}
 	journal, err := DataJournal.NewJournalLib(handler.DB, srv.config.DataTable.Data, jLogger)
 	if err != nil {
-		srv.log.Fatalf("failed to create Journal Library. Error: %s", err)
+		return fmt.Errorf("failed to create Journal Library. Error: %s", err)
 	}
 	srv.journal = journal
-	srv.data.AddJournal = srv.journal.AddJournal
-	srv.RunJournalHandler()
-	srv.RunHttp()
+	srv.data.AddJournal = func(dataType string, dataId string, before map[string]interface{}, after map[string]interface{}) *Http.HttpError {
+		err := srv.journal.AddJournal(dataType, dataId, before, after)
+		srv.data.Notify(dataType, dataId, before, after)
+		return err
+	}
+	ex = srv.RunJournalHandler()
+	if ex != nil {
+		return ex
+	}
+	defer srv.stopBackend()
+	return srv.RunHttp()
 }
 
-func (srv *Server) RunHttp() {
-	http.HandleFunc("/", srv.handler)
+// server around data layer that is already initialized, serves its routes without journal
+func NewWithHandler(config Config.Confuguration, handler *DataHandler.Handler, logger *log.Logger) *Server {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &Server{
+		Port:    PORT_DEFAULT,
+		args:    make(map[string]string),
+		config:  config,
+		data:    handler,
+		log:     logger,
+		started: time.Now(),
+	}
+}
+
+func (srv *Server) RunHttp() error {
+	err := Http.CheckServerConfig(srv.config.Http)
+	if err != nil {
+		return fmt.Errorf("invalid http config. Error: %s", err)
+	}
 	srv.log.Printf("Data Server Listen @%s://%s:%s", srv.config.Http.HttpType, srv.config.Http.DnsName, srv.Port)
-	srv.log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", srv.Port), nil))
+	err = srv.newHttpServer(fmt.Sprintf(":%s", srv.Port)).ListenAndServe(syscall.SIGINT, syscall.SIGTERM)
+	if err != nil {
+		srv.log.Printf("Data Server stopped, Error: %s", err)
+		return err
+	}
+	srv.log.Printf("Data Server stopped")
+	return nil
+}
+
+// serve routes on listener until it fails or Stop is called
+func (srv *Server) Serve(listener net.Listener) error {
+	return srv.newHttpServer(listener.Addr().String()).Serve(listener)
+}
+
+// open watch streams are ended on shutdown, otherwise they hold shutdown until it times out
+func (srv *Server) newHttpServer(addr string) *Http.GracefulServer {
+	srv.httpServer = Http.NewGracefulServer(addr, srv.Routes(), srv.config.Http)
+	srv.httpServer.Server.RegisterOnShutdown(srv.data.Watches.Close)
+	return srv.httpServer
+}
+
+// all routes of data server, probes and metrics are served next to data paths
+func (srv *Server) Routes() http.Handler {
+	var handler http.Handler = http.HandlerFunc(srv.handler)
+	mux := http.NewServeMux()
+	if srv.config.Http.Metrics.Enabled {
+		metrics := Http.NewMetrics("unitao_data", srv.config.Http.Metrics)
+		handler = Http.MetricsHandler(handler, metrics, srv.metricsType)
+		metricsHandler := Http.SecurityHandler(metrics.Handler(srv.config.Http), srv.config.Http)
+		mux.Handle(Http.PathMetrics, metricsHandler)
+		mux.Handle(Http.PathMetricsAlias, metricsHandler)
+	}
+	handler = Http.DebugBodyHandler(handler, srv.config.Http, srv.log, srv.data.SensitiveAttrs)
+	health := Http.SecurityHandler(Http.HealthHandler(Version, srv.started, srv.config.Http), srv.config.Http)
+	ready := Http.SecurityHandler(Http.ReadyHandler(Version, srv.started, srv.Ready, srv.config.Http), srv.config.Http)
+	mux.Handle(Http.PathHealth, health)
+	mux.Handle(Http.PathHealthAlias, health)
+	mux.Handle(Http.PathReady, ready)
+	mux.Handle(Http.PathReadyAlias, ready)
+	mux.Handle("/", Http.SecurityHandler(Http.LimitHandler(handler, srv.config.Http), srv.config.Http))
+	return mux
 }
 
-func (srv *Server) RunJournalHandler() {
+// data type label of request in metrics, only types with loaded schema so label values stay bounded
+func (srv *Server) metricsType(r *http.Request) string {
+	dataType, _ := Util.ParsePath(r.URL.Path)
+	if _, ok := Common.ReadOnlyTypes[dataType]; ok || dataType == Common.KeyPath || srv.data.KnownType(dataType) {
+		return dataType
+	}
+	return Http.MetricsUnknown
+}
+
+// ready once data layer is initialized and its database responds
+func (srv *Server) Ready() *Http.HttpError {
+	if srv.data == nil {
+		return Http.NewHttpError("data layer is not initialized", http.StatusServiceUnavailable)
+	}
+	return srv.data.Ping()
+}
+
+// shut down http server of running data server, in-flight requests finish until ctx is done.
+// journal handler and background work of data layer are stopped after it
+func (srv *Server) Stop(ctx context.Context) error {
+	var err error
+	if srv.httpServer != nil {
+		err = srv.httpServer.Stop(ctx)
+	}
+	srv.stopBackend()
+	return err
+}
+
+func (srv *Server) stopBackend() {
+	srv.stopOnce.Do(func() {
+		if srv.BackendCtl != nil {
+			worker := srv.BackendCtl.GetWorker(JOURNAL_WORKER)
+			if worker != nil {
+				worker.Stop()
+			}
+		}
+		if srv.data != nil {
+			srv.data.Close()
+		}
+	})
+}
+
+func (srv *Server) RunJournalHandler() error {
 	journal, err := DataJournal.NewJournalHandler(srv.data, srv.journal, srv.journal.Logger)
 	if err != nil {
-		srv.log.Fatalf("failed to load Journal Handler. Error:%s", err)
+		return fmt.Errorf("failed to load Journal Handler. Error:%s", err)
 	}
 	srv.journalHandler = journal
-	worker, err := srv.BackendCtl.AddWorker("journalHandler", srv.journalHandler.Run)
+	worker, err := srv.BackendCtl.AddWorker(JOURNAL_WORKER, srv.journalHandler.Run)
 	if err != nil {
-		srv.log.Fatalf("failed to create Journal Handler as backend process.")
+		return fmt.Errorf("failed to create Journal Handler as backend process. Error: %s", err)
 	}
 	srv.journal.HandlerNotify = worker.Notify
 	worker.Run()
+	return nil
 }
 
 func (srv *Server) init() error {
@@ -160,20 +288,26 @@ func (srv *Server) init() error {
 	if err != nil {
 		return err
 	}
-	if port != "" {
-		srv.Port = port
-
-	} else if srv.config.Http.Port != "" {
-		srv.Port = srv.config.Http.Port
-	}
+	srv.Port = srv.config.ListenPort(port, srv.Port)
 	return nil
 }
 
 func (srv *Server) handler(w http.ResponseWriter, r *http.Request) {
-	requestUrl, err := Http.GetUrl(r)
+	requestUrl, err := requestPath(r)
 	if err != nil {
 		srv.log.Printf("failed to parse request URL. Error:%s", err)
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	if pathType, pathExpr := Util.ParsePath(requestUrl); pathType == Common.KeyPath && r.Method == http.MethodGet {
+		srv.handlePath(w, r, pathExpr)
+		return
+	}
+	requestUrl, query, err := parseQuery(requestUrl)
+	if err != nil {
+		srv.log.Printf("failed to parse request query. Error:%s", err)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
 	}
 	dataType, idPath := Util.ParsePath(requestUrl)
 	srv.log.Printf("process request[%s] on [%s/%s]", r.Method, dataType, idPath)
@@ -197,15 +331,59 @@ func (srv *Server) handler(w http.ResponseWriter, r *http.Request) {
 		}, http.StatusBadRequest, srv.config.Http)
 		return
 	}
+	if srv.data.IsView(dataType) {
+		srv.handleView(w, r, dataType, idPath)
+		return
+	}
 	switch r.Method {
 	case http.MethodGet:
-		srv.handleGet(w, dataType, idPath)
+		if dataType == Common.KeySummary && idPath == "" {
+			srv.handleSummary(w)
+			break
+		}
+		if dataType == Common.KeySchemaErrors && idPath == "" {
+			Http.ResponseJson(w, srv.data.FailedSchemas(), http.StatusOK, srv.config.Http)
+			break
+		}
+		if (dataType == Common.KeyOpenApi || dataType == Common.KeyOpenApiAlias) && idPath == "" {
+			srv.handleOpenApi(w)
+			break
+		}
+		if dataId, action := Util.ParsePath(idPath); action == Common.KeyWatch {
+			srv.handleWatch(w, r, dataType, recordId(dataId), query.Get(Common.QueryPath))
+			break
+		}
+		srv.handleGet(w, r, dataType, idPath, query)
 	case http.MethodPost:
-		srv.handlePost(w, r, dataType, idPath)
+		if dataType == Common.KeyImport {
+			srv.handleImport(w, r, query)
+			break
+		}
+		if (dataType == Common.KeyBatch || dataType == Common.KeyBatchAlias) && idPath == "" {
+			srv.handleBatch(w, r, query)
+			break
+		}
+		if (dataType == Common.KeyBatch || dataType == Common.KeyBatchAlias) && idPath == Common.KeyGet {
+			srv.handleBatchGet(w, r)
+			break
+		}
+		if dataId, action := Util.ParsePath(idPath); action == Common.KeyRename {
+			srv.handleRename(w, r, dataType, recordId(dataId))
+			break
+		}
+		if dataId, action := Util.ParsePath(idPath); action == Common.KeyTouch {
+			srv.handleTouch(w, r, dataType, recordId(dataId))
+			break
+		}
+		if idPath == Common.KeyRetype {
+			srv.handleRetype(w, r, dataType, query)
+			break
+		}
+		srv.handlePost(w, r, dataType, recordId(idPath))
 	case http.MethodDelete:
-		srv.handleDelete(w, dataType, idPath)
+		srv.handleDelete(w, dataType, recordId(idPath), query)
 	case http.MethodPut:
-		srv.handlePut(w, r, dataType, idPath)
+		srv.handlePut(w, r, dataType, recordId(idPath))
 	case http.MethodPatch:
 		srv.handlePatch(w, r, dataType, idPath)
 	default:
@@ -218,14 +396,204 @@ func (srv *Server) handler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (srv *Server) handleGet(w http.ResponseWriter, dataType string, idPath string) {
+// path of request split on its escaped form, so id with %2F stays one segment. type is decoded,
+// record id is decoded and encoded again as path key, see Util.EscapeKey. under /_path type and id come
+// after it. path inside record is kept as sent, SchemaPath decodes its keys. query is kept after ?
+func requestPath(r *http.Request) (string, *Http.HttpError) {
+	segments := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	idIdx := 1
+	for idx := 0; idx < len(segments) && idx <= idIdx; idx++ {
+		segment, ex := url.PathUnescape(segments[idx])
+		if ex != nil {
+			return "", Http.WrapError(ex, fmt.Sprintf("failed to parse Url[%s]", r.RequestURI), http.StatusBadRequest)
+		}
+		if idx == 0 && segment == Common.KeyPath {
+			idIdx = 2
+		}
+		if idx == idIdx {
+			segment = Util.EscapeKey(segment)
+		}
+		segments[idx] = segment
+	}
+	requestUrl := strings.Join(segments, "/")
+	if r.URL.RawQuery != "" {
+		requestUrl = fmt.Sprintf("%s%s%s", requestUrl, PathCmd.CmdPrefix, r.URL.RawQuery)
+	}
+	return requestUrl, nil
+}
+
+// record id of id path from requestPath. path that goes into record is kept as it is
+func recordId(idPath string) string {
+	dataId, nextPath := Util.ParsePath(idPath)
+	if nextPath != "" {
+		return idPath
+	}
+	rawId, ex := Util.UnescapeKey(dataId)
+	if ex != nil {
+		return idPath
+	}
+	return rawId
+}
+
+// split server query options from request url.
+// SchemaPath commands like ?ref or ?schema stay in the path and are walked by DataHandler
+func parseQuery(requestUrl string) (string, url.Values, *Http.HttpError) {
+	qIdx := strings.Index(requestUrl, PathCmd.CmdPrefix)
+	if qIdx < 0 || PathCmd.Validate(requestUrl[qIdx:]) == nil {
+		return requestUrl, url.Values{}, nil
+	}
+	query, err := url.ParseQuery(requestUrl[qIdx+1:])
+	if err != nil {
+		return "", nil, Http.WrapError(err, fmt.Sprintf("failed to parse query [%s]", requestUrl[qIdx:]), http.StatusBadRequest)
+	}
+	return requestUrl[:qIdx], query, nil
+}
+
+func queryFlag(query url.Values, key string) (bool, *Http.HttpError) {
+	value := query.Get(key)
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, Http.WrapError(err, fmt.Sprintf("invalid value of query [%s]=[%s], expect true or false", key, value), http.StatusBadRequest)
+	}
+	return enabled, nil
+}
+
+// non-negative integer value of query key, 0 when not given
+func queryCount(query url.Values, key string) (int, *Http.HttpError) {
+	value := query.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return 0, Http.NewHttpError(fmt.Sprintf("invalid value of query [%s]=[%s], expect non-negative integer", key, value), http.StatusBadRequest)
+	}
+	return count, nil
+}
+
+// comma separated values of query key, empty items are ignored.
+// comma inside brackets does not split, like expand=a.ref(f1,f2),b
+func queryList(query url.Values, key string) []string {
+	result := []string{}
+	for _, value := range query[key] {
+		depth := 0
+		start := 0
+		for idx := 0; idx <= len(value); idx++ {
+			if idx < len(value) {
+				switch value[idx] {
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+				if value[idx] != ',' || depth > 0 {
+					continue
+				}
+			}
+			item := strings.TrimSpace(value[start:idx])
+			if item != "" {
+				result = append(result, item)
+			}
+			start = idx + 1
+		}
+	}
+	return result
+}
+
+func (srv *Server) handleGet(w http.ResponseWriter, r *http.Request, dataType string, idPath string, query url.Values) {
+	if Http.NoCache(r) {
+		srv.dropSchemaCache(dataType, idPath)
+	}
+	if walkPath := query.Get(Common.QueryWalk); idPath == "" && walkPath != "" {
+		srv.handleWalk(w, r, dataType, walkPath)
+		return
+	}
+	format, e := queryFormat(r, query)
+	if e != nil {
+		Http.ResponseJson(w, e, e.Status, srv.config.Http)
+		return
+	}
+	jsonApi := format == Common.FormatJsonApi
+	if format == Common.FormatCsv && (idPath != "" || dataType == JsonKey.Schema || dataType == Common.KeyJournal) {
+		e = Http.NewHttpError(fmt.Sprintf("[%s]=[%s] only works on list of records, not on [%s/%s]", Common.QueryFormat, Common.FormatCsv, dataType, idPath), http.StatusBadRequest)
+		Http.ResponseJson(w, e, e.Status, srv.config.Http)
+		return
+	}
+	if jsonApi && idPath != "" {
+		srv.handleJsonApi(w, dataType, idPath)
+		return
+	}
+	raw, e := queryFlag(query, Common.QueryRaw)
+	if e != nil {
+		Http.ResponseJson(w, e, e.Status, srv.config.Http)
+		return
+	}
+	if raw {
+		srv.handleRaw(w, dataType, idPath, format)
+		return
+	}
+	normalized, e := queryFlag(query, Common.QueryNormalized)
+	if e != nil {
+		Http.ResponseJson(w, e, e.Status, srv.config.Http)
+		return
+	}
+	if normalized {
+		srv.handleNormalized(w, dataType, idPath, format)
+		return
+	}
 	if idPath == "" {
 		srv.log.Printf("list id of [%s]", dataType)
-		idList, err := srv.data.List(dataType)
+		offset, err := queryCount(query, Common.QueryOffset)
+		if err != nil {
+			Http.ResponseJson(w, err, err.Status, srv.config.Http)
+			return
+		}
+		limit, err := queryCount(query, Common.QueryLimit)
+		if err != nil {
+			Http.ResponseJson(w, err, err.Status, srv.config.Http)
+			return
+		}
+		if query.Has(Common.QueryLimit) && limit == 0 {
+			err = Http.NewHttpError(fmt.Sprintf("invalid value of query [%s]=[0], expect positive integer", Common.QueryLimit), http.StatusBadRequest)
+			Http.ResponseJson(w, err, err.Status, srv.config.Http)
+			return
+		}
+		page, snapshot, err := srv.data.ListRange(dataType, query.Get(Common.QuerySnapshot), offset, limit, query[Common.QueryFilter])
 		if err != nil {
 			Http.ResponseJson(w, err, err.Status, srv.config.Http)
 			return
 		}
+		idList, next := page.Items, page.Next
+		if next > 0 {
+			w.Header().Set(Common.HeaderTruncated, "true")
+			w.Header().Set(Common.HeaderNextOffset, strconv.Itoa(next))
+		}
+		if snapshot != "" {
+			w.Header().Set(Common.HeaderSnapshot, snapshot)
+		}
+		Http.SetCacheHeaders(w, srv.config.Http, dataType)
+		if jsonApi {
+			jsonApiCfg := srv.config.Http
+			jsonApiCfg.ContentType = Http.MediaJsonApi
+			Http.ResponseJson(w, srv.data.JsonApiList(dataType, idList, next), http.StatusOK, jsonApiCfg)
+			return
+		}
+		if format == Common.FormatCsv {
+			rows, err := srv.data.FlatTable(dataType, idList)
+			if err != nil {
+				Http.ResponseJson(w, err, err.Status, srv.config.Http)
+				return
+			}
+			Http.ResponseCsv(w, rows, http.StatusOK, srv.config.Http)
+			return
+		}
+		if limit > 0 {
+			Http.ResponseJson(w, page, http.StatusOK, srv.config.Http)
+			return
+		}
 		Http.ResponseJson(w, idList, http.StatusOK, srv.config.Http)
 		return
 	}
@@ -235,9 +603,201 @@ func (srv *Server) handleGet(w http.ResponseWriter, dataType string, idPath stri
 	case Common.KeyJournal:
 		srv.log.Printf("get Journal of type [%s]", idPath)
 		result, err = srv.journal.GetJournal(idPath)
-	default:
+	case JsonKey.Schema:
+		effective, e := queryFlag(query, Common.QueryEffective)
+		if e != nil {
+			Http.ResponseJson(w, e, e.Status, srv.config.Http)
+			return
+		}
+		if effective {
+			srv.log.Printf("get effective schema of [%s]", idPath)
+			result, err = srv.data.EffectiveSchema(idPath)
+			break
+		}
 		srv.log.Printf("get data of [%s/%s]", dataType, idPath)
 		result, err = srv.data.Get(dataType, idPath)
+	default:
+		if query.Has(Common.QueryVersions) {
+			srv.log.Printf("list versions of [%s/%s]", dataType, idPath)
+			result, err = srv.data.ListVersions(dataType, recordId(idPath))
+			break
+		}
+		if version := query.Get(Common.QueryVersion); version != "" {
+			srv.log.Printf("get version [%s] of [%s/%s]", version, dataType, idPath)
+			result, err = srv.data.GetVersion(dataType, recordId(idPath), version)
+			break
+		}
+		expandList := queryList(query, Common.QueryExpand)
+		fieldList := queryList(query, Common.QueryFields)
+		if len(fieldList) > 0 {
+			if len(expandList) > 0 {
+				err = Http.NewHttpError(fmt.Sprintf("query [%s] and [%s] can not be used together, walk through ref in [%s] instead", Common.QueryFields, Common.QueryExpand, Common.QueryFields), http.StatusBadRequest)
+				break
+			}
+			srv.log.Printf("get data of [%s/%s] fields %s", dataType, idPath, fieldList)
+			result, err = srv.data.GetProjected(dataType, recordId(idPath), fieldList)
+			break
+		}
+		if len(expandList) > 0 {
+			srv.log.Printf("get data of [%s/%s] expand %s", dataType, idPath, expandList)
+			result, err = srv.data.GetExpanded(dataType, recordId(idPath), expandList)
+			break
+		}
+		srv.log.Printf("get data of [%s/%s]", dataType, idPath)
+		result, err = srv.data.GetContext(r.Context(), dataType, idPath)
+		if data, ok := result.(map[string]interface{}); ok && err == nil && !strings.ContainsAny(idPath, "/"+PathCmd.CmdPrefix) {
+			// whole record carries ETag for If-Match on write, record still returns when it can not have one
+			etag, etagErr := DataHandler.DataETag(data)
+			if etagErr == nil {
+				w.Header().Set(Common.HeaderETag, etag)
+			} else {
+				srv.log.Printf("no ETag for [%s/%s]. Error: %s", dataType, idPath, etagErr)
+			}
+		}
+	}
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	if !strings.ContainsAny(idPath, "/"+PathCmd.CmdPrefix) {
+		result = srv.wireRecord(result)
+	}
+	Http.SetCacheHeaders(w, srv.config.Http, dataType)
+	Http.ResponseJson(w, result, http.StatusOK, srv.config.Http)
+}
+
+// JSON:API format is asked by query format=jsonapi or by Accept header of its media type. native format is the default
+func queryFormat(r *http.Request, query url.Values) (string, *Http.HttpError) {
+	switch format := query.Get(Common.QueryFormat); format {
+	case "":
+		if strings.Contains(r.Header.Get(Common.HeaderAccept), Http.MediaJsonApi) {
+			return Common.FormatJsonApi, nil
+		}
+		return "", nil
+	case Common.FormatJsonApi, Common.FormatCsv:
+		return format, nil
+	default:
+		return "", Http.NewHttpError(fmt.Sprintf("invalid value of query [%s]=[%s], expect one of [%s, %s]", Common.QueryFormat, format, Common.FormatJsonApi, Common.FormatCsv), http.StatusBadRequest)
+	}
+}
+
+// record as stored, not loaded into map and written again. Warning 214 tells it was serialized again
+// when database does not keep the bytes
+func (srv *Server) handleRaw(w http.ResponseWriter, dataType string, idPath string, format string) {
+	dataId, nextPath := Util.ParsePath(idPath)
+	if dataId == "" || nextPath != "" || format != "" || strings.Contains(dataId, PathCmd.CmdPrefix) || dataType == Common.KeyJournal {
+		err := Http.NewHttpError(fmt.Sprintf("[%s] only works on record without [%s], not on [%s/%s]", Common.QueryRaw, Common.QueryFormat, dataType, idPath), http.StatusBadRequest)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	srv.log.Printf("get raw data of [%s/%s]", dataType, dataId)
+	data, raw, err := srv.data.GetRaw(dataType, recordId(dataId))
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	if !raw {
+		w.Header().Set(Common.HeaderWarning, fmt.Sprintf("214 - %s", strconv.Quote("database does not keep raw bytes, record is serialized again")))
+	}
+	Http.SetCacheHeaders(w, srv.config.Http, dataType)
+	w.Header().Set(Http.HeaderContent, Http.JsonContentType(srv.config.Http))
+	Http.Response(w, data, http.StatusOK, srv.config.Http)
+}
+
+// record with data normalized to its schema, read only, stored record is not changed
+func (srv *Server) handleNormalized(w http.ResponseWriter, dataType string, idPath string, format string) {
+	dataId, nextPath := Util.ParsePath(idPath)
+	if dataId == "" || nextPath != "" || format != "" || strings.Contains(dataId, PathCmd.CmdPrefix) || dataType == Common.KeyJournal {
+		err := Http.NewHttpError(fmt.Sprintf("[%s] only works on record without [%s], not on [%s/%s]", Common.QueryNormalized, Common.QueryFormat, dataType, idPath), http.StatusBadRequest)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	srv.log.Printf("get normalized data of [%s/%s]", dataType, dataId)
+	record, err := srv.data.GetNormalized(dataType, recordId(dataId))
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	Http.SetCacheHeaders(w, srv.config.Http, dataType)
+	Http.ResponseJson(w, record, http.StatusOK, srv.config.Http)
+}
+
+// record in JSON:API document, only whole record can be formatted, not a path inside it
+func (srv *Server) handleJsonApi(w http.ResponseWriter, dataType string, idPath string) {
+	dataId, nextPath := Util.ParsePath(idPath)
+	if nextPath != "" || strings.Contains(dataId, PathCmd.CmdPrefix) || dataType == Common.KeyJournal {
+		err := Http.NewHttpError(fmt.Sprintf("[%s]=[%s] only works on record or list of records, not on [%s/%s]", Common.QueryFormat, Common.FormatJsonApi, dataType, idPath), http.StatusBadRequest)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	srv.log.Printf("get JSON:API document of [%s/%s]", dataType, dataId)
+	result, err := srv.data.GetJsonApi(dataType, recordId(dataId))
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	jsonApiCfg := srv.config.Http
+	jsonApiCfg.ContentType = Http.MediaJsonApi
+	Http.SetCacheHeaders(w, srv.config.Http, dataType)
+	Http.ResponseJson(w, result, http.StatusOK, jsonApiCfg)
+}
+
+// view is read-only, list ids that match its filter or get one record through it
+func (srv *Server) handleView(w http.ResponseWriter, r *http.Request, viewName string, idPath string) {
+	if r.Method != http.MethodGet {
+		srv.log.Printf("Invalid update request on view [%s]", viewName)
+		err := Http.NewHttpError(fmt.Sprintf("update on view [%s] is not supported", viewName), http.StatusBadRequest)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	var result interface{}
+	var err *Http.HttpError
+	dataId, nextPath := Util.ParsePath(idPath)
+	switch {
+	case idPath == "":
+		srv.log.Printf("list id of view [%s]", viewName)
+		result, err = srv.data.ListView(viewName)
+	case nextPath != "" || strings.Contains(dataId, PathCmd.CmdPrefix):
+		err = Http.NewHttpError(fmt.Sprintf("path query on view [%s] is not supported", viewName), http.StatusBadRequest)
+	default:
+		srv.log.Printf("get data of view [%s/%s]", viewName, dataId)
+		result, err = srv.data.GetView(viewName, recordId(dataId))
+	}
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	Http.SetCacheHeaders(w, srv.config.Http, viewName)
+	Http.ResponseJson(w, result, http.StatusOK, srv.config.Http)
+}
+
+// run path expression {type}/{id}/{attrPath}?cmd, or with query describe=true return its result type without reading data.
+// server query follows the last ?, like host/h01/nics?len?describe=true
+func (srv *Server) handlePath(w http.ResponseWriter, r *http.Request, pathExpr string) {
+	query := url.Values{}
+	if qIdx := strings.LastIndex(pathExpr, PathCmd.CmdPrefix); qIdx >= 0 && PathCmd.Validate(pathExpr[qIdx:]) != nil {
+		values, ex := url.ParseQuery(pathExpr[qIdx+1:])
+		if ex != nil {
+			err := Http.WrapError(ex, fmt.Sprintf("failed to parse query [%s]", pathExpr[qIdx:]), http.StatusBadRequest)
+			Http.ResponseJson(w, err, err.Status, srv.config.Http)
+			return
+		}
+		pathExpr = pathExpr[:qIdx]
+		query = values
+	}
+	describe, err := queryFlag(query, Common.QueryDescribe)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	dataType, dataPath := Util.ParsePath(pathExpr)
+	var result interface{}
+	if describe {
+		srv.log.Printf("describe path [%s] of [%s]", dataPath, dataType)
+		result, err = srv.data.DescribePath(dataType, dataPath)
+	} else {
+		srv.log.Printf("get data of [%s/%s]", dataType, dataPath)
+		result, err = srv.data.GetContext(r.Context(), dataType, dataPath)
 	}
 	if err != nil {
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
@@ -246,6 +806,94 @@ func (srv *Server) handleGet(w http.ResponseWriter, dataType string, idPath stri
 	Http.ResponseJson(w, result, http.StatusOK, srv.config.Http)
 }
 
+// stream value of path on every record of type, one JSON line per record
+func (srv *Server) handleWalk(w http.ResponseWriter, r *http.Request, dataType string, walkPath string) {
+	srv.log.Printf("walk [%s] on all records of [%s]", walkPath, dataType)
+	compiled, err := SchemaPath.Compile(walkPath)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	_, err = srv.data.LocalSchema(dataType, "")
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	out := make(chan SchemaPath.PathResult)
+	go compiled.StreamContext(r.Context(), srv.data.Connection(), dataType, out)
+	Http.ResponseJsonLines(w, out, srv.config.Http)
+}
+
+// stream change of record, or of value at path of record, as server-sent events until client disconnects.
+// stream ends with event [dropped] when client cannot keep up with changes
+func (srv *Server) handleWatch(w http.ResponseWriter, r *http.Request, dataType string, dataId string, path string) {
+	if _, ok := Common.InternalTypes[dataType]; ok {
+		err := Http.NewHttpError(fmt.Sprintf("watch on type[%s] is not allowed", dataType), http.StatusBadRequest)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	_, err := srv.data.LocalSchema(dataType, "")
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := Http.NewHttpError("streaming is not supported by connection", http.StatusInternalServerError)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	srv.log.Printf("watch [%s/%s] path=[%s]", dataType, dataId, path)
+	watch := srv.data.Watches.Watch(dataType, dataId, path)
+	defer srv.data.Watches.Unwatch(watch)
+	w.Header().Set(Http.HeaderContent, Http.ContentType(Http.MediaEventStream, srv.config.Http))
+	w.Header().Set(Http.HeaderCacheControl, Http.CacheNoCache)
+	Http.Response(w, nil, http.StatusOK, srv.config.Http)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			srv.log.Printf("watch [%s/%s] client disconnected", dataType, dataId)
+			return
+		case event, ok := <-watch.Events:
+			if !ok && srv.data.Watches.Closed() {
+				fmt.Fprintf(w, "event: closed\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			if !ok {
+				fmt.Fprintf(w, "event: dropped\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			if event.Path == "" {
+				event.Before = srv.wireRecord(event.Before)
+				event.After = srv.wireRecord(event.After)
+			}
+			eventData, ex := json.Marshal(event)
+			if ex != nil {
+				srv.log.Printf("failed to marshal watch event of [%s/%s]. Error: %s", dataType, dataId, ex)
+				continue
+			}
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", eventData)
+			flusher.Flush()
+		}
+	}
+}
+
+// schema is the internal cache of DataService, reload it from database on client no-cache request
+func (srv *Server) dropSchemaCache(dataType string, idPath string) {
+	schemaId := dataType
+	if dataType == JsonKey.Schema {
+		schemaId, _ = Util.ParsePath(idPath)
+	}
+	if _, ok := Common.InternalTypes[schemaId]; ok || schemaId == "" {
+		return
+	}
+	srv.log.Printf("client no-cache, reload schema of [%s]", schemaId)
+	srv.data.SetLocalSchema(schemaId, nil)
+}
+
 func (srv *Server) BuildRecord(payload map[string]interface{}, dataType string, dataId string) (*Record.Record, *Http.HttpError) {
 	if dataType == "" {
 		return nil, Http.NewHttpError(fmt.Sprintf("empty data type in path. [%s/%s]=''", Record.DataType, Record.DataId), http.StatusBadRequest)
@@ -262,7 +910,7 @@ func (srv *Server) BuildRecord(payload map[string]interface{}, dataType string,
 }
 
 func (srv *Server) handlePost(w http.ResponseWriter, r *http.Request, dataType string, dataId string) {
-	reqBody, err := Http.LoadRequest(r)
+	reqBody, err := Http.LoadJsonRequest(r, srv.config.Http)
 	if err != nil {
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
 		return
@@ -283,28 +931,223 @@ func (srv *Server) handlePost(w http.ResponseWriter, r *http.Request, dataType s
 			Http.ResponseJson(w, Http.NewHttpError("data id expect to be empty for action=[POST]", http.StatusBadRequest), http.StatusBadRequest, srv.config.Http)
 			return
 		}
-		record, ex = Record.LoadMap(payload)
+		record, ex = Record.LoadMap(srv.storedRecord(payload))
 		if ex != nil {
 			Http.ResponseJson(w, Http.WrapError(ex, "failed to load payload as Record", http.StatusBadRequest), http.StatusBadRequest, srv.config.Http)
 			return
 		}
 	} else {
-		record, err = srv.BuildRecord(payload, dataType, dataId)
+		record, err = srv.BuildRecord(srv.data.WireData(dataType, "", payload, Http.WireKeyFunc(srv.config.Http), true), dataType, dataId)
 		if err != nil {
 			Http.ResponseJson(w, err, err.Status, srv.config.Http)
 			return
 		}
 	}
-	err = srv.data.Add(record)
+	headers := Http.ParseHeaders(r)
+	record.ModifiedBy = srv.data.Actor(headers)
+	record.Tenant = srv.data.Tenant(headers)
+	err = srv.data.AddWith(record, headers)
 	if err != nil {
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
 		return
 	}
+	srv.setWarnings(w, record)
+	w.Header().Set(Http.HeaderLocation, Http.ResourcePath(srv.config.Http, record.Type, record.Id))
 	Http.ResponseText(w, []byte(record.Id), http.StatusCreated, srv.config.Http)
 }
 
+// record with names of its attributes in casing of client, data that is not record is kept as it is
+func (srv *Server) wireRecord(data interface{}) interface{} {
+	return srv.data.WireRecord(data, Http.WireKeyFunc(srv.config.Http), false)
+}
+
+// record from client with names of its attributes back in stored casing
+func (srv *Server) storedRecord(data interface{}) map[string]interface{} {
+	record, _ := srv.data.WireRecord(data, Http.WireKeyFunc(srv.config.Http), true).(map[string]interface{})
+	return record
+}
+
+// advisory findings are returned as Warning headers, write is not blocked by them
+func (srv *Server) setWarnings(w http.ResponseWriter, record *Record.Record) {
+	for _, warning := range srv.data.Warnings(record) {
+		w.Header().Add(Common.HeaderWarning, fmt.Sprintf("299 - %s", strconv.Quote(warning)))
+	}
+}
+
+func (srv *Server) handleOpenApi(w http.ResponseWriter) {
+	doc, err := srv.data.OpenApi(Version)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	Http.ResponseJson(w, doc, http.StatusOK, srv.config.Http)
+}
+
+func (srv *Server) handleSummary(w http.ResponseWriter) {
+	summary, err := srv.data.Summary()
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	Http.ResponseJson(w, summary, http.StatusOK, srv.config.Http)
+}
+
+func (srv *Server) handleImport(w http.ResponseWriter, r *http.Request, query url.Values) {
+	options := DataHandler.ImportOptions{}
+	if workers := query.Get(Common.QueryWorkers); workers != "" {
+		count, ex := strconv.Atoi(workers)
+		if ex != nil || count <= 0 {
+			err := Http.NewHttpError(fmt.Sprintf("invalid value of query [%s]=[%s], expect positive integer", Common.QueryWorkers, workers), http.StatusBadRequest)
+			Http.ResponseJson(w, err, err.Status, srv.config.Http)
+			return
+		}
+		options.Workers = count
+	}
+	stopOnError, err := queryFlag(query, Common.QueryStopOnError)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	options.StopOnError = stopOnError
+	coerce, err := queryFlag(query, Common.QueryCoerce)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	options.Coerce = coerce
+	options.Actor = srv.data.Actor(Http.ParseHeaders(r))
+	options.Tenant = srv.data.Tenant(Http.ParseHeaders(r))
+	srv.log.Printf("import records with options %v", options)
+	result, err := srv.data.Import(r.Body, options)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	Http.ResponseJson(w, result, http.StatusOK, srv.config.Http)
+}
+
+// array of records in body are written one by one, ?atomic writes them in one transaction of database
+func (srv *Server) handleBatch(w http.ResponseWriter, r *http.Request, query url.Values) {
+	atomic, err := queryFlag(query, Common.QueryAtomic)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	recordList, err := Http.LoadJsonListRequest(r, srv.config.Http)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	for idx, item := range recordList {
+		recordList[idx] = srv.data.WireRecord(item, Http.WireKeyFunc(srv.config.Http), true)
+	}
+	results, err := srv.data.Batch(recordList, Http.ParseHeaders(r), atomic)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	status := http.StatusOK
+	if atomic {
+		for _, result := range results {
+			if result.Error != nil && result.Status != http.StatusFailedDependency {
+				status = result.Status
+			}
+		}
+	}
+	Http.ResponseJson(w, results, status, srv.config.Http)
+}
+
+// records of array of {type, id} in body, in same order with null for missing record
+func (srv *Server) handleBatchGet(w http.ResponseWriter, r *http.Request) {
+	keyList, err := Http.LoadJsonListRequest(r, srv.config.Http)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	results, err := srv.data.BatchGet(keyList)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	for idx, data := range results {
+		results[idx] = srv.wireRecord(data)
+	}
+	Http.ResponseJson(w, results, http.StatusOK, srv.config.Http)
+}
+
+func (srv *Server) handleRename(w http.ResponseWriter, r *http.Request, dataType string, dataId string) {
+	reqBody, err := Http.LoadJsonRequest(r, srv.config.Http)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	payload, ok := reqBody.(map[string]interface{})
+	if !ok {
+		Http.ResponseJson(w, Http.NewHttpError("failed to parse request into JSON object", http.StatusBadRequest), http.StatusBadRequest, srv.config.Http)
+		return
+	}
+	newId, ok := payload[Common.KeyNewId].(string)
+	if !ok {
+		err = Http.NewHttpError(fmt.Sprintf("missing string field [%s] in request", Common.KeyNewId), http.StatusBadRequest)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	srv.log.Printf("RENAME [%s/%s] -> [%s]", dataType, dataId, newId)
+	refs, err := srv.data.Rename(dataType, dataId, newId)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	result := map[string]interface{}{
+		Common.KeyNewId: newId,
+		"refs":          refs,
+	}
+	Http.ResponseJson(w, result, http.StatusOK, srv.config.Http)
+}
+
+func (srv *Server) handleTouch(w http.ResponseWriter, r *http.Request, dataType string, dataId string) {
+	srv.log.Printf("TOUCH [%s/%s]", dataType, dataId)
+	result, err := srv.data.Touch(dataType, dataId, Http.ParseHeaders(r))
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	Http.ResponseJson(w, result, http.StatusOK, srv.config.Http)
+}
+
+func (srv *Server) handleRetype(w http.ResponseWriter, r *http.Request, dataType string, query url.Values) {
+	reqBody, err := Http.LoadJsonRequest(r, srv.config.Http)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	payload, ok := reqBody.(map[string]interface{})
+	if !ok {
+		Http.ResponseJson(w, Http.NewHttpError("failed to parse request into JSON object", http.StatusBadRequest), http.StatusBadRequest, srv.config.Http)
+		return
+	}
+	newType, ok := payload[Common.KeyNewType].(string)
+	if !ok {
+		err = Http.NewHttpError(fmt.Sprintf("missing string field [%s] in request", Common.KeyNewType), http.StatusBadRequest)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	dryRun, err := queryFlag(query, Common.QueryDryRun)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	srv.log.Printf("RETYPE [%s] -> [%s], dryRun=[%t]", dataType, newType, dryRun)
+	report, err := srv.data.Retype(dataType, newType, dryRun)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	Http.ResponseJson(w, report, http.StatusOK, srv.config.Http)
+}
+
 func (srv *Server) handlePut(w http.ResponseWriter, r *http.Request, dataType string, dataId string) {
-	reqBody, err := Http.LoadRequest(r)
+	reqBody, err := Http.LoadJsonRequest(r, srv.config.Http)
 	if err != nil {
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
 		return
@@ -316,30 +1159,42 @@ func (srv *Server) handlePut(w http.ResponseWriter, r *http.Request, dataType st
 	var record *Record.Record
 	var ex error
 	if len(r.Header.Values(Record.NotRecord)) == 0 {
-		record, ex = Record.LoadMap(payload)
+		record, ex = Record.LoadMap(srv.storedRecord(payload))
 		if ex != nil {
 			Http.ResponseJson(w, Http.WrapError(ex, "failed to load payload as Record", http.StatusBadRequest), http.StatusBadRequest, srv.config.Http)
 			return
 		}
 	} else {
-		record, err = srv.BuildRecord(payload, dataType, dataId)
+		record, err = srv.BuildRecord(srv.data.WireData(dataType, "", payload, Http.WireKeyFunc(srv.config.Http), true), dataType, dataId)
 		if err != nil {
 			Http.ResponseJson(w, err, err.Status, srv.config.Http)
 			return
 		}
 	}
-	err = srv.data.Set(dataType, dataId, record)
+	record.ModifiedBy = srv.data.Actor(Http.ParseHeaders(r))
+	record.Tenant = srv.data.Tenant(Http.ParseHeaders(r))
+	err = srv.data.SetIf(dataType, dataId, record, Http.ParseHeaders(r))
 	if err != nil {
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
 		return
 	}
+	srv.setWarnings(w, record)
+	w.Header().Set(Http.HeaderLocation, Http.ResourcePath(srv.config.Http, record.Type, record.Id))
 	Http.ResponseText(w, []byte(record.Id), http.StatusCreated, srv.config.Http)
 }
 
-func (srv *Server) handleDelete(w http.ResponseWriter, dataType string, dataId string) {
-	err := srv.data.Delete(dataType, dataId)
+// ?force deletes record even when other records ref it
+func (srv *Server) handleDelete(w http.ResponseWriter, dataType string, dataId string, query url.Values) {
+	force, err := queryFlag(query, Common.QueryForce)
+	if err != nil {
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
+	}
+	force = force || (query.Has(Common.QueryForce) && query.Get(Common.QueryForce) == "")
+	err = srv.data.DeleteChecked(dataType, dataId, force)
 	if err != nil {
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return
 	}
 	result := map[string]string{
 		"result": fmt.Sprintf("item [type/id]=[%s/%s] deleted", dataType, dataId),
@@ -348,18 +1203,78 @@ func (srv *Server) handleDelete(w http.ResponseWriter, dataType string, dataId s
 }
 
 func (srv *Server) handlePatch(w http.ResponseWriter, r *http.Request, dataType string, idPath string) {
-	payload, e := Http.LoadRequest(r)
+	if Http.RequestMediaType(r) == Http.MediaJsonPatch {
+		srv.handleJsonPatch(w, r, dataType, idPath)
+		return
+	}
+	payload, e := Http.LoadJsonRequest(r, srv.config.Http)
 	if e != nil {
 		srv.log.Printf("PATCH: [%s/%s] failed to load request, Error: %s", dataType, idPath, e)
 		Http.ResponseJson(w, e, e.Status, srv.config.Http)
 		return
 	}
 	headers := Http.ParseHeaders(r)
+	if Http.RequestMediaType(r) == Http.MediaMergePatch {
+		srv.handleMergePatch(w, dataType, idPath, headers, payload)
+		return
+	}
 	srv.log.Printf("PATCH [%s/%s]: call handler Patch", dataType, idPath)
 	response, e := srv.data.Patch(dataType, idPath, headers, payload)
 	if e != nil {
 		Http.ResponseJson(w, e, e.Status, srv.config.Http)
 		return
 	}
-	Http.ResponseJson(w, response, http.StatusAccepted, srv.config.Http)
+	Http.ResponseJson(w, srv.wireRecord(response), http.StatusAccepted, srv.config.Http)
+}
+
+// PATCH with Content-Type application/json-patch+json applies the operation list on data of the whole record
+func (srv *Server) handleJsonPatch(w http.ResponseWriter, r *http.Request, dataType string, dataId string) {
+	if !srv.patchWholeRecord(w, dataType, dataId) {
+		return
+	}
+	ops, e := Http.LoadJsonListRequest(r, srv.config.Http)
+	if e != nil {
+		Http.ResponseJson(w, e, e.Status, srv.config.Http)
+		return
+	}
+	srv.log.Printf("PATCH [%s/%s]: call handler JsonPatch", dataType, dataId)
+	response, e := srv.data.JsonPatch(dataType, recordId(dataId), Http.ParseHeaders(r), ops)
+	srv.patchResponse(w, response, e)
+}
+
+// PATCH with Content-Type application/merge-patch+json merges body into data of the whole record
+func (srv *Server) handleMergePatch(w http.ResponseWriter, dataType string, dataId string, headers map[string]interface{}, payload interface{}) {
+	if !srv.patchWholeRecord(w, dataType, dataId) {
+		return
+	}
+	patch, ok := payload.(map[string]interface{})
+	if !ok {
+		Http.ResponseJson(w, Http.NewHttpError("merge patch body must be JSON object", http.StatusBadRequest), http.StatusBadRequest, srv.config.Http)
+		return
+	}
+	srv.log.Printf("PATCH [%s/%s]: call handler MergePatch", dataType, dataId)
+	patch = srv.data.WireData(dataType, "", patch, Http.WireKeyFunc(srv.config.Http), true)
+	response, e := srv.data.MergePatch(dataType, recordId(dataId), headers, patch)
+	srv.patchResponse(w, response, e)
+}
+
+// patch by document applies to the whole record, path must be {dataType}/{dataId}
+func (srv *Server) patchWholeRecord(w http.ResponseWriter, dataType string, dataId string) bool {
+	if id, nextPath := Util.ParsePath(dataId); id == "" || nextPath != "" {
+		err := Http.NewHttpError(fmt.Sprintf("invalid path=[%s/%s], expect format=[{dataType}/{dataId}]", dataType, dataId), http.StatusBadRequest)
+		Http.ResponseJson(w, err, err.Status, srv.config.Http)
+		return false
+	}
+	return true
+}
+
+func (srv *Server) patchResponse(w http.ResponseWriter, response map[string]interface{}, e *Http.HttpError) {
+	if e != nil {
+		Http.ResponseJson(w, e, e.Status, srv.config.Http)
+		return
+	}
+	if record, ex := Record.LoadMap(response); ex == nil {
+		srv.setWarnings(w, record)
+	}
+	Http.ResponseJson(w, srv.wireRecord(response), http.StatusAccepted, srv.config.Http)
 }
@@ -114,9 +114,10 @@ func (srv *Server) Run() {
 		srv.log.Fatalf("failed to initialize data layer, Err:%s", err)
 	}
 	srv.data = handler
-	http.HandleFunc("/", srv.handler)
+	http.Handle("/", Http.SecurityHandler(Http.LimitHandler(http.HandlerFunc(srv.handler), srv.config.Http), srv.config.Http))
 	srv.log.Printf("Data Server Listen @%s://%s:%s", srv.config.Http.HttpType, srv.config.Http.DnsName, srv.Port)
-	srv.log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", srv.Port), nil))
+	server := Http.NewServer(fmt.Sprintf(":%s", srv.Port), nil, srv.config.Http)
+	srv.log.Fatal(server.ListenAndServe())
 }
 
 func (srv *Server) handler(w http.ResponseWriter, r *http.Request) {
@@ -150,6 +151,7 @@ func (srv *Server) handleGet(w http.ResponseWriter, r *http.Request) {
 			Http.ResponseJson(w, err, err.Status, srv.config.Http)
 			return
 		}
+		Http.SetCacheHeaders(w, srv.config.Http, dataType)
 		Http.ResponseJson(w, idList, http.StatusOK, srv.config.Http)
 		return
 	}
@@ -158,6 +160,7 @@ func (srv *Server) handleGet(w http.ResponseWriter, r *http.Request) {
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
 		return
 	}
+	Http.SetCacheHeaders(w, srv.config.Http, dataType)
 	Http.ResponseJson(w, data, http.StatusOK, srv.config.Http)
 }
 
@@ -171,7 +174,7 @@ func (srv *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 		Http.ResponseJson(w, err, err.Status, srv.config.Http)
 		return
 	}
-	reqBody, e := Http.LoadRequest(r)
+	reqBody, e := Http.LoadJsonRequest(r, srv.config.Http)
 	if e != nil {
 		Http.ResponseJson(w, e, e.Status, srv.config.Http)
 		return
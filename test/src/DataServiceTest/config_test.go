@@ -0,0 +1,137 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"DataService/Config"
+)
+
+const envConfig = `{
+	"database": {
+		"type": "mongodb",
+		"mongodb": {
+			"endpoint": "mongodb://file:27017",
+			"database": "fileDb",
+			"user": "fileUser",
+			"password": "filePass"
+		}
+	},
+	"table": {
+		"data": "fileTable"
+	},
+	"http": {
+		"type": "http",
+		"dns": "file.local",
+		"port": "8011"
+	}
+}`
+
+func readEnvConfig(t *testing.T, data string) Config.Confuguration {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	ex := os.WriteFile(configPath, []byte(data), 0644)
+	if ex != nil {
+		t.Fatalf("failed to write config. Error: %s", ex)
+	}
+	config := Config.Confuguration{}
+	ex = Config.Read(configPath, &config)
+	if ex != nil {
+		t.Fatalf("failed to read config. Error: %s", ex)
+	}
+	return config
+}
+
+func TestConfigEnv(t *testing.T) {
+	config := readEnvConfig(t, envConfig)
+	if config.Http.Port != "8011" || config.Database.Mongodb.EndPoint != "mongodb://file:27017" {
+		t.Fatalf("expect values of file when no env is set, got port=[%s] endpoint=[%s]", config.Http.Port, config.Database.Mongodb.EndPoint)
+	}
+	testList := []struct {
+		env   string
+		value string
+		field func(config Config.Confuguration) string
+	}{
+		{"UNITAO_PORT", "9011", func(c Config.Confuguration) string { return c.Http.Port }},
+		{"UNITAO_HTTP_TYPE", "https", func(c Config.Confuguration) string { return c.Http.HttpType }},
+		{"UNITAO_HTTP_DNS", "env.local", func(c Config.Confuguration) string { return c.Http.DnsName }},
+		{"UNITAO_HTTP_CERT_FILE", "/env/cert.pem", func(c Config.Confuguration) string { return c.Http.CertFile }},
+		{"UNITAO_HTTP_KEY_FILE", "/env/key.pem", func(c Config.Confuguration) string { return c.Http.KeyFile }},
+		{"UNITAO_DATA_TABLE", "envTable", func(c Config.Confuguration) string { return c.DataTable.Data }},
+		{"UNITAO_INVENTORY_URL", "http://inv:8004", func(c Config.Confuguration) string { return c.Inv.Url }},
+		{"UNITAO_DB_TYPE", "dynamodb", func(c Config.Confuguration) string { return c.Database.DbType }},
+		{"UNITAO_DYNAMODB_REGION", "us-west-2", func(c Config.Confuguration) string { return c.Database.Dynamodb.Region }},
+		{"UNITAO_DYNAMODB_ENDPOINT", "http://dynamo:8000", func(c Config.Confuguration) string { return c.Database.Dynamodb.EndPoint }},
+		{"UNITAO_DYNAMODB_ACCESS_KEY", "envAccess", func(c Config.Confuguration) string { return c.Database.Dynamodb.AccessKey }},
+		{"UNITAO_DYNAMODB_SECRET_KEY", "envSecret", func(c Config.Confuguration) string { return c.Database.Dynamodb.SecretKey }},
+		{"UNITAO_DYNAMODB_ACCESS_TOKEN", "envToken", func(c Config.Confuguration) string { return c.Database.Dynamodb.AccessToken }},
+		{"UNITAO_MONGODB_ENDPOINT", "mongodb://env:27017", func(c Config.Confuguration) string { return c.Database.Mongodb.EndPoint }},
+		{"UNITAO_MONGODB_DATABASE", "envDb", func(c Config.Confuguration) string { return c.Database.Mongodb.Database }},
+		{"UNITAO_MONGODB_USER", "envUser", func(c Config.Confuguration) string { return c.Database.Mongodb.UserName }},
+		{"UNITAO_MONGODB_PASSWORD", "envPass", func(c Config.Confuguration) string { return c.Database.Mongodb.Password }},
+		{"UNITAO_SYSDIRFILE_PATH", "/env/data", func(c Config.Confuguration) string { return c.Database.SysDirFile.Path }},
+	}
+	for _, test := range testList {
+		t.Run(test.env, func(t *testing.T) {
+			t.Setenv(test.env, test.value)
+			config := readEnvConfig(t, envConfig)
+			if test.field(config) != test.value {
+				t.Fatalf("expect [%s] to override config with [%s], got [%s]", test.env, test.value, test.field(config))
+			}
+			if test.env != "UNITAO_HTTP_DNS" && config.Http.DnsName != "file.local" {
+				t.Fatalf("expect [%s] to leave other values of file, got dns=[%s]", test.env, config.Http.DnsName)
+			}
+		})
+	}
+	t.Setenv("UNITAO_PORT", "")
+	config = readEnvConfig(t, envConfig)
+	if config.Http.Port != "8011" {
+		t.Fatalf("expect empty env to leave value of file, got port=[%s]", config.Http.Port)
+	}
+}
+
+func TestConfigListenPort(t *testing.T) {
+	testList := []struct {
+		flag   string
+		env    string
+		config string
+		port   string
+	}{
+		{"7011", "9011", envConfig, "7011"},
+		{"", "9011", envConfig, "9011"},
+		{"", "", envConfig, "8011"},
+		{"", "", `{"table": {"data": "fileTable"}}`, "8010"},
+	}
+	for _, test := range testList {
+		t.Setenv("UNITAO_PORT", test.env)
+		config := readEnvConfig(t, test.config)
+		if port := config.ListenPort(test.flag, "8010"); port != test.port {
+			t.Fatalf("expect port [%s] of flag=[%s] env=[%s], got [%s]", test.port, test.flag, test.env, port)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestAppendOnlyAttr(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "ticket",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "ticket",
+				"version": "0.0.1",
+				"properties": {
+					"status": {
+						"type": "string"
+					},
+					"events": {
+						"type": "array",
+						"appendOnly": true,
+						"items": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		}`,
+		`{"__id": "t01", "__type": "ticket", "__ver": "0.0.1", "data": {"status": "open", "events": ["created", "assigned"]}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	setEvents := func(events ...interface{}) *Record.Record {
+		return Record.NewRecord("ticket", "0.0.1", "t01", map[string]interface{}{
+			"status": "open",
+			"events": events,
+		})
+	}
+	err := handler.Set("ticket", "t01", setEvents("created", "assigned", "resolved"))
+	if err != nil {
+		t.Fatalf("failed to append to events. Error: %s", err)
+	}
+	rejectList := map[string]*Record.Record{
+		"changed":   setEvents("created", "reopened", "resolved"),
+		"reordered": setEvents("assigned", "created", "resolved"),
+		"removed":   setEvents("created", "assigned"),
+		"cleared":   Record.NewRecord("ticket", "0.0.1", "t01", map[string]interface{}{"status": "open", "events": nil}),
+	}
+	for name, record := range rejectList {
+		err = handler.Set("ticket", "t01", record)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("expect 400 on [%s] events, got %v", name, err)
+		}
+	}
+	_, err = handler.Patch("ticket", "t01/events", nil, []interface{}{"created", "assigned", "resolved", "closed"})
+	if err != nil {
+		t.Fatalf("failed to append to events by patch. Error: %s", err)
+	}
+	_, err = handler.Patch("ticket", "t01/events", nil, []interface{}{"closed"})
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expect 400 on patch that rewrites events, got %v", err)
+	}
+	_, err = handler.Patch("ticket", "t01/status", nil, "closed")
+	if err != nil {
+		t.Fatalf("failed to patch other attr. Error: %s", err)
+	}
+	record, err := handler.GetRecord("ticket", "t01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	events := record.Data["events"].([]interface{})
+	if len(events) != 4 || events[3] != "closed" {
+		t.Fatalf("expect 4 events ending with closed, got %v", events)
+	}
+}
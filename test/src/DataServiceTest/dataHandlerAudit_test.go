@@ -0,0 +1,174 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"DataService/Common"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestAuditFields(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	schemaRec, ex := Record.LoadStr(`{
+		"__id": "auditTest",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "auditTest",
+			"version": "0.0.1",
+			"properties": {
+				"value": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if ex != nil {
+		t.Fatalf("failed to load schema record. Error: %s", ex)
+	}
+	err := handler.Add(schemaRec)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	if schemaRec.CreatedBy != Common.DefaultAnonymous {
+		t.Fatalf("expect anonymous creator without actor, got [%s]", schemaRec.CreatedBy)
+	}
+	actorHeader := map[string]interface{}{"x-actor": "alice"}
+	record := Record.NewRecord("auditTest", "0.0.1", "audit01", map[string]interface{}{"value": "v1"})
+	record.CreatedBy = "mallory"
+	record.ModifiedBy = handler.Actor(actorHeader)
+	err = handler.Add(record)
+	if err != nil {
+		t.Fatalf("failed to add record. Error: %s", err)
+	}
+	stored, err := handler.GetRecord("auditTest", "audit01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	if stored.CreatedBy != "alice" || stored.ModifiedBy != "alice" {
+		t.Fatalf("expect created/modified by [alice], got [%s]/[%s]", stored.CreatedBy, stored.ModifiedBy)
+	}
+	record = Record.NewRecord("auditTest", "0.0.1", "audit01", map[string]interface{}{"value": "v2"})
+	record.CreatedBy = "mallory"
+	record.ModifiedBy = handler.Actor(map[string]interface{}{"x-actor": "bob"})
+	err = handler.Set("", "", record)
+	if err != nil {
+		t.Fatalf("failed to set record. Error: %s", err)
+	}
+	stored, _ = handler.GetRecord("auditTest", "audit01")
+	if stored.CreatedBy != "alice" || stored.ModifiedBy != "bob" {
+		t.Fatalf("expect created by [alice] modified by [bob], got [%s]/[%s]", stored.CreatedBy, stored.ModifiedBy)
+	}
+	_, err = handler.Patch("auditTest", "audit01/value", map[string]interface{}{"x-actor": "carol"}, "v3")
+	if err != nil {
+		t.Fatalf("failed to patch record. Error: %s", err)
+	}
+	stored, _ = handler.GetRecord("auditTest", "audit01")
+	if stored.CreatedBy != "alice" || stored.ModifiedBy != "carol" {
+		t.Fatalf("expect created by [alice] modified by [carol], got [%s]/[%s]", stored.CreatedBy, stored.ModifiedBy)
+	}
+	_, err = handler.Patch("auditTest", "audit01/"+Record.CreatedBy, actorHeader, "mallory")
+	if err == nil {
+		t.Fatalf("patch on [%s] should be rejected", Record.CreatedBy)
+	}
+	_, err = handler.Patch("auditTest", "audit01/value", nil, "v4")
+	if err != nil {
+		t.Fatalf("failed to patch record. Error: %s", err)
+	}
+	stored, _ = handler.GetRecord("auditTest", "audit01")
+	if stored.ModifiedBy != Common.DefaultAnonymous {
+		t.Fatalf("expect modified by [%s] without actor header, got [%s]", Common.DefaultAnonymous, stored.ModifiedBy)
+	}
+}
+
+func TestRecordTimestamps(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "stampTest",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "stampTest",
+			"version": "0.0.1",
+			"properties": {
+				"value": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	record := Record.NewRecord("stampTest", "0.0.1", "stamp01", map[string]interface{}{"value": "v1"})
+	record.Created = "2000-01-01T00:00:00Z"
+	err = handler.Add(record)
+	if err != nil {
+		t.Fatalf("failed to add record. Error: %s", err)
+	}
+	stored, err := handler.GetRecord("stampTest", "stamp01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	created := stored.Created
+	if created == "" || created != stored.Modified {
+		t.Fatalf("expect created and modified set on add, got [%s]/[%s]", stored.Created, stored.Modified)
+	}
+	if stamp, _ := stored.CreatedTime(); stamp.Year() == 2000 {
+		t.Fatalf("created time from caller should be replaced, got [%s]", stored.Created)
+	}
+	time.Sleep(time.Millisecond)
+	record = Record.NewRecord("stampTest", "0.0.1", "stamp01", map[string]interface{}{"value": "v2"})
+	err = handler.Set("", "", record)
+	if err != nil {
+		t.Fatalf("failed to set record. Error: %s", err)
+	}
+	stored, _ = handler.GetRecord("stampTest", "stamp01")
+	if stored.Created != created || stored.Modified == created {
+		t.Fatalf("expect created [%s] kept and modified renewed on set, got [%s]/[%s]", created, stored.Created, stored.Modified)
+	}
+	_, err = handler.Patch("stampTest", "stamp01/value", nil, "v3")
+	if err != nil {
+		t.Fatalf("failed to patch record. Error: %s", err)
+	}
+	stored, _ = handler.GetRecord("stampTest", "stamp01")
+	if stored.Created != created {
+		t.Fatalf("expect created [%s] kept on patch, got [%s]", created, stored.Created)
+	}
+	_, err = handler.Patch("stampTest", "stamp01/"+Record.Created, nil, "2000-01-01T00:00:00Z")
+	if err == nil {
+		t.Fatalf("patch on [%s] should be rejected", Record.Created)
+	}
+}
@@ -0,0 +1,300 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"Data/DbIface"
+	"DataService/DataHandler"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func batchList(t *testing.T, data string) []interface{} {
+	recordList := []interface{}{}
+	ex := json.Unmarshal([]byte(data), &recordList)
+	if ex != nil {
+		t.Fatalf("failed to load batch. Error: %s", ex)
+	}
+	return recordList
+}
+
+func batchStatus(results []DataHandler.BatchResult) string {
+	statusList := []int{}
+	for _, result := range results {
+		statusList = append(statusList, result.Status)
+	}
+	return fmt.Sprint(statusList)
+}
+
+type countDb struct {
+	DbIface.Database
+	gets map[string]int
+}
+
+func (d *countDb) Get(queryArgs map[string]interface{}) ([]map[string]interface{}, error) {
+	d.gets[fmt.Sprintf("%v/%v", queryArgs[Record.DataType], queryArgs[Record.DataId])]++
+	return d.Database.Get(queryArgs)
+}
+
+// puts back writes made while fn runs when it fails, stands in for database with transaction
+type txDb struct {
+	DbIface.Database
+	undo []func()
+	inTx bool
+}
+
+func (d *txDb) Transaction(fn func() error) error {
+	d.undo = []func(){}
+	d.inTx = true
+	err := fn()
+	d.inTx = false
+	if err != nil {
+		for idx := len(d.undo) - 1; idx >= 0; idx-- {
+			d.undo[idx]()
+		}
+	}
+	d.undo = nil
+	return err
+}
+
+func (d *txDb) keep(table string, keys map[string]interface{}) {
+	if d.undo == nil {
+		return
+	}
+	args := map[string]interface{}{DbIface.Table: table}
+	for key, value := range keys {
+		args[key] = value
+	}
+	before, _ := d.Database.Get(args)
+	d.undo = append(d.undo, func() {
+		if len(before) == 0 {
+			d.Database.Delete(table, keys)
+			return
+		}
+		d.Database.Replace(table, keys, before[0])
+	})
+}
+
+func (d *txDb) Create(table string, data interface{}) error {
+	record, _ := data.(map[string]interface{})
+	d.keep(table, map[string]interface{}{Record.DataType: record[Record.DataType], Record.DataId: record[Record.DataId]})
+	return d.Database.Create(table, data)
+}
+
+func (d *txDb) Update(table string, keys map[string]interface{}, data interface{}) (map[string]interface{}, error) {
+	d.keep(table, keys)
+	return d.Database.Update(table, keys, data)
+}
+
+func (d *txDb) Replace(table string, keys map[string]interface{}, data interface{}) error {
+	d.keep(table, keys)
+	return d.Database.Replace(table, keys, data)
+}
+
+func (d *txDb) Delete(table string, keys map[string]interface{}) error {
+	d.keep(table, keys)
+	return d.Database.Delete(table, keys)
+}
+
+func TestBatch(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		historySchema("0.0.1"),
+		`{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	docName := func(dataId string) interface{} {
+		data, err := handler.LocalData("doc", dataId)
+		if err != nil {
+			return nil
+		}
+		return data[Record.Data].(map[string]interface{})["name"]
+	}
+	results, err := handler.Batch(batchList(t, `[
+		{"__id": "doc02", "__type": "doc", "__ver": "0.0.1", "data": {"name": "two"}},
+		{"__id": "doc03", "__type": "doc", "__ver": "0.0.1", "data": {"name": 3}},
+		{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "uno"}},
+		"doc04"
+	]`), nil, false)
+	if err != nil {
+		t.Fatalf("failed to write batch. Error: %s", err)
+	}
+	if batchStatus(results) != "[201 400 200 400]" || results[1].Error == nil {
+		t.Fatalf("expect result of each item, got %s", batchStatus(results))
+	}
+	if docName("doc01") != "uno" || docName("doc02") != "two" || docName("doc03") != nil {
+		t.Fatalf("expect valid items written, got doc01=[%v] doc02=[%v] doc03=[%v]", docName("doc01"), docName("doc02"), docName("doc03"))
+	}
+	_, err = handler.Batch(batchList(t, `[
+		{"__id": "doc05", "__type": "doc", "__ver": "0.0.1", "data": {"name": "five"}}
+	]`), nil, true)
+	if err == nil || err.Status != http.StatusNotImplemented || docName("doc05") != nil {
+		t.Fatalf("atomic batch on database without transaction should fail with [%d], got %v", http.StatusNotImplemented, err)
+	}
+	handler.DB = &txDb{Database: handler.DB}
+	results, err = handler.Batch(batchList(t, `[
+		{"__id": "doc05", "__type": "doc", "__ver": "0.0.1", "data": {"name": "five"}},
+		{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}},
+		{"__id": "doc06", "__type": "doc", "__ver": "0.0.1", "data": {"name": 6}},
+		{"__id": "doc07", "__type": "doc", "__ver": "0.0.1", "data": {"name": "seven"}}
+	]`), nil, true)
+	if err != nil {
+		t.Fatalf("failed to write atomic batch. Error: %s", err)
+	}
+	if batchStatus(results) != "[424 424 400 424]" {
+		t.Fatalf("expect atomic batch to fail @[2] and roll back, got %s", batchStatus(results))
+	}
+	if docName("doc01") != "uno" || docName("doc05") != nil || docName("doc07") != nil {
+		t.Fatalf("expect atomic batch rolled back, got doc01=[%v] doc05=[%v] doc07=[%v]", docName("doc01"), docName("doc05"), docName("doc07"))
+	}
+	_, err = handler.Batch(batchList(t, `[
+		{"__id": "doc05", "__type": "doc", "__ver": "0.0.1", "data": {"name": "five"}},
+		"doc06"
+	]`), nil, true)
+	if err == nil || err.Status != http.StatusBadRequest || docName("doc05") != nil {
+		t.Fatalf("atomic batch with invalid item should fail with [%d] before write, got %v", http.StatusBadRequest, err)
+	}
+	_, err = handler.Batch(batchList(t, fmt.Sprintf(`[%s]`, historySchema("0.0.2"))), nil, true)
+	if err == nil || err.Status != http.StatusNotImplemented {
+		t.Fatalf("atomic batch of schema should fail with [%d], got %v", http.StatusNotImplemented, err)
+	}
+	results, err = handler.Batch(batchList(t, `[
+		{"__id": "doc05", "__type": "doc", "__ver": "0.0.1", "data": {"name": "five"}},
+		{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}}
+	]`), nil, true)
+	if err != nil || batchStatus(results) != "[201 200]" || docName("doc01") != "one" || docName("doc05") != "five" {
+		t.Fatalf("expect atomic batch written, got %v, Error: %v", results, err)
+	}
+}
+
+func TestBatchJournal(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, historySchema("0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	db := &txDb{Database: handler.DB}
+	handler.DB = db
+	journaled := []string{}
+	handler.AddJournal = func(dataType string, dataId string, before map[string]interface{}, after map[string]interface{}) *Http.HttpError {
+		if db.inTx {
+			t.Fatalf("change of [%s/%s] journaled before atomic batch commits", dataType, dataId)
+		}
+		journaled = append(journaled, fmt.Sprintf("%s/%s", dataType, dataId))
+		return nil
+	}
+	_, err = handler.Batch(batchList(t, `[
+		{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}},
+		{"__id": "doc02", "__type": "doc", "__ver": "0.0.1", "data": {"name": 2}}
+	]`), nil, true)
+	if err != nil {
+		t.Fatalf("failed to write atomic batch. Error: %s", err)
+	}
+	if len(journaled) != 0 {
+		t.Fatalf("expect no change journaled for atomic batch rolled back, got %v", journaled)
+	}
+	results, err := handler.Batch(batchList(t, `[
+		{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}},
+		{"__id": "doc02", "__type": "doc", "__ver": "0.0.1", "data": {"name": "two"}}
+	]`), nil, true)
+	if err != nil || batchStatus(results) != "[201 201]" {
+		t.Fatalf("expect atomic batch written, got %v, Error: %v", results, err)
+	}
+	if fmt.Sprint(journaled) != "[doc/doc01 doc/doc02]" {
+		t.Fatalf("expect changes journaled in order after commit, got %v", journaled)
+	}
+}
+
+func TestBatchGet(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		historySchema("0.0.1"),
+		`{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}}`,
+		`{"__id": "doc02", "__type": "doc", "__ver": "0.0.1", "data": {"name": "two"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	db := &countDb{Database: handler.DB, gets: map[string]int{}}
+	handler.DB = db
+	results, err := handler.BatchGet(batchList(t, `[
+		{"type": "doc", "id": "doc02"},
+		{"type": "doc", "id": "doc09"},
+		{"type": "doc", "id": "doc01"},
+		{"type": "doc", "id": "doc02"}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to get batch. Error: %s", err)
+	}
+	idList := []interface{}{}
+	for _, result := range results {
+		if result == nil {
+			idList = append(idList, nil)
+			continue
+		}
+		idList = append(idList, result.(map[string]interface{})[Record.DataId])
+	}
+	if fmt.Sprint(idList) != "[doc02 <nil> doc01 doc02]" {
+		t.Fatalf("expect records in order of keys with nil for missing one, got %v", idList)
+	}
+	if db.gets["doc/doc02"] != 1 {
+		t.Fatalf("expect same key read once, got [%d] reads of doc/doc02", db.gets["doc/doc02"])
+	}
+	for _, keys := range []string{`[{"type": "doc"}]`, `["doc01"]`, `[{"type": "doc", "id": "doc01/data"}]`} {
+		_, err = handler.BatchGet(batchList(t, keys))
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("batch get of invalid keys %s should fail with [%d], got %v", keys, http.StatusBadRequest, err)
+		}
+	}
+	handler.Config.Batch.MaxSize = 2
+	_, err = handler.BatchGet(batchList(t, `[{"type": "doc", "id": "doc01"}, {"type": "doc", "id": "doc02"}, {"type": "doc", "id": "doc03"}]`))
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("batch get over max size should fail with [%d], got %v", http.StatusBadRequest, err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatTable(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "switch",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "switch",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"model": {
+						"type": "string",
+						"required": false
+					},
+					"ports": {
+						"type": "array",
+						"required": false,
+						"items": {
+							"type": "object",
+							"$ref": "#/definitions/port"
+						}
+					}
+				},
+				"definitions": {
+					"port": {
+						"name": "port",
+						"key": "{name}",
+						"properties": {
+							"name": {
+								"type": "string"
+							},
+							"speed": {
+								"type": "integer"
+							}
+						}
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "sw01",
+			"__type": "switch",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "sw01",
+				"ports": [
+					{"name": "e1", "speed": 10}
+				]
+			}
+		}`,
+		`{
+			"__id": "sw02",
+			"__type": "switch",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "sw02",
+				"model": "x100"
+			}
+		}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	rows, err := handler.FlatTable("switch", []interface{}{"sw01", "sw02"})
+	if err != nil {
+		t.Fatalf("failed to flatten records. Error: %s", err)
+	}
+	expected := [][]string{
+		{"__id", "model", "name", "ports[e1].name", "ports[e1].speed"},
+		{"sw01", "", "sw01", "e1", "10"},
+		{"sw02", "x100", "sw02", "", ""},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("table mismatch, expect %v, got %v", expected, rows)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"Data/DbIface"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// database that indexes refs, answers every target with fixed referrers
+type refIndexDb struct {
+	DbIface.Database
+	refs []string
+}
+
+func (d *refIndexDb) FindRefs(table string, dataType string, dataId string) ([]string, error) {
+	return d.refs, nil
+}
+
+func TestDeleteCheckRefs(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "delTarget",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "delTarget",
+				"version": "0.0.1",
+				"properties": {
+					"value": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "delHolder",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "delHolder",
+				"version": "0.0.1",
+				"properties": {
+					"targets": {
+						"type": "array",
+						"items": {
+							"type": "string",
+							"contentMediaType": "inventory/delTarget"
+						}
+					}
+				}
+			}
+		}`,
+		`{"__id": "target01", "__type": "delTarget", "__ver": "0.0.1", "data": {"value": "01"}}`,
+		`{"__id": "target02", "__type": "delTarget", "__ver": "0.0.1", "data": {"value": "02"}}`,
+		`{"__id": "holder01", "__type": "delHolder", "__ver": "0.0.1", "data": {"targets": ["target01"]}}`,
+		`{"__id": "holder02", "__type": "delHolder", "__ver": "0.0.1", "data": {"targets": ["target02", "target01"]}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	refs, err := handler.InboundRefs("delTarget", "target01")
+	if err != nil {
+		t.Fatalf("failed to find refs. Error: %s", err)
+	}
+	if !reflect.DeepEqual(refs, []string{"delHolder/holder01", "delHolder/holder02"}) {
+		t.Fatalf("expect both holders ref target01, got %v", refs)
+	}
+	err = handler.Delete("delTarget", "target01")
+	if err != nil {
+		t.Fatalf("expect unconditional delete when check is off. Error: %s", err)
+	}
+	handler.Config.Delete.CheckRefs = true
+	err = handler.Delete("delTarget", "target02")
+	if err == nil || err.Status != http.StatusConflict {
+		t.Fatalf("expect [%d] on delete of referenced record, got %v", http.StatusConflict, err)
+	}
+	if fmt.Sprint(err.Payload) != "[delHolder/holder02]" {
+		t.Fatalf("expect referrer in payload, got %v", err.Payload)
+	}
+	_, err = handler.GetRecord("delTarget", "target02")
+	if err != nil {
+		t.Fatalf("record should stay when delete is refused. Error: %s", err)
+	}
+	err = handler.DeleteChecked("delTarget", "target02", true)
+	if err != nil {
+		t.Fatalf("failed to force delete. Error: %s", err)
+	}
+	err = handler.Delete("delHolder", "holder02")
+	if err != nil {
+		t.Fatalf("failed to delete record nobody refs. Error: %s", err)
+	}
+	handler.DB = &refIndexDb{Database: handler.DB, refs: []string{"delHolder/holder01", "delTarget/target03"}}
+	refs, err = handler.InboundRefs("delTarget", "target03")
+	if err != nil {
+		t.Fatalf("failed to find refs with database index. Error: %s", err)
+	}
+	if !reflect.DeepEqual(refs, []string{"delHolder/holder01"}) {
+		t.Fatalf("expect refs from database index without self ref, got %v", refs)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "host",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "host",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"owner": {
+						"type": "string",
+						"required": false
+					},
+					"labels": {
+						"type": "map",
+						"items": {
+							"type": "string"
+						},
+						"required": false
+					}
+				}
+			}
+		}`,
+		`{"__id": "h01", "__type": "host", "__ver": "0.0.1", "data": {"name": "h01", "owner": "ops", "labels": {"env": "prod", "zone": "a"}}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	patch := map[string]interface{}{
+		"owner": nil,
+		"labels": map[string]interface{}{
+			"zone": nil,
+			"rack": "r1",
+		},
+	}
+	result, err := handler.MergePatch("host", "h01", map[string]interface{}{}, patch)
+	if err != nil {
+		t.Fatalf("failed to merge patch. Error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"name": "h01",
+		"labels": map[string]interface{}{
+			"env":  "prod",
+			"rack": "r1",
+		},
+	}
+	if !reflect.DeepEqual(result["data"], expected) {
+		t.Fatalf("unexpected merged data %v", result["data"])
+	}
+	record, err := handler.GetRecord("host", "h01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	if !reflect.DeepEqual(record.Data, expected) {
+		t.Fatalf("merged data not stored, got %v", record.Data)
+	}
+	_, err = handler.MergePatch("host", "h01", map[string]interface{}{}, map[string]interface{}{"name": nil})
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expect 400 on removing required attr, got %v", err)
+	}
+	_, err = handler.MergePatch("host", "h02", map[string]interface{}{}, map[string]interface{}{"owner": "dev"})
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("expect 404 on missing record, got %v", err)
+	}
+}
+
+func TestJsonPatch(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "host",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "host",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"tags": {
+						"type": "array",
+						"items": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		}`,
+		`{"__id": "h01", "__type": "host", "__ver": "0.0.1", "data": {"name": "h01", "tags": ["a", "b"]}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	loadOps := func(opsStr string) []interface{} {
+		ops := []interface{}{}
+		ex := json.Unmarshal([]byte(opsStr), &ops)
+		if ex != nil {
+			t.Fatalf("failed to load ops. Error: %s", ex)
+		}
+		return ops
+	}
+	result, err := handler.JsonPatch("host", "h01", map[string]interface{}{}, loadOps(`[
+		{"op": "test", "path": "/tags/0", "value": "a"},
+		{"op": "add", "path": "/tags/1", "value": "new"},
+		{"op": "remove", "path": "/tags/0"}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to json patch. Error: %s", err)
+	}
+	expected := []interface{}{"new", "b"}
+	if !reflect.DeepEqual(result["data"].(map[string]interface{})["tags"], expected) {
+		t.Fatalf("unexpected patched data %v", result["data"])
+	}
+	_, err = handler.JsonPatch("host", "h01", map[string]interface{}{}, loadOps(`[
+		{"op": "add", "path": "/tags/-", "value": "c"},
+		{"op": "test", "path": "/name", "value": "h02"}
+	]`))
+	if err == nil || err.Status != http.StatusConflict {
+		t.Fatalf("expect 409 on failed test op, got %v", err)
+	}
+	_, err = handler.JsonPatch("host", "h01", map[string]interface{}{}, loadOps(`[{"op": "remove", "path": "/tags/5"}]`))
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expect 400 on invalid op, got %v", err)
+	}
+	record, err := handler.GetRecord("host", "h01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	if !reflect.DeepEqual(record.Data["tags"], expected) {
+		t.Fatalf("expect record unchanged by aborted patch, got %v", record.Data)
+	}
+}
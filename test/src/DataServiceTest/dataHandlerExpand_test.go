@@ -0,0 +1,199 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestGetExpanded(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "refItem",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "refItem",
+				"version": "0.0.1",
+				"key": "{name}",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"value": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "holder",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "holder",
+				"version": "0.0.1",
+				"properties": {
+					"owner": {
+						"type": "string",
+						"contentMediaType": "inventory/refItem"
+					},
+					"itemArray": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"$ref": "#/definitions/item"
+						}
+					}
+				},
+				"definitions": {
+					"item": {
+						"name": "item",
+						"key": "{name}",
+						"properties": {
+							"name": {
+								"type": "string"
+							},
+							"refIdx": {
+								"type": "string",
+								"contentMediaType": "inventory/refItem"
+							}
+						}
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "ref01",
+			"__type": "refItem",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "ref01",
+				"value": "value01"
+			}
+		}`,
+		`{
+			"__id": "ref02",
+			"__type": "refItem",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "ref02",
+				"value": "value02"
+			}
+		}`,
+		`{
+			"__id": "holder01",
+			"__type": "holder",
+			"__ver": "0.0.1",
+			"data": {
+				"owner": "ref01",
+				"itemArray": [
+					{
+						"name": "item01",
+						"refIdx": "ref02"
+					}
+				]
+			}
+		}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	result, err := handler.GetExpanded("holder", "holder01", []string{"itemArray.refIdx"})
+	if err != nil {
+		t.Fatalf("failed to get expanded record. Error: %s", err)
+	}
+	record, ex := Record.LoadMap(result)
+	if ex != nil {
+		t.Fatalf("failed to load expanded result as record. Error: %s", ex)
+	}
+	if record.Data["owner"] != "ref01" {
+		t.Fatalf("attr [owner] not in expand list should stay as ref, got [%v]", record.Data["owner"])
+	}
+	item := record.Data["itemArray"].([]interface{})[0].(map[string]interface{})
+	if item["name"] != "item01" {
+		t.Fatalf("item data changed by expand, [name]=[%v]", item["name"])
+	}
+	refData, ok := item["refIdx"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("failed to expand [itemArray.refIdx], got [%v]", item["refIdx"])
+	}
+	refRecord, ex := Record.LoadMap(refData)
+	if ex != nil {
+		t.Fatalf("expanded ref is not a record. Error: %s", ex)
+	}
+	if refRecord.Id != "ref02" || refRecord.Data["value"] != "value02" {
+		t.Fatalf("expanded wrong ref record [%s]", refRecord.Id)
+	}
+	stored, err := handler.LocalData("holder", "holder01")
+	if err != nil {
+		t.Fatalf("failed to get stored record. Error: %s", err)
+	}
+	storedItem := stored[Record.Data].(map[string]interface{})["itemArray"].([]interface{})[0].(map[string]interface{})
+	if storedItem["refIdx"] != "ref02" {
+		t.Fatalf("stored record changed by expand")
+	}
+	_, err = handler.GetExpanded("holder", "holder01", []string{"itemArray.name"})
+	if err == nil {
+		t.Fatalf("expand on attr which is not a ref should fail")
+	}
+	result, err = handler.GetExpanded("holder", "holder01", []string{"itemArray.refIdx(value)", "owner(name,missing)"})
+	if err != nil {
+		t.Fatalf("failed to get expanded record with projection. Error: %s", err)
+	}
+	record, _ = Record.LoadMap(result)
+	item = record.Data["itemArray"].([]interface{})[0].(map[string]interface{})
+	refRecord, ex = Record.LoadMap(item["refIdx"].(map[string]interface{}))
+	if ex != nil {
+		t.Fatalf("projected ref is not a record. Error: %s", ex)
+	}
+	if refRecord.Id != "ref02" || len(refRecord.Data) != 1 || refRecord.Data["value"] != "value02" {
+		t.Fatalf("expect only [value] inlined for [itemArray.refIdx], got [%v]", refRecord.Data)
+	}
+	ownerRecord, ex := Record.LoadMap(record.Data["owner"].(map[string]interface{}))
+	if ex != nil {
+		t.Fatalf("projected owner is not a record. Error: %s", ex)
+	}
+	if len(ownerRecord.Data) != 1 || ownerRecord.Data["name"] != "ref01" {
+		t.Fatalf("expect only [name] inlined for [owner], got [%v]", ownerRecord.Data)
+	}
+	for _, expandPath := range []string{"owner(name", "owner()", "itemArray..refIdx"} {
+		_, err = handler.GetExpanded("holder", "holder01", []string{expandPath})
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("invalid expand path [%s] should return err.Code=[%d], got [%v]", expandPath, http.StatusBadRequest, err)
+		}
+	}
+}
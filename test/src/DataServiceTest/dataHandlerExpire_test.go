@@ -0,0 +1,199 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestRecordExpire(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "lease",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "lease",
+				"version": "0.0.1",
+				"ttl": 3600,
+				"ttlAttr": "leaseTime",
+				"properties": {
+					"holder": {
+						"type": "string"
+					},
+					"leaseTime": {
+						"type": "integer",
+						"required": false
+					}
+				}
+			}
+		}`,
+		`{"__id": "short", "__type": "lease", "__ver": "0.0.1", "data": {"holder": "a", "leaseTime": 1}}`,
+		`{"__id": "long", "__type": "lease", "__ver": "0.0.1", "data": {"holder": "b"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	data, err := handler.Get("lease", "long")
+	if err != nil {
+		t.Fatalf("failed to get record before expire. Error: %s", err)
+	}
+	ttl, ok := data.(map[string]interface{})[Record.Ttl].(float64)
+	if !ok || ttl <= 3500 || ttl > 3600 {
+		t.Fatalf("expect remaining ttl close to 3600, got [%v]", data.(map[string]interface{})[Record.Ttl])
+	}
+	time.Sleep(1100 * time.Millisecond)
+	_, err = handler.Get("lease", "short")
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("expect 404 on expired record, got [%v]", err)
+	}
+	idList, err := handler.List("lease")
+	if err != nil {
+		t.Fatalf("failed to list. Error: %s", err)
+	}
+	if len(idList) != 1 || idList[0] != "long" {
+		t.Fatalf("expect expired record excluded from list, got %v", idList)
+	}
+	purged, err := handler.Sweep("lease")
+	if err != nil {
+		t.Fatalf("failed to sweep. Error: %s", err)
+	}
+	if len(purged) != 1 || purged[0] != "short" {
+		t.Fatalf("expect sweep to purge [short], got %v", purged)
+	}
+	recordList, err := handler.QueryDb("lease", "short")
+	if err != nil {
+		t.Fatalf("failed to query db. Error: %s", err)
+	}
+	if len(recordList) != 0 {
+		t.Fatalf("expect expired record removed from db by sweep")
+	}
+	_, err = handler.Get("lease", "long")
+	if err != nil {
+		t.Fatalf("sweep should keep record not expired. Error: %s", err)
+	}
+	err = AddData(handler, `{"__id": "long", "__type": "lease", "__ver": "0.0.1", "data": {"holder": "c"}}`)
+	if err == nil || err.Status != http.StatusConflict {
+		t.Fatalf("expect conflict on record not expired, got [%v]", err)
+	}
+}
+
+func TestRecordExpireReAdd(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "session",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "session",
+				"version": "0.0.1",
+				"ttl": 1,
+				"properties": {
+					"user": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{"__id": "s01", "__type": "session", "__ver": "0.0.1", "data": {"user": "a"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	time.Sleep(1100 * time.Millisecond)
+	err := AddData(handler, `{"__id": "s01", "__type": "session", "__ver": "0.0.1", "data": {"user": "b"}}`)
+	if err != nil {
+		t.Fatalf("expect add over expired record to succeed. Error: %s", err)
+	}
+	record, err := handler.GetRecord("session", "s01")
+	if err != nil {
+		t.Fatalf("failed to get renewed record. Error: %s", err)
+	}
+	if record.Data["user"] != "b" || record.Ttl != 1 {
+		t.Fatalf("expect new record with ttl [1], got user=[%v] ttl=[%d]", record.Data["user"], record.Ttl)
+	}
+}
+
+func TestInvalidTtlSchema(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "badTtl",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "badTtl",
+			"version": "0.0.1",
+			"ttlAttr": "holder",
+			"properties": {
+				"holder": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err == nil {
+		t.Fatalf("expect schema with ttlAttr of non integer attr rejected")
+	}
+}
+
+func TestHandlerCloseSweep(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Close()
+	stopped := make(chan interface{})
+	go func() {
+		handler.RunSweep(3600)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("expect sweep stopped by Close")
+	}
+}
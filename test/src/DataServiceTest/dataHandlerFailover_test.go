@@ -0,0 +1,219 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"Data/DbConfig"
+	"Data/DbIface"
+	"DataService/Config"
+	"DataService/DataHandler"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+// database that fails every call while down
+type downDb struct {
+	DbIface.Database
+	down bool
+}
+
+func (d *downDb) err() error {
+	return fmt.Errorf("database %s is down", d.Database.Name())
+}
+
+func (d *downDb) ListTable() ([]interface{}, error) {
+	if d.down {
+		return nil, d.err()
+	}
+	return d.Database.ListTable()
+}
+
+func (d *downDb) Get(queryArgs map[string]interface{}) ([]map[string]interface{}, error) {
+	if d.down {
+		return nil, d.err()
+	}
+	return d.Database.Get(queryArgs)
+}
+
+func (d *downDb) Create(table string, data interface{}) error {
+	if d.down {
+		return d.err()
+	}
+	return d.Database.Create(table, data)
+}
+
+func (d *downDb) Replace(table string, keys map[string]interface{}, data interface{}) error {
+	if d.down {
+		return d.err()
+	}
+	return d.Database.Replace(table, keys, data)
+}
+
+func TestFailoverRead(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "failover",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "failover",
+				"version": "0.0.1",
+				"properties": {
+					"value": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{"__id": "data01", "__type": "failover", "__ver": "0.0.1", "data": {"value": "01"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	// primary and replica share the same store, primary can be taken down
+	primary := &downDb{Database: handler.DB}
+	failover := DataHandler.NewFailoverDb(primary, []DbIface.Database{handler.DB}, 0, nil)
+	handler.DB = failover
+	primary.down = true
+	record, err := handler.GetRecord("failover", "data01")
+	if err != nil {
+		t.Fatalf("read should fail over to replica when primary is down. Error: %s", err)
+	}
+	if record.Data["value"] != "01" {
+		t.Fatalf("invalid record read from replica [%v]", record.Data)
+	}
+	if !failover.PrimaryHealthy() {
+		t.Fatalf("failed read should leave health of primary to health check")
+	}
+	failover.CheckHealth()
+	if failover.PrimaryHealthy() {
+		t.Fatalf("health check should mark down primary unhealthy")
+	}
+	err = AddData(handler, `{"__id": "data02", "__type": "failover", "__ver": "0.0.1", "data": {"value": "02"}}`)
+	if err == nil {
+		t.Fatalf("write should fail when primary is down")
+	}
+	primary.down = false
+	failover.CheckHealth()
+	if !failover.PrimaryHealthy() {
+		t.Fatalf("health check should find primary back")
+	}
+	err = AddData(handler, `{"__id": "data02", "__type": "failover", "__ver": "0.0.1", "data": {"value": "02"}}`)
+	if err != nil {
+		t.Fatalf("write should succeed when primary is back. Error: %s", err)
+	}
+}
+
+// reads of one type fail, the database itself stays up
+type badTypeDb struct {
+	DbIface.Database
+	dataType string
+}
+
+func (d *badTypeDb) Get(queryArgs map[string]interface{}) ([]map[string]interface{}, error) {
+	if queryArgs[Record.DataType] == d.dataType {
+		return nil, fmt.Errorf("invalid query of type [%s]", d.dataType)
+	}
+	return d.Database.Get(queryArgs)
+}
+
+func TestFailoverReadError(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, historySchema("0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	primary := &badTypeDb{Database: handler.DB, dataType: "badType"}
+	failover := DataHandler.NewFailoverDb(primary, nil, 0, nil)
+	handler.DB = failover
+	_, err = handler.QueryDb("badType", "")
+	if err == nil {
+		t.Fatalf("read of bad type should fail")
+	}
+	if !failover.PrimaryHealthy() {
+		t.Fatalf("failed read should not mark primary unhealthy")
+	}
+	err = AddData(handler, `{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}}`)
+	if err != nil {
+		t.Fatalf("write should not be blocked by failed read. Error: %s", err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	db := &downDb{Database: handler.DB}
+	handler.DB = db
+	if err := handler.Ping(); err != nil {
+		t.Fatalf("expect ping on healthy database, got [%s]", err)
+	}
+	db.down = true
+	err := handler.Ping()
+	if err == nil || err.Status != http.StatusServiceUnavailable {
+		t.Fatalf("expect [%d] on ping of down database, got %v", http.StatusServiceUnavailable, err)
+	}
+}
+
+func TestHandlerCloseFailover(t *testing.T) {
+	handler, ex := MockHandlerConfig(func(config *Config.Confuguration) {
+		config.Replica.Databases = []DbConfig.DatabaseConfig{config.Database}
+		config.Replica.HealthCheck = 3600
+	})
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	if handler.Failover == nil {
+		t.Fatalf("expect failover kept on handler with replica")
+	}
+	handler.Close()
+	handler.Close()
+	stopped := make(chan interface{})
+	go func() {
+		handler.Failover.Run()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("expect health check stopped by Close")
+	}
+}
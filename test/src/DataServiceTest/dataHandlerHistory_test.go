@@ -0,0 +1,151 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func historySchema(version string) string {
+	return fmt.Sprintf(`{
+		"__id": "doc",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "doc",
+			"version": "%s",
+			"properties": {
+				"name": {
+					"type": "string"
+				}
+			}
+		}
+	}`, version)
+}
+
+func TestVersionHistory(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Config.History.Depth = 2
+	err := AddData(handler, historySchema("0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	setDoc := func(version string, name string) {
+		record, ex := Record.LoadStr(fmt.Sprintf(`{"__id": "doc01", "__type": "doc", "__ver": "%s", "data": {"name": "%s"}}`, version, name))
+		if ex != nil {
+			t.Fatalf("failed to load record. Error: %s", ex)
+		}
+		err := handler.Set("doc", "doc01", record)
+		if err != nil {
+			t.Fatalf("failed to set doc01 @[%s]. Error: %s", version, err)
+		}
+	}
+	setDoc("0.0.1", "first")
+	setDoc("0.0.1", "one")
+	versions, err := handler.ListVersions("doc", "doc01")
+	if err != nil {
+		t.Fatalf("failed to list versions. Error: %s", err)
+	}
+	if len(versions) != 1 || !versions[0].Current || versions[0].Modified == "" {
+		t.Fatalf("expect only current version when version did not change, got %v", versions)
+	}
+	for _, version := range []string{"0.0.2", "0.0.3", "0.0.4"} {
+		err = AddData(handler, historySchema(version))
+		if err != nil {
+			t.Fatalf("failed to update schema to [%s]. Error: %s", version, err)
+		}
+		setDoc(version, "v"+version)
+	}
+	versions, err = handler.ListVersions("doc", "doc01")
+	if err != nil {
+		t.Fatalf("failed to list versions. Error: %s", err)
+	}
+	verList := []string{}
+	for _, info := range versions {
+		verList = append(verList, info.Version)
+	}
+	if fmt.Sprint(verList) != "[0.0.2 0.0.3 0.0.4]" || !versions[2].Current || versions[0].Current {
+		t.Fatalf("expect 2 kept versions and current one, got %v", versions)
+	}
+	data, err := handler.GetVersion("doc", "doc01", "0.0.2")
+	if err != nil {
+		t.Fatalf("failed to get version [0.0.2]. Error: %s", err)
+	}
+	if name := data[Record.Data].(map[string]interface{})["name"]; name != "v0.0.2" {
+		t.Fatalf("expect name of version [0.0.2], got [%v]", name)
+	}
+	data, err = handler.GetVersion("doc", "doc01", "0.0.4")
+	if err != nil || data[Record.Version] != "0.0.4" {
+		t.Fatalf("expect current record on its own version, got [%v], Error: %v", data, err)
+	}
+	_, err = handler.GetVersion("doc", "doc01", "0.0.1")
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("expect [%d] on version dropped over depth, got %v", http.StatusNotFound, err)
+	}
+	err = handler.Delete("doc", "doc01")
+	if err != nil {
+		t.Fatalf("failed to delete doc01. Error: %s", err)
+	}
+	recordList, err := handler.QueryDb(Common.KeyHistory, "")
+	if err != nil || len(recordList) != 0 {
+		t.Fatalf("expect history dropped with record, got %v, Error: %v", recordList, err)
+	}
+}
+
+func TestVersionHistoryOff(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	for _, data := range []string{
+		historySchema("0.0.1"),
+		`{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}}`,
+		historySchema("0.0.2"),
+	} {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data. Error: %s", err)
+		}
+	}
+	record, _ := Record.LoadStr(`{"__id": "doc01", "__type": "doc", "__ver": "0.0.2", "data": {"name": "two"}}`)
+	err := handler.Set("doc", "doc01", record)
+	if err != nil {
+		t.Fatalf("failed to set doc01. Error: %s", err)
+	}
+	_, err = handler.GetVersion("doc", "doc01", "0.0.1")
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("expect no version kept when history is off, got %v", err)
+	}
+}
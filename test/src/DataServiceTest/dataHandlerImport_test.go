@@ -0,0 +1,163 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"DataService/DataHandler"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func importLines(count int, badLine int) string {
+	lines := []string{
+		`{"__id": "importTest", "__type": "schema", "__ver": "0.0.1", "data": {"name": "importTest", "version": "0.0.1", "properties": {"value": {"type": "integer"}}}}`,
+	}
+	for idx := 0; idx < count; idx++ {
+		if idx == badLine {
+			lines = append(lines, fmt.Sprintf(`{"__id": "import%03d", "__type": "importTest", "__ver": "0.0.1", "data": {"value": "bad"}}`, idx))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf(`{"__id": "import%03d", "__type": "importTest", "__ver": "0.0.1", "data": {"value": %d}}`, idx, idx))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestImport(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Config.Import.Workers = 8
+	data := importLines(200, -1) + "\nnot a json line\n"
+	_, err := handler.Import(strings.NewReader(data), DataHandler.ImportOptions{Workers: 9})
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("import with workers over max should fail with [%d], got %v", http.StatusBadRequest, err)
+	}
+	result, err := handler.Import(strings.NewReader(data), DataHandler.ImportOptions{Workers: 8})
+	if err != nil {
+		t.Fatalf("import failed. Error: %s", err)
+	}
+	if result.Imported != 201 {
+		t.Fatalf("expect 201 records imported, got [%d]", result.Imported)
+	}
+	if result.Failed != 1 || len(result.Errors) != 1 {
+		t.Fatalf("expect 1 failed line, got [%d]", result.Failed)
+	}
+	if result.Errors[0].Line != 202 {
+		t.Fatalf("failed line reported as [%d], expect [202]", result.Errors[0].Line)
+	}
+	idList, err := handler.List("importTest")
+	if err != nil {
+		t.Fatalf("failed to list imported records. Error: %s", err)
+	}
+	if len(idList) != 200 {
+		t.Fatalf("expect 200 records of importTest, got [%d]", len(idList))
+	}
+}
+
+func TestImportStopOnError(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	data := importLines(50, 2)
+	result, err := handler.Import(strings.NewReader(data), DataHandler.ImportOptions{Workers: 1, StopOnError: true})
+	if err != nil {
+		t.Fatalf("import failed. Error: %s", err)
+	}
+	if !result.Stopped {
+		t.Fatalf("import should stop on bad record")
+	}
+	if result.Failed != 1 || result.Errors[0].DataId != "import002" {
+		t.Fatalf("bad record not reported, errors: %v", result.Errors)
+	}
+	if result.Imported >= 50 {
+		t.Fatalf("import should not continue after error, imported [%d]", result.Imported)
+	}
+	handler, ex = MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	result, err = handler.Import(strings.NewReader(data), DataHandler.ImportOptions{Workers: 4})
+	if err != nil {
+		t.Fatalf("import failed. Error: %s", err)
+	}
+	if result.Stopped || result.Failed != 1 || result.Imported != 50 {
+		t.Fatalf("import should continue on error, imported=[%d] failed=[%d]", result.Imported, result.Failed)
+	}
+}
+
+func TestImportCoerce(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	lines := []string{
+		`{"__id": "coerceTest", "__type": "schema", "__ver": "0.0.1", "data": {"name": "coerceTest", "version": "0.0.1", "properties": {"port": {"type": "integer"}, "enabled": {"type": "boolean"}, "since": {"type": "string", "format": "date-time"}, "ports": {"type": "array", "items": {"type": "integer"}}}}}`,
+		`{"__id": "coerce01", "__type": "coerceTest", "__ver": "0.0.1", "data": {"port": "8010", "enabled": "true", "since": "2022-03-04 05:06:07", "ports": ["80", 443]}}`,
+		`{"__id": "coerce02", "__type": "coerceTest", "__ver": "0.0.1", "data": {"port": "eighty", "enabled": "false", "since": "2022-03-04", "ports": []}}`,
+	}
+	data := strings.Join(lines, "\n")
+	result, err := handler.Import(strings.NewReader(data), DataHandler.ImportOptions{})
+	if err != nil {
+		t.Fatalf("import failed. Error: %s", err)
+	}
+	if result.Imported != 1 || result.Failed != 2 {
+		t.Fatalf("without coerce expect only schema imported, imported=[%d] failed=[%d]", result.Imported, result.Failed)
+	}
+	handler, ex = MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	result, err = handler.Import(strings.NewReader(data), DataHandler.ImportOptions{Coerce: true})
+	if err != nil {
+		t.Fatalf("import failed. Error: %s", err)
+	}
+	if result.Imported != 2 || result.Failed != 1 {
+		t.Fatalf("with coerce expect 2 imported and 1 failed, imported=[%d] failed=[%d]", result.Imported, result.Failed)
+	}
+	if result.Errors[0].Line != 3 || !strings.Contains(result.Errors[0].Error.Error(), "eighty") {
+		t.Fatalf("uncoercible value not reported, errors: %v", result.Errors)
+	}
+	record, err := handler.GetRecord("coerceTest", "coerce01")
+	if err != nil {
+		t.Fatalf("failed to get coerced record. Error: %s", err)
+	}
+	if port, ok := record.Data["port"].(float64); !ok || port != 8010 {
+		t.Fatalf("string [8010] not coerced into integer, got [%v]", record.Data["port"])
+	}
+	if enabled, ok := record.Data["enabled"].(bool); !ok || !enabled {
+		t.Fatalf("string [true] not coerced into boolean, got [%v]", record.Data["enabled"])
+	}
+	if record.Data["since"] != "2022-03-04T05:06:07Z" {
+		t.Fatalf("date not normalized, got [%v]", record.Data["since"])
+	}
+	if port, ok := record.Data["ports"].([]interface{})[0].(float64); !ok || port != 80 {
+		t.Fatalf("array item [80] not coerced into integer, got [%v]", record.Data["ports"])
+	}
+}
@@ -0,0 +1,166 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"net/http"
+	"testing"
+
+	"DataService/DataHandler"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestGetJsonApi(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "site",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "site",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "rack",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "rack",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"site": {
+						"type": "string",
+						"contentMediaType": "inventory/site"
+					},
+					"backups": {
+						"type": "array",
+						"items": {
+							"type": "string",
+							"contentMediaType": "inventory/site"
+						}
+					},
+					"byRole": {
+						"type": "map",
+						"items": {
+							"type": "string",
+							"contentMediaType": "inventory/site"
+						}
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "s01",
+			"__type": "site",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "s01"
+			}
+		}`,
+		`{
+			"__id": "s02",
+			"__type": "site",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "s02"
+			}
+		}`,
+		`{
+			"__id": "r01",
+			"__type": "rack",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "r01",
+				"site": "s01",
+				"backups": ["s02", "s01"],
+				"byRole": {"s02": "s02", "s01": "s01"}
+			}
+		}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	result, err := handler.GetJsonApi("rack", "r01")
+	if err != nil {
+		t.Fatalf("failed to get JSON:API document. Error: %s", err)
+	}
+	resource, ok := result[DataHandler.JsonApiData].(map[string]interface{})
+	if !ok || resource[DataHandler.JsonApiType] != "rack" || resource[DataHandler.JsonApiId] != "r01" {
+		t.Fatalf("expect resource of [rack/r01] in data, got [%v]", result)
+	}
+	attributes := resource[DataHandler.JsonApiAttributes].(map[string]interface{})
+	if len(attributes) != 1 || attributes["name"] != "r01" {
+		t.Fatalf("expect only [name] in attributes, got [%v]", attributes)
+	}
+	relationships := resource[DataHandler.JsonApiRelationships].(map[string]interface{})
+	site := relationships["site"].(map[string]interface{})[DataHandler.JsonApiData].(map[string]interface{})
+	if site[DataHandler.JsonApiType] != "site" || site[DataHandler.JsonApiId] != "s01" {
+		t.Fatalf("expect [site] linked to [site/s01], got [%v]", site)
+	}
+	backups := relationships["backups"].(map[string]interface{})[DataHandler.JsonApiData].([]interface{})
+	if len(backups) != 2 || backups[0].(map[string]interface{})[DataHandler.JsonApiId] != "s02" {
+		t.Fatalf("expect [backups] linked in order of array, got [%v]", backups)
+	}
+	byRole := relationships["byRole"].(map[string]interface{})[DataHandler.JsonApiData].([]interface{})
+	first := byRole[0].(map[string]interface{})
+	if len(byRole) != 2 || first[DataHandler.JsonApiId] != "s01" || first[DataHandler.JsonApiMeta].(map[string]interface{})[DataHandler.JsonApiKey] != "s01" {
+		t.Fatalf("expect [byRole] linked in order of key with key in meta, got [%v]", byRole)
+	}
+	meta := resource[DataHandler.JsonApiMeta].(map[string]interface{})
+	if meta[Record.Version] != "0.0.1" || meta[Record.Data] != nil {
+		t.Fatalf("expect system fields in meta, got [%v]", meta)
+	}
+	links := resource[DataHandler.JsonApiLinks].(map[string]interface{})
+	if links[DataHandler.JsonApiSelf] != "/rack/r01" {
+		t.Fatalf("invalid self link [%v]", links[DataHandler.JsonApiSelf])
+	}
+	_, err = handler.GetJsonApi("rack", "missing")
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("expect 404 on missing record, got [%v]", err)
+	}
+	list := handler.JsonApiList("rack", []interface{}{"r01"}, 1)
+	listLinks := list[DataHandler.JsonApiLinks].(map[string]interface{})
+	if len(list[DataHandler.JsonApiData].([]interface{})) != 1 || listLinks[DataHandler.JsonApiNext] != "/rack?offset=1" {
+		t.Fatalf("invalid JSON:API list document [%v]", list)
+	}
+}
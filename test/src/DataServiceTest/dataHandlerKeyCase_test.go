@@ -0,0 +1,135 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestKeyCaseRecord(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "host",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "host",
+			"version": "0.0.1",
+			"properties": {
+				"hostName": {
+					"type": "string"
+				},
+				"HTTPPort": {
+					"type": "integer"
+				},
+				"networkCards": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"$ref": "#/definitions/card"
+					}
+				},
+				"labelMap": {
+					"type": "map",
+					"items": {
+						"type": "string"
+					}
+				}
+			},
+			"definitions": {
+				"card": {
+					"name": "card",
+					"key": "{macAddress}",
+					"properties": {
+						"macAddress": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	stored := map[string]interface{}{}
+	ex = json.Unmarshal([]byte(`{
+		"__id": "h01",
+		"__type": "host",
+		"__ver": "0.0.1",
+		"data": {
+			"hostName": "h01",
+			"HTTPPort": 8080,
+			"networkCards": [
+				{"macAddress": "aa:bb"}
+			],
+			"labelMap": {"my_host": "a", "ownerTeam": "b"}
+		}
+	}`), &stored)
+	if ex != nil {
+		t.Fatalf("failed to parse record. Error: %s", ex)
+	}
+	toSnake := Http.WireKeyFunc(Http.Config{KeyCase: Http.KeyCaseSnake})
+	wire := handler.WireRecord(stored, toSnake, false).(map[string]interface{})
+	if _, ok := wire["__id"]; !ok {
+		t.Fatalf("system field __id should keep its name, got %v", wire)
+	}
+	data := wire["data"].(map[string]interface{})
+	if data["host_name"] != "h01" || data["http_port"] != float64(8080) {
+		t.Fatalf("expect declared attributes in snake_case, got %v", data)
+	}
+	card := data["network_cards"].([]interface{})[0].(map[string]interface{})
+	if card["mac_address"] != "aa:bb" {
+		t.Fatalf("expect snake_case keys in array item, got %v", card)
+	}
+	labels := data["label_map"].(map[string]interface{})
+	if labels["my_host"] != "a" || labels["ownerTeam"] != "b" {
+		t.Fatalf("keys of map items should be kept, got %v", labels)
+	}
+	back := handler.WireRecord(wire, toSnake, true)
+	if !reflect.DeepEqual(back, stored) {
+		t.Fatalf("round trip through snake_case changed record.\nexpect: %v\ngot: %v", stored, back)
+	}
+	schema, err := handler.Get(JsonKey.Schema, "host")
+	if err != nil {
+		t.Fatalf("failed to get schema. Error: %s", err)
+	}
+	toCamel := Http.WireKeyFunc(Http.Config{KeyCase: Http.KeyCaseCamel})
+	if !reflect.DeepEqual(handler.WireRecord(schema, toCamel, true), schema) {
+		t.Fatalf("schema record should be kept as it is")
+	}
+	if !reflect.DeepEqual(handler.WireRecord(stored, nil, false), stored) {
+		t.Fatalf("record should be kept as it is without KeyCase")
+	}
+}
@@ -0,0 +1,330 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+)
+
+func TestListMaxSize(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Config.List.MaxListSize = 2
+	err := AddData(handler, `{
+		"__id": "listCap",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "listCap",
+			"version": "0.0.1",
+			"properties": {
+				"value": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	for idx := 4; idx >= 0; idx-- {
+		err = AddData(handler, fmt.Sprintf(`{"__id": "item%02d", "__type": "listCap", "__ver": "0.0.1", "data": {"value": "%d"}}`, idx, idx))
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	idList, next, err := handler.ListPage("listCap", 0)
+	if err != nil {
+		t.Fatalf("failed to list. Error: %s", err)
+	}
+	if len(idList) != 2 || idList[0] != "item00" || idList[1] != "item01" {
+		t.Fatalf("expect first 2 ids of sorted list, got %v", idList)
+	}
+	if next != 2 {
+		t.Fatalf("truncated list should return next offset=[2], got [%d]", next)
+	}
+	collected := []interface{}{}
+	offset := 0
+	for {
+		idList, next, err = handler.ListPage("listCap", offset)
+		if err != nil {
+			t.Fatalf("failed to list from offset=[%d]. Error: %s", offset, err)
+		}
+		collected = append(collected, idList...)
+		if next == 0 {
+			break
+		}
+		offset = next
+	}
+	if len(collected) != 5 || collected[4] != "item04" {
+		t.Fatalf("expect all 5 ids by following next offset, got %v", collected)
+	}
+	handler.Config.List.MaxListSize = 0
+	idList, next, err = handler.ListPage("listCap", 0)
+	if err != nil {
+		t.Fatalf("failed to list without cap. Error: %s", err)
+	}
+	if len(idList) != 5 || next != 0 {
+		t.Fatalf("list without cap should not be truncated, got %v, next=[%d]", idList, next)
+	}
+	_, _, err = handler.ListPage("listCap", -1)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("negative offset should return err.Code=[%d], got [%v]", http.StatusBadRequest, err)
+	}
+}
+
+func TestListSnapshot(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Config.List.MaxListSize = 2
+	err := AddData(handler, `{
+		"__id": "listSnap",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "listSnap",
+			"version": "0.0.1",
+			"properties": {
+				"value": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	addItem := func(id string) {
+		err := AddData(handler, fmt.Sprintf(`{"__id": "%s", "__type": "listSnap", "__ver": "0.0.1", "data": {"value": "%s"}}`, id, id))
+		if err != nil {
+			t.Fatalf("failed to add [%s]. Error: %s", id, err)
+		}
+	}
+	for _, id := range []string{"item02", "item04", "item06", "item08"} {
+		addItem(id)
+	}
+	// page through the list while an item sorted before the next page is inserted
+	pageAll := func(useToken bool, insertId string) []interface{} {
+		idList, next, token, err := handler.ListSnapshot("listSnap", "", 0)
+		if err != nil {
+			t.Fatalf("failed to list first page. Error: %s", err)
+		}
+		if token == "" {
+			t.Fatalf("truncated first page should return snapshot token")
+		}
+		if !useToken {
+			token = ""
+		}
+		collected := append([]interface{}{}, idList...)
+		inserted := false
+		for next > 0 {
+			if !inserted {
+				addItem(insertId)
+				inserted = true
+			}
+			idList, next, _, err = handler.ListSnapshot("listSnap", token, next)
+			if err != nil {
+				t.Fatalf("failed to list from offset=[%d]. Error: %s", next, err)
+			}
+			collected = append(collected, idList...)
+		}
+		return collected
+	}
+	collected := pageAll(true, "item01")
+	expected := []interface{}{"item02", "item04", "item06", "item08"}
+	if !reflect.DeepEqual(collected, expected) {
+		t.Fatalf("pages with snapshot token should see the list as of first page, expect %v, got %v", expected, collected)
+	}
+	collected = pageAll(false, "item00")
+	expected = []interface{}{"item01", "item02", "item02", "item04", "item06", "item08"}
+	if !reflect.DeepEqual(collected, expected) {
+		t.Fatalf("pages without snapshot token should shift on insert, expect %v, got %v", expected, collected)
+	}
+	_, _, _, err = handler.ListSnapshot("listCap", "unknown", 2)
+	if err == nil || err.Status != http.StatusGone {
+		t.Fatalf("unknown snapshot should return err.Code=[%d], got [%v]", http.StatusGone, err)
+	}
+	_, _, token, err := handler.ListSnapshot("listSnap", "", 0)
+	if err != nil {
+		t.Fatalf("failed to list first page. Error: %s", err)
+	}
+	_, _, _, err = handler.ListSnapshot(JsonKey.Schema, token, 2)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("snapshot of other type should return err.Code=[%d], got [%v]", http.StatusBadRequest, err)
+	}
+	handler.Config.List.MaxListSize = 0
+	_, _, token, err = handler.ListSnapshot("listSnap", "", 0)
+	if err != nil || token != "" {
+		t.Fatalf("list without cap should not capture snapshot, got token=[%s]. Error: %v", token, err)
+	}
+}
+
+func TestListRange(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "listRange",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "listRange",
+			"version": "0.0.1",
+			"properties": {
+				"value": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	for idx := 4; idx >= 0; idx-- {
+		err = AddData(handler, fmt.Sprintf(`{"__id": "item%02d", "__type": "listRange", "__ver": "0.0.1", "data": {"value": "%d"}}`, idx, idx))
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	page, _, err := handler.ListRange("listRange", "", 1, 2, nil)
+	if err != nil {
+		t.Fatalf("failed to list range. Error: %s", err)
+	}
+	if !reflect.DeepEqual(page.Items, []interface{}{"item01", "item02"}) || page.Total != 5 || page.Next != 3 {
+		t.Fatalf("expect items [item01 item02] of total 5 with next 3, got %v", page)
+	}
+	page, _, err = handler.ListRange("listRange", "", 3, 2, nil)
+	if err != nil {
+		t.Fatalf("failed to list last range. Error: %s", err)
+	}
+	if len(page.Items) != 2 || page.Next != 0 {
+		t.Fatalf("expect last 2 items without next, got %v", page)
+	}
+	handler.Config.List.MaxListSize = 1
+	page, _, err = handler.ListRange("listRange", "", 0, 3, nil)
+	if err != nil {
+		t.Fatalf("failed to list range over cap. Error: %s", err)
+	}
+	if len(page.Items) != 1 || page.Next != 1 {
+		t.Fatalf("expect limit capped by MaxListSize, got %v", page)
+	}
+	for _, args := range [][]int{{-1, 2}, {0, -1}} {
+		_, _, err = handler.ListRange("listRange", "", args[0], args[1], nil)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("expect 400 on offset=[%d] limit=[%d], got %v", args[0], args[1], err)
+		}
+	}
+}
+
+func TestListWhere(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "listWhere",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "listWhere",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"size": {
+						"type": "integer"
+					},
+					"spec": {
+						"type": "object",
+						"$ref": "#/definitions/spec",
+						"required": false
+					}
+				},
+				"definitions": {
+					"spec": {
+						"name": "spec",
+						"properties": {
+							"owner": {
+								"type": "string"
+							}
+						}
+					}
+				}
+			}
+		}`,
+		`{"__id": "w01", "__type": "listWhere", "__ver": "0.0.1", "data": {"name": "data1", "size": 1, "spec": {"owner": "ops"}}}`,
+		`{"__id": "w02", "__type": "listWhere", "__ver": "0.0.1", "data": {"name": "data1", "size": 2, "spec": {"owner": "dev"}}}`,
+		`{"__id": "w03", "__type": "listWhere", "__ver": "0.0.1", "data": {"name": "data2", "size": 1}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	testList := map[string]struct {
+		filters  []string
+		expected []interface{}
+	}{
+		"single":   {[]string{"name=data1"}, []interface{}{"w01", "w02"}},
+		"and":      {[]string{"name=data1", "size=2"}, []interface{}{"w02"}},
+		"nested":   {[]string{"spec.owner=ops"}, []interface{}{"w01"}},
+		"missing":  {[]string{"spec.owner=dev", "size=1"}, []interface{}{}},
+		"no match": {[]string{"name=data3"}, []interface{}{}},
+	}
+	for name, test := range testList {
+		page, _, err := handler.ListRange("listWhere", "", 0, 0, test.filters)
+		if err != nil {
+			t.Fatalf("failed to list [%s]. Error: %s", name, err)
+		}
+		// list without paging keeps order of db
+		sort.Slice(page.Items, func(i, j int) bool {
+			return page.Items[i].(string) < page.Items[j].(string)
+		})
+		if !reflect.DeepEqual(page.Items, test.expected) {
+			t.Fatalf("expect %v on [%s] filter %v, got %v", test.expected, name, test.filters, page.Items)
+		}
+	}
+	for _, filter := range []string{"name", "=data1", "spec..owner=ops"} {
+		_, err := handler.ListWhere("listWhere", []string{filter})
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("expect 400 on invalid filter [%s], got %v", filter, err)
+		}
+	}
+}
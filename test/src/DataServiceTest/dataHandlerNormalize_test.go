@@ -0,0 +1,102 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestNormalizedView(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "service",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "service",
+			"version": "0.0.1",
+			"additionalProperties": false,
+			"properties": {
+				"name": {
+					"type": "string"
+				},
+				"replicas": {
+					"type": "integer"
+				},
+				"port": {
+					"type": "integer",
+					"default": "8080",
+					"required": false
+				},
+				"public": {
+					"type": "boolean",
+					"default": "false",
+					"required": false
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	legacy, ex := Record.LoadStr(`{"__id": "s01", "__type": "service", "__ver": "0.0.1", "data": {"name": "s01", "replicas": "3", "public": true, "owner": "ops"}}`)
+	if ex != nil {
+		t.Fatalf("failed to load legacy record. Error: %s", ex)
+	}
+	ex = handler.DB.Create(handler.Config.DataTable.Data, legacy.Map())
+	if ex != nil {
+		t.Fatalf("failed to create legacy record. Error: %s", ex)
+	}
+	result, err := handler.GetNormalized("service", "s01")
+	if err != nil {
+		t.Fatalf("failed to get normalized view. Error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"name":     "s01",
+		"replicas": float64(3),
+		"port":     float64(8080),
+		"public":   true,
+	}
+	if !reflect.DeepEqual(result["data"], expected) {
+		t.Fatalf("unexpected normalized data %v", result["data"])
+	}
+	record, err := handler.GetRecord("service", "s01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	if !reflect.DeepEqual(record.Data, legacy.Data) {
+		t.Fatalf("expect stored record unchanged, got %v", record.Data)
+	}
+	if _, ok := record.Data["port"]; ok {
+		t.Fatalf("expect no default in stored record, got %v", record.Data)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"testing"
+)
+
+func TestOpenApiOfHandler(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Config.Http.BasePath = "/inv"
+	err := AddData(handler, historySchema("0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	err = AddData(handler, historySchema("0.0.2"))
+	if err != nil {
+		t.Fatalf("failed to update schema. Error: %s", err)
+	}
+	doc, err := handler.OpenApi("1.2.3")
+	if err != nil {
+		t.Fatalf("failed to get OpenAPI. Error: %s", err)
+	}
+	if version := doc["info"].(map[string]interface{})["version"]; version != "1.2.3" {
+		t.Fatalf("expect version of service in info, got [%v]", version)
+	}
+	paths := doc["paths"].(map[string]interface{})
+	if _, ok := paths["/inv/doc/{id}"]; !ok {
+		t.Fatalf("missing path of type doc under base path, got %v", paths)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("expect paths of doc and add record only, got %v", paths)
+	}
+}
@@ -0,0 +1,280 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"DataService/DataHandler"
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestPatchIfField(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "order",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "order",
+				"version": "0.0.1",
+				"properties": {
+					"status": {
+						"type": "string"
+					},
+					"spec": {
+						"type": "object",
+						"$ref": "#/definitions/spec"
+					}
+				},
+				"definitions": {
+					"spec": {
+						"name": "spec",
+						"properties": {
+							"owner": {
+								"type": "string"
+							}
+						}
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "order01",
+			"__type": "order",
+			"__ver": "0.0.1",
+			"data": {
+				"status": "pending",
+				"spec": {
+					"owner": "alice"
+				}
+			}
+		}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	ifField := func(predicate string) map[string]interface{} {
+		return map[string]interface{}{"if-field": predicate}
+	}
+	_, err := handler.Patch("order", "order01/status", ifField("status=done"), "shipped")
+	if err == nil || err.Status != http.StatusPreconditionFailed {
+		t.Fatalf("expect [%d] when predicate not satisfied, got [%v]", http.StatusPreconditionFailed, err)
+	}
+	_, err = handler.Patch("order", "order01/status", ifField("spec/owner=bob"), "shipped")
+	if err == nil || err.Status != http.StatusPreconditionFailed {
+		t.Fatalf("expect [%d] when nested predicate not satisfied, got [%v]", http.StatusPreconditionFailed, err)
+	}
+	_, err = handler.Patch("order", "order01/status", ifField("status"), "shipped")
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expect [%d] on invalid predicate, got [%v]", http.StatusBadRequest, err)
+	}
+	data, err := handler.LocalData("order", "order01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	if status := data[Record.Data].(map[string]interface{})["status"]; status != "pending" {
+		t.Fatalf("record should not change when predicate fails, got status [%v]", status)
+	}
+	_, err = handler.Patch("order", "order01/status", ifField("status=pending"), "shipped")
+	if err != nil {
+		t.Fatalf("failed to patch with satisfied predicate. Error: %s", err)
+	}
+	_, err = handler.Patch("order", "order01/spec/owner", ifField("spec/owner=alice"), "bob")
+	if err != nil {
+		t.Fatalf("failed to patch with satisfied nested predicate. Error: %s", err)
+	}
+	data, _ = handler.LocalData("order", "order01")
+	recordData := data[Record.Data].(map[string]interface{})
+	if recordData["status"] != "shipped" || recordData["spec"].(map[string]interface{})["owner"] != "bob" {
+		t.Fatalf("expect record patched when predicate satisfied, got [%v]", recordData)
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "order",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "order",
+				"version": "0.0.1",
+				"properties": {
+					"status": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{"__id": "order01", "__type": "order", "__ver": "0.0.1", "data": {"status": "pending"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	currentETag := func() string {
+		data, err := handler.Get("order", "order01")
+		if err != nil {
+			t.Fatalf("failed to get record. Error: %s", err)
+		}
+		etag, err := DataHandler.DataETag(data.(map[string]interface{}))
+		if err != nil {
+			t.Fatalf("failed to get ETag. Error: %s", err)
+		}
+		return etag
+	}
+	ifMatch := func(etag string) map[string]interface{} {
+		return map[string]interface{}{"if-match": etag}
+	}
+	stale := currentETag()
+	if again := currentETag(); again != stale {
+		t.Fatalf("expect same ETag of unchanged record, got [%s] and [%s]", stale, again)
+	}
+	_, err := handler.Patch("order", "order01/status", ifMatch(stale), "shipped")
+	if err != nil {
+		t.Fatalf("failed to patch with matching ETag. Error: %s", err)
+	}
+	if currentETag() == stale {
+		t.Fatalf("expect ETag to change with record")
+	}
+	_, err = handler.Patch("order", "order01/status", ifMatch(stale), "lost")
+	if err == nil || err.Status != http.StatusPreconditionFailed {
+		t.Fatalf("expect [%d] on patch with stale ETag, got [%v]", http.StatusPreconditionFailed, err)
+	}
+	_, err = handler.MergePatch("order", "order01", ifMatch(stale), map[string]interface{}{"status": "lost"})
+	if err == nil || err.Status != http.StatusPreconditionFailed {
+		t.Fatalf("expect [%d] on merge patch with stale ETag, got [%v]", http.StatusPreconditionFailed, err)
+	}
+	_, err = handler.MergePatch("order", "order01", ifMatch("W/"+currentETag()), map[string]interface{}{"status": "lost"})
+	if err == nil || err.Status != http.StatusPreconditionFailed {
+		t.Fatalf("expect [%d] on weak ETag, got [%v]", http.StatusPreconditionFailed, err)
+	}
+	_, err = handler.JsonPatch("order", "order01", ifMatch(`"other", `+currentETag()), []interface{}{
+		map[string]interface{}{"op": "replace", "path": "/status", "value": "delivered"},
+	})
+	if err != nil {
+		t.Fatalf("failed to json patch with one of listed ETags matching. Error: %s", err)
+	}
+	record, ex := Record.LoadStr(`{"__id": "order01", "__type": "order", "__ver": "0.0.1", "data": {"status": "lost"}}`)
+	if ex != nil {
+		t.Fatalf("failed to load record. Error: %s", ex)
+	}
+	err = handler.SetIf("order", "order01", record, ifMatch(stale))
+	if err == nil || err.Status != http.StatusPreconditionFailed {
+		t.Fatalf("expect [%d] on put with stale ETag, got [%v]", http.StatusPreconditionFailed, err)
+	}
+	data, _ := handler.LocalData("order", "order01")
+	if status := data[Record.Data].(map[string]interface{})["status"]; status != "delivered" {
+		t.Fatalf("record should not change when ETag does not match, got status [%v]", status)
+	}
+	err = handler.SetIf("order", "order01", record, ifMatch(currentETag()))
+	if err != nil {
+		t.Fatalf("failed to put with matching ETag. Error: %s", err)
+	}
+	newRecord, _ := Record.LoadStr(`{"__id": "order02", "__type": "order", "__ver": "0.0.1", "data": {"status": "new"}}`)
+	err = handler.SetIf("order", "order02", newRecord, ifMatch("*"))
+	if err == nil || err.Status != http.StatusPreconditionFailed {
+		t.Fatalf("expect [%d] on put of new record with [If-Match]=[*], got [%v]", http.StatusPreconditionFailed, err)
+	}
+	err = handler.SetIf("order", "order02", newRecord, nil)
+	if err != nil {
+		t.Fatalf("failed to put new record without If-Match. Error: %s", err)
+	}
+}
+
+func TestIfMatchTtl(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "lease",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "lease",
+				"version": "0.0.1",
+				"ttl": 3600,
+				"properties": {
+					"holder": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{"__id": "lease01", "__type": "lease", "__ver": "0.0.1", "data": {"holder": "a"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	data, err := handler.Get("lease", "lease01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	if _, ok := data.(map[string]interface{})[Record.Ttl]; !ok {
+		t.Fatalf("expect [%s] on read of record with ttl, got %v", Record.Ttl, data)
+	}
+	etag, err := DataHandler.DataETag(data.(map[string]interface{}))
+	if err != nil {
+		t.Fatalf("failed to get ETag. Error: %s", err)
+	}
+	record, ex := Record.LoadStr(`{"__id": "lease01", "__type": "lease", "__ver": "0.0.1", "data": {"holder": "b"}}`)
+	if ex != nil {
+		t.Fatalf("failed to load record. Error: %s", ex)
+	}
+	err = handler.SetIf("lease", "lease01", record, map[string]interface{}{"if-match": etag})
+	if err != nil {
+		t.Fatalf("failed to put with ETag of read record that has ttl. Error: %s", err)
+	}
+	data, err = handler.Get("lease", "lease01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	etag, _ = DataHandler.DataETag(data.(map[string]interface{}))
+	_, err = handler.Patch("lease", "lease01/holder", map[string]interface{}{"if-match": etag}, "c")
+	if err != nil {
+		t.Fatalf("failed to patch with ETag of read record that has ttl. Error: %s", err)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// GET /_path/{type}/{id}/{path} runs the path query through GetContext
+func TestPathQuery(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "site",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "site",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"region": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "rack",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "rack",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"site": {
+						"type": "string",
+						"contentMediaType": "inventory/site"
+					}
+				}
+			}
+		}`,
+		`{"__id": "s01", "__type": "site", "__ver": "0.0.1", "data": {"name": "s01", "region": "west"}}`,
+		`{"__id": "r01", "__type": "rack", "__ver": "0.0.1", "data": {"name": "r01", "site": "s01"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	testList := map[string]interface{}{
+		"r01/site/region":        "west",
+		"r01/site?ref":           "s01",
+		"r01/site?flat":          map[string]interface{}{"name": "s01", "region": "west"},
+		"r01/site/region?schema": map[string]interface{}{"type": "string"},
+	}
+	for path, expected := range testList {
+		result, err := handler.GetContext(context.Background(), "rack", path)
+		if err != nil {
+			t.Fatalf("failed to query [rack/%s]. Error: %s", path, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("expect %v on [rack/%s], got %v", expected, path, result)
+		}
+	}
+	result, err := handler.GetContext(context.Background(), "rack", "r01/site?schema")
+	if err != nil {
+		t.Fatalf("failed to query schema of ref. Error: %s", err)
+	}
+	if schema, _ := result.(map[string]interface{}); schema["name"] != "site" {
+		t.Fatalf("expect schema of ref target [site], got %v", result)
+	}
+	errList := map[string]int{
+		"r02/site":         http.StatusNotFound,
+		"r01/missing":      http.StatusNotFound,
+		"r01/site?unknown": http.StatusBadRequest,
+	}
+	for path, status := range errList {
+		_, err = handler.GetContext(context.Background(), "rack", path)
+		if err == nil || err.Status != status {
+			t.Fatalf("expect [%d] on [rack/%s], got %v", status, path, err)
+		}
+	}
+}
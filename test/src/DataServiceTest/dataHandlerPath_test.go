@@ -0,0 +1,129 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+)
+
+func TestHandlerConnection(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "pathBase",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "pathBase",
+				"version": "0.0.1",
+				"properties": {
+					"owner": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "pathTest",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "pathTest",
+				"version": "0.0.1",
+				"extends": "pathBase",
+				"properties": {
+					"info": {
+						"type": "object",
+						"$ref": "#/definitions/info"
+					}
+				},
+				"definitions": {
+					"info": {
+						"name": "info",
+						"properties": {
+							"size": {
+								"type": "integer"
+							}
+						}
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "path01",
+			"__type": "pathTest",
+			"__ver": "0.0.1",
+			"data": {
+				"owner": "someone",
+				"info": {
+					"size": 3
+				}
+			}
+		}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	conn := handler.Connection()
+	schema, err := conn.GetSchema("pathTest")
+	if err != nil {
+		t.Fatalf("failed to get schema from connection. Error: %s", err)
+	}
+	if _, ok := schema.Properties()["owner"]; !ok {
+		t.Fatalf("schema from connection missing inherited property [owner]")
+	}
+	record, err := conn.GetRecord("pathTest", "path01")
+	if err != nil {
+		t.Fatalf("failed to get record from connection. Error: %s", err)
+	}
+	if record.Id != "path01" {
+		t.Fatalf("got wrong record [%s] from connection", record.Id)
+	}
+	for queryPath, expected := range map[string]interface{}{
+		"path01/info/size": float64(3),
+		"path01/owner":     "someone",
+	} {
+		query, err := SchemaPath.CreateQuery(conn, "pathTest", queryPath)
+		if err != nil {
+			t.Fatalf("failed to create query [%s]. Error: %s", queryPath, err)
+		}
+		value, err := query.WalkValue()
+		if err != nil {
+			t.Fatalf("failed to walk path [%s]. Error: %s", queryPath, err)
+		}
+		if value != expected {
+			t.Fatalf("walk path [%s] got [%v], expect [%v]", queryPath, value, expected)
+		}
+	}
+}
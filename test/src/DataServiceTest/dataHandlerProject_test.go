@@ -0,0 +1,191 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestGetProjected(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "site",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "site",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"region": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "rack",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "rack",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"serial": {
+						"type": "string"
+					},
+					"site": {
+						"type": "string",
+						"contentMediaType": "inventory/site"
+					},
+					"rows": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"$ref": "#/definitions/row"
+						}
+					}
+				},
+				"definitions": {
+					"row": {
+						"name": "row",
+						"key": "{name}",
+						"properties": {
+							"name": {
+								"type": "string"
+							},
+							"height": {
+								"type": "integer"
+							}
+						}
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "s01",
+			"__type": "site",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "s01",
+				"region": "west"
+			}
+		}`,
+		`{
+			"__id": "r01",
+			"__type": "rack",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "r01",
+				"serial": "sn01",
+				"site": "s01",
+				"rows": [
+					{
+						"name": "row01",
+						"height": 42
+					}
+				]
+			}
+		}`,
+		`{
+			"__id": "r02",
+			"__type": "rack",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "r02",
+				"serial": "sn02",
+				"site": "s01",
+				"rows": []
+			}
+		}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	result, err := handler.GetProjected("rack", "r01", []string{"name", "site.region", "rows.height"})
+	if err != nil {
+		t.Fatalf("failed to get projected record. Error: %s", err)
+	}
+	record, ex := Record.LoadMap(result)
+	if ex != nil {
+		t.Fatalf("failed to load projected result as record. Error: %s", ex)
+	}
+	if record.Id != "r01" || len(record.Data) != 3 || record.Data["name"] != "r01" {
+		t.Fatalf("expect [name, site, rows] projected, got [%v]", record.Data)
+	}
+	site, ok := record.Data["site"].(map[string]interface{})
+	if !ok || len(site) != 1 || site["region"] != "west" {
+		t.Fatalf("expect [site.region] inlined from ref, got [%v]", record.Data["site"])
+	}
+	row := record.Data["rows"].([]interface{})[0].(map[string]interface{})
+	if len(row) != 1 || row["height"] != float64(42) {
+		t.Fatalf("expect only [height] in [rows], got [%v]", row)
+	}
+	result, err = handler.GetProjected("rack", "r01", []string{"site.name", "site"})
+	if err != nil {
+		t.Fatalf("failed to get projected record with whole ref. Error: %s", err)
+	}
+	record, _ = Record.LoadMap(result)
+	if record.Data["site"] != "s01" {
+		t.Fatalf("path [site] should keep ref value, got [%v]", record.Data["site"])
+	}
+	err = handler.Delete("site", "s01")
+	if err != nil {
+		t.Fatalf("failed to delete ref target. Error: %s", err)
+	}
+	result, err = handler.GetProjected("rack", "r02", []string{"serial", "site.region"})
+	if err != nil {
+		t.Fatalf("failed to project dangling ref. Error: %s", err)
+	}
+	record, _ = Record.LoadMap(result)
+	if value, ok := record.Data["site"]; !ok || value != nil {
+		t.Fatalf("expect dangling ref projected as null, got [%v]", record.Data["site"])
+	}
+	if record.Data["serial"] != "sn02" {
+		t.Fatalf("expect [serial] projected next to dangling ref, got [%v]", record.Data["serial"])
+	}
+	for _, field := range []string{"missing", "name.sub", "site..region"} {
+		_, err = handler.GetProjected("rack", "r01", []string{field})
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("invalid field path [%s] should return err.Code=[%d], got [%v]", field, http.StatusBadRequest, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"Data/DbConfig"
+	"Data/DbIface"
+	"Data/SysDirFile"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+// database that keeps bytes of records as they were written
+type rawDb struct {
+	DbIface.Database
+	stored map[string][]byte
+}
+
+func (db *rawDb) GetRaw(queryArgs map[string]interface{}) ([]byte, error) {
+	return db.stored[queryArgs[Record.DataId].(string)], nil
+}
+
+const rawRecord = `{"__type": "host",   "__id": "h01",
+	"__ver": "0.0.1",
+	"data": {"name": "h01"}
+}`
+
+func TestGetRaw(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "host",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "host",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	err = AddData(handler, rawRecord)
+	if err != nil {
+		t.Fatalf("failed to add host. Error: %s", err)
+	}
+	// database without raw bytes, record is serialized again
+	data, raw, err := handler.GetRaw("host", "h01")
+	if err != nil {
+		t.Fatalf("failed to get raw data. Error: %s", err)
+	}
+	if raw {
+		t.Fatalf("expect serialized data from database without raw bytes")
+	}
+	normalized := map[string]interface{}{}
+	ex = json.Unmarshal(data, &normalized)
+	if ex != nil {
+		t.Fatalf("failed to parse serialized data. Error: %s", ex)
+	}
+	stored, err := handler.LocalData("host", "h01")
+	if err != nil {
+		t.Fatalf("failed to get data. Error: %s", err)
+	}
+	if !reflect.DeepEqual(stored, normalized) {
+		t.Fatalf("serialized data not match, expect %v, got %v", stored, normalized)
+	}
+	handler.DB = &rawDb{
+		Database: handler.DB,
+		stored:   map[string][]byte{"h01": []byte(rawRecord)},
+	}
+	data, raw, err = handler.GetRaw("host", "h01")
+	if err != nil {
+		t.Fatalf("failed to get raw data. Error: %s", err)
+	}
+	if !raw || string(data) != rawRecord {
+		t.Fatalf("expect raw data as stored, got raw=[%t] %s", raw, data)
+	}
+	_, _, err = handler.GetRaw("host", "h02")
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("expect 404 on raw data of missing record, got %v", err)
+	}
+}
+
+func TestSysDirFileGetRaw(t *testing.T) {
+	rootPath := t.TempDir()
+	db, ex := SysDirFile.Connect(DbConfig.DatabaseConfig{
+		SysDirFile: DbConfig.SysDirFileConfig{Path: rootPath},
+	}, nil)
+	if ex != nil {
+		t.Fatalf("failed to connect. Error: %s", ex)
+	}
+	ex = db.CreateTable("data", nil)
+	if ex != nil {
+		t.Fatalf("failed to create table. Error: %s", ex)
+	}
+	ex = os.WriteFile(filepath.Join(rootPath, "data", "h01"), []byte(rawRecord), 0644)
+	if ex != nil {
+		t.Fatalf("failed to write record. Error: %s", ex)
+	}
+	getter, ok := db.(DbIface.RawGetter)
+	if !ok {
+		t.Fatalf("expect %s to keep raw bytes", SysDirFile.Name)
+	}
+	data, ex := getter.GetRaw(map[string]interface{}{DbIface.Table: "data", Record.DataId: "h01"})
+	if ex != nil {
+		t.Fatalf("failed to get raw data. Error: %s", ex)
+	}
+	if string(data) != rawRecord {
+		t.Fatalf("raw data changed, got %s", data)
+	}
+	data, ex = getter.GetRaw(map[string]interface{}{DbIface.Table: "data", Record.DataId: "h02"})
+	if ex != nil || data != nil {
+		t.Fatalf("expect no data and no error for missing record, got [%s], Error: %v", data, ex)
+	}
+}
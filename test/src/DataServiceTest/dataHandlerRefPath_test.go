@@ -0,0 +1,213 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"DataService/Common"
+	"DataService/DataHandler"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+// handler with refHolder that refers to path in record of refTarget, and target record ref01
+func mockRefPath(t *testing.T) *DataHandler.Handler {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "refTarget",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "refTarget",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"items": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"$ref": "#/definitions/item"
+						}
+					}
+				},
+				"definitions": {
+					"item": {
+						"name": "item",
+						"key": "{name}",
+						"properties": {
+							"name": {
+								"type": "string"
+							},
+							"attr01": {
+								"type": "string"
+							}
+						}
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "refHolder",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "refHolder",
+				"version": "0.0.1",
+				"properties": {
+					"target": {
+						"type": "string",
+						"contentMediaType": "inventory/refTarget"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "ref01",
+			"__type": "refTarget",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "ref01",
+				"items": [
+					{
+						"name": "item01",
+						"attr01": "value01"
+					}
+				]
+			}
+		}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	return handler
+}
+
+func TestRefValuePath(t *testing.T) {
+	handler := mockRefPath(t)
+	holderIdx := 0
+	addHolder := func(target string) error {
+		holderIdx++
+		err := AddData(handler, fmt.Sprintf(`{"__id": "holder%02d", "__type": "refHolder", "__ver": "0.0.1", "data": {"target": "%s"}}`, holderIdx, target))
+		if err != nil {
+			if err.Status != http.StatusBadRequest {
+				t.Fatalf("invalid ref [%s] should return err.Code=[%d], got [%d]", target, http.StatusBadRequest, err.Status)
+			}
+			return err
+		}
+		return nil
+	}
+	testList := []struct {
+		refCheck string
+		target   string
+		valid    bool
+	}{
+		{"", "ref01", true},
+		{"", "ref01/items[item01]/attr01", true},
+		{"", "ref01/items[item01]/attr02", false},
+		{"", "ref01/items[item02]/attr01", false},
+		{"", "ref02/items[item01]/attr01", false},
+		{Common.RefStructure, "ref02/items[item01]/attr01", true},
+		{Common.RefStructure, "ref02/items[item01]/attr02", false},
+		{Common.RefStructure, "ref02/name[item01]", false},
+		{Common.RefStructure, "ref02/items/attr01", false},
+		{Common.RefNone, "ref02/items[item01]/attr02", true},
+	}
+	for _, test := range testList {
+		handler.Config.Schema.RefCheck = test.refCheck
+		err := addHolder(test.target)
+		if test.valid && err != nil {
+			t.Fatalf("ref [%s] with check [%s] should be valid. Error: %s", test.target, test.refCheck, err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("ref [%s] with check [%s] should be invalid", test.target, test.refCheck)
+		}
+	}
+}
+
+func TestRefCheckHeader(t *testing.T) {
+	handler := mockRefPath(t)
+	holder := func(id string, target string) *Record.Record {
+		record, ex := Record.LoadStr(fmt.Sprintf(`{"__id": "%s", "__type": "refHolder", "__ver": "0.0.1", "data": {"target": "%s"}}`, id, target))
+		if ex != nil {
+			t.Fatalf("failed to load holder [%s]. Error: %s", id, ex)
+		}
+		return record
+	}
+	header := func(mode string) map[string]interface{} {
+		return map[string]interface{}{"x-ref-check": mode}
+	}
+	testList := []struct {
+		refCheck string
+		header   map[string]interface{}
+		target   string
+		status   int
+	}{
+		{"", nil, "ref02", http.StatusBadRequest},
+		{"", header(Common.RefNone), "ref02", 0},
+		{"", header(Common.RefStructure), "ref02/items[item01]/attr01", 0},
+		{"", header(Common.RefStructure), "ref02/items[item01]/attr02", http.StatusBadRequest},
+		{"", header("skip"), "ref01", http.StatusBadRequest},
+		{Common.RefNone, nil, "ref02", 0},
+		{Common.RefNone, header(Common.RefResolve), "ref02", http.StatusBadRequest},
+		{"skip", nil, "ref01", http.StatusBadRequest},
+	}
+	for idx, test := range testList {
+		handler.Config.Schema.RefCheck = test.refCheck
+		holderId := fmt.Sprintf("holder%02d", idx)
+		err := handler.AddWith(holder(holderId, test.target), test.header)
+		if test.status == 0 && err != nil {
+			t.Fatalf("add ref [%s] with config [%s] and header %v failed. Error: %s", test.target, test.refCheck, test.header, err)
+		}
+		if test.status != 0 && (err == nil || err.Status != test.status) {
+			t.Fatalf("add ref [%s] with config [%s] and header %v should fail with [%d], got %v", test.target, test.refCheck, test.header, test.status, err)
+		}
+	}
+	handler.Config.Schema.RefCheck = ""
+	err := handler.SetIf("", "", holder("holder00", "ref01/items[item02]/attr01"), nil)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("set broken ref should fail with [%d], got %v", http.StatusBadRequest, err)
+	}
+	err = handler.SetIf("", "", holder("holder01", "ref01/items[item02]/attr01"), header(Common.RefNone))
+	if err != nil {
+		t.Fatalf("set broken ref with check [%s] failed. Error: %s", Common.RefNone, err)
+	}
+	_, err = handler.MergePatch("refHolder", "holder01", header(Common.RefResolve), map[string]interface{}{"target": "ref01/items[item01]/attr03"})
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("patch to broken ref should fail with [%d], got %v", http.StatusBadRequest, err)
+	}
+}
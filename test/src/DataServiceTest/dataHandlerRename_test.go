@@ -0,0 +1,171 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"DataService/Common"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestRename(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "renameTarget",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "renameTarget",
+				"version": "0.0.1",
+				"properties": {
+					"value": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "renameHolder",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "renameHolder",
+				"version": "0.0.1",
+				"properties": {
+					"target": {
+						"type": "string",
+						"contentMediaType": "inventory/renameTarget"
+					}
+				}
+			}
+		}`,
+		`{"__id": "target01", "__type": "renameTarget", "__ver": "0.0.1", "data": {"value": "01"}}`,
+		`{"__id": "target02", "__type": "renameTarget", "__ver": "0.0.1", "data": {"value": "02"}}`,
+		`{"__id": "holder01", "__type": "renameHolder", "__ver": "0.0.1", "data": {"target": "target01"}}`,
+		`{"__id": "holder02", "__type": "renameHolder", "__ver": "0.0.1", "data": {"target": "target01/value"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	refs, err := handler.Rename("renameTarget", "target01", "target03")
+	if err != nil {
+		t.Fatalf("failed to rename. Error: %s", err)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].DataId < refs[j].DataId
+	})
+	if len(refs) != 2 || refs[0].DataId != "holder01" || refs[1].DataId != "holder02" {
+		t.Fatalf("expect refs of holder01 and holder02 rewritten, got %v", refs)
+	}
+	_, err = handler.GetRecord("renameTarget", "target01")
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("old id should be gone after rename")
+	}
+	record, err := handler.GetRecord("renameTarget", "target03")
+	if err != nil {
+		t.Fatalf("failed to get renamed record. Error: %s", err)
+	}
+	if record.Data["value"] != "01" {
+		t.Fatalf("renamed record has wrong data [%v]", record.Data)
+	}
+	holder, err := handler.GetRecord("renameHolder", "holder01")
+	if err != nil {
+		t.Fatalf("failed to get referrer. Error: %s", err)
+	}
+	if holder.Data["target"] != "target03" {
+		t.Fatalf("ref not rewritten, got [%v]", holder.Data["target"])
+	}
+	holder, err = handler.GetRecord("renameHolder", "holder02")
+	if err != nil {
+		t.Fatalf("failed to get referrer. Error: %s", err)
+	}
+	if holder.Data["target"] != "target03/value" {
+		t.Fatalf("ref with path not rewritten, got [%v]", holder.Data["target"])
+	}
+	_, err = handler.Rename("renameTarget", "target03", "target02")
+	if err == nil {
+		t.Fatalf("rename onto existing id should fail")
+	}
+	if err.Status != http.StatusConflict {
+		t.Fatalf("rename collision return err.Code=[%d], expect err.Code=[%d]", err.Status, http.StatusConflict)
+	}
+	_, err = handler.GetRecord("renameTarget", "target03")
+	if err != nil {
+		t.Fatalf("record should stay after failed rename. Error: %s", err)
+	}
+}
+
+func TestRenameHistory(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Config.History.Depth = 2
+	for idx, data := range []string{
+		historySchema("0.0.1"),
+		`{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "first"}}`,
+		historySchema("0.0.2"),
+	} {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	record, ex := Record.LoadStr(`{"__id": "doc01", "__type": "doc", "__ver": "0.0.2", "data": {"name": "second"}}`)
+	if ex != nil {
+		t.Fatalf("failed to load record. Error: %s", ex)
+	}
+	err := handler.Set("doc", "doc01", record)
+	if err != nil {
+		t.Fatalf("failed to set doc01. Error: %s", err)
+	}
+	_, err = handler.Rename("doc", "doc01", "doc02")
+	if err != nil {
+		t.Fatalf("failed to rename. Error: %s", err)
+	}
+	data, err := handler.GetVersion("doc", "doc02", "0.0.1")
+	if err != nil {
+		t.Fatalf("expect kept version moved with renamed record. Error: %s", err)
+	}
+	if data[Record.DataId] != "doc02" || data[Record.Data].(map[string]interface{})["name"] != "first" {
+		t.Fatalf("kept version has wrong data [%v]", data)
+	}
+	recordList, err := handler.QueryDb(Common.KeyHistory, "")
+	if err != nil || len(recordList) != 1 {
+		t.Fatalf("expect only history of new id, got %v, Error: %v", recordList, err)
+	}
+}
@@ -0,0 +1,240 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"Data/DbIface"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/CmtIndex"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestRetype(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "schemaRef",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "schemaRef",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "other",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "other",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "holder",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "holder",
+				"version": "0.0.1",
+				"properties": {
+					"target": {
+						"type": "string",
+						"contentMediaType": "inventory/schemaRef"
+					},
+					"any": {
+						"type": "string",
+						"contentMediaType": "inventory/schemaRef|other"
+					}
+				}
+			}
+		}`,
+		`{"__id": "sr01", "__type": "schemaRef", "__ver": "0.0.1", "data": {"name": "sr01"}}`,
+		`{"__id": "sr02", "__type": "schemaRef", "__ver": "0.0.1", "data": {"name": "sr02"}}`,
+		`{"__id": "other01", "__type": "other", "__ver": "0.0.1", "data": {"name": "other01"}}`,
+		`{"__id": "holder01", "__type": "holder", "__ver": "0.0.1", "data": {"target": "sr01", "any": "schemaRef/sr02"}}`,
+		`{"__id": "holder02", "__type": "holder", "__ver": "0.0.1", "data": {"target": "sr02", "any": "other/other01"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	// cmtIdx is maintained by journal process, which is not running with mock handler
+	idxRecord, _ := Record.LoadStr(`{
+		"__id": "schemaRef",
+		"__type": "cmtIdx",
+		"__ver": "0.0.1",
+		"data": {
+			"dataType": "schemaRef",
+			"cmtSubscriber": {
+				"holder": {
+					"dataType": "holder",
+					"versionIndex": {}
+				}
+			}
+		}
+	}`)
+	ex = handler.DB.Create(handler.Config.DataTable.Data, idxRecord.Map())
+	if ex != nil {
+		t.Fatalf("failed to create cmtIdx. Error: %s", ex)
+	}
+	report, err := handler.Retype("schemaRef", "reference", true)
+	if err != nil {
+		t.Fatalf("failed to dry run retype. Error: %s", err)
+	}
+	if !report.DryRun || !reflect.DeepEqual(report.Schemas, []string{"schemaRef"}) || !reflect.DeepEqual(report.Records, []string{"sr01", "sr02"}) {
+		t.Fatalf("dry run report missing moved schema or records, got %v", report)
+	}
+	if !reflect.DeepEqual(report.RefSchemas, []string{"holder"}) {
+		t.Fatalf("dry run report should list referrer schema [holder], got %v", report.RefSchemas)
+	}
+	if len(report.Refs) != 1 || report.Refs[0].DataId != "holder01" || report.Refs[0].AttrPath != "/any" {
+		t.Fatalf("dry run report should list polymorphic ref of holder01, got %v", report.Refs)
+	}
+	_, err = handler.GetRecord("schemaRef", "sr01")
+	if err != nil {
+		t.Fatalf("dry run should not move records. Error: %s", err)
+	}
+	report, err = handler.Retype("schemaRef", "reference", false)
+	if err != nil {
+		t.Fatalf("failed to retype. Error: %s", err)
+	}
+	if report.DryRun || len(report.Records) != 2 {
+		t.Fatalf("retype report should list 2 moved records, got %v", report)
+	}
+	_, err = handler.LocalData("schemaRef", "sr01")
+	if err == nil {
+		t.Fatalf("records of old type should be gone after retype")
+	}
+	record, err := handler.GetRecord("reference", "sr01")
+	if err != nil {
+		t.Fatalf("failed to get retyped record. Error: %s", err)
+	}
+	if record.Type != "reference" || record.Data["name"] != "sr01" {
+		t.Fatalf("retyped record has wrong type or data, got [%s] %v", record.Type, record.Data)
+	}
+	holder, err := handler.GetRecord("holder", "holder01")
+	if err != nil {
+		t.Fatalf("failed to get referrer. Error: %s", err)
+	}
+	if holder.Data["target"] != "sr01" || holder.Data["any"] != "reference/sr02" {
+		t.Fatalf("refs of holder01 should point at new type, got %v", holder.Data)
+	}
+	value, err := handler.GetDataByPath("holder", "holder01", "target/name")
+	if err != nil || value != "sr01" {
+		t.Fatalf("ref should follow into new type, got [%v]. Error: %v", value, err)
+	}
+	value, err = handler.GetDataByPath("holder", "holder02", "any/name")
+	if err != nil || value != "other01" {
+		t.Fatalf("ref to other type should not change, got [%v]. Error: %v", value, err)
+	}
+	idxData, err := handler.LocalData(CmtIndex.KeyCmtIdx, "reference")
+	if err != nil {
+		t.Fatalf("cmtIdx should move to new type. Error: %s", err)
+	}
+	if idxData[Record.Data].(map[string]interface{})["dataType"] != "reference" {
+		t.Fatalf("cmtIdx should carry new type, got %v", idxData)
+	}
+	_, err = handler.Retype("other", "holder", false)
+	if err == nil || err.Status != http.StatusConflict {
+		t.Fatalf("retype onto existing type should return err.Code=[%d], got [%v]", http.StatusConflict, err)
+	}
+	_, err = handler.Retype("schema", "schemaNew", false)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("retype of internal type should return err.Code=[%d], got [%v]", http.StatusBadRequest, err)
+	}
+}
+
+// database that fails next failDeletes calls of Delete, every call when it is negative
+type failDeleteDb struct {
+	DbIface.Database
+	failDeletes int
+}
+
+func (d *failDeleteDb) Delete(table string, keys map[string]interface{}) error {
+	if d.failDeletes == 0 {
+		return d.Database.Delete(table, keys)
+	}
+	if d.failDeletes > 0 {
+		d.failDeletes--
+	}
+	return fmt.Errorf("failed to delete %v", keys)
+}
+
+func TestRetypeRollback(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	for idx, data := range []string{
+		historySchema("0.0.1"),
+		`{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}}`,
+	} {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	db := &failDeleteDb{Database: handler.DB, failDeletes: 1}
+	handler.DB = db
+	_, err := handler.Retype("doc", "paper", false)
+	if err == nil || !strings.Contains(err.Error(), "changes rolled back") {
+		t.Fatalf("expect retype rolled back when a write fails, got %v", err)
+	}
+	recordList, err := handler.QueryDb("paper", "")
+	if err != nil || len(recordList) != 0 {
+		t.Fatalf("expect no record of new type after roll back, got %v, Error: %v", recordList, err)
+	}
+	_, err = handler.GetRecord("doc", "doc01")
+	if err != nil {
+		t.Fatalf("expect record of old type kept after roll back. Error: %s", err)
+	}
+	db.failDeletes = -1
+	_, err = handler.Retype("doc", "paper", false)
+	if err == nil || !strings.Contains(err.Error(), "roll back incomplete") {
+		t.Fatalf("expect failed undo reported, got %v", err)
+	}
+}
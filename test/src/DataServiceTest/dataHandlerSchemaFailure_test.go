@@ -0,0 +1,143 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestSchemaLoadFailure(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	for _, dataType := range []string{"rack", "host"} {
+		err := AddData(handler, fmt.Sprintf(`{
+			"__id": "%s",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "%s",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					}
+				}
+			}
+		}`, dataType, dataType))
+		if err != nil {
+			t.Fatalf("failed to add schema [%s]. Error: %s", dataType, err)
+		}
+	}
+	err := AddData(handler, `{"__id": "h01", "__type": "host", "__ver": "0.0.1", "data": {"name": "h01"}}`)
+	if err != nil {
+		t.Fatalf("failed to add host. Error: %s", err)
+	}
+	// stored schema that extends a schema not exists, like one left behind by an old version
+	broken, ex := Record.LoadStr(`{
+		"__id": "switch",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "switch",
+			"version": "0.0.1",
+			"extends": "missingBase",
+			"properties": {
+				"name": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if ex != nil {
+		t.Fatalf("failed to load broken schema. Error: %s", ex)
+	}
+	ex = handler.DB.Create(handler.Config.DataTable.Data, broken.Map())
+	if ex != nil {
+		t.Fatalf("failed to create broken schema. Error: %s", ex)
+	}
+	handler.CheckSchemas()
+	failed := handler.FailedSchemas()
+	if len(failed) != 1 {
+		t.Fatalf("expect 1 failed schema, got %v", failed)
+	}
+	if _, ok := failed["switch"]; !ok {
+		t.Fatalf("expect failed schema [switch], got %v", failed)
+	}
+	_, err = handler.LocalSchema("switch", "")
+	if err == nil || err.Status != http.StatusServiceUnavailable {
+		t.Fatalf("expect 503 on schema failed to load, got %v", err)
+	}
+	err = AddData(handler, `{"__id": "s01", "__type": "switch", "__ver": "0.0.1", "data": {"name": "s01"}}`)
+	if err == nil || err.Status != http.StatusServiceUnavailable {
+		t.Fatalf("expect 503 on adding record of failed schema, got %v", err)
+	}
+	err = AddData(handler, `{"__id": "h02", "__type": "host", "__ver": "0.0.1", "data": {"name": "h02"}}`)
+	if err != nil {
+		t.Fatalf("failed to add host next to failed schema. Error: %s", err)
+	}
+	_, err = handler.List("rack")
+	if err != nil {
+		t.Fatalf("failed to list rack next to failed schema. Error: %s", err)
+	}
+	summary, err := handler.Summary()
+	if err != nil {
+		t.Fatalf("failed to get summary. Error: %s", err)
+	}
+	if _, ok := summary["switch"]; ok || summary["host"] != 2 {
+		t.Fatalf("expect summary without failed schema, got %v", summary)
+	}
+	err = AddData(handler, `{
+		"__id": "missingBase",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "missingBase",
+			"version": "0.0.1",
+			"properties": {
+				"model": {
+					"type": "string",
+					"required": false
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add base schema. Error: %s", err)
+	}
+	_, err = handler.LocalSchema("switch", "")
+	if err != nil {
+		t.Fatalf("failed to load schema once base exists. Error: %s", err)
+	}
+	if failed := handler.FailedSchemas(); len(failed) != 0 {
+		t.Fatalf("expect no failed schema once fixed, got %v", failed)
+	}
+}
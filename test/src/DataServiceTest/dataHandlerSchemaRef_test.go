@@ -0,0 +1,215 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"DataService/Config"
+)
+
+func TestSchemaExternalRef(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "shared",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "shared",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				}
+			},
+			"definitions": {
+				"address": {
+					"name": "address",
+					"properties": {
+						"street": {
+							"type": "string"
+						},
+						"geo": {
+							"type": "object",
+							"$ref": "#/definitions/geo",
+							"required": false
+						}
+					}
+				},
+				"geo": {
+					"name": "geo",
+					"properties": {
+						"lat": {
+							"type": "number"
+						}
+					}
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add shared schema. Error: %s", err)
+	}
+	err = AddData(handler, `{
+		"__id": "site",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "site",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				},
+				"address": {
+					"type": "object",
+					"$ref": "shared#/definitions/address"
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema with external ref. Error: %s", err)
+	}
+	err = AddData(handler, `{"__id": "s01", "__type": "site", "__ver": "0.0.1", "data": {"name": "s01", "address": {"street": "main", "geo": {"lat": 1.5}}}}`)
+	if err != nil {
+		t.Fatalf("failed to add record of schema with external ref. Error: %s", err)
+	}
+	err = AddData(handler, `{"__id": "s02", "__type": "site", "__ver": "0.0.1", "data": {"name": "s02", "address": {"street": "main", "geo": {"lat": "north"}}}}`)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("invalid data in external definition expect 400, got [%v]", err)
+	}
+	lat, err := handler.GetDataByPath("site", "s01", "address/geo/lat")
+	if err != nil {
+		t.Fatalf("failed to walk into external definition. Error: %s", err)
+	}
+	if lat != 1.5 {
+		t.Fatalf("unexpected value of address/geo/lat, [%v]", lat)
+	}
+	err = AddData(handler, `{
+		"__id": "broken",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "broken",
+			"version": "0.0.1",
+			"properties": {
+				"address": {
+					"type": "object",
+					"$ref": "shared#/definitions/notExists"
+				}
+			}
+		}
+	}`)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("schema ref to unknown external definition expect 400, got [%v]", err)
+	}
+}
+
+func TestSchemaUrlRef(t *testing.T) {
+	sharedStr := `{
+		"name": "shared",
+		"version": "0.0.1",
+		"properties": {},
+		"definitions": {
+			"address": {
+				"name": "address",
+				"properties": {
+					"street": {"type": "string"}
+				}
+			}
+		}
+	}`
+	bigStr := fmt.Sprintf(`{"name": "big", "version": "0.0.1", "description": "%s", "properties": {}, "definitions": {"address": {"name": "address", "properties": {}}}}`, strings.Repeat("x", 4096))
+	fetched := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		switch r.URL.Path {
+		case "/schemas/shared", "/other/shared":
+			w.Write([]byte(sharedStr))
+		case "/schemas/big":
+			w.Write([]byte(bigStr))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	schemaStr := func(schemaId string, ref string) string {
+		return fmt.Sprintf(`{
+			"__id": "%s",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "%s",
+				"version": "0.0.1",
+				"properties": {
+					"address": {"type": "object", "$ref": "%s#/definitions/address"}
+				}
+			}
+		}`, schemaId, schemaId, ref)
+	}
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, schemaStr("site", srv.URL+"/schemas/shared"))
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("url ref without allowed prefix expect 400, got [%v]", err)
+	}
+	if fetched != 0 {
+		t.Fatalf("url ref without allowed prefix should not be fetched, fetched %d times", fetched)
+	}
+	handler, ex = MockHandlerConfig(func(config *Config.Confuguration) {
+		config.Schema.RefUrl.Allow = []string{srv.URL + "/schemas/"}
+		config.Schema.RefUrl.MaxSize = 1024
+	})
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err = AddData(handler, schemaStr("site", srv.URL+"/schemas/shared"))
+	if err != nil {
+		t.Fatalf("failed to add schema with allowed url ref. Error: %s", err)
+	}
+	err = AddData(handler, `{"__id": "s01", "__type": "site", "__ver": "0.0.1", "data": {"address": {"street": "main"}}}`)
+	if err != nil {
+		t.Fatalf("failed to add record of schema with url ref. Error: %s", err)
+	}
+	err = AddData(handler, schemaStr("office", srv.URL+"/other/shared"))
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("url ref outside allowed prefix expect 400, got [%v]", err)
+	}
+	err = AddData(handler, schemaStr("campus", srv.URL+"/schemas/big"))
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("url ref larger than maxSize expect 400, got [%v]", err)
+	}
+}
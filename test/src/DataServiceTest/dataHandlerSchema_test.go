@@ -26,8 +26,10 @@ This copyright notice and license applies to all files in this directory or sub-
 package DataServiceTest
 
 import (
+	"DataService/Common"
 	"DataService/DataHandler"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
@@ -219,3 +221,690 @@ func TestAddData(t *testing.T) {
 		t.Fatalf("failed to add data on archived schema")
 	}
 }
+
+func TestEffectiveSchema(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	baseSchema := `{
+		"__id": "baseItem",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "baseItem",
+			"version": "0.0.1",
+			"properties": {
+				"owner": {
+					"type": "string"
+				},
+				"note": {
+					"type": "string",
+					"required": false
+				}
+			}
+		}
+	}`
+	err := AddData(handler, baseSchema)
+	if err != nil {
+		t.Fatalf("failed to add base schema. Error: %s", err)
+	}
+	childSchema := `{
+		"__id": "childItem",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "childItem",
+			"version": "0.0.1",
+			"extends": "baseItem",
+			"properties": {
+				"size": {
+					"type": "integer"
+				}
+			}
+		}
+	}`
+	err = AddData(handler, childSchema)
+	if err != nil {
+		t.Fatalf("failed to add child schema. Error: %s", err)
+	}
+	effective, err := handler.EffectiveSchema("childItem")
+	if err != nil {
+		t.Fatalf("failed to get effective schema. Error: %s", err)
+	}
+	record, ex := Record.LoadMap(effective)
+	if ex != nil {
+		t.Fatalf("failed to load effective schema as record. Error: %s", ex)
+	}
+	properties := record.Data[JsonKey.Properties].(map[string]interface{})
+	for _, attr := range []string{"owner", "note", "size"} {
+		if _, ok := properties[attr]; !ok {
+			t.Fatalf("missing property [%s] from effective schema", attr)
+		}
+	}
+	if properties["owner"].(map[string]interface{})[JsonKey.Required] != true {
+		t.Fatalf("default [%s]=true not surfaced on inherited property [owner]", JsonKey.Required)
+	}
+	if properties["note"].(map[string]interface{})[JsonKey.Required] != false {
+		t.Fatalf("explicit [%s]=false lost on inherited property [note]", JsonKey.Required)
+	}
+	stored, err := handler.Get(JsonKey.Schema, "childItem")
+	if err != nil {
+		t.Fatalf("failed to get stored child schema. Error: %s", err)
+	}
+	storedProps := stored.(map[string]interface{})[Record.Data].(map[string]interface{})[JsonKey.Properties].(map[string]interface{})
+	if _, ok := storedProps["owner"]; ok {
+		t.Fatalf("stored schema should not be changed by inherited properties")
+	}
+	childData := `{
+		"__id": "child01",
+		"__type": "childItem",
+		"__ver": "0.0.1",
+		"data": {
+			"size": 1
+		}
+	}`
+	err = AddData(handler, childData)
+	if err == nil {
+		t.Fatalf("failed to validate inherited required property [owner]")
+	}
+	childData = `{
+		"__id": "child01",
+		"__type": "childItem",
+		"__ver": "0.0.1",
+		"data": {
+			"owner": "someone",
+			"size": 1
+		}
+	}`
+	err = AddData(handler, childData)
+	if err != nil {
+		t.Fatalf("failed to add child data with inherited property. Error: %s", err)
+	}
+	loopSchema := `{
+		"__id": "loopItem",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "loopItem",
+			"version": "0.0.1",
+			"extends": "loopItem",
+			"properties": {
+				"size": {
+					"type": "integer"
+				}
+			}
+		}
+	}`
+	err = AddData(handler, loopSchema)
+	if err == nil {
+		t.Fatalf("failed to detect circular extends")
+	}
+}
+
+func TestSchemaEvolutionRead(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Config.Schema.ReadPolicy = Common.ReadLenient
+	schemaV1 := `{
+		"__id": "evolve",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "evolve",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				}
+			}
+		}
+	}`
+	schemaV2 := `{
+		"__id": "evolve",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "evolve",
+			"version": "0.0.2",
+			"properties": {
+				"name": {
+					"type": "string"
+				},
+				"owner": {
+					"type": "string"
+				}
+			}
+		}
+	}`
+	oldRecord := `{
+		"__id": "evolve01",
+		"__type": "evolve",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "evolve01"
+		}
+	}`
+	for _, data := range []string{schemaV1, oldRecord, schemaV2} {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data. Error: %s", err)
+		}
+	}
+	record, err := handler.GetRecord("evolve", "evolve01")
+	if err != nil {
+		t.Fatalf("lenient read of old version record failed. Error: %s", err)
+	}
+	if record.Version != "0.0.1" {
+		t.Fatalf("expect record version [0.0.1], got [%s]", record.Version)
+	}
+	_, err = handler.Get("evolve", "evolve01")
+	if err != nil {
+		t.Fatalf("lenient get of old version record failed. Error: %s", err)
+	}
+	handler.Config.Schema.ReadPolicy = Common.ReadStrict
+	_, err = handler.Get("evolve", "evolve01")
+	if err == nil {
+		t.Fatalf("strict read of old record missing new required field should fail")
+	}
+	handler.Config.Schema.ReadPolicy = Common.ReadLenient
+	newRecord := `{
+		"__id": "evolve02",
+		"__type": "evolve",
+		"__ver": "0.0.2",
+		"data": {
+			"name": "evolve02"
+		}
+	}`
+	err = AddData(handler, newRecord)
+	if err == nil {
+		t.Fatalf("write of record missing required field [owner] should be rejected")
+	}
+	err = AddData(handler, strings.ReplaceAll(oldRecord, "evolve01", "evolve03"))
+	if err == nil {
+		t.Fatalf("write of record with old schema version should be rejected")
+	}
+}
+
+func TestSchemaExamples(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	schemaStr := `{
+		"__id": "exampleItem",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "exampleItem",
+			"version": "0.0.1",
+			"properties": {
+				"port": {
+					"type": "integer",
+					"examples": ["8010", "http"]
+				},
+				"status": {
+					"type": "string",
+					"enum": ["active", "retired"],
+					"examples": ["active"]
+				}
+			}
+		}
+	}`
+	err := AddData(handler, schemaStr)
+	if err != nil {
+		t.Fatalf("failed to add schema with examples. Error: %s", err)
+	}
+	schema, err := handler.LocalSchema("exampleItem", "")
+	if err != nil {
+		t.Fatalf("failed to load schema. Error: %s", err)
+	}
+	if len(schema.Schema.Examples["port"]) != 1 || schema.Schema.Examples["port"][0] != float64(8010) {
+		t.Fatalf("example of port not parsed as integer, got %v", schema.Schema.Examples["port"])
+	}
+	if len(schema.Schema.Warnings) != 1 {
+		t.Fatalf("expect 1 warning for example [http] of integer port, got %v", schema.Schema.Warnings)
+	}
+	data, err := handler.Get(JsonKey.Schema, "exampleItem")
+	if err != nil {
+		t.Fatalf("failed to get schema. Error: %s", err)
+	}
+	record, ex := Record.LoadMap(data.(map[string]interface{}))
+	if ex != nil {
+		t.Fatalf("failed to load schema response as record. Error: %s", ex)
+	}
+	statusDef := record.Data[JsonKey.Properties].(map[string]interface{})["status"].(map[string]interface{})
+	if examples, ok := statusDef[JsonKey.Examples].([]interface{}); !ok || len(examples) != 1 || examples[0] != "active" {
+		t.Fatalf("examples missing from schema response, got %v", statusDef)
+	}
+	effective, err := handler.EffectiveSchema("exampleItem")
+	if err != nil {
+		t.Fatalf("failed to get effective schema. Error: %s", err)
+	}
+	effectiveData := effective[Record.Data].(map[string]interface{})
+	portDef := effectiveData[JsonKey.Properties].(map[string]interface{})["port"].(map[string]interface{})
+	if examples, ok := portDef[JsonKey.Examples].([]interface{}); !ok || len(examples) != 1 || examples[0] != float64(8010) {
+		t.Fatalf("effective schema should show typed examples, got %v", portDef[JsonKey.Examples])
+	}
+}
+
+func TestPolymorphicRefWrite(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{"__id": "refUser", "__type": "schema", "__ver": "0.0.1", "data": {"name": "refUser", "version": "0.0.1", "properties": {"email": {"type": "string"}}}}`,
+		`{"__id": "refTeam", "__type": "schema", "__ver": "0.0.1", "data": {"name": "refTeam", "version": "0.0.1", "properties": {"channel": {"type": "string"}}}}`,
+		`{"__id": "refOther", "__type": "schema", "__ver": "0.0.1", "data": {"name": "refOther", "version": "0.0.1", "properties": {"value": {"type": "string"}}}}`,
+		`{
+			"__id": "refOwned",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "refOwned",
+				"version": "0.0.1",
+				"properties": {
+					"owner": {
+						"type": "string",
+						"contentMediaType": "inventory/refUser|refTeam"
+					}
+				}
+			}
+		}`,
+		`{"__id": "user01", "__type": "refUser", "__ver": "0.0.1", "data": {"email": "user01@example.com"}}`,
+		`{"__id": "team01", "__type": "refTeam", "__ver": "0.0.1", "data": {"channel": "#team01"}}`,
+		`{"__id": "other01", "__type": "refOther", "__ver": "0.0.1", "data": {"value": "01"}}`,
+		`{"__id": "owned01", "__type": "refOwned", "__ver": "0.0.1", "data": {"owner": "refUser/user01"}}`,
+		`{"__id": "owned02", "__type": "refOwned", "__ver": "0.0.1", "data": {"owner": "refTeam/team01"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	badList := []string{
+		// type not listed in contentMediaType
+		`{"__id": "owned03", "__type": "refOwned", "__ver": "0.0.1", "data": {"owner": "refOther/other01"}}`,
+		// polymorphic ref value must carry the type
+		`{"__id": "owned04", "__type": "refOwned", "__ver": "0.0.1", "data": {"owner": "user01"}}`,
+		// allowed type but record does not exist
+		`{"__id": "owned05", "__type": "refOwned", "__ver": "0.0.1", "data": {"owner": "refTeam/user01"}}`,
+	}
+	for idx, data := range badList {
+		err := AddData(handler, data)
+		if err == nil {
+			t.Fatalf("invalid ref should be rejected @[%d]", idx)
+		}
+		if err.Status != http.StatusBadRequest {
+			t.Fatalf("invalid ref @[%d] return err.Code=[%d], expect err.Code=[%d]", idx, err.Status, http.StatusBadRequest)
+		}
+	}
+	err := AddData(handler, `{
+		"__id": "refBadIdx",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "refBadIdx",
+			"version": "0.0.1",
+			"properties": {
+				"owner": {
+					"type": "string",
+					"contentMediaType": "inventory/refUser|refTeam",
+					"indexTemplate": "{email}"
+				}
+			}
+		}
+	}`)
+	if err == nil {
+		t.Fatalf("indexTemplate on polymorphic ref should be rejected")
+	}
+}
+
+func TestRecordWarnings(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "warnTest",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "warnTest",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"oldName": {
+						"type": "string",
+						"deprecated": true,
+						"required": false
+					}
+				}
+			}
+		}`,
+		`{"__id": "warn01", "__type": "warnTest", "__ver": "0.0.1", "data": {"name": "01", "oldName": "one"}}`,
+		`{"__id": "warn02", "__type": "warnTest", "__ver": "0.0.1", "data": {"name": "02"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("record with warnings should not be blocked @[%d]. Error: %s", idx, err)
+		}
+	}
+	record, err := handler.GetRecord("warnTest", "warn01")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	warnings := handler.Warnings(record)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "oldName") {
+		t.Fatalf("expect 1 warning on deprecated attr, got %v", warnings)
+	}
+	record, err = handler.GetRecord("warnTest", "warn02")
+	if err != nil {
+		t.Fatalf("failed to get record. Error: %s", err)
+	}
+	warnings = handler.Warnings(record)
+	if len(warnings) != 0 {
+		t.Fatalf("expect no warning, got %v", warnings)
+	}
+}
+
+func TestValidateAttrOnWrite(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	schemaList := []string{
+		`{
+			"__id": "looseTest",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "looseTest",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"size": {
+						"type": "integer",
+						"required": false
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "strictTest",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "strictTest",
+				"version": "0.0.1",
+				"additionalProperties": false,
+				"properties": {
+					"name": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "openTest",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "openTest",
+				"version": "0.0.1",
+				"additionalProperties": true,
+				"properties": {
+					"name": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{"__id": "loose01", "__type": "looseTest", "__ver": "0.0.1", "data": {"name": "01"}}`,
+	}
+	for idx, data := range schemaList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	err := AddData(handler, `{"__id": "loose02", "__type": "looseTest", "__ver": "0.0.1", "data": {"name": "02", "size": "big"}}`)
+	if err == nil || err.Status != http.StatusBadRequest || !strings.Contains(err.Error(), "size") {
+		t.Fatalf("expect 400 naming attr [size] on add with wrong type, got %v", err)
+	}
+	record := Record.NewRecord("looseTest", "0.0.1", "loose01", map[string]interface{}{
+		"name": 1,
+	})
+	err = handler.Set("looseTest", "loose01", record)
+	if err == nil || err.Status != http.StatusBadRequest || !strings.Contains(err.Error(), "expected string") {
+		t.Fatalf("expect 400 with expected type on set with wrong type, got %v", err)
+	}
+	// unknown attr is kept and reported, unless schema does not allow it
+	record = Record.NewRecord("looseTest", "0.0.1", "loose01", map[string]interface{}{
+		"name":  "01",
+		"color": "red",
+	})
+	err = handler.Set("looseTest", "loose01", record)
+	if err != nil {
+		t.Fatalf("unknown attr should not block write. Error: %s", err)
+	}
+	warnings := handler.Warnings(record)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "color") {
+		t.Fatalf("expect 1 warning on unknown attr, got %v", warnings)
+	}
+	record = Record.NewRecord("openTest", "0.0.1", "open01", map[string]interface{}{
+		"name":  "01",
+		"color": "red",
+	})
+	err = handler.Add(record)
+	if err != nil {
+		t.Fatalf("unknown attr should be allowed with additionalProperties=true. Error: %s", err)
+	}
+	warnings = handler.Warnings(record)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "color") {
+		t.Fatalf("expect 1 warning on unknown attr with additionalProperties=true, got %v", warnings)
+	}
+	err = AddData(handler, `{"__id": "strict01", "__type": "strictTest", "__ver": "0.0.1", "data": {"name": "01", "color": "red"}}`)
+	if err == nil || err.Status != http.StatusBadRequest || !strings.Contains(err.Error(), "color") {
+		t.Fatalf("expect 400 naming unknown attr [color] with additionalProperties=false, got %v", err)
+	}
+}
+
+func TestRequiredAttrsOnWrite(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "reqTest",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "reqTest",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				},
+				"size": {
+					"type": "integer"
+				},
+				"note": {
+					"type": "string",
+					"required": false
+				},
+				"spec": {
+					"type": "object",
+					"$ref": "#/definitions/spec"
+				},
+				"ports": {
+					"type": "array",
+					"required": false,
+					"items": {
+						"type": "object",
+						"$ref": "#/definitions/port"
+					}
+				}
+			},
+			"definitions": {
+				"spec": {
+					"name": "spec",
+					"properties": {
+						"owner": {
+							"type": "string"
+						},
+						"team": {
+							"type": "string"
+						}
+					}
+				},
+				"port": {
+					"name": "port",
+					"key": "{id}",
+					"properties": {
+						"id": {
+							"type": "string"
+						},
+						"speed": {
+							"type": "integer"
+						}
+					}
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	err = AddData(handler, `{"__id": "r01", "__type": "reqTest", "__ver": "0.0.1", "data": {"spec": {"team": "infra"}, "ports": [{"id": "p0", "speed": 10}, {"id": "p1"}]}}`)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expect 400 on record missing required attrs, got %v", err)
+	}
+	errMsg := strings.Join(err.Message, " ")
+	for _, missing := range []string{"reqTest/r01/name", "reqTest/r01/size", "reqTest/r01/spec/owner", "reqTest/r01/ports[1]/speed"} {
+		if !strings.Contains(errMsg, missing) {
+			t.Fatalf("expect [%s] in missing attrs, got [%s]", missing, errMsg)
+		}
+	}
+	if strings.Contains(errMsg, "note") || strings.Contains(errMsg, "team") {
+		t.Fatalf("attr present or not required should not be reported, got [%s]", errMsg)
+	}
+	err = AddData(handler, `{"__id": "r01", "__type": "reqTest", "__ver": "0.0.1", "data": {"name": "r01", "size": 1, "spec": {"owner": "alice", "team": "infra"}}}`)
+	if err != nil {
+		t.Fatalf("failed to add record with all required attrs. Error: %s", err)
+	}
+}
+
+func TestKnownType(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "knownType",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "knownType",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	if !handler.KnownType(JsonKey.Schema) {
+		t.Fatalf("expect [%s] to be known", JsonKey.Schema)
+	}
+	if handler.KnownType("noSuchType") {
+		t.Fatalf("expect type without schema to be unknown")
+	}
+	_, err = handler.GetSchema("knownType")
+	if err != nil {
+		t.Fatalf("failed to load schema. Error: %s", err)
+	}
+	if !handler.KnownType("knownType") {
+		t.Fatalf("expect type with loaded schema to be known")
+	}
+}
+
+func TestDiscriminatorSchema(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "device",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "device",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				},
+				"kind": {
+					"type": "string"
+				}
+			},
+			"discriminator": {
+				"propertyName": "kind",
+				"mapping": {
+					"server": "#/definitions/server",
+					"switch": "switch"
+				}
+			},
+			"definitions": {
+				"server": {
+					"properties": {
+						"cpu": {
+							"type": "integer"
+						}
+					}
+				},
+				"switch": {
+					"properties": {
+						"ports": {
+							"type": "integer"
+						}
+					}
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema with discriminator. Error: %s", err)
+	}
+	for idx, data := range []string{
+		`{"__id": "srv01", "__type": "device", "__ver": "0.0.1", "data": {"name": "srv01", "kind": "server", "cpu": 16}}`,
+		`{"__id": "sw01", "__type": "device", "__ver": "0.0.1", "data": {"name": "sw01", "kind": "switch", "ports": 48}}`,
+	} {
+		err = AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add record of kind @[%d]. Error: %s", idx, err)
+		}
+	}
+	err = AddData(handler, `{"__id": "sw02", "__type": "device", "__ver": "0.0.1", "data": {"name": "sw02", "kind": "switch", "cpu": 16}}`)
+	if err == nil || err.Status != http.StatusBadRequest || !strings.Contains(err.Error(), "ports") {
+		t.Fatalf("expect 400 naming [ports] on record missing attr of its kind, got %v", err)
+	}
+	err = AddData(handler, `{"__id": "rt01", "__type": "device", "__ver": "0.0.1", "data": {"name": "rt01", "kind": "router"}}`)
+	if err == nil || err.Status != http.StatusBadRequest || !strings.Contains(err.Error(), "router") {
+		t.Fatalf("expect 400 naming unknown kind [router], got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"Data/DbIface"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+// database that counts records on its own, like mongodb does
+type countingDb struct {
+	DbIface.Database
+	counted []string
+}
+
+func (db *countingDb) Count(queryArgs map[string]interface{}) (int, error) {
+	db.counted = append(db.counted, queryArgs[Record.DataType].(string))
+	recordList, err := db.Get(queryArgs)
+	if err != nil {
+		return 0, err
+	}
+	return len(recordList), nil
+}
+
+func TestSummary(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	for _, dataType := range []string{"rack", "host"} {
+		err := AddData(handler, fmt.Sprintf(`{
+			"__id": "%s",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "%s",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					}
+				}
+			}
+		}`, dataType, dataType))
+		if err != nil {
+			t.Fatalf("failed to add schema [%s]. Error: %s", dataType, err)
+		}
+	}
+	for idx := 0; idx < 3; idx++ {
+		err := AddData(handler, fmt.Sprintf(`{"__id": "h%02d", "__type": "host", "__ver": "0.0.1", "data": {"name": "h%02d"}}`, idx, idx))
+		if err != nil {
+			t.Fatalf("failed to add host @[%d]. Error: %s", idx, err)
+		}
+	}
+	err := AddData(handler, `{"__id": "r01", "__type": "rack", "__ver": "0.0.1", "data": {"name": "r01"}}`)
+	if err != nil {
+		t.Fatalf("failed to add rack. Error: %s", err)
+	}
+	expected := map[string]int{
+		"host": 3,
+		"rack": 1,
+	}
+	summary, err := handler.Summary()
+	if err != nil {
+		t.Fatalf("failed to get summary. Error: %s", err)
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Fatalf("expect summary %v, got %v", expected, summary)
+	}
+	db := &countingDb{Database: handler.DB}
+	handler.DB = db
+	summary, err = handler.Summary()
+	if err != nil {
+		t.Fatalf("failed to get summary with counter. Error: %s", err)
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Fatalf("expect summary %v from counter, got %v", expected, summary)
+	}
+	if len(db.counted) != 2 {
+		t.Fatalf("expect database to count both types, got %v", db.counted)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"DataService/DataHandler"
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func addTenantData(handler *DataHandler.Handler, tenant string, data string) *Http.HttpError {
+	record, ex := Record.LoadStr(data)
+	if ex != nil {
+		return Http.WrapError(ex, "failed to load data as record", http.StatusBadRequest)
+	}
+	record.Tenant = tenant
+	return handler.Add(record)
+}
+
+func prepareTenantHandler(t *testing.T) *DataHandler.Handler {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "machine",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "machine",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				},
+				"size": {
+					"type": "string",
+					"enum": ["small", "large"]
+				},
+				"owner": {
+					"type": "string",
+					"required": false
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	return handler
+}
+
+func TestTenantOverlay(t *testing.T) {
+	handler := prepareTenantHandler(t)
+	err := handler.SetOverlay("t1", "machine", map[string]interface{}{
+		"properties": map[string]interface{}{
+			"costCenter": map[string]interface{}{
+				"type": "string",
+			},
+			"owner": map[string]interface{}{
+				"required": true,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to set overlay. Error: %s", err)
+	}
+	testList := []struct {
+		tenant string
+		data   string
+		status int
+	}{
+		{"", `{"__id": "m01", "__type": "machine", "__ver": "0.0.1", "data": {"name": "m01", "size": "small"}}`, 0},
+		{"t2", `{"__id": "m02", "__type": "machine", "__ver": "0.0.1", "data": {"name": "m02", "size": "small"}}`, 0},
+		{"t1", `{"__id": "m03", "__type": "machine", "__ver": "0.0.1", "data": {"name": "m03", "size": "small", "owner": "a"}}`, http.StatusBadRequest},
+		{"t1", `{"__id": "m04", "__type": "machine", "__ver": "0.0.1", "data": {"name": "m04", "size": "small", "costCenter": "c1"}}`, http.StatusBadRequest},
+		{"t1", `{"__id": "m05", "__type": "machine", "__ver": "0.0.1", "data": {"name": "m05", "size": "small", "owner": "a", "costCenter": "c1"}}`, 0},
+		{"t1", `{"__id": "m06", "__type": "machine", "__ver": "0.0.1", "data": {"name": "m06", "size": "medium", "owner": "a", "costCenter": "c1"}}`, http.StatusBadRequest},
+	}
+	for idx, test := range testList {
+		err := addTenantData(handler, test.tenant, test.data)
+		if test.status == 0 && err != nil {
+			t.Fatalf("write @[%d] of tenant [%s] should pass. Error: %s", idx, test.tenant, err)
+		}
+		if test.status != 0 && (err == nil || err.Status != test.status) {
+			t.Fatalf("write @[%d] of tenant [%s] expect status [%d], got [%v]", idx, test.tenant, test.status, err)
+		}
+	}
+	err = handler.SetOverlay("t1", "machine", nil)
+	if err != nil {
+		t.Fatalf("failed to remove overlay. Error: %s", err)
+	}
+	err = addTenantData(handler, "t1", `{"__id": "m07", "__type": "machine", "__ver": "0.0.1", "data": {"name": "m07", "size": "small"}}`)
+	if err != nil {
+		t.Fatalf("write of tenant should pass after overlay removed. Error: %s", err)
+	}
+}
+
+func TestTenantOverlayLoosen(t *testing.T) {
+	handler := prepareTenantHandler(t)
+	overlayList := map[string]map[string]interface{}{
+		"optional required attr": {
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{"required": false},
+			},
+		},
+		"change type": {
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{"type": "integer"},
+			},
+		},
+		"widen enum": {
+			"properties": map[string]interface{}{
+				"size": map[string]interface{}{"enum": []interface{}{"small", "medium"}},
+			},
+		},
+		"unsupported key": {
+			"extends": "base",
+		},
+	}
+	for name, overlay := range overlayList {
+		err := handler.SetOverlay("t1", "machine", overlay)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("overlay [%s] should be rejected with 400, got [%v]", name, err)
+		}
+	}
+	err := handler.SetOverlay("t1", "machine", map[string]interface{}{
+		"properties": map[string]interface{}{
+			"size": map[string]interface{}{"enum": []interface{}{"large"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("overlay narrowing enum should pass. Error: %s", err)
+	}
+	err = handler.SetOverlay("t1", "notExists", map[string]interface{}{})
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("overlay on unknown type expect 404, got [%v]", err)
+	}
+}
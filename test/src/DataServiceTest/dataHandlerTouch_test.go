@@ -0,0 +1,124 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestTouch(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	dataList := []string{
+		`{
+			"__id": "device",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "device",
+				"version": "0.0.1",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"tags": {
+						"type": "array",
+						"items": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		}`,
+		`{
+			"__id": "d01",
+			"__type": "device",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "d01",
+				"tags": ["a", "b"]
+			}
+		}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	stored, err := handler.LocalData("device", "d01")
+	if err != nil {
+		t.Fatalf("failed to get stored record. Error: %s", err)
+	}
+	before, _ := Record.LoadMap(stored)
+	modifiedBefore, ex := time.Parse(time.RFC3339Nano, before.Modified)
+	if ex != nil {
+		t.Fatalf("invalid [%s]=[%s] on added record. Error: %s", Record.Modified, before.Modified, ex)
+	}
+	time.Sleep(10 * time.Millisecond)
+	result, err := handler.Touch("device", "d01", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("failed to touch record. Error: %s", err)
+	}
+	touched, ex := Record.LoadMap(result)
+	if ex != nil {
+		t.Fatalf("touch result is not a record. Error: %s", ex)
+	}
+	modifiedAfter, ex := time.Parse(time.RFC3339Nano, touched.Modified)
+	if ex != nil {
+		t.Fatalf("invalid [%s]=[%s] on touched record. Error: %s", Record.Modified, touched.Modified, ex)
+	}
+	if !modifiedAfter.After(modifiedBefore) {
+		t.Fatalf("[%s] did not advance, before=[%s], after=[%s]", Record.Modified, before.Modified, touched.Modified)
+	}
+	stored, err = handler.LocalData("device", "d01")
+	if err != nil {
+		t.Fatalf("failed to get touched record. Error: %s", err)
+	}
+	after, _ := Record.LoadMap(stored)
+	if after.Modified != touched.Modified {
+		t.Fatalf("touch is not stored, expect [%s]=[%s], got [%s]", Record.Modified, touched.Modified, after.Modified)
+	}
+	if !reflect.DeepEqual(before.Data, after.Data) || after.Version != before.Version {
+		t.Fatalf("touch changed record, before=[%v], after=[%v]", before.Data, after.Data)
+	}
+	_, err = handler.Touch("device", "d01", map[string]interface{}{JsonKey.Version: "0.0.2"})
+	if err == nil || err.Status != http.StatusNotModified {
+		t.Fatalf("touch with mismatched version should return err.Code=[%d], got [%v]", http.StatusNotModified, err)
+	}
+	_, err = handler.Touch("device", "d02", map[string]interface{}{})
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("touch on missing record should return err.Code=[%d], got [%v]", http.StatusNotFound, err)
+	}
+}
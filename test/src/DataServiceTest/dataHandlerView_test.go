@@ -0,0 +1,143 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"DataService/Config"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDataView(t *testing.T) {
+	handler, ex := MockHandlerConfig(func(config *Config.Confuguration) {
+		config.Views = map[string]Config.ViewConfig{
+			"activeDevices": {
+				Type:   "device",
+				Filter: `status == "active"`,
+				Fields: []string{"name", "status"},
+			},
+		}
+	})
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	err := AddData(handler, `{
+		"__id": "device",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "device",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				},
+				"status": {
+					"type": "string",
+					"required": false
+				},
+				"serial": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	for _, data := range []string{
+		`{"__id": "d01", "__type": "device", "__ver": "0.0.1", "data": {"name": "d01", "status": "active", "serial": "s01"}}`,
+		`{"__id": "d02", "__type": "device", "__ver": "0.0.1", "data": {"name": "d02", "status": "retired", "serial": "s02"}}`,
+		`{"__id": "d03", "__type": "device", "__ver": "0.0.1", "data": {"name": "d03", "serial": "s03"}}`,
+		`{"__id": "d04", "__type": "device", "__ver": "0.0.1", "data": {"name": "d04", "status": "active", "serial": "s04"}}`,
+	} {
+		err = AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add device. Error: %s", err)
+		}
+	}
+	if !handler.IsView("activeDevices") || handler.IsView("device") {
+		t.Fatalf("only [activeDevices] should be a view")
+	}
+	idList, err := handler.ListView("activeDevices")
+	if err != nil {
+		t.Fatalf("failed to list view. Error: %s", err)
+	}
+	if !reflect.DeepEqual(idList, []interface{}{"d01", "d04"}) {
+		t.Fatalf("view list expect [d01 d04], got %v", idList)
+	}
+	data, err := handler.GetView("activeDevices", "d01")
+	if err != nil {
+		t.Fatalf("failed to get record through view. Error: %s", err)
+	}
+	if data["__type"] != "activeDevices" {
+		t.Fatalf("record of view expect type [activeDevices], got [%v]", data["__type"])
+	}
+	expected := map[string]interface{}{"name": "d01", "status": "active"}
+	if !reflect.DeepEqual(data["data"], expected) {
+		t.Fatalf("record of view expect projected data %v, got %v", expected, data["data"])
+	}
+	for _, dataId := range []string{"d02", "d03", "notExists"} {
+		_, err = handler.GetView("activeDevices", dataId)
+		if err == nil || err.Status != http.StatusNotFound {
+			t.Fatalf("record [%s] outside of view expect 404, got [%v]", dataId, err)
+		}
+	}
+	err = AddData(handler, `{
+		"__id": "activeDevices",
+		"__type": "schema",
+		"__ver": "0.0.1",
+		"data": {
+			"name": "activeDevices",
+			"version": "0.0.1",
+			"properties": {
+				"name": {
+					"type": "string"
+				}
+			}
+		}
+	}`)
+	if err == nil || err.Status != http.StatusConflict {
+		t.Fatalf("schema with name of view expect 409, got [%v]", err)
+	}
+}
+
+func TestDataViewInvalid(t *testing.T) {
+	for name, view := range map[string]Config.ViewConfig{
+		"schema":   {Type: "device"},
+		"noBase":   {},
+		"badRule":  {Type: "device", Filter: `status ==`},
+		"selfBase": {Type: "selfBase"},
+	} {
+		_, ex := MockHandlerConfig(func(config *Config.Confuguration) {
+			config.Views = map[string]Config.ViewConfig{name: view}
+		})
+		if ex == nil {
+			t.Fatalf("invalid view [%s] should fail handler creation", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"DataService/DataHandler"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func prepareWatchHandler(t *testing.T, buffer int) *DataHandler.Handler {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf(ex.Error())
+	}
+	handler.Watches = DataHandler.NewWatchHub(buffer)
+	handler.AddJournal = func(dataType string, dataId string, before map[string]interface{}, after map[string]interface{}) *Http.HttpError {
+		handler.Notify(dataType, dataId, before, after)
+		return nil
+	}
+	dataList := []string{
+		`{
+			"__id": "watchTest",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "watchTest",
+				"version": "0.0.1",
+				"properties": {
+					"status": {
+						"type": "string"
+					},
+					"note": {
+						"type": "string"
+					}
+				}
+			}
+		}`,
+		`{"__id": "w01", "__type": "watchTest", "__ver": "0.0.1", "data": {"status": "up", "note": "a"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	return handler
+}
+
+func setWatchData(t *testing.T, handler *DataHandler.Handler, status string, note string) {
+	record := Record.NewRecord("watchTest", "0.0.1", "w01", map[string]interface{}{
+		"status": status,
+		"note":   note,
+	})
+	err := handler.Set("watchTest", "w01", record)
+	if err != nil {
+		t.Fatalf("failed to set record. Error: %s", err)
+	}
+}
+
+func nextEvent(watch *DataHandler.Watch) (*DataHandler.WatchEvent, bool) {
+	select {
+	case event, ok := <-watch.Events:
+		return event, ok
+	case <-time.After(100 * time.Millisecond):
+		return nil, true
+	}
+}
+
+func TestWatchRecord(t *testing.T) {
+	handler := prepareWatchHandler(t, 0)
+	recordWatch := handler.Watches.Watch("watchTest", "w01", "")
+	statusWatch := handler.Watches.Watch("watchTest", "w01", "status")
+	otherWatch := handler.Watches.Watch("watchTest", "w02", "")
+	setWatchData(t, handler, "up", "b")
+	event, _ := nextEvent(recordWatch)
+	if event == nil || event.After.(map[string]interface{})["data"].(map[string]interface{})["note"] != "b" {
+		t.Fatalf("expect record watch event on change of note, got %v", event)
+	}
+	event, _ = nextEvent(statusWatch)
+	if event != nil {
+		t.Fatalf("status watch should not get event on change of note, got %v", event)
+	}
+	setWatchData(t, handler, "down", "b")
+	event, _ = nextEvent(statusWatch)
+	if event == nil || event.Before != "up" || event.After != "down" {
+		t.Fatalf("expect status watch event [up]->[down], got %v", event)
+	}
+	event, _ = nextEvent(otherWatch)
+	if event != nil {
+		t.Fatalf("watch of other record should not get event, got %v", event)
+	}
+	handler.Watches.Unwatch(statusWatch)
+	if _, ok := <-statusWatch.Events; ok {
+		t.Fatalf("events of removed watch should be closed")
+	}
+	err := handler.Delete("watchTest", "w01")
+	if err != nil {
+		t.Fatalf("failed to delete record. Error: %s", err)
+	}
+	// skip event of status change
+	nextEvent(recordWatch)
+	event, _ = nextEvent(recordWatch)
+	if event == nil || event.After != nil {
+		t.Fatalf("expect watch event with nil after on delete, got %v", event)
+	}
+}
+
+func TestWatchFallBehind(t *testing.T) {
+	handler := prepareWatchHandler(t, 1)
+	watch := handler.Watches.Watch("watchTest", "w01", "note")
+	setWatchData(t, handler, "up", "b")
+	setWatchData(t, handler, "up", "c")
+	event, ok := nextEvent(watch)
+	if !ok || event == nil || event.After != "b" {
+		t.Fatalf("expect buffered event of note [b], got %v", event)
+	}
+	_, ok = nextEvent(watch)
+	if ok {
+		t.Fatalf("watch that fell behind should be closed")
+	}
+}
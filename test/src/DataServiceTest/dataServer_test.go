@@ -0,0 +1,292 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package DataServiceTest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"DataService/Config"
+	"DataService/DataHandler"
+	"DataService/DataServer"
+)
+
+// data server routes on top of mock handler, config changed by setConfig before handler is created
+func MockServer(t *testing.T, setConfig func(config *Config.Confuguration)) (*httptest.Server, *DataServer.Server, *DataHandler.Handler) {
+	handler, err := MockHandlerConfig(setConfig)
+	if err != nil {
+		t.Fatalf("failed to create mock handler. Error: %s", err)
+	}
+	srv := DataServer.NewWithHandler(handler.Config, handler, nil)
+	return httptest.NewServer(srv.Routes()), srv, handler
+}
+
+func TestServerProbes(t *testing.T) {
+	ts, _, _ := MockServer(t, nil)
+	defer ts.Close()
+	for _, path := range []string{"/health", "/ready", "/_health", "/_ready"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to get [%s]. Error: %s", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expect [%s] served with [%d], got [%d]", path, http.StatusOK, resp.StatusCode)
+		}
+	}
+}
+
+func TestServerMetrics(t *testing.T) {
+	ts, _, _ := MockServer(t, func(config *Config.Confuguration) {
+		config.Http.Metrics.Enabled = true
+	})
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/schema")
+	if err != nil {
+		t.Fatalf("failed to list schema. Error: %s", err)
+	}
+	resp.Body.Close()
+	for _, path := range []string{"/metrics", "/_metrics"} {
+		resp, err = http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to get [%s]. Error: %s", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expect [%s] served with [%d], got [%d]", path, http.StatusOK, resp.StatusCode)
+		}
+		if !strings.Contains(string(body), `unitao_data_requests_total{method="GET",dataType="schema"} 1`) {
+			t.Fatalf("expect request of schema counted in [%s], got [%s]", path, body)
+		}
+	}
+}
+
+func TestServerBatch(t *testing.T) {
+	ts, _, handler := MockServer(t, nil)
+	defer ts.Close()
+	err := AddData(handler, historySchema("0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	for idx, path := range []string{"/batch", "/_batch"} {
+		body := fmt.Sprintf(`[
+			{"__id": "doc%d1", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}},
+			{"__id": "doc%d2", "__type": "doc", "__ver": "0.0.1", "data": {"name": 2}}
+		]`, idx, idx)
+		resp, ex := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if ex != nil {
+			t.Fatalf("failed to post [%s]. Error: %s", path, ex)
+		}
+		results := []map[string]interface{}{}
+		ex = json.NewDecoder(resp.Body).Decode(&results)
+		resp.Body.Close()
+		if ex != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("expect [%s] served with [%d], got [%d], Error: %v", path, http.StatusOK, resp.StatusCode, ex)
+		}
+		if len(results) != 2 || results[0]["status"] != float64(http.StatusCreated) || results[1]["status"] != float64(http.StatusBadRequest) {
+			t.Fatalf("expect result of each item from [%s], got %v", path, results)
+		}
+	}
+	resp, ex := http.Post(ts.URL+"/batch", "application/json", strings.NewReader(`{"__id": "doc09"}`))
+	if ex != nil {
+		t.Fatalf("failed to post batch. Error: %s", ex)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect batch of object to fail with [%d], got [%d]", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestServerBatchGet(t *testing.T) {
+	ts, _, handler := MockServer(t, nil)
+	defer ts.Close()
+	dataList := []string{
+		historySchema("0.0.1"),
+		`{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}}`,
+	}
+	for idx, data := range dataList {
+		err := AddData(handler, data)
+		if err != nil {
+			t.Fatalf("failed to add data @[%d]. Error: %s", idx, err)
+		}
+	}
+	for _, path := range []string{"/batch/get", "/_batch/get"} {
+		resp, ex := http.Post(ts.URL+path, "application/json", strings.NewReader(`[{"type": "doc", "id": "doc09"}, {"type": "doc", "id": "doc01"}]`))
+		if ex != nil {
+			t.Fatalf("failed to post [%s]. Error: %s", path, ex)
+		}
+		results := []map[string]interface{}{}
+		ex = json.NewDecoder(resp.Body).Decode(&results)
+		resp.Body.Close()
+		if ex != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("expect [%s] served with [%d], got [%d], Error: %v", path, http.StatusOK, resp.StatusCode, ex)
+		}
+		if len(results) != 2 || results[0] != nil || results[1]["__id"] != "doc01" {
+			t.Fatalf("expect records in order of keys from [%s], got %v", path, results)
+		}
+	}
+}
+
+func TestServerLocation(t *testing.T) {
+	ts, _, handler := MockServer(t, nil)
+	defer ts.Close()
+	err := AddData(handler, historySchema("0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	for _, dataId := range []string{"sfo/r01", "a+b", "50%"} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"__id":   dataId,
+			"__type": "doc",
+			"__ver":  "0.0.1",
+			"data":   map[string]interface{}{"name": dataId},
+		})
+		resp, ex := http.Post(ts.URL, "application/json", strings.NewReader(string(body)))
+		if ex != nil {
+			t.Fatalf("failed to post [%s]. Error: %s", dataId, ex)
+		}
+		resp.Body.Close()
+		location := resp.Header.Get("Location")
+		if resp.StatusCode != http.StatusCreated || location == "" {
+			t.Fatalf("expect [%s] created with Location, got [%d] Location=[%s]", dataId, resp.StatusCode, location)
+		}
+		resp, ex = http.Get(ts.URL + location)
+		if ex != nil {
+			t.Fatalf("failed to get [%s]. Error: %s", location, ex)
+		}
+		record := map[string]interface{}{}
+		ex = json.NewDecoder(resp.Body).Decode(&record)
+		resp.Body.Close()
+		if ex != nil || resp.StatusCode != http.StatusOK || record["__id"] != dataId {
+			t.Fatalf("expect Location [%s] to get record [%s], got [%d] %v, Error: %v", location, dataId, resp.StatusCode, record, ex)
+		}
+		req, _ := http.NewRequest(http.MethodDelete, ts.URL+location, nil)
+		resp, ex = http.DefaultClient.Do(req)
+		if ex != nil {
+			t.Fatalf("failed to delete [%s]. Error: %s", location, ex)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("expect Location [%s] to delete record [%s], got [%d]", location, dataId, resp.StatusCode)
+		}
+	}
+}
+
+func TestServerEscapedPath(t *testing.T) {
+	ts, _, handler := MockServer(t, nil)
+	defer ts.Close()
+	err := AddData(handler, historySchema("0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	for _, dataId := range []string{"sfo/r01", "a+b"} {
+		err = AddData(handler, fmt.Sprintf(`{"__id": "%s", "__type": "doc", "__ver": "0.0.1", "data": {"name": "%s"}}`, dataId, dataId))
+		if err != nil {
+			t.Fatalf("failed to add record [%s]. Error: %s", dataId, err)
+		}
+		for _, path := range []string{
+			"/doc/" + url.PathEscape(dataId) + "/name",
+			"/_path/doc/" + url.PathEscape(dataId) + "/name",
+		} {
+			resp, ex := http.Get(ts.URL + path)
+			if ex != nil {
+				t.Fatalf("failed to get [%s]. Error: %s", path, ex)
+			}
+			var value interface{}
+			ex = json.NewDecoder(resp.Body).Decode(&value)
+			resp.Body.Close()
+			if ex != nil || resp.StatusCode != http.StatusOK || value != dataId {
+				t.Fatalf("expect path [%s] to get name [%s], got [%d] %v, Error: %v", path, dataId, resp.StatusCode, value, ex)
+			}
+		}
+	}
+}
+
+func TestServerStopWatch(t *testing.T) {
+	handler, ex := MockHandler()
+	if ex != nil {
+		t.Fatalf("failed to create handler. Error: %s", ex)
+	}
+	err := AddData(handler, historySchema("0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to add schema. Error: %s", err)
+	}
+	err = AddData(handler, `{"__id": "doc01", "__type": "doc", "__ver": "0.0.1", "data": {"name": "one"}}`)
+	if err != nil {
+		t.Fatalf("failed to add record. Error: %s", err)
+	}
+	srv := DataServer.NewWithHandler(handler.Config, handler, nil)
+	listener, ex := net.Listen("tcp", "127.0.0.1:0")
+	if ex != nil {
+		t.Fatalf("failed to listen. Error: %s", ex)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(listener)
+	}()
+	resp, ex := http.Get(fmt.Sprintf("http://%s/doc/doc01/_watch", listener.Addr()))
+	if ex != nil {
+		t.Fatalf("failed to watch. Error: %s", ex)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expect watch stream open, got [%d]", resp.StatusCode)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	ex = srv.Stop(ctx)
+	if ex != nil || time.Since(start) > 2*time.Second {
+		t.Fatalf("expect Stop with open watch to return at once, took [%s]. Error: %v", time.Since(start), ex)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "event: closed") {
+		t.Fatalf("expect watch stream ended with event closed, got [%s]", body)
+	}
+	if ex = <-served; ex != nil {
+		t.Fatalf("expect clean stop of Serve. Error: %s", ex)
+	}
+	stopped := make(chan interface{})
+	go func() {
+		handler.RunSweep(3600)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("expect Stop to close data layer")
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/salesforce/UniTAO/lib/Schema/Record"
 )
@@ -38,6 +39,7 @@ type MockDatabase struct {
 	logger *log.Logger
 	config DbConfig.DatabaseConfig
 	Data   map[string]interface{}
+	lock   *sync.RWMutex
 }
 
 func (db MockDatabase) Name() string {
@@ -57,6 +59,7 @@ func NewMockDb(config DbConfig.DatabaseConfig, dataStr string, logger *log.Logge
 		logger: logger,
 		config: config,
 		Data:   data,
+		lock:   &sync.RWMutex{},
 	}
 	return &db, nil
 }
@@ -89,6 +92,8 @@ func (db MockDatabase) DeleteTable(name string) error {
 	return nil
 }
 func (db MockDatabase) Get(queryArgs map[string]interface{}) ([]map[string]interface{}, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
 	dataType, ok := queryArgs[Record.DataType].(string)
 	if !ok {
 		return nil, fmt.Errorf("invalid queryArgs. missing=[%s]", Record.DataType)
@@ -120,6 +125,8 @@ func (db MockDatabase) Update(table string, keys map[string]interface{}, data in
 	return nil, nil
 }
 func (db MockDatabase) Replace(table string, keys map[string]interface{}, data interface{}) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
 	dataType, ok := keys[Record.DataType].(string)
 	if !ok {
 		return fmt.Errorf("invalid queryArgs. missing=[%s]", Record.DataType)
@@ -137,7 +144,7 @@ func (db MockDatabase) Replace(table string, keys map[string]interface{}, data i
 		return fmt.Errorf("invalid data format, failed to convert to record")
 	}
 	if dataType != record.Type || dataId != record.Id {
-		db.Delete(table, keys)
+		db.deleteData(table, keys)
 	}
 	typeMap, ok := db.Data[record.Type].(map[string]interface{})
 	if !ok {
@@ -149,6 +156,12 @@ func (db MockDatabase) Replace(table string, keys map[string]interface{}, data i
 }
 
 func (db MockDatabase) Delete(table string, keys map[string]interface{}) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	return db.deleteData(table, keys)
+}
+
+func (db MockDatabase) deleteData(table string, keys map[string]interface{}) error {
 	dataType, ok := keys[Record.DataType].(string)
 	if !ok {
 		return fmt.Errorf("invalid queryArgs. missing=[%s]", Record.DataType)
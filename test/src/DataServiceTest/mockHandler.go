@@ -73,6 +73,11 @@ func GetSchemaOfSchema() (string, error) {
 }
 
 func MockHandler() (*DataHandler.Handler, error) {
+	return MockHandlerConfig(nil)
+}
+
+// mock handler with config changed by setConfig before handler is created
+func MockHandlerConfig(setConfig func(config *Config.Confuguration)) (*DataHandler.Handler, error) {
 	configStr := `
 	{
 		"database": {
@@ -102,6 +107,9 @@ func MockHandler() (*DataHandler.Handler, error) {
 		return nil, fmt.Errorf("faild to load config str. invalid format. Error:%s", err)
 	}
 	log.Print("config loaded")
+	if setConfig != nil {
+		setConfig(&config)
+	}
 	dbStr, err := GetSchemaOfSchema()
 	if err != nil {
 		return nil, err
@@ -150,6 +150,87 @@ func TestConn(t *testing.T) {
 	}
 }
 
+func TestConnCache(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"testSch01": {
+				"__id": "testSch01",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "testSch01",
+					"version": "0.0.1",
+					"properties": {
+						"testAttr01": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		},
+		"testSch01": {
+			"testId01": {
+				"__id": "testId01",
+				"__type": "testSch01",
+				"__ver": "0.0.1",
+				"data": {
+					"testAttr01": "testValue01"
+				}
+			}
+		}
+	}`
+	source := PrepareConn(recordStr)
+	recordReads := 0
+	schemaReads := 0
+	conn := &SchemaPathData.Connection{
+		FuncRecord: func(dataType string, dataId string) (*Record.Record, *Http.HttpError) {
+			recordReads++
+			return source.FuncRecord(dataType, dataId)
+		},
+		FuncSchema: func(dataType string) (*SchemaDoc.SchemaDoc, *Http.HttpError) {
+			schemaReads++
+			return source.GetSchema(dataType)
+		},
+	}
+	queryPath := "testSch01/testId01/testAttr01"
+	for idx := 0; idx < 2; idx++ {
+		_, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+	}
+	// without SchemaCache schema is read on every walk, record is cached as before
+	if recordReads != 1 || schemaReads != 2 {
+		t.Fatalf("expect record read once and schema twice without schema cache, got [%d] and [%d]", recordReads, schemaReads)
+	}
+	conn.SchemaCache = map[string]*SchemaDoc.SchemaDoc{}
+	for idx := 0; idx < 2; idx++ {
+		QueryPath(conn, queryPath)
+	}
+	if recordReads != 1 || schemaReads != 3 {
+		t.Fatalf("expect schema read once with schema cache, got [%d] schema reads", schemaReads-2)
+	}
+	conn.ClearCache()
+	value, err := QueryPath(conn, queryPath)
+	if err != nil || value != "testValue01" {
+		t.Fatalf("failed to query path=[%s] after clear cache, got [%v]. Error: %v", queryPath, value, err)
+	}
+	if recordReads != 2 || schemaReads != 4 || conn.SchemaCache == nil {
+		t.Fatalf("expect record and schema read again after clear cache, got [%d] and [%d]", recordReads, schemaReads)
+	}
+	// cache shared by another connection saves its reads
+	shared := &SchemaPathData.Connection{
+		FuncRecord:  conn.FuncRecord,
+		FuncSchema:  conn.FuncSchema,
+		RecordCache: conn.RecordCache,
+		SchemaCache: conn.SchemaCache,
+	}
+	QueryPath(shared, queryPath)
+	if recordReads != 2 || schemaReads != 4 {
+		t.Fatalf("expect no read through shared cache, got [%d] and [%d]", recordReads, schemaReads)
+	}
+}
+
 func TestPathNode(t *testing.T) {
 	recordStr := `{
 		"schema": {
@@ -0,0 +1,94 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	SchemaPathData "github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestWalkBatchRefs(t *testing.T) {
+	source := PrepareConn(refChainRecords)
+	leafReads := 0
+	batches := [][]string{}
+	conn := &SchemaPathData.Connection{
+		FuncRecord: func(dataType string, dataId string) (*Record.Record, *Http.HttpError) {
+			if dataType == "leafObj" {
+				leafReads++
+			}
+			return source.FuncRecord(dataType, dataId)
+		},
+		FuncRecords: func(dataType string, dataIdList []string) ([]*Record.Record, *Http.HttpError) {
+			batches = append(batches, append([]string{dataType}, dataIdList...))
+			recordList := []*Record.Record{}
+			for _, dataId := range dataIdList {
+				record, err := source.FuncRecord(dataType, dataId)
+				if err == nil {
+					recordList = append(recordList, record)
+				}
+			}
+			return recordList, nil
+		},
+	}
+	testList := map[string][][]string{
+		"schemaWithRef/refData01/refData/itemArray[*]/refLeaf/name":            {{"leafObj", "leaf01", "leaf02"}},
+		"schemaWithRef/refData01/refData/itemArray[01_01]/refLeafList[*]/name": {{"leafObj", "leaf01", "leaf02"}},
+	}
+	for queryPath, expectedBatches := range testList {
+		conn.ClearCache()
+		leafReads = 0
+		batches = [][]string{}
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s] with batch. Error: %s", queryPath, err)
+		}
+		expected, err := QueryPath(PrepareConn(refChainRecords), queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if !reflect.DeepEqual(value, expected) {
+			t.Fatalf("batch value of path=[%s] not match, got [%v], expect [%v]", queryPath, value, expected)
+		}
+		if !reflect.DeepEqual(batches, expectedBatches) {
+			t.Fatalf("invalid batch of path=[%s], got %v, expect %v", queryPath, batches, expectedBatches)
+		}
+		if leafReads != 0 {
+			t.Fatalf("expect leaf records read by batch only on path=[%s], got [%d] single reads", queryPath, leafReads)
+		}
+	}
+	// without batch function each record is read on its own
+	records, err := source.GetRecords("leafObj", []string{"leaf01", "leaf02", "noLeaf"})
+	if err != nil {
+		t.Fatalf("failed to get records one by one. Error: %s", err)
+	}
+	if len(records) != 2 || records["leaf01"].Id != "leaf01" || records["leaf02"].Id != "leaf02" {
+		t.Fatalf("invalid records read one by one, got %v", records)
+	}
+}
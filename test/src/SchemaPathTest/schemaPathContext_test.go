@@ -0,0 +1,99 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	SchemaPathData "github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestQueryContextCancel(t *testing.T) {
+	source := PrepareConn(refChainRecords)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	readAfterCancel := 0
+	conn := &SchemaPathData.Connection{
+		FuncRecord: func(dataType string, dataId string) (*Record.Record, *Http.HttpError) {
+			if ctx.Err() != nil {
+				readAfterCancel++
+			}
+			record, err := source.FuncRecord(dataType, dataId)
+			if dataType == "schemaWithRef" {
+				// cancel once walk reached first record, before it follows any ref
+				cancel()
+			}
+			return record, err
+		},
+	}
+	queryPath := "refData01/refData/itemArray[*]/refLeaf/name"
+	query, err := SchemaPath.CreateQueryContext(ctx, conn, "schemaWithRef", queryPath)
+	if err == nil {
+		_, err = query.WalkValue()
+	}
+	if err == nil || err.Status != http.StatusRequestTimeout {
+		t.Fatalf("expect walk on path=[%s] stopped with [%d] after cancel, got [%v]", queryPath, http.StatusRequestTimeout, err)
+	}
+	if readAfterCancel > 0 {
+		t.Fatalf("expect no record read after cancel, got [%d]", readAfterCancel)
+	}
+	value, err := QueryPath(conn, "schemaWithRef/"+queryPath)
+	if err != nil || len(value.([]interface{})) != 2 {
+		t.Fatalf("expect query without context unaffected by cancelled query, got [%v]. Error: %v", value, err)
+	}
+}
+
+func TestStreamContextCancel(t *testing.T) {
+	conn := PrepareConn(refChainRecords)
+	listCalls := 0
+	conn.FuncList = func(dataType string) ([]interface{}, *Http.HttpError) {
+		listCalls++
+		return []interface{}{"refData01"}, nil
+	}
+	compiled, err := SchemaPath.Compile("refData")
+	if err != nil {
+		t.Fatalf("failed to compile path. Error: %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out := make(chan SchemaPath.PathResult)
+	go compiled.StreamContext(ctx, conn, "schemaWithRef", out)
+	results := []SchemaPath.PathResult{}
+	for result := range out {
+		results = append(results, result)
+	}
+	if len(results) != 1 || results[0].Err == nil || results[0].Err.Status != http.StatusRequestTimeout {
+		t.Fatalf("expect stream of cancelled context end with single [%d] error, got [%v]", http.StatusRequestTimeout, results)
+	}
+	if listCalls > 0 {
+		t.Fatalf("expect no id list read with cancelled context, got [%d]", listCalls)
+	}
+}
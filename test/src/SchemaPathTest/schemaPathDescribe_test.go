@@ -0,0 +1,152 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+)
+
+func TestDescribePath(t *testing.T) {
+	// no record of schemaWitArray, describe only reads schema
+	recordStr := `{
+		"schema": {
+			"schemaWitArray": {
+				"__id": "schemaWitArray",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWitArray",
+					"version": "0.0.1",
+					"description": "schema with array of object",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						},
+						"sizes": {
+							"type": "array",
+							"items": {
+								"type": "integer"
+							}
+						}
+					},
+					"definitions": {
+						"itemObj": {
+							"name": "itemObj",
+							"key": "{key}",
+							"properties": {
+								"key": {
+									"type": "string"
+								},
+								"someNumber": {
+									"type": "number",
+									"required": false
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string]string{
+		"noRecord/name":                        JsonKey.String,
+		"noRecord/attrArray[01]/someNumber":    JsonKey.Number,
+		"noRecord/attrArray[01]/key":           JsonKey.String,
+		"noRecord/attrArray[-1]":               JsonKey.Object,
+		"noRecord/attrArray?len":               JsonKey.Integer,
+		"noRecord/name?eq=abc":                 JsonKey.Boolean,
+		"noRecord/attrArray/someNumber?sum":    JsonKey.Number,
+		"noRecord/attrArray[*]/key?sum":        "",
+		"noRecord/attrArray[*]/someNumber?avg": JsonKey.Number,
+		"noRecord/sizes?max":                   JsonKey.Number,
+		"noRecord?schema":                      JsonKey.Object,
+	}
+	for queryPath, expected := range testList {
+		result, err := SchemaPath.DescribePath(conn, "schemaWitArray", queryPath)
+		if expected == "" {
+			if err == nil || err.Status != http.StatusBadRequest {
+				t.Fatalf("expect 400 on describe path=[%s], got [%v]", queryPath, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("failed to describe path=[%s]. Error: %s", queryPath, err)
+		}
+		if result[SchemaPath.DescribeType] != expected {
+			t.Fatalf("invalid type of path=[%s], got [%v], expect [%s]", queryPath, result[SchemaPath.DescribeType], expected)
+		}
+	}
+	// array path returns element schema
+	for queryPath, itemType := range map[string]string{
+		"noRecord/attrArray":          JsonKey.Object,
+		"noRecord/attrArray[*]/key":   JsonKey.String,
+		"noRecord/attrArray[1:3]/key": JsonKey.String,
+		"noRecord/attrArray[*]?len":   JsonKey.Integer,
+		"noRecord/sizes":              JsonKey.Integer,
+	} {
+		result, err := SchemaPath.DescribePath(conn, "schemaWitArray", queryPath)
+		if err != nil {
+			t.Fatalf("failed to describe path=[%s]. Error: %s", queryPath, err)
+		}
+		if result[SchemaPath.DescribeType] != JsonKey.Array {
+			t.Fatalf("path=[%s] should be %s, got [%v]", queryPath, JsonKey.Array, result[SchemaPath.DescribeType])
+		}
+		items, ok := result[SchemaPath.DescribeItems].(map[string]interface{})
+		if !ok || items[SchemaPath.DescribeType] != itemType {
+			t.Fatalf("invalid items of path=[%s], got [%v], expect [%s]", queryPath, result[SchemaPath.DescribeItems], itemType)
+		}
+	}
+	result, err := SchemaPath.DescribePath(conn, "schemaWitArray", "noRecord/attrArray[01]/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema, ok := result[SchemaPath.DescribeSchema].(map[string]interface{}); !ok || schema[JsonKey.Type] != JsonKey.String {
+		t.Fatalf("invalid schema of attr, got [%v]", result)
+	}
+	for _, queryPath := range []string{
+		"noRecord/missing",
+		"noRecord/name/sub",
+		"noRecord/attrArray/key",
+		"noRecord/attrArray[01]/missing",
+	} {
+		_, err := SchemaPath.DescribePath(conn, "schemaWitArray", queryPath)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("expect 400 on describe invalid path=[%s], got [%v]", queryPath, err)
+		}
+	}
+}
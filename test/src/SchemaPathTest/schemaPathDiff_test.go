@@ -0,0 +1,204 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+)
+
+func TestDiffRecords(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"host": {
+				"__id": "host",
+				"__type": "schema",
+				"__ver": "0.0.2",
+				"data": {
+					"name": "host",
+					"version": "0.0.2",
+					"description": "host with rack ref",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"cpu": {
+							"type": "integer"
+						},
+						"rack": {
+							"type": "string",
+							"contentMediaType": "inventory/rack"
+						},
+						"ports": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/port"
+							}
+						}
+					},
+					"definitions": {
+						"port": {
+							"name": "port",
+							"key": "{name}",
+							"properties": {
+								"name": {
+									"type": "string"
+								},
+								"speed": {
+									"type": "integer"
+								}
+							}
+						}
+					}
+				}
+			},
+			"host__0.0.1": {
+				"__id": "host__0.0.1",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "host",
+					"version": "0.0.1",
+					"description": "host with cpu as string",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"cpu": {
+							"type": "string"
+						},
+						"rack": {
+							"type": "string",
+							"contentMediaType": "inventory/rack"
+						},
+						"ports": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/port"
+							}
+						}
+					},
+					"definitions": {
+						"port": {
+							"name": "port",
+							"key": "{name}",
+							"properties": {
+								"name": {
+									"type": "string"
+								},
+								"speed": {
+									"type": "integer"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"host": {
+			"host01": {
+				"__id": "host01",
+				"__type": "host",
+				"__ver": "0.0.2",
+				"data": {
+					"name": "host01",
+					"cpu": 8,
+					"rack": "rack01",
+					"ports": [{"name": "eth0", "speed": 10}, {"name": "eth1", "speed": 10}]
+				}
+			},
+			"host02": {
+				"__id": "host02",
+				"__type": "host",
+				"__ver": "0.0.2",
+				"data": {
+					"name": "host02",
+					"cpu": 16,
+					"rack": "rack02",
+					"ports": [{"name": "eth0", "speed": 25}]
+				}
+			},
+			"host03": {
+				"__id": "host03",
+				"__type": "host",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "host01",
+					"cpu": "8",
+					"rack": "rack01",
+					"ports": [{"name": "eth0", "speed": 10}, {"name": "eth1", "speed": 10}]
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	diff, err := SchemaPath.DiffRecords(conn, "host", "host01", "host02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SchemaPath.DiffEntry{
+		{Path: "/cpu", Kind: SchemaPath.DiffValue},
+		{Path: "/name", Kind: SchemaPath.DiffValue},
+		{Path: "/ports[eth0]/speed", Kind: SchemaPath.DiffValue},
+		{Path: "/ports[eth1]", Kind: SchemaPath.DiffRemoved},
+		{Path: "/rack", Kind: SchemaPath.DiffRelation},
+	}
+	if len(diff.Entries) != len(expected) {
+		t.Fatalf("expect [%d] diff entries, got %v", len(expected), diff.Entries)
+	}
+	for idx, entry := range expected {
+		if diff.Entries[idx].Path != entry.Path || diff.Entries[idx].Kind != entry.Kind {
+			t.Fatalf("invalid diff entry @[%d], expect [%s:%s], got [%s:%s]", idx, entry.Path, entry.Kind, diff.Entries[idx].Path, diff.Entries[idx].Kind)
+		}
+	}
+	if diff.Entries[0].Before != 8.0 || diff.Entries[0].After != 16.0 {
+		t.Fatalf("invalid value change of [cpu], got [%v]->[%v]", diff.Entries[0].Before, diff.Entries[0].After)
+	}
+	if diff.Entries[4].Before != "rack01" || diff.Entries[4].After != "rack02" {
+		t.Fatalf("invalid relation change of [rack], got [%v]->[%v]", diff.Entries[4].Before, diff.Entries[4].After)
+	}
+	diff, err = SchemaPath.DiffRecords(conn, "host", "host03", "host01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Entries) != 1 || diff.Entries[0].Path != "/cpu" || diff.Entries[0].Kind != SchemaPath.DiffType {
+		t.Fatalf("expect only type change of [cpu] across schema versions, got %v", diff.Entries)
+	}
+	diff, err = SchemaPath.DiffRecords(conn, "host", "host01", "host01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Entries) != 0 {
+		t.Fatalf("same record should have no diff, got %v", diff.Entries)
+	}
+	_, err = SchemaPath.DiffRecords(conn, "host", "host01", "host09")
+	if err == nil {
+		t.Fatalf("diff with missing record should fail")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+)
+
+func TestPathError(t *testing.T) {
+	conn := PrepareConn(refChainRecords)
+	testList := []struct {
+		queryPath string
+		segment   string
+		index     int
+		status    int
+	}{
+		{"schemaWithRef/refData09/refData", "refData09", 0, http.StatusNotFound},
+		{"schemaWithRef/refData01/refData/itemArray[01_09]/refLeaf", "itemArray[01_09]", 2, http.StatusNotFound},
+		{"schemaWithRef/refData01/refData/itemArray[]/refLeaf", "itemArray[]", 2, http.StatusBadRequest},
+		{"schemaWithRef/refData01/refData/itemArray[01_01]/refLeaf/noSuchAttr", "noSuchAttr", 4, http.StatusNotFound},
+	}
+	for _, test := range testList {
+		_, err := QueryPath(conn, test.queryPath)
+		if err == nil {
+			t.Fatalf("expect error on path=[%s]", test.queryPath)
+		}
+		var pathErr *SchemaPath.PathError
+		if !errors.As(err, &pathErr) {
+			t.Fatalf("expect PathError in error of path=[%s], got [%v]", test.queryPath, err)
+		}
+		if pathErr.Segment != test.segment || pathErr.Index != test.index || pathErr.Cause.Status != test.status || err.Status != test.status {
+			t.Fatalf("expect segment [%d]=[%s] failed with [%d] on path=[%s], got [%d]=[%s] with [%d]", test.index, test.segment, test.status, test.queryPath, pathErr.Index, pathErr.Segment, pathErr.Cause.Status)
+		}
+		if pathErr.Path != test.queryPath[len("schemaWithRef/"):] {
+			t.Fatalf("expect full path in PathError, got [%s]", pathErr.Path)
+		}
+	}
+}
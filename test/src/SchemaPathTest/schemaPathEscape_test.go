@@ -0,0 +1,130 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	"github.com/salesforce/UniTAO/lib/Util"
+)
+
+func TestEscapedKeyPath(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"rack": {
+				"__id": "rack",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "rack",
+					"version": "0.0.1",
+					"properties": {
+						"ports": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/port"
+							}
+						},
+						"labels": {
+							"type": "map",
+							"items": {
+								"type": "string"
+							}
+						}
+					},
+					"definitions": {
+						"port": {
+							"key": "{name}",
+							"properties": {
+								"name": {
+									"type": "string"
+								},
+								"speed": {
+									"type": "integer"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"rack": {
+			"sfo/r01": {
+				"__id": "sfo/r01",
+				"__type": "rack",
+				"__ver": "0.0.1",
+				"data": {
+					"ports": [
+						{
+							"name": "eth/0",
+							"speed": 10
+						},
+						{
+							"name": "x?[1]",
+							"speed": 25
+						}
+					],
+					"labels": {
+						"a+b": "plus",
+						"50% off": "sale"
+					}
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	rackId := Util.EscapeKey("sfo/r01")
+	testList := map[string]interface{}{
+		"rack/" + rackId + "/ports[eth%2F0]/speed":                                float64(10),
+		"rack/" + rackId + "/ports[" + Util.EscapeKey("x?[1]") + "]/speed":        float64(25),
+		"rack/" + rackId + "/ports[" + Util.EscapeKey("x?[1]") + "]/speed?schema": "integer",
+		"rack/" + rackId + "/labels/a+b":                                          "plus",
+		"rack/" + rackId + "/labels/" + Util.EscapeKey("50% off"):                 "sale",
+		"rack/" + rackId + "/labels[" + Util.EscapeKey("50% off") + "]":           "sale",
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if schema, ok := value.(map[string]interface{}); ok {
+			value = schema["type"]
+		}
+		if value != expected {
+			t.Fatalf("expect [%v] from path=[%s], got [%v]", expected, queryPath, value)
+		}
+	}
+	compiled, err := SchemaPath.Compile("ports[eth%2F0]/speed")
+	if err != nil {
+		t.Fatalf("failed to compile path. Error: %s", err)
+	}
+	value, err := compiled.Walk(conn, "rack", "sfo/r01")
+	if err != nil || value != float64(10) {
+		t.Fatalf("expect walk on record id with / resolve to [10], got [%v]. Error: %v", value, err)
+	}
+}
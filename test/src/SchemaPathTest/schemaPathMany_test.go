@@ -0,0 +1,142 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	SchemaPathData "github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestQueryMany(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schemaWitArray": {
+				"__id": "schemaWitArray",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWitArray",
+					"version": "0.0.1",
+					"description": "schema with array of object",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						}
+					},
+					"definitions": {
+						"itemObj": {
+							"name": "itemObj",
+							"key": "{key}",
+							"properties": {
+								"key": {
+									"type": "string"
+								},
+								"someNumber": {
+									"type": "number"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schemaWitArray": {
+			"testArray01": {
+				"__id": "testArray01",
+				"__type": "schemaWitArray",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "testArray01",
+					"attrArray": [
+						{"key": "01", "someNumber": 1},
+						{"key": "02", "someNumber": 2}
+					]
+				}
+			}
+		}
+	}`
+	source := PrepareConn(recordStr)
+	readCount := map[string]int{}
+	conn := &SchemaPathData.Connection{
+		FuncRecord: func(dataType string, dataId string) (*Record.Record, *Http.HttpError) {
+			readCount[fmt.Sprintf("%s/%s", dataType, dataId)]++
+			return source.FuncRecord(dataType, dataId)
+		},
+	}
+	paths := []string{
+		"schemaWitArray/testArray01/name",
+		"schemaWitArray/testArray01/attrArray[02]/someNumber",
+		"schemaWitArray/testArray01/notExists",
+		"schemaWitArray/testArray01/attrArray?len",
+		"schemaWitArray/notExists/name",
+		"schemaWitArray/testArray01/attrArray/someNumber?sum",
+	}
+	results, errs := SchemaPath.QueryMany(conn, paths)
+	if len(results) != len(paths) || len(errs) != len(paths) {
+		t.Fatalf("result and error should align with paths, got len [%d] and [%d], expect [%d]", len(results), len(errs), len(paths))
+	}
+	expected := []interface{}{"testArray01", 2.0, nil, 2, nil, 3.0}
+	for idx, path := range paths {
+		if expected[idx] == nil {
+			if errs[idx] == nil || results[idx] != nil {
+				t.Fatalf("expect error on path=[%s], got value [%v]", path, results[idx])
+			}
+			continue
+		}
+		if errs[idx] != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", path, errs[idx])
+		}
+		if results[idx] != expected[idx] {
+			t.Fatalf("invalid value from path=[%s], got [%v], expect [%v]", path, results[idx], expected[idx])
+		}
+	}
+	if httpErr, ok := errs[4].(*Http.HttpError); !ok || httpErr.Status != http.StatusNotFound {
+		t.Fatalf("expect 404 on missing record, got [%v]", errs[4])
+	}
+	for key, count := range readCount {
+		if count != 1 {
+			t.Fatalf("[%s] read [%d] times in one batch, expect once", key, count)
+		}
+	}
+	// next batch does not reuse cache of the previous one
+	SchemaPath.QueryMany(conn, paths[:1])
+	if readCount["schemaWitArray/testArray01"] != 2 {
+		t.Fatalf("new batch should read record again, read [%d] times", readCount["schemaWitArray/testArray01"])
+	}
+}
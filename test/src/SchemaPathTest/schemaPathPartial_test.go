@@ -0,0 +1,76 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+)
+
+func TestBuildPartial(t *testing.T) {
+	testList := []struct {
+		path     string
+		value    interface{}
+		expected string
+	}{
+		{"name", "abc", `{"name": "abc"}`},
+		{"data/value/value1", "x", `{"data": {"value": {"value1": "x"}}}`},
+		{"data/value", map[string]interface{}{"value1": "x"}, `{"data": {"value": {"value1": "x"}}}`},
+		{"/data/size/", 3.0, `{"data": {"size": 3}}`},
+		{"items[name=a]/info/size", 1.0, `{"items": [{"name": "a", "info": {"size": 1}}]}`},
+		{"items[name=a]", map[string]interface{}{"size": 1.0}, `{"items": [{"name": "a", "size": 1}]}`},
+		{"data/items[name=a]/sub[id=01]/value", nil, `{"data": {"items": [{"name": "a", "sub": [{"id": "01", "value": null}]}]}}`},
+	}
+	for _, test := range testList {
+		var expected interface{}
+		json.Unmarshal([]byte(test.expected), &expected)
+		partial, err := SchemaPath.BuildPartial(test.path, test.value)
+		if err != nil {
+			t.Fatalf("failed to build partial of path=[%s]. Error: %s", test.path, err)
+		}
+		if !reflect.DeepEqual(map[string]interface{}(partial), expected) {
+			t.Fatalf("invalid partial of path=[%s], got [%v], expect %s", test.path, partial, test.expected)
+		}
+	}
+	for path, value := range map[string]interface{}{
+		"":                   "x",
+		"items[01]/name":     "x",
+		"items[*]/name":      "x",
+		"items[-1]":          "x",
+		"items[name=a]":      "x",
+		"data/items[name=a]": map[string]interface{}{"name": "b"},
+		"data/value?len":     "x",
+		"items[=a]/name":     "x",
+	} {
+		_, err := SchemaPath.BuildPartial(path, value)
+		if err == nil {
+			t.Fatalf("expect error on build partial of path=[%s] with value [%v]", path, value)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+)
+
+func TestParsePointer(t *testing.T) {
+	tokens, err := SchemaPath.ParsePointer("/a~1b/c~0d/0")
+	if err != nil {
+		t.Fatalf("failed to parse pointer. Error: %s", err)
+	}
+	expected := []string{"a/b", "c~d", "0"}
+	if len(tokens) != len(expected) {
+		t.Fatalf("pointer tokens %v, expect %v", tokens, expected)
+	}
+	for idx := range expected {
+		if tokens[idx] != expected[idx] {
+			t.Fatalf("pointer token @[%d]=[%s], expect [%s]", idx, tokens[idx], expected[idx])
+		}
+	}
+	_, err = SchemaPath.ParsePointer("itemArray/0")
+	if err == nil {
+		t.Fatalf("pointer not start with / should fail")
+	}
+}
+
+func TestQueryPointer(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"pointerTest": {
+				"__id": "pointerTest",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "pointerTest",
+					"version": "0.0.1",
+					"properties": {
+						"itemArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/item"
+							}
+						},
+						"strArray": {
+							"type": "array",
+							"items": {
+								"type": "string"
+							}
+						},
+						"mapStr": {
+							"type": "map",
+							"items": {
+								"type": "string"
+							}
+						}
+					},
+					"definitions": {
+						"item": {
+							"name": "item",
+							"key": "{name}",
+							"properties": {
+								"name": {
+									"type": "string"
+								},
+								"refIdx": {
+									"type": "string"
+								},
+								"info": {
+									"type": "object",
+									"$ref": "#/definitions/info"
+								}
+							}
+						},
+						"info": {
+							"name": "info",
+							"properties": {
+								"size": {
+									"type": "integer"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"pointerTest": {
+			"pointer01": {
+				"__id": "pointer01",
+				"__type": "pointerTest",
+				"__ver": "0.0.1",
+				"data": {
+					"itemArray": [
+						{
+							"name": "item01",
+							"refIdx": "ref01",
+							"info": {
+								"size": 1
+							}
+						},
+						{
+							"name": "item02",
+							"refIdx": "ref02",
+							"info": {
+								"size": 2
+							}
+						}
+					],
+					"strArray": ["str01", "str02"],
+					"mapStr": {
+						"a~b": "tilde"
+					}
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	for pointer, expected := range map[string]interface{}{
+		"/itemArray/1/refIdx":    "ref02",
+		"/itemArray/0/info/size": float64(1),
+		"/strArray/1":            "str02",
+		"/mapStr/a~0b":           "tilde",
+	} {
+		query, err := SchemaPath.NewFromPointer(conn, "pointerTest", "pointer01", pointer)
+		if err != nil {
+			t.Fatalf("failed to create query from pointer [%s]. Error: %s", pointer, err)
+		}
+		value, err := query.WalkValue()
+		if err != nil {
+			t.Fatalf("failed to walk pointer [%s]. Error: %s", pointer, err)
+		}
+		if value != expected {
+			t.Fatalf("pointer [%s] got [%v], expect [%v]", pointer, value, expected)
+		}
+	}
+	path, err := SchemaPath.PointerToPath(conn, "pointerTest", "pointer01", "/itemArray/1/refIdx")
+	if err != nil {
+		t.Fatalf("failed to convert pointer. Error: %s", err)
+	}
+	if path != "itemArray[item02]/refIdx" {
+		t.Fatalf("pointer converted to [%s], expect [itemArray[item02]/refIdx]", path)
+	}
+	for _, pointer := range []string{"/itemArray/5/refIdx", "/itemArray/item01/refIdx", "/notExists"} {
+		_, err = SchemaPath.NewFromPointer(conn, "pointerTest", "pointer01", pointer)
+		if err == nil {
+			t.Fatalf("invalid pointer [%s] should fail", pointer)
+		}
+	}
+}
+
+func TestPathToPointer(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schemaWitArray": {
+				"__id": "schemaWitArray",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWitArray",
+					"version": "0.0.1",
+					"properties": {
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						},
+						"attrArraySimple": {
+							"type": "array",
+							"items": {
+								"type": "string"
+							}
+						},
+						"attrMap": {
+							"type": "map",
+							"items": {
+								"type": "string"
+							}
+						},
+						"attrRef": {
+							"type": "string",
+							"contentMediaType": "inventory/refObj"
+						}
+					},
+					"definitions": {
+						"itemObj": {
+							"name": "itemObj",
+							"key": "{key1}_{key2}",
+							"properties": {
+								"key1": {
+									"type": "string"
+								},
+								"key2": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			},
+			"refObj": {
+				"__id": "refObj",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "refObj",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		},
+		"schemaWitArray": {
+			"testArray01": {
+				"__id": "testArray01",
+				"__type": "schemaWitArray",
+				"__ver": "0.0.1",
+				"data": {
+					"attrArray": [
+						{
+							"key1": "01",
+							"key2": "01"
+						},
+						{
+							"key1": "01",
+							"key2": "02"
+						}
+					],
+					"attrArraySimple": ["01_01", "01_02"],
+					"attrMap": {
+						"a~b": "tilde"
+					},
+					"attrRef": "ref01"
+				}
+			}
+		},
+		"refObj": {
+			"ref01": {
+				"__id": "ref01",
+				"__type": "refObj",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "ref01"
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	for path, expected := range map[string]string{
+		"attrArray[01_02]/key2":  "/attrArray/1/key2",
+		"attrArray[key2=01]":     "/attrArray/0",
+		"attrArraySimple[01_02]": "/attrArraySimple/1",
+		"attrMap[a~b]":           "/attrMap/a~0b",
+		"attrRef":                "/attrRef",
+		"":                       "",
+	} {
+		pointer, err := SchemaPath.PathToPointer(conn, "schemaWitArray", "testArray01", path)
+		if err != nil {
+			t.Fatalf("failed to convert path [%s]. Error: %s", path, err)
+		}
+		if pointer != expected {
+			t.Fatalf("path [%s] converted to [%s], expect [%s]", path, pointer, expected)
+		}
+	}
+	for _, path := range []string{"attrRef/name", "attrArray[*]/key2"} {
+		_, err := SchemaPath.PathToPointer(conn, "schemaWitArray", "testArray01", path)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("expect 400 on path [%s], got [%v]", path, err)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// schemaWithRef refs a record of schemaWithItems, items of its array ref leafObj records, and leafObj refs back to schemaWithRef
+const refChainRecords = `{
+	"schema": {
+		"schemaWithRef": {
+			"__id": "schemaWithRef",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "schemaWithRef",
+				"version": "0.0.1",
+				"description": "entry record with ref to record of item array",
+				"properties": {
+					"refData": {
+						"type": "string",
+						"contentMediaType": "inventory/schemaWithItems"
+					}
+				}
+			}
+		},
+		"schemaWithItems": {
+			"__id": "schemaWithItems",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "schemaWithItems",
+				"version": "0.0.1",
+				"description": "record with array of item that carries refs",
+				"properties": {
+					"itemArray": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"$ref": "#/definitions/item"
+						}
+					}
+				},
+				"definitions": {
+					"item": {
+						"name": "item",
+						"key": "{key1}_{key2}",
+						"properties": {
+							"key1": {
+								"type": "string"
+							},
+							"key2": {
+								"type": "string"
+							},
+							"refLeaf": {
+								"type": "string",
+								"contentMediaType": "inventory/leafObj"
+							},
+							"refLeafList": {
+								"type": "array",
+								"items": {
+									"type": "string",
+									"contentMediaType": "inventory/leafObj"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"leafObj": {
+			"__id": "leafObj",
+			"__type": "schema",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "leafObj",
+				"version": "0.0.1",
+				"description": "leaf record that refs back to entry record",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"back": {
+						"type": "string",
+						"contentMediaType": "inventory/schemaWithRef"
+					}
+				}
+			}
+		}
+	},
+	"schemaWithRef": {
+		"refData01": {
+			"__id": "refData01",
+			"__type": "schemaWithRef",
+			"__ver": "0.0.1",
+			"data": {
+				"refData": "items01"
+			}
+		}
+	},
+	"schemaWithItems": {
+		"items01": {
+			"__id": "items01",
+			"__type": "schemaWithItems",
+			"__ver": "0.0.1",
+			"data": {
+				"itemArray": [
+					{
+						"key1": "01",
+						"key2": "01",
+						"refLeaf": "leaf01",
+						"refLeafList": ["leaf01", "leaf02"]
+					},
+					{
+						"key1": "01",
+						"key2": "02",
+						"refLeaf": "leaf02",
+						"refLeafList": ["leaf02"]
+					}
+				]
+			}
+		}
+	},
+	"leafObj": {
+		"leaf01": {
+			"__id": "leaf01",
+			"__type": "leafObj",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "leaf 01",
+				"back": "refData01"
+			}
+		},
+		"leaf02": {
+			"__id": "leaf02",
+			"__type": "leafObj",
+			"__ver": "0.0.1",
+			"data": {
+				"name": "leaf 02",
+				"back": "refData01"
+			}
+		}
+	}
+}`
+
+func TestWalkRefArrayRefChain(t *testing.T) {
+	conn := PrepareConn(refChainRecords)
+	testList := map[string]string{
+		"schemaWithRef/refData01/refData/itemArray[*]/refLeaf/name":                                                                            `["leaf 01", "leaf 02"]`,
+		"schemaWithRef/refData01/refData/itemArray[01_02]/refLeafList[*]/name":                                                                 `["leaf 02"]`,
+		"schemaWithRef/refData01/refData/itemArray[*]/refLeafList[*]/name":                                                                     `[["leaf 01", "leaf 02"], ["leaf 02"]]`,
+		"schemaWithRef/refData01/refData/itemArray[*]/refLeafList?ref":                                                                         `[["leaf01", "leaf02"], ["leaf02"]]`,
+		"schemaWithRef/refData01/refData/itemArray?flat":                                                                                       `["01_01", "01_02"]`,
+		"schemaWithRef/refData01/refData/itemArray[01_01]/refLeafList[*]?flat":                                                                 `[{"name": "leaf 01", "back": "refData01"}, {"name": "leaf 02", "back": "refData01"}]`,
+		"schemaWithRef/refData01/refData/itemArray[01_01]/refLeaf/back/refData/itemArray[01_02]/refLeafList[*]/back/refData/itemArray[*]/key2": `[["01", "02"]]`,
+		"schemaWithRef/refData01/refData/itemArray[*]/refLeafList[*]/name?iterator": `{
+			"queryPath": "refData01/refData/itemArray[*]/refLeafList[*]/name",
+			"queryResults": [
+				{"data": "leaf 01", "iterators": ["01_01", "leaf01"]},
+				{"data": "leaf 02", "iterators": ["01_01", "leaf02"]},
+				{"data": "leaf 02", "iterators": ["01_02", "leaf02"]}
+			]
+		}`,
+	}
+	for queryPath, expectedStr := range testList {
+		var expected interface{}
+		ex := json.Unmarshal([]byte(expectedStr), &expected)
+		if ex != nil {
+			t.Fatalf("invalid expected value of path=[%s]. Error: %s", queryPath, ex)
+		}
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		// round trip through JSON so typed result like iterator compares with expected map
+		valueBytes, _ := json.Marshal(value)
+		var result interface{}
+		json.Unmarshal(valueBytes, &result)
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("invalid value of path=[%s], got [%s], expect [%s]", queryPath, string(valueBytes), expectedStr)
+		}
+	}
+}
+
+func TestMaxRefDepth(t *testing.T) {
+	conn := PrepareConn(refChainRecords)
+	conn.MaxRefDepth = 1
+	queryPath := "schemaWithRef/refData01/refData/itemArray[*]/refLeaf"
+	value, err := QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+	}
+	if !reflect.DeepEqual(value, []interface{}{"leaf01", "leaf02"}) {
+		t.Fatalf("ref beyond max depth should stay as its value, got [%v]", value)
+	}
+	queryPath = "schemaWithRef/refData01/refData/itemArray[*]/refLeaf/name"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil || err.Status != http.StatusBadRequest || !strings.Contains(strings.Join(err.Message, " "), "max ref depth [1]") {
+		t.Fatalf("expect error with max ref depth on path=[%s], got [%v]", queryPath, err)
+	}
+	conn.MaxRefDepth = 2
+	value, err = QueryPath(conn, queryPath)
+	if err != nil || !reflect.DeepEqual(value, []interface{}{"leaf 01", "leaf 02"}) {
+		t.Fatalf("expect ref followed within max depth on path=[%s], got [%v]. Error: %v", queryPath, value, err)
+	}
+	queryPath = "schemaWithRef/refData01/refData/itemArray[01_01]/refLeaf/back"
+	value, err = QueryPath(conn, queryPath)
+	if err != nil || value != "refData01" {
+		t.Fatalf("expect third ref not followed on path=[%s], got [%v]. Error: %v", queryPath, value, err)
+	}
+	hops, err := QueryPath(conn, queryPath+"?hops")
+	if err != nil || hops != 2 {
+		t.Fatalf("expect [2] hops within max depth, got [%v]. Error: %v", hops, err)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWalkRefPathCycle(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"chainNode": {
+				"__id": "chainNode",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "chainNode",
+					"version": "0.0.1",
+					"description": "record with ref that can carry path into the target",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"next": {
+							"type": "string",
+							"contentMediaType": "inventory/chainNode"
+						},
+						"links": {
+							"type": "array",
+							"items": {
+								"type": "string",
+								"contentMediaType": "inventory/chainNode"
+							}
+						}
+					}
+				}
+			}
+		},
+		"chainNode": {
+			"n01": {
+				"__id": "n01",
+				"__type": "chainNode",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "n01",
+					"next": "n02/next"
+				}
+			},
+			"n02": {
+				"__id": "n02",
+				"__type": "chainNode",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "n02",
+					"next": "n03/next"
+				}
+			},
+			"n03": {
+				"__id": "n03",
+				"__type": "chainNode",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "n03",
+					"next": "n01/next"
+				}
+			},
+			"m01": {
+				"__id": "m01",
+				"__type": "chainNode",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "m01",
+					"next": "m02/next",
+					"links": ["m02/name", "m02/name"]
+				}
+			},
+			"m02": {
+				"__id": "m02",
+				"__type": "chainNode",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "m02",
+					"next": "m03"
+				}
+			},
+			"m03": {
+				"__id": "m03",
+				"__type": "chainNode",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "m03",
+					"next": "m02"
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string]interface{}{
+		// ref path lands on ref of m02, which is followed into m03
+		"chainNode/m01/next/name": "m03",
+		// same record on two branches is not a cycle
+		"chainNode/m01/links[*]": []interface{}{"m02", "m02"},
+		// walk back into m02 by steps of path is not a cycle
+		"chainNode/m02/next/next/next/name": "m03",
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if !reflect.DeepEqual(value, expected) {
+			t.Fatalf("invalid value of path=[%s], got [%v], expect [%v]", queryPath, value, expected)
+		}
+	}
+	for _, queryPath := range []string{"chainNode/n01/next", "chainNode/n02/name", "chainNode/n01/next/name?flat"} {
+		_, err := QueryPath(conn, queryPath)
+		if queryPath == "chainNode/n02/name" {
+			if err != nil {
+				t.Fatalf("record in cycle should still be read by attr not on the cycle. Error: %s", err)
+			}
+			continue
+		}
+		if err == nil || err.Status != http.StatusLoopDetected {
+			t.Fatalf("expect [%d] on path=[%s], got [%v]", http.StatusLoopDetected, queryPath, err)
+		}
+		chain := "chainNode/n02/next -> chainNode/n03/next -> chainNode/n01/next -> chainNode/n02/next"
+		if !strings.Contains(strings.Join(err.Message, " "), chain) {
+			t.Fatalf("expect ref chain [%s] in error, got %s", chain, err.Message)
+		}
+	}
+}
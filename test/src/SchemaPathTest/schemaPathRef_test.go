@@ -513,3 +513,273 @@ func TestRefAllPath(t *testing.T) {
 		t.Fatalf("got invalid value type=[%s] from Ref on idx=[*], expected type=[%s]", reflect.TypeOf(value).Kind(), reflect.Slice)
 	}
 }
+
+func TestQueryRecord(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"host": {
+				"__id": "host",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "host",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"rack": {
+							"type": "string",
+							"contentMediaType": "inventory/rack"
+						}
+					}
+				}
+			},
+			"rack": {
+				"__id": "rack",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "rack",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"site": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		},
+		"host": {
+			"h01": {
+				"__id": "h01",
+				"__type": "host",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "h01",
+					"rack": "r01"
+				}
+			}
+		},
+		"rack": {
+			"r01": {
+				"__id": "r01",
+				"__type": "rack",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "r01",
+					"site": "s01"
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string]string{
+		"host/h01/name?record":      "h01",
+		"host/h01/rack?record":      "r01",
+		"host/h01?record":           "h01",
+		"host/h01/rack/site?record": "r01",
+		"host/h01/rack/?record":     "r01",
+	}
+	for queryPath, expectedId := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+		}
+		record, ok := value.(map[string]interface{})
+		if !ok {
+			t.Fatalf("[%s] expect record envelope, got [%v]", queryPath, value)
+		}
+		if record["__id"] != expectedId {
+			t.Fatalf("[%s] expect record [%s], got [%v]", queryPath, expectedId, record["__id"])
+		}
+		if _, ok := record["data"].(map[string]interface{}); !ok || record["__type"] == nil || record["__ver"] == nil {
+			t.Fatalf("[%s] missing envelope fields, got [%v]", queryPath, record)
+		}
+	}
+}
+
+func TestQueryHops(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"host": {
+				"__id": "host",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "host",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"rack": {
+							"type": "string",
+							"contentMediaType": "inventory/rack"
+						}
+					}
+				}
+			},
+			"rack": {
+				"__id": "rack",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "rack",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"site": {
+							"type": "string",
+							"contentMediaType": "inventory/site"
+						}
+					}
+				}
+			},
+			"site": {
+				"__id": "site",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "site",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		},
+		"host": {
+			"h01": {
+				"__id": "h01",
+				"__type": "host",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "h01",
+					"rack": "r01"
+				}
+			}
+		},
+		"rack": {
+			"r01": {
+				"__id": "r01",
+				"__type": "rack",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "r01",
+					"site": "s01"
+				}
+			}
+		},
+		"site": {
+			"s01": {
+				"__id": "s01",
+				"__type": "site",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "s01"
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string]int{
+		"host/h01/name?hops":           0,
+		"host/h01/rack/name?hops":      1,
+		"host/h01/rack/site/name?hops": 2,
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+		}
+		if value != expected {
+			t.Fatalf("[%s] expect [%d] hops, got [%v]", queryPath, expected, value)
+		}
+	}
+	value, err := QueryPath(conn, "host/h01/rack/site/name")
+	if err != nil || value != "s01" {
+		t.Fatalf("expect value [s01] through 2 hops, got [%v]. Error: %v", value, err)
+	}
+}
+
+func TestQueryMediaType(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"host": {
+				"__id": "host",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "host",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"rack": {
+							"type": "string",
+							"contentMediaType": "inventory/rack"
+						}
+					}
+				}
+			},
+			"rack": {
+				"__id": "rack",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "rack",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		},
+		"host": {
+			"h01": {
+				"__id": "h01",
+				"__type": "host",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "h01",
+					"rack": "r01"
+				}
+			}
+		},
+		"rack": {
+			"r01": {
+				"__id": "r01",
+				"__type": "rack",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "r01"
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string]string{
+		"host/h01/rack?mediatype": "inventory/rack",
+		"host/h01/name?mediatype": "",
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+		}
+		if value != expected {
+			t.Fatalf("[%s] expect media type [%s], got [%v]", queryPath, expected, value)
+		}
+	}
+}
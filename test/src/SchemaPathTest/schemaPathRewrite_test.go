@@ -0,0 +1,190 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	SchemaPathData "github.com/salesforce/UniTAO/lib/SchemaPath/Data"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+// connection on an in memory record map that support list and put
+func PrepareWritableConn(recordStr string) (*SchemaPathData.Connection, map[string]interface{}, error) {
+	recordMap := map[string]interface{}{}
+	err := json.Unmarshal([]byte(recordStr), &recordMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn := PrepareConn(recordStr)
+	getSchema := conn.FuncRecord
+	conn.FuncRecord = func(dataType string, dataId string) (*Record.Record, *Http.HttpError) {
+		if dataType == JsonKey.Schema {
+			return getSchema(dataType, dataId)
+		}
+		data, ok := recordMap[dataType].(map[string]interface{})[dataId].(map[string]interface{})
+		if !ok {
+			return nil, Http.NewHttpError(fmt.Sprintf("record [%s/%s] does not exists", dataType, dataId), http.StatusNotFound)
+		}
+		record, ex := Record.LoadMap(data)
+		if ex != nil {
+			return nil, Http.WrapError(ex, "failed to load data as Record.", http.StatusInternalServerError)
+		}
+		return record, nil
+	}
+	conn.FuncList = func(dataType string) ([]interface{}, *Http.HttpError) {
+		idList := []interface{}{}
+		for id := range recordMap[dataType].(map[string]interface{}) {
+			idList = append(idList, id)
+		}
+		return idList, nil
+	}
+	conn.FuncPut = func(record *Record.Record) *Http.HttpError {
+		recordMap[record.Type].(map[string]interface{})[record.Id] = record.Map()
+		return nil
+	}
+	return conn, recordMap, nil
+}
+
+func TestRewriteRefs(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"refTarget": {
+				"__id": "refTarget",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "refTarget",
+					"version": "0.0.1",
+					"properties": {
+						"value": {
+							"type": "string"
+						}
+					}
+				}
+			},
+			"refHolder": {
+				"__id": "refHolder",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "refHolder",
+					"version": "0.0.1",
+					"properties": {
+						"ref": {
+							"type": "string",
+							"contentMediaType": "inventory/refTarget"
+						},
+						"refList": {
+							"type": "array",
+							"items": {
+								"type": "string",
+								"contentMediaType": "inventory/refTarget"
+							}
+						}
+					}
+				}
+			}
+		},
+		"refTarget": {
+			"ref01": {
+				"__id": "ref01",
+				"__type": "refTarget",
+				"__ver": "0.0.1",
+				"data": {
+					"value": "01"
+				}
+			}
+		},
+		"refHolder": {
+			"refData01": {
+				"__id": "refData01",
+				"__type": "refHolder",
+				"__ver": "0.0.1",
+				"data": {
+					"ref": "ref01",
+					"refList": ["ref02", "ref01"]
+				}
+			},
+			"refData02": {
+				"__id": "refData02",
+				"__type": "refHolder",
+				"__ver": "0.0.1",
+				"data": {
+					"ref": "ref02",
+					"refList": []
+				}
+			}
+		}
+	}`
+	conn, recordMap, ex := PrepareWritableConn(recordStr)
+	if ex != nil {
+		t.Fatalf("failed to prepare connection. Error: %s", ex)
+	}
+	affected, err := SchemaPath.RewriteRefs(conn, "refTarget/ref01", "refTarget/ref01b", true)
+	if err != nil {
+		t.Fatalf("dry run failed. Error: %s", err)
+	}
+	if len(affected) != 2 {
+		t.Fatalf("dry run expect 2 affected refs, got [%d]", len(affected))
+	}
+	refData01 := recordMap["refHolder"].(map[string]interface{})["refData01"].(map[string]interface{})
+	if refData01[Record.Data].(map[string]interface{})["ref"] != "ref01" {
+		t.Fatalf("dry run should not change stored record")
+	}
+	affected, err = SchemaPath.RewriteRefs(conn, "refTarget/ref01", "refTarget/ref01b", false)
+	if err != nil {
+		t.Fatalf("rewrite refs failed. Error: %s", err)
+	}
+	for _, ref := range affected {
+		if ref.DataType != "refHolder" || ref.DataId != "refData01" {
+			t.Fatalf("wrong record affected [%s/%s]", ref.DataType, ref.DataId)
+		}
+	}
+	refData01 = recordMap["refHolder"].(map[string]interface{})["refData01"].(map[string]interface{})
+	data := refData01[Record.Data].(map[string]interface{})
+	if data["ref"] != "ref01b" {
+		t.Fatalf("ref of refData01 not updated, got [%v]", data["ref"])
+	}
+	refList := data["refList"].([]interface{})
+	if refList[0] != "ref02" || refList[1] != "ref01b" {
+		t.Fatalf("refList of refData01 not updated correctly, got %v", refList)
+	}
+	refData02 := recordMap["refHolder"].(map[string]interface{})["refData02"].(map[string]interface{})
+	if refData02[Record.Data].(map[string]interface{})["ref"] != "ref02" {
+		t.Fatalf("refData02 should not be changed")
+	}
+	_, err = SchemaPath.RewriteRefs(conn, "refTarget/ref01", "otherType/ref01b", false)
+	if err == nil {
+		t.Fatalf("rewrite ref across types should fail")
+	}
+}
@@ -26,6 +26,7 @@ This copyright notice and license applies to all files in this directory or sub-
 package SchemaPathTest
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -433,3 +434,317 @@ func TestWalkMapSchema(t *testing.T) {
 		t.Fatal("got wrong error status, expect StatusNotFound")
 	}
 }
+
+func TestQueryEnum(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schema1": {
+				"__id": "schema1",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schema1",
+					"version": "0.0.1",
+					"description": "schema with enum constrained attribute",
+					"properties": {
+						"status": {
+							"type": "string",
+							"enum": ["active", "retired"]
+						},
+						"tags": {
+							"type": "array",
+							"items": {
+								"type": "string",
+								"enum": ["red", "blue"]
+							}
+						},
+						"name": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		},
+		"schema1": {
+			"data1": {
+				"__id": "data1",
+				"__type": "schema1",
+				"__ver": "0.0.1",
+				"data": {
+					"status": "active",
+					"tags": ["red"],
+					"name": "data1"
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	queryPath := "schema1/data1/status?enum"
+	value, err := QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enum, ok := value.([]interface{})
+	if !ok || len(enum) != 2 || enum[0] != "active" || enum[1] != "retired" {
+		t.Fatalf("invalid enum from path=[%s], got [%v]", queryPath, value)
+	}
+	queryPath = "schema1/data1/tags?enum"
+	value, err = QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enum, ok = value.([]interface{})
+	if !ok || len(enum) != 2 || enum[0] != "red" {
+		t.Fatalf("invalid item enum from path=[%s], got [%v]", queryPath, value)
+	}
+	queryPath = "schema1/data1/name?enum"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil {
+		t.Fatalf("attribute without enum should return error, path=[%s]", queryPath)
+	}
+	if err.Status != http.StatusBadRequest {
+		t.Fatalf("attribute without enum return err.Code=[%d], expect err.Code=[%d]", err.Status, http.StatusBadRequest)
+	}
+}
+
+func TestPolymorphicRef(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schema1": {
+				"__id": "schema1",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schema1",
+					"version": "0.0.1",
+					"description": "schema with polymorphic ref",
+					"properties": {
+						"owner": {
+							"type": "string",
+							"contentMediaType": "inventory/user|team"
+						},
+						"watchers": {
+							"type": "array",
+							"items": {
+								"type": "string",
+								"contentMediaType": "inventory/user|team"
+							}
+						},
+						"name": {
+							"type": "string"
+						}
+					}
+				}
+			},
+			"user": {
+				"__id": "user",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "user",
+					"version": "0.0.1",
+					"description": "user",
+					"properties": {
+						"email": {
+							"type": "string"
+						}
+					}
+				}
+			},
+			"team": {
+				"__id": "team",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "team",
+					"version": "0.0.1",
+					"description": "team",
+					"properties": {
+						"channel": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		},
+		"schema1": {
+			"data1": {
+				"__id": "data1",
+				"__type": "schema1",
+				"__ver": "0.0.1",
+				"data": {
+					"owner": "team/team1",
+					"watchers": ["user/user1", "team/team1"],
+					"name": "data1"
+				}
+			},
+			"data2": {
+				"__id": "data2",
+				"__type": "schema1",
+				"__ver": "0.0.1",
+				"data": {
+					"owner": "schema1/data1",
+					"name": "data2"
+				}
+			}
+		},
+		"user": {
+			"user1": {
+				"__id": "user1",
+				"__type": "user",
+				"__ver": "0.0.1",
+				"data": {
+					"email": "user1@example.com"
+				}
+			}
+		},
+		"team": {
+			"team1": {
+				"__id": "team1",
+				"__type": "team",
+				"__ver": "0.0.1",
+				"data": {
+					"channel": "#team1"
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	queryPath := "schema1/data1/owner/channel"
+	value, err := QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "#team1" {
+		t.Fatalf("invalid value from path=[%s], got [%v], expect [#team1]", queryPath, value)
+	}
+	queryPath = "schema1/data1/watchers[*]?schema=ref"
+	value, err = QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaList, ok := value.([]interface{})
+	if !ok || len(schemaList) != 2 {
+		t.Fatalf("invalid schema list from path=[%s], got [%v]", queryPath, value)
+	}
+	queryPath = "schema1/data1/owner?schema=ref"
+	value, err = QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	union, ok := value.(map[string]interface{})
+	if !ok {
+		t.Fatalf("invalid schema from path=[%s], expect map, got [%v]", queryPath, value)
+	}
+	candidates, ok := union[JsonKey.OneOf].([]interface{})
+	if !ok || len(candidates) != 2 {
+		t.Fatalf("invalid %s from path=[%s], got [%v]", JsonKey.OneOf, queryPath, value)
+	}
+	for idx, name := range []string{"user", "team"} {
+		if candidates[idx].(map[string]interface{})[JsonKey.Name] != name {
+			t.Fatalf("invalid candidate[%d] from path=[%s], expect [%s], got [%v]", idx, queryPath, name, candidates[idx])
+		}
+	}
+	queryPath = "schema1/data1/name?schema=ref"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("non ref attribute should return err.Code=[%d], path=[%s], got [%v]", http.StatusBadRequest, queryPath, err)
+	}
+	queryPath = "schema1/data2/owner/name"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("ref to type not allowed should return err.Code=[%d], path=[%s], got [%v]", http.StatusBadRequest, queryPath, err)
+	}
+}
+
+func TestQueryRequired(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schemaWitArray": {
+				"__id": "schemaWitArray",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWitArray",
+					"version": "0.0.1",
+					"description": "schema of object with array of object in attribute",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"note": {
+							"type": "string",
+							"required": false
+						},
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						}
+					},
+					"definitions": {
+						"itemObj": {
+							"name": "itemObj",
+							"description": "item object of an array",
+							"key": "{key1}_{key2}",
+							"properties": {
+								"key1": {
+									"type": "string"
+								},
+								"key2": {
+									"type": "string"
+								},
+								"comment": {
+									"type": "string",
+									"required": false
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schemaWitArray": {
+			"testArray01": {
+				"__id": "testArray01",
+				"__type": "schemaWitArray",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "test",
+					"attrArray": [
+						{
+							"key1": "01",
+							"key2": "01"
+						},
+						{
+							"key1": "01",
+							"key2": "02"
+						}
+					]
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string]string{
+		"schemaWitArray/testArray01?required":                  `["attrArray","name"]`,
+		"schemaWitArray/testArray01/attrArray[01_02]?required": `["key1","key2"]`,
+		"schemaWitArray/testArray01/attrArray?required":        `["key1","key2"]`,
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+		}
+		valueBytes, _ := json.Marshal(value)
+		if string(valueBytes) != expected {
+			t.Fatalf("invalid required list of [%s], expect %s, got %s", queryPath, expected, valueBytes)
+		}
+	}
+	_, err := QueryPath(conn, "schemaWitArray/testArray01/name?required")
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expect 400 on [?required] of string attr, got [%v]", err)
+	}
+}
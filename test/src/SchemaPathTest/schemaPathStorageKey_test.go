@@ -0,0 +1,92 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+)
+
+func TestStorageKey(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"keyedHost": {
+				"__id": "keyedHost",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "keyedHost",
+					"version": "0.0.1",
+					"key": "{site}_{name}",
+					"properties": {
+						"site": {
+							"type": "string"
+						},
+						"name": {
+							"type": "string"
+						}
+					}
+				}
+			},
+			"plainHost": {
+				"__id": "plainHost",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "plainHost",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						}
+					}
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	key, err := SchemaPath.StorageKey(conn, "keyedHost", map[string]interface{}{"site": "sfo", "name": "h01"})
+	if err != nil {
+		t.Fatalf("failed to build storage key. Error: %s", err)
+	}
+	if key != "keyedHost/sfo_h01" {
+		t.Fatalf("expect key [keyedHost/sfo_h01], got [%s]", key)
+	}
+	_, err = SchemaPath.StorageKey(conn, "keyedHost", map[string]interface{}{"name": "h01"})
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expect [%d] when key attribute missing, got [%v]", http.StatusBadRequest, err)
+	}
+	_, err = SchemaPath.StorageKey(conn, "plainHost", map[string]interface{}{"name": "h01"})
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("expect [%d] on type without key template, got [%v]", http.StatusBadRequest, err)
+	}
+	_, err = SchemaPath.StorageKey(conn, "noSuchType", map[string]interface{}{"name": "h01"})
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("expect [%d] on unknown type, got [%v]", http.StatusNotFound, err)
+	}
+}
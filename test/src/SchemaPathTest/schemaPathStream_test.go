@@ -0,0 +1,123 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaPathTest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/SchemaPath"
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestStreamPath(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"streamTest": {
+				"__id": "streamTest",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "streamTest",
+					"version": "0.0.1",
+					"properties": {
+						"tags": {
+							"type": "array",
+							"items": {
+								"type": "string"
+							}
+						}
+					}
+				}
+			}
+		},
+		"streamTest": {
+			"s03": {
+				"__id": "s03",
+				"__type": "streamTest",
+				"__ver": "0.0.1",
+				"data": {
+					"tags": ["a", "b", "c"]
+				}
+			},
+			"s01": {
+				"__id": "s01",
+				"__type": "streamTest",
+				"__ver": "0.0.1",
+				"data": {
+					"tags": ["a"]
+				}
+			},
+			"s02": {
+				"__id": "s02",
+				"__type": "streamTest",
+				"__ver": "0.0.1",
+				"data": {}
+			}
+		}
+	}`
+	conn, _, ex := PrepareWritableConn(recordStr)
+	if ex != nil {
+		t.Fatalf("failed to prepare connection. Error: %s", ex)
+	}
+	compiled, err := SchemaPath.Compile("tags?len")
+	if err != nil {
+		t.Fatalf("failed to compile path. Error: %s", err)
+	}
+	out := make(chan SchemaPath.PathResult)
+	go compiled.Stream(conn, "streamTest", out)
+	resultList := []SchemaPath.PathResult{}
+	for result := range out {
+		resultList = append(resultList, result)
+	}
+	if len(resultList) != 3 {
+		t.Fatalf("expect 3 streamed results, got [%d]", len(resultList))
+	}
+	for idx, id := range []string{"s01", "s02", "s03"} {
+		if resultList[idx].Id != id {
+			t.Fatalf("result @[%d] id=[%s], expect [%s]", idx, resultList[idx].Id, id)
+		}
+	}
+	if resultList[0].Err != nil || resultList[0].Value != 1 {
+		t.Fatalf("result of [s01] expect 1, got [%v], Error: %v", resultList[0].Value, resultList[0].Err)
+	}
+	if resultList[1].Err == nil {
+		t.Fatalf("result of [s02] expect error on missing attr")
+	}
+	if resultList[1].StreamErr() != nil {
+		t.Fatalf("error of one record should not fail the stream")
+	}
+	if (SchemaPath.PathResult{Err: Http.NewHttpError("failed to list", http.StatusInternalServerError)}).StreamErr() == nil {
+		t.Fatalf("result without id should fail the stream")
+	}
+	if resultList[2].Err != nil || resultList[2].Value != 3 {
+		t.Fatalf("result of [s03] expect 3, got [%v], Error: %v", resultList[2].Value, resultList[2].Err)
+	}
+	_, err = SchemaPath.Compile("tags?notCmd")
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("compile invalid command expect 400, got [%v]", err)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -222,6 +223,338 @@ func TestWalkInArray(t *testing.T) {
 	if value.(string) != "02" {
 		t.Errorf("failed to get the correct value=[%s] from [path]=[%s]", value.(string), queryPath)
 	}
+	queryPath = "schemaWitArray/testArray01/attrArray[key1=01]"
+	value, err = QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.TypeOf(value).Kind() != reflect.Slice || len(value.([]interface{})) != 2 {
+		t.Errorf("filter should select both items from [path]=[%s], got [%v]", queryPath, value)
+	}
+	firstLast := map[string]string{
+		"schemaWitArray/testArray01/attrArray[key1=01]?first": "01",
+		"schemaWitArray/testArray01/attrArray[key1=01]?last":  "02",
+		"schemaWitArray/testArray01/attrArray[key2=02]?first": "02",
+		"schemaWitArray/testArray01/attrArray?last":           "02",
+	}
+	for queryPath, key2 := range firstLast {
+		value, err = QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		item, ok := value.(map[string]interface{})
+		if !ok || item["key2"] != key2 {
+			t.Errorf("expect single item with key2=[%s] from [path]=[%s], got [%v]", key2, queryPath, value)
+		}
+	}
+	queryPath = "schemaWitArray/testArray01/attrArray[key1=09]?first"
+	value, err = QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != nil {
+		t.Errorf("non-matching filter should return nil from [path]=[%s], got [%v]", queryPath, value)
+	}
+	queryPath = "schemaWitArray/testArray01/attrArray[key1=09]?first=strict"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Errorf("non-matching filter in strict mode should return err.Code=[%d] from [path]=[%s], got [%v]", http.StatusNotFound, queryPath, err)
+	}
+	queryPath = "schemaWitArray/testArray01/attrArray[key1=01]?first=any"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Errorf("invalid option should return err.Code=[%d] from [path]=[%s], got [%v]", http.StatusBadRequest, queryPath, err)
+	}
+}
+
+func TestWalkInArrayFilterPrecedence(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schemaWithLabels": {
+				"__id": "schemaWithLabels",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWithLabels",
+					"version": "0.0.1",
+					"properties": {
+						"labels": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/label"
+							}
+						}
+					},
+					"definitions": {
+						"label": {
+							"key": "{name}",
+							"properties": {
+								"name": {
+									"type": "string"
+								},
+								"env": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schemaWithLabels": {
+			"labels01": {
+				"__id": "labels01",
+				"__type": "schemaWithLabels",
+				"__ver": "0.0.1",
+				"data": {
+					"labels": [
+						{
+							"name": "env=prod",
+							"env": "dev"
+						},
+						{
+							"name": "web",
+							"env": "prod"
+						},
+						{
+							"name": "db",
+							"env": "prod"
+						}
+					]
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	// item key takes precedence over filter of same text
+	testList := map[string]interface{}{
+		"schemaWithLabels/labels01/labels[env=prod]/name": "env=prod",
+		"schemaWithLabels/labels01/labels[env=dev]/name":  "env=prod",
+		"schemaWithLabels/labels01/labels[name=web]/env":  "prod",
+		"schemaWithLabels/labels01/labels[env=prod]/env":  "dev",
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if value != expected {
+			t.Fatalf("expect [%v] from [path]=[%s], got [%v]", expected, queryPath, value)
+		}
+	}
+	queryPath := "schemaWithLabels/labels01/labels[name=db]"
+	value, err := QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item, ok := value.(map[string]interface{}); !ok || item["name"] != "db" {
+		t.Fatalf("single match of filter should return the item from [path]=[%s], got [%v]", queryPath, value)
+	}
+	queryPath = "schemaWithLabels/labels01/labels[=prod]"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("filter without attribute should return err.Code=[%d] from [path]=[%s], got [%v]", http.StatusBadRequest, queryPath, err)
+	}
+}
+
+func TestWalkInArrayNegativeIdx(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schemaWitArray": {
+				"__id": "schemaWitArray",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWitArray",
+					"version": "0.0.1",
+					"description": "schema of object with array of object in attribute",
+					"properties": {
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						}
+					},
+					"definitions": {
+						"itemObj": {
+							"description": "item object of an array",
+							"key": "{key1}_{key2}",
+							"properties": {
+								"key1": {
+									"type": "string"
+								},
+								"key2": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schemaWitArray": {
+			"testArray01": {
+				"__id": "testArray01",
+				"__type": "schemaWitArray",
+				"__ver": "0.0.1",
+				"data": {
+					"attrArray": [
+						{
+							"key1": "01",
+							"key2": "01"
+						},
+						{
+							"key1": "01",
+							"key2": "02"
+						},
+						{
+							"key1": "01",
+							"key2": "03"
+						}
+					]
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string]string{
+		"schemaWitArray/testArray01/attrArray[-1]/key2":    "03",
+		"schemaWitArray/testArray01/attrArray[-2]/key2":    "02",
+		"schemaWitArray/testArray01/attrArray[-3]/key2":    "01",
+		"schemaWitArray/testArray01/attrArray[01_01]/key2": "01",
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+		}
+		if value != expected {
+			t.Fatalf("[%s] expect [%s], got [%v]", queryPath, expected, value)
+		}
+	}
+	queryPath := "schemaWitArray/testArray01/attrArray[-5]"
+	_, err := QueryPath(conn, queryPath)
+	if err == nil || err.Status != http.StatusNotFound {
+		t.Fatalf("out of range idx should return err.Code=[%d] from [path]=[%s], got [%v]", http.StatusNotFound, queryPath, err)
+	}
+	if !strings.Contains(err.Error(), "array length [3]") {
+		t.Fatalf("out of range error should mention array length, got [%s]", err)
+	}
+	queryPath = "schemaWitArray/testArray01/attrArray[-0]"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil || err.Status != http.StatusBadRequest {
+		t.Fatalf("idx [-0] should return err.Code=[%d], got [%v]", http.StatusBadRequest, err)
+	}
+}
+
+func TestWalkInArraySlice(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schemaWitArray": {
+				"__id": "schemaWitArray",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWitArray",
+					"version": "0.0.1",
+					"description": "schema of object with array of object in attribute",
+					"properties": {
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						}
+					},
+					"definitions": {
+						"itemObj": {
+							"description": "item object of an array",
+							"key": "{key1}_{key2}",
+							"properties": {
+								"key1": {
+									"type": "string"
+								},
+								"key2": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schemaWitArray": {
+			"testArray01": {
+				"__id": "testArray01",
+				"__type": "schemaWitArray",
+				"__ver": "0.0.1",
+				"data": {
+					"attrArray": [
+						{
+							"key1": "01",
+							"key2": "01"
+						},
+						{
+							"key1": "01",
+							"key2": "02"
+						},
+						{
+							"key1": "01",
+							"key2": "03"
+						}
+					]
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string][]string{
+		"schemaWitArray/testArray01/attrArray[1:3]/key2": {"02", "03"},
+		"schemaWitArray/testArray01/attrArray[1:]/key2":  {"02", "03"},
+		"schemaWitArray/testArray01/attrArray[:2]/key2":  {"01", "02"},
+		"schemaWitArray/testArray01/attrArray[:9]/key2":  {"01", "02", "03"},
+		"schemaWitArray/testArray01/attrArray[-2:]/key2": {"02", "03"},
+		"schemaWitArray/testArray01/attrArray[2:3]/key2": {"03"},
+		"schemaWitArray/testArray01/attrArray[2:1]/key2": {},
+		"schemaWitArray/testArray01/attrArray[5:]/key2":  {},
+		"schemaWitArray/testArray01/attrArray[0:0]/key2": {},
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+		}
+		valueList, ok := value.([]interface{})
+		if !ok || len(valueList) != len(expected) {
+			t.Fatalf("[%s] expect slice %v, got [%v]", queryPath, expected, value)
+		}
+		for idx, key2 := range expected {
+			if valueList[idx] != key2 {
+				t.Fatalf("[%s] expect slice %v, got [%v]", queryPath, expected, value)
+			}
+		}
+	}
+	queryPath := "schemaWitArray/testArray01/attrArray[1:3]"
+	value, err := QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+	}
+	itemList, ok := value.([]interface{})
+	if !ok || len(itemList) != 2 || itemList[0].(map[string]interface{})["key2"] != "02" {
+		t.Fatalf("[%s] expect items 1 and 2, got [%v]", queryPath, value)
+	}
+	queryPath = "schemaWitArray/testArray01/attrArray[5:]?flat"
+	value, err = QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+	}
+	if itemList, ok := value.([]interface{}); !ok || len(itemList) != 0 {
+		t.Fatalf("[%s] expect empty slice, got [%v]", queryPath, value)
+	}
 }
 
 func TestWalkInAll(t *testing.T) {
@@ -411,10 +744,34 @@ func TestWalkInAll(t *testing.T) {
 		if reflect.TypeOf(value).Kind() != reflect.Slice {
 			t.Fatalf("invalid return value type=[%s], expected=[%s], path=[%s]", reflect.TypeOf(value).Kind(), reflect.Slice, path)
 		}
+		if strings.HasPrefix(pathPart, "array") {
+			// array item without the path keeps its position as nil
+			valueList := value.([]interface{})
+			if len(valueList) != 3 || valueList[0] != "01" || valueList[2] != nil {
+				t.Fatalf("invalid aligned list [%v] from path=[%s], expect [01 02 <nil>]", valueList, path)
+			}
+			continue
+		}
 		if len(value.([]interface{})) != 2 {
 			t.Fatalf("failed to filter not exists path. get list len=[%d], expected len=[2]", len(value.([]interface{})))
 		}
-
+	}
+	for _, path := range []string{
+		"SchemaAllPath/allPath01/arrayObj[*]/key4",
+		"SchemaAllPath/allPath01/arrayRef[*]/key4",
+	} {
+		_, err := QueryPath(conn, path)
+		if err == nil || err.Status != http.StatusNotFound {
+			t.Fatalf("expect 404 when no item has the path=[%s], got [%v]", path, err)
+		}
+	}
+	path := "SchemaAllPath/allPath01/arrayObj[*]/key3?len"
+	value, err := QueryPath(conn, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lenList, ok := value.([]interface{}); !ok || len(lenList) != 2 {
+		t.Fatalf("command other than value should skip missing item, got [%v] from path=[%s]", value, path)
 	}
 }
 
@@ -510,3 +867,571 @@ func TestWalkRecordCmtRecordDirect(t *testing.T) {
 		t.Fatalf("invalid return value type=[%s], expected=[%s], path=[%s]", reflect.TypeOf(value).Kind(), reflect.Map, path)
 	}
 }
+
+func TestWalkAsMap(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schemaWitArray": {
+				"__id": "schemaWitArray",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWitArray",
+					"version": "0.0.1",
+					"description": "schema of object with array of object in attribute",
+					"properties": {
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						},
+						"strArray": {
+							"type": "array",
+							"items": {
+								"type": "string"
+							}
+						}
+					},
+					"definitions": {
+						"itemObj": {
+							"description": "item object of an array",
+							"key": "{key1}_{key2}",
+							"properties": {
+								"key1": {
+									"type": "string"
+								},
+								"key2": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schemaWitArray": {
+			"testArray01": {
+				"__id": "testArray01",
+				"__type": "schemaWitArray",
+				"__ver": "0.0.1",
+				"data": {
+					"attrArray": [
+						{
+							"key1": "01",
+							"key2": "01"
+						},
+						{
+							"key1": "01",
+							"key2": "02"
+						}
+					],
+					"strArray": ["a", "b"]
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	queryPath := "schemaWitArray/testArray01/attrArray?asmap"
+	value, err := QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyedMap, ok := value.(map[string]interface{})
+	if !ok {
+		t.Fatalf("failed to get map from path=[%s], got [%v]", queryPath, value)
+	}
+	if len(keyedMap) != 2 {
+		t.Fatalf("expect 2 items from path=[%s], got [%d]", queryPath, len(keyedMap))
+	}
+	for _, key := range []string{"01_01", "01_02"} {
+		item, ok := keyedMap[key].(map[string]interface{})
+		if !ok {
+			t.Fatalf("missing item key=[%s] from path=[%s]", key, queryPath)
+		}
+		if fmt.Sprintf("%s_%s", item["key1"], item["key2"]) != key {
+			t.Fatalf("item under key=[%s] does not match, item=[%v]", key, item)
+		}
+	}
+	queryPath = "schemaWitArray/testArray01/strArray?asmap"
+	_, err = QueryPath(conn, queryPath)
+	if err == nil {
+		t.Fatalf("array without key template should return error, path=[%s]", queryPath)
+	}
+	if err.Status != http.StatusBadRequest {
+		t.Fatalf("array without key template return err.Code=[%d], expect err.Code=[%d]", err.Status, http.StatusBadRequest)
+	}
+}
+
+func TestCompareValue(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schema1": {
+				"__id": "schema1",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schema1",
+					"version": "0.0.1",
+					"description": "schema for compare test",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"port": {
+							"type": "integer"
+						},
+						"enabled": {
+							"type": "boolean"
+						}
+					}
+				}
+			}
+		},
+		"schema1": {
+			"data1": {
+				"__id": "data1",
+				"__type": "schema1",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "data1",
+					"port": 8010,
+					"enabled": true
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	for queryPath, expected := range map[string]bool{
+		"schema1/data1/name?eq=data1":    true,
+		"schema1/data1/name?eq=data2":    false,
+		"schema1/data1/name?ne=data1":    false,
+		"schema1/data1/name?ne=data2":    true,
+		"schema1/data1/port?eq=8010":     true,
+		"schema1/data1/port?eq=8011":     false,
+		"schema1/data1/enabled?eq=true":  true,
+		"schema1/data1/enabled?ne=true":  false,
+		"schema1/data1/missing?eq=data1": false,
+		"schema1/data1/missing?ne=data1": true,
+	} {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if value != expected {
+			t.Fatalf("path=[%s] return [%v], expect [%v]", queryPath, value, expected)
+		}
+	}
+	queryPath := "schema1/data1/port?eq=abc"
+	_, err := QueryPath(conn, queryPath)
+	if err == nil {
+		t.Fatalf("literal cannot convert to integer should return error, path=[%s]", queryPath)
+	}
+	if err.Status != http.StatusBadRequest {
+		t.Fatalf("invalid literal return err.Code=[%d], expect err.Code=[%d]", err.Status, http.StatusBadRequest)
+	}
+}
+
+func TestQueryLen(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schema1": {
+				"__id": "schema1",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schema1",
+					"version": "0.0.1",
+					"description": "schema to count length",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"tags": {
+							"type": "array",
+							"items": {
+								"type": "string"
+							}
+						},
+						"labels": {
+							"type": "map",
+							"items": {
+								"type": "string"
+							}
+						},
+						"size": {
+							"type": "integer"
+						},
+						"info": {
+							"type": "object",
+							"$ref": "#/definitions/info"
+						}
+					},
+					"definitions": {
+						"info": {
+							"name": "info",
+							"properties": {
+								"note": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schema1": {
+			"data1": {
+				"__id": "data1",
+				"__type": "schema1",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "héllo",
+					"tags": ["a", "b", "c"],
+					"labels": {"env": "prod", "team": "infra"},
+					"size": 10,
+					"info": {"note": "n"}
+				}
+			},
+			"data2": {
+				"__id": "data2",
+				"__type": "schema1",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "empty",
+					"tags": null
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	expected := map[string]int{
+		"schema1/data1/name?len":   5,
+		"schema1/data1/tags?len":   3,
+		"schema1/data1/labels?len": 2,
+	}
+	for queryPath, length := range expected {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if value != length {
+			t.Fatalf("invalid len from path=[%s], got [%v], expect [%d]", queryPath, value, length)
+		}
+	}
+	queryPath := "schema1/data1/tags[*]?len"
+	value, err := QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lenList, ok := value.([]interface{})
+	if !ok || len(lenList) != 3 || lenList[0] != 1 {
+		t.Fatalf("invalid len list from path=[%s], got [%v]", queryPath, value)
+	}
+	for _, queryPath := range []string{"schema1/data1/size?len", "schema1/data1/info?len", "schema1/data1?len"} {
+		_, err := QueryPath(conn, queryPath)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("path=[%s] should return err.Code=[%d], got [%v]", queryPath, http.StatusBadRequest, err)
+		}
+	}
+	// ?count only counts array and map, null collection is 0
+	expected = map[string]int{
+		"schema1/data1/tags?count":   3,
+		"schema1/data1/labels?count": 2,
+		"schema1/data2/tags?count":   0,
+	}
+	for queryPath, count := range expected {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if value != count {
+			t.Fatalf("invalid count from path=[%s], got [%v], expect [%d]", queryPath, value, count)
+		}
+	}
+	for _, queryPath := range []string{"schema1/data1/name?count", "schema1/data1/size?count", "schema1/data1/info?count"} {
+		_, err := QueryPath(conn, queryPath)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("path=[%s] should return err.Code=[%d], got [%v]", queryPath, http.StatusBadRequest, err)
+		}
+	}
+}
+
+func TestQueryKeys(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schema1": {
+				"__id": "schema1",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schema1",
+					"version": "0.0.1",
+					"description": "schema to list keys",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"tags": {
+							"type": "array",
+							"items": {
+								"type": "string"
+							}
+						},
+						"mapStr": {
+							"type": "map",
+							"items": {
+								"type": "string"
+							}
+						},
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/item"
+							}
+						}
+					},
+					"definitions": {
+						"item": {
+							"name": "item",
+							"key": "{key1}_{key2}",
+							"properties": {
+								"key1": {
+									"type": "string"
+								},
+								"key2": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schema1": {
+			"data1": {
+				"__id": "data1",
+				"__type": "schema1",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "data1",
+					"tags": ["a", "b"],
+					"mapStr": {"keyExists": "exists"},
+					"attrArray": [
+						{"key1": "01", "key2": "01"},
+						{"key1": "01", "key2": "02"}
+					]
+				}
+			},
+			"data2": {
+				"__id": "data2",
+				"__type": "schema1",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "data2",
+					"mapStr": null
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	expected := map[string][]string{
+		"schema1/data1/mapStr?keys":    {"keyExists"},
+		"schema1/data1/attrArray?keys": {"01_01", "01_02"},
+		"schema1/data2/mapStr?keys":    {},
+	}
+	for queryPath, keys := range expected {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if !reflect.DeepEqual(value, keys) {
+			t.Fatalf("invalid keys from path=[%s], got [%v], expect [%v]", queryPath, value, keys)
+		}
+	}
+	for _, queryPath := range []string{"schema1/data1/name?keys", "schema1/data1/tags?keys", "schema1/data1/attrArray[01_01]?keys", "schema1/data1?keys"} {
+		_, err := QueryPath(conn, queryPath)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("path=[%s] should return err.Code=[%d], got [%v]", queryPath, http.StatusBadRequest, err)
+		}
+	}
+}
+
+func TestQueryAggregate(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"schemaWitArray": {
+				"__id": "schemaWitArray",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "schemaWitArray",
+					"version": "0.0.1",
+					"description": "schema with array of object that has number",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"attrArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						},
+						"emptyArray": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"$ref": "#/definitions/itemObj"
+							}
+						},
+						"sizes": {
+							"type": "array",
+							"items": {
+								"type": "integer"
+							}
+						}
+					},
+					"definitions": {
+						"itemObj": {
+							"name": "itemObj",
+							"key": "{key}",
+							"properties": {
+								"key": {
+									"type": "string"
+								},
+								"someNumber": {
+									"type": "number",
+									"required": false
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"schemaWitArray": {
+			"testArray01": {
+				"__id": "testArray01",
+				"__type": "schemaWitArray",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "testArray01",
+					"attrArray": [
+						{"key": "01", "someNumber": 1.5},
+						{"key": "02", "someNumber": 4},
+						{"key": "03"},
+						{"key": "04", "someNumber": null},
+						{"key": "05", "someNumber": 0.5}
+					],
+					"emptyArray": [],
+					"sizes": [3, 1, 2]
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	testList := map[string]interface{}{
+		"schemaWitArray/testArray01/attrArray/someNumber?sum":     6.0,
+		"schemaWitArray/testArray01/attrArray/someNumber?avg":     2.0,
+		"schemaWitArray/testArray01/attrArray/someNumber?min":     0.5,
+		"schemaWitArray/testArray01/attrArray/someNumber?max":     4.0,
+		"schemaWitArray/testArray01/attrArray[*]/someNumber?sum":  6.0,
+		"schemaWitArray/testArray01/attrArray[02]/someNumber?sum": 4.0,
+		"schemaWitArray/testArray01/sizes?sum":                    6.0,
+		"schemaWitArray/testArray01/sizes?avg":                    2.0,
+		"schemaWitArray/testArray01/emptyArray/someNumber?sum":    0.0,
+		"schemaWitArray/testArray01/emptyArray/someNumber?avg":    nil,
+		"schemaWitArray/testArray01/attrArray[04]/someNumber?avg": nil,
+	}
+	for queryPath, expected := range testList {
+		value, err := QueryPath(conn, queryPath)
+		if err != nil {
+			t.Fatalf("failed to query path=[%s]. Error: %s", queryPath, err)
+		}
+		if value != expected {
+			t.Fatalf("invalid aggregate from path=[%s], got [%v], expect [%v]", queryPath, value, expected)
+		}
+	}
+	for _, queryPath := range []string{
+		"schemaWitArray/testArray01/attrArray/key?sum",
+		"schemaWitArray/testArray01/name?max",
+	} {
+		_, err := QueryPath(conn, queryPath)
+		if err == nil || err.Status != http.StatusBadRequest {
+			t.Fatalf("expect 400 on aggregate of non numeric path=[%s], got [%v]", queryPath, err)
+		}
+	}
+}
+
+func TestWalkDiscriminator(t *testing.T) {
+	recordStr := `{
+		"schema": {
+			"device": {
+				"__id": "device",
+				"__type": "schema",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "device",
+					"version": "0.0.1",
+					"properties": {
+						"name": {
+							"type": "string"
+						},
+						"kind": {
+							"type": "string"
+						}
+					},
+					"discriminator": {
+						"propertyName": "kind",
+						"mapping": {
+							"switch": "switch"
+						}
+					},
+					"definitions": {
+						"switch": {
+							"properties": {
+								"ports": {
+									"type": "integer"
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"device": {
+			"sw01": {
+				"__id": "sw01",
+				"__type": "device",
+				"__ver": "0.0.1",
+				"data": {
+					"name": "sw01",
+					"kind": "switch",
+					"ports": 48
+				}
+			}
+		}
+	}`
+	conn := PrepareConn(recordStr)
+	queryPath := "device/sw01/ports"
+	value, err := QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatalf("failed to walk attr of kind [%s]. Error: %s", queryPath, err)
+	}
+	if value != float64(48) {
+		t.Fatalf("[%s] expect [48], got [%v]", queryPath, value)
+	}
+	queryPath = "device/sw01/ports?schema"
+	value, err = QueryPath(conn, queryPath)
+	if err != nil {
+		t.Fatalf("failed to query [%s]. Error: %s", queryPath, err)
+	}
+	attrDef, ok := value.(map[string]interface{})
+	if !ok || attrDef["type"] != "integer" {
+		t.Fatalf("[%s] expect definition of attr from kind [switch], got [%v]", queryPath, value)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaTest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/OpenApi"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+	"github.com/salesforce/UniTAO/lib/Schema/SchemaDoc"
+	"github.com/salesforce/UniTAO/lib/Util"
+	"github.com/salesforce/UniTAO/lib/Util/Json"
+)
+
+// schema docs of demo01 sample, with one that has map attrs
+func openApiDocs(t *testing.T) []*SchemaDoc.SchemaDoc {
+	rootDir, err := Util.RootDir()
+	if err != nil {
+		t.Fatalf("failed to get running dir. Error: %s", err)
+	}
+	sampleData, err := Json.LoadJsonFile(filepath.Join(rootDir, "demo/demo01/data/vmComputeSchema.json"))
+	if err != nil {
+		t.Fatalf("failed to load sample schemas. Error: %s", err)
+	}
+	docs := []*SchemaDoc.SchemaDoc{}
+	for _, recordList := range sampleData.(map[string]interface{}) {
+		for _, item := range recordList.([]interface{}) {
+			record, err := Record.LoadMap(item.(map[string]interface{}))
+			if err != nil {
+				t.Fatalf("failed to load sample record. Error: %s", err)
+			}
+			if record.Type != JsonKey.Schema {
+				continue
+			}
+			doc, err := SchemaDoc.New(record.Data)
+			if err != nil {
+				t.Fatalf("failed to load sample schema [%s]. Error: %s", record.Id, err)
+			}
+			docs = append(docs, doc)
+		}
+	}
+	doc, err := SchemaDoc.FromString(`{
+		"name": "VmGroup",
+		"version": "0.0.1",
+		"properties": {
+			"vms": {
+				"type": "map",
+				"items": {
+					"type": "object",
+					"$ref": "#/definitions/member"
+				}
+			},
+			"labels": {
+				"type": "map"
+			},
+			"size": {
+				"type": "integer",
+				"minimum": 0,
+				"required": false
+			}
+		},
+		"definitions": {
+			"member": {
+				"properties": {
+					"vm": {
+						"type": "string",
+						"contentMediaType": "inventory/VirtualMachine"
+					},
+					"group": {
+						"type": "object",
+						"$ref": "#",
+						"required": false
+					}
+				}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to load map schema. Error: %s", err)
+	}
+	return append(docs, doc)
+}
+
+// every $ref in value that does not point at a component
+func danglingRefs(value interface{}, components map[string]interface{}) []string {
+	dangling := []string{}
+	switch data := value.(type) {
+	case map[string]interface{}:
+		for key, item := range data {
+			if ref, ok := item.(string); ok && key == JsonKey.Ref {
+				if _, ok := components[strings.TrimPrefix(ref, OpenApi.ComponentPrefix)]; !ok {
+					dangling = append(dangling, ref)
+				}
+				continue
+			}
+			dangling = append(dangling, danglingRefs(item, components)...)
+		}
+	case []interface{}:
+		for _, item := range data {
+			dangling = append(dangling, danglingRefs(item, components)...)
+		}
+	}
+	return dangling
+}
+
+func TestOpenApi(t *testing.T) {
+	doc, err := OpenApi.Generate("UniTAO", "dev", "api", openApiDocs(t))
+	if err != nil {
+		t.Fatalf("failed to generate OpenAPI. Error: %s", err)
+	}
+	if doc["openapi"] != OpenApi.Version {
+		t.Fatalf("expect openapi [%s], got [%v]", OpenApi.Version, doc["openapi"])
+	}
+	paths := doc["paths"].(map[string]interface{})
+	for _, path := range []string{"/api/", "/api/VirtualMachine", "/api/VirtualMachine/{id}", "/api/VmHost/{id}", "/api/VmGroup/{id}"} {
+		if _, ok := paths[path]; !ok {
+			t.Fatalf("missing path [%s]", path)
+		}
+	}
+	recordPath := paths["/api/VirtualMachine/{id}"].(map[string]interface{})
+	for _, method := range []string{"get", "put", "patch", "delete"} {
+		if _, ok := recordPath[method]; !ok {
+			t.Fatalf("missing [%s] of record path", method)
+		}
+	}
+	components := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	if dangling := danglingRefs(doc, components); len(dangling) > 0 {
+		t.Fatalf("refs without component %v", dangling)
+	}
+	vmProps := components["VirtualMachine"].(map[string]interface{})[JsonKey.Properties].(map[string]interface{})
+	network := vmProps["network"].(map[string]interface{})
+	if network[JsonKey.Type] != JsonKey.Array || fmt.Sprint(network[JsonKey.Items]) != fmt.Sprintf("map[$ref:%sVirtualMachine.definitions.nic]", OpenApi.ComponentPrefix) {
+		t.Fatalf("expect array of nic component, got %v", network)
+	}
+	nic := components["VirtualMachine.definitions.nic"].(map[string]interface{})
+	if fmt.Sprint(nic[JsonKey.Required]) != "[link name]" {
+		t.Fatalf("expect required attrs of nic without optional ip, got %v", nic[JsonKey.Required])
+	}
+	link := nic[JsonKey.Properties].(map[string]interface{})["link"].(map[string]interface{})
+	if link[JsonKey.ContentMediaType] != "inventory/VmLink" {
+		t.Fatalf("expect contentMediaType of ref attr kept, got %v", link)
+	}
+	groupProps := components["VmGroup"].(map[string]interface{})[JsonKey.Properties].(map[string]interface{})
+	vms := groupProps["vms"].(map[string]interface{})
+	if vms[JsonKey.Type] != JsonKey.Object || fmt.Sprint(vms[JsonKey.AdditionalProperties]) != fmt.Sprintf("map[$ref:%sVmGroup.definitions.member]", OpenApi.ComponentPrefix) {
+		t.Fatalf("expect map of member component, got %v", vms)
+	}
+	if labels := groupProps["labels"].(map[string]interface{}); labels[JsonKey.AdditionalProperties] != true {
+		t.Fatalf("expect freeform map, got %v", labels)
+	}
+	if size := groupProps["size"].(map[string]interface{}); size["minimum"] != float64(0) || size[JsonKey.Type] != JsonKey.Integer {
+		t.Fatalf("expect integer with minimum, got %v", size)
+	}
+	member := components["VmGroup.definitions.member"].(map[string]interface{})[JsonKey.Properties].(map[string]interface{})
+	if fmt.Sprint(member["group"]) != fmt.Sprintf("map[$ref:%sVmGroup]", OpenApi.ComponentPrefix) {
+		t.Fatalf("expect ref to root to point at type component, got %v", member["group"])
+	}
+	record := components["VmGroup"+OpenApi.RecordSuffix].(map[string]interface{})[JsonKey.Properties].(map[string]interface{})
+	if fmt.Sprint(record[Record.Data]) != fmt.Sprintf("map[$ref:%sVmGroup]", OpenApi.ComponentPrefix) {
+		t.Fatalf("expect data of record to be type component, got %v", record[Record.Data])
+	}
+}
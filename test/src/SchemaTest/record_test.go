@@ -0,0 +1,112 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaTest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestRecordCanonicalJSON(t *testing.T) {
+	first := Record.NewRecord("test", "0.0.1", "test01", map[string]interface{}{})
+	first.Data["name"] = "test01"
+	first.Data["size"] = 3
+	first.Data["attrs"] = map[string]interface{}{
+		"b": []interface{}{map[string]interface{}{"y": 1, "x": 2}},
+		"a": "valueA",
+	}
+	second := Record.NewRecord("test", "0.0.1", "test01", map[string]interface{}{})
+	second.Data["attrs"] = map[string]interface{}{
+		"a": "valueA",
+		"b": []interface{}{map[string]interface{}{"x": 2, "y": 1}},
+	}
+	second.Data["size"] = 3
+	second.Data["name"] = "test01"
+	firstRaw, err := first.CanonicalJSON()
+	if err != nil {
+		t.Fatalf("failed to serialize first record, Error: %s", err)
+	}
+	secondRaw, err := second.CanonicalJSON()
+	if err != nil {
+		t.Fatalf("failed to serialize second record, Error: %s", err)
+	}
+	if string(firstRaw) != string(secondRaw) {
+		t.Fatalf("canonical bytes not identical.\n%s\n%s", firstRaw, secondRaw)
+	}
+	expected := `{"__id":"test01","__type":"test","__ver":"0.0.1","data":{"attrs":{"a":"valueA","b":[{"x":2,"y":1}]},"name":"test01","size":3}}`
+	if string(firstRaw) != expected {
+		t.Fatalf("unexpected canonical bytes.\n%s\nexpected:\n%s", firstRaw, expected)
+	}
+	firstHash, _ := first.Hash()
+	secondHash, _ := second.Hash()
+	if firstHash != secondHash {
+		t.Fatalf("hash mismatch, [%s]!=[%s]", firstHash, secondHash)
+	}
+	second.Data["size"] = 4
+	secondHash, _ = second.Hash()
+	if firstHash == secondHash {
+		t.Fatalf("hash should change when data changed")
+	}
+}
+
+func TestRecordTimestamps(t *testing.T) {
+	record, err := Record.LoadMap(map[string]interface{}{
+		Record.DataId:   "test01",
+		Record.DataType: "test",
+		Record.Version:  "0.0.1",
+		Record.Created:  "2022-05-01T10:00:00.5Z",
+		Record.Modified: "2022-05-02T10:00:00Z",
+		Record.Data:     map[string]interface{}{},
+	})
+	if err != nil {
+		t.Fatalf("failed to load record. Error: %s", err)
+	}
+	created, err := record.CreatedTime()
+	if err != nil || !created.Equal(time.Date(2022, 5, 1, 10, 0, 0, 500000000, time.UTC)) {
+		t.Fatalf("invalid created time [%s], Error: %v", created, err)
+	}
+	modified, err := record.ModifiedTime()
+	if err != nil || !modified.Equal(time.Date(2022, 5, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("invalid modified time [%s], Error: %v", modified, err)
+	}
+	data := record.Map()
+	if data[Record.Created] != record.Created || data[Record.Modified] != record.Modified {
+		t.Fatalf("timestamps not kept in record map, got [%v]/[%v]", data[Record.Created], data[Record.Modified])
+	}
+	empty := Record.NewRecord("test", "0.0.1", "test02", map[string]interface{}{})
+	if _, ok := empty.Map()[Record.Created]; ok {
+		t.Fatalf("expect no [%s] on record not written yet", Record.Created)
+	}
+	if created, err = empty.CreatedTime(); err != nil || !created.IsZero() {
+		t.Fatalf("expect zero created time on record not written yet, got [%s], Error: %v", created, err)
+	}
+	record.Created = "yesterday"
+	if _, err = record.CreatedTime(); err == nil {
+		t.Fatalf("expect error on invalid created time")
+	}
+}
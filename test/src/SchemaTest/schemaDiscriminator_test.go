@@ -0,0 +1,145 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaTest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+const discriminatorSchema = `{
+	"name": "device",
+	"version": "0.0.1",
+	"properties": {
+		"name": {
+			"type": "string"
+		},
+		"kind": {
+			"type": "string"
+		}
+	},
+	"discriminator": {
+		"propertyName": "kind",
+		"mapping": {
+			"server": "#/definitions/server",
+			"switch": "switch"
+		}
+	},
+	"definitions": {
+		"server": {
+			"properties": {
+				"cpu": {
+					"type": "integer"
+				}
+			},
+			"rules": [
+				"cpu > 0"
+			]
+		},
+		"switch": {
+			"properties": {
+				"ports": {
+					"type": "integer"
+				},
+				"uplink": {
+					"type": "string",
+					"required": false
+				}
+			}
+		}
+	}
+}`
+
+func TestSchemaDiscriminator(t *testing.T) {
+	schema, err := LoadSchema(discriminatorSchema)
+	if err != nil {
+		t.Fatalf("failed load schema with discriminator. Error:%s", err)
+	}
+	if schema.Schema.Discriminator == nil || len(schema.Schema.Discriminator.Mapping) != 2 {
+		t.Fatalf("expect discriminator with 2 kinds, got [%v]", schema.Schema.Discriminator)
+	}
+	goodList := []string{
+		`{"name": "srv01", "kind": "server", "cpu": 16}`,
+		`{"name": "sw01", "kind": "switch", "ports": 48}`,
+		`{"name": "sw02", "kind": "switch", "ports": 24, "uplink": "sw01"}`,
+	}
+	for _, dataStr := range goodList {
+		record, err := Record.LoadStr(`{"__id": "d01", "__type": "device", "__ver": "0.0.1", "data": ` + dataStr + `}`)
+		if err != nil {
+			t.Fatalf("failed to load record [%s]. Error:%s", dataStr, err)
+		}
+		err = schema.ValidateRecord(record)
+		if err != nil {
+			t.Fatalf("failed to validate [%s] against its kind. Error:%s", dataStr, err)
+		}
+	}
+	badList := map[string]string{
+		`{"name": "srv02", "kind": "server"}`:                "cpu",
+		`{"name": "srv03", "kind": "server", "cpu": "many"}`: "cpu",
+		`{"name": "srv04", "kind": "server", "cpu": 0}`:      "cpu > 0",
+		`{"name": "sw03", "kind": "switch", "cpu": 16}`:      "ports",
+		`{"name": "rt01", "kind": "router", "ports": 4}`:     "no sub-schema for [kind]=[router]",
+	}
+	for dataStr, errPart := range badList {
+		record, err := Record.LoadStr(`{"__id": "d01", "__type": "device", "__ver": "0.0.1", "data": ` + dataStr + `}`)
+		if err != nil {
+			t.Fatalf("failed to load record [%s]. Error:%s", dataStr, err)
+		}
+		err = schema.ValidateRecord(record)
+		if err == nil {
+			t.Fatalf("failed to reject [%s]", dataStr)
+		}
+		if !strings.Contains(err.Error(), errPart) {
+			t.Fatalf("error of [%s] should mention [%s]. Error:%s", dataStr, errPart, err)
+		}
+	}
+	kindDoc, err := schema.Schema.KindDoc(map[string]interface{}{"kind": "switch"})
+	if err != nil {
+		t.Fatalf("failed to get doc of kind [switch]. Error:%s", err)
+	}
+	if _, ok := kindDoc.Properties()["ports"]; !ok {
+		t.Fatalf("doc of kind [switch] missing attr [ports]")
+	}
+	if _, ok := kindDoc.Properties()["name"]; !ok {
+		t.Fatalf("doc of kind [switch] missing base attr [name]")
+	}
+}
+
+func TestSchemaDiscriminatorInvalid(t *testing.T) {
+	invalidList := map[string]string{
+		"undefined attr": strings.Replace(discriminatorSchema, `"propertyName": "kind"`, `"propertyName": "type"`, 1),
+		"missing def":    strings.Replace(discriminatorSchema, `"switch": "switch"`, `"switch": "router"`, 1),
+		"dup attr":       strings.Replace(discriminatorSchema, `"ports": {`, `"name": {`, 1),
+	}
+	for name, schemaStr := range invalidList {
+		_, err := LoadSchema(schemaStr)
+		if err == nil {
+			t.Fatalf("failed to catch invalid discriminator [%s]", name)
+		}
+	}
+}
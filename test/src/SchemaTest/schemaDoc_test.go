@@ -27,6 +27,7 @@ package SchemaTest
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/salesforce/UniTAO/lib/Schema/Record"
@@ -179,3 +180,194 @@ func TestHashArrayKeyReq(t *testing.T) {
 		t.Errorf("failed to validate a good data")
 	}
 }
+
+func TestPathTemplates(t *testing.T) {
+	schemaStr := `{
+		"name": "holder",
+		"version": "0.0.1",
+		"properties": {
+			"name": {
+				"type": "string"
+			},
+			"owner": {
+				"type": "string",
+				"contentMediaType": "inventory/refItem"
+			},
+			"itemArray": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"$ref": "#/definitions/item"
+				}
+			},
+			"labels": {
+				"type": "object",
+				"additionalProperties": {
+					"type": "string"
+				}
+			},
+			"location": {
+				"type": "object",
+				"$ref": "#/definitions/location"
+			}
+		},
+		"definitions": {
+			"item": {
+				"name": "item",
+				"key": "{name}",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"refIdx": {
+						"type": "string",
+						"contentMediaType": "inventory/refItem|otherItem"
+					}
+				}
+			},
+			"location": {
+				"name": "location",
+				"properties": {
+					"rack": {
+						"type": "string"
+					}
+				}
+			}
+		}
+	}`
+	data := map[string]interface{}{}
+	err := json.Unmarshal([]byte(schemaStr), &data)
+	if err != nil {
+		t.Fatalf("failed to parse schema. Error: %s", err)
+	}
+	schema, err := SchemaDoc.New(data)
+	if err != nil {
+		t.Fatalf("failed to load schema. Error: %s", err)
+	}
+	expected := []string{
+		"itemArray",
+		"itemArray[{key}]",
+		"itemArray[{key}]/name",
+		"itemArray[{key}]/refIdx",
+		"itemArray[{key}]/refIdx/{ref:refItem|otherItem}",
+		"labels",
+		"labels[{key}]",
+		"location",
+		"location/rack",
+		"name",
+		"owner",
+		"owner/{ref:refItem}",
+	}
+	templates := schema.PathTemplates()
+	if !reflect.DeepEqual(templates, expected) {
+		t.Fatalf("path templates not match.\nexpect: %v\ngot: %v", expected, templates)
+	}
+}
+
+func TestAppendOnlyOnArrayOnly(t *testing.T) {
+	schemaStr := `{
+		"name": "test",
+		"version": "0.0.1",
+		"description": "test schema",
+		"properties": {
+			"events": {
+				"type": "array",
+				"appendOnly": true,
+				"items": {
+					"type": "string"
+				}
+			},
+			"status": {
+				"type": "string",
+				"appendOnly": true
+			}
+		}
+	}`
+	data := map[string]interface{}{}
+	err := json.Unmarshal([]byte(schemaStr), &data)
+	if err != nil {
+		t.Fatalf("failed to load schemaStr. Error:%s", err)
+	}
+	_, err = SchemaDoc.New(data)
+	if err == nil {
+		t.Fatalf("failed to catch appendOnly on string attr")
+	}
+	delete(data["properties"].(map[string]interface{}), "status")
+	_, err = SchemaDoc.New(data)
+	if err != nil {
+		t.Fatalf("failed to load schema with appendOnly array. Error:%s", err)
+	}
+}
+
+func TestSensitiveAttrs(t *testing.T) {
+	schemaStr := `{
+		"name": "test",
+		"version": "0.0.1",
+		"description": "test schema",
+		"properties": {
+			"name": {
+				"type": "string"
+			},
+			"password": {
+				"type": "string",
+				"sensitive": true
+			},
+			"apiKey": {
+				"type": "object",
+				"$ref": "#/definitions/key"
+			}
+		},
+		"definitions": {
+			"key": {
+				"name": "key",
+				"properties": {
+					"token": {
+						"type": "string",
+						"sensitive": true
+					}
+				}
+			}
+		}
+	}`
+	data := map[string]interface{}{}
+	err := json.Unmarshal([]byte(schemaStr), &data)
+	if err != nil {
+		t.Fatalf("failed to load schemaStr. Error:%s", err)
+	}
+	doc, err := SchemaDoc.New(data)
+	if err != nil {
+		t.Fatalf("failed to load schema. Error:%s", err)
+	}
+	names := doc.SensitiveAttrs()
+	if len(names) != 2 || !names["password"] || !names["token"] {
+		t.Fatalf("expect sensitive attrs [password token], got %v", names)
+	}
+}
+
+func TestDefaultMatchesType(t *testing.T) {
+	schemaStr := `{
+		"name": "test",
+		"version": "0.0.1",
+		"description": "test schema",
+		"properties": {
+			"port": {
+				"type": "integer",
+				"default": "http"
+			}
+		}
+	}`
+	data := map[string]interface{}{}
+	err := json.Unmarshal([]byte(schemaStr), &data)
+	if err != nil {
+		t.Fatalf("failed to load schemaStr. Error:%s", err)
+	}
+	_, err = SchemaDoc.New(data)
+	if err == nil {
+		t.Fatalf("failed to catch default that is not integer")
+	}
+	data["properties"].(map[string]interface{})["port"].(map[string]interface{})["default"] = "8080"
+	_, err = SchemaDoc.New(data)
+	if err != nil {
+		t.Fatalf("failed to load schema with integer default. Error:%s", err)
+	}
+}
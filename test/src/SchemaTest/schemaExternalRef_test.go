@@ -0,0 +1,204 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaTest
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Schema"
+	"github.com/salesforce/UniTAO/lib/Schema/JsonKey"
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestSchemaExternalRef(t *testing.T) {
+	docs := map[string]string{
+		"common": `{
+			"name": "common",
+			"version": "0.0.1",
+			"properties": {},
+			"definitions": {
+				"owner": {
+					"name": "owner",
+					"properties": {
+						"name": {"type": "string"},
+						"badge": {"type": "object", "$ref": "#/definitions/badge"}
+					}
+				},
+				"badge": {
+					"name": "badge",
+					"properties": {
+						"id": {"type": "string"},
+						"tag": {"type": "object", "$ref": "site#/definitions/tag"}
+					}
+				}
+			}
+		}`,
+	}
+	fetchCount := map[string]int{}
+	fetch := func(schemaId string) (map[string]interface{}, error) {
+		fetchCount[schemaId]++
+		docStr, ok := docs[schemaId]
+		if !ok {
+			return nil, fmt.Errorf("schema [%s] not found", schemaId)
+		}
+		data := map[string]interface{}{}
+		err := json.Unmarshal([]byte(docStr), &data)
+		return data, err
+	}
+	schemaStr := `{
+		"name": "site",
+		"version": "0.0.1",
+		"properties": {
+			"owner": {"type": "object", "$ref": "common#/definitions/owner"},
+			"contact": {"type": "object", "$ref": "common#/definitions/owner", "required": false}
+		},
+		"definitions": {
+			"tag": {
+				"name": "tag",
+				"properties": {
+					"label": {"type": "string"}
+				}
+			}
+		}
+	}`
+	schemaData := map[string]interface{}{}
+	err := json.Unmarshal([]byte(schemaStr), &schemaData)
+	if err != nil {
+		t.Fatalf("failed to load schema str. Error: %s", err)
+	}
+	schemaRec := Record.NewRecord(JsonKey.Schema, "0.0.1", "site", schemaData)
+	_, err = Schema.LoadSchemaOpsRecord(schemaRec)
+	if err == nil {
+		t.Fatalf("schema with external ref should fail to load without fetcher")
+	}
+	schema, err := Schema.LoadSchemaOpsWithFetcher(schemaRec, Schema.CachedFetcher(fetch, 0, 0))
+	if err != nil {
+		t.Fatalf("failed to load schema with external ref. Error: %s", err)
+	}
+	if fetchCount["common"] != 1 || fetchCount["site"] != 0 {
+		t.Fatalf("expect [common] fetched once and ref back to [site] resolved locally, got %v", fetchCount)
+	}
+	for _, defName := range []string{"owner", "badge", "tag"} {
+		if _, ok := schema.Schema.Definitions[defName]; !ok {
+			t.Fatalf("missing definition [%s] in resolved schema", defName)
+		}
+	}
+	if _, ok := schema.Schema.SubDocs["owner"]; !ok {
+		t.Fatalf("missing sub doc of [owner] in resolved schema")
+	}
+	goodRec := Record.NewRecord("site", "0.0.1", "s01", map[string]interface{}{
+		"owner": map[string]interface{}{
+			"name":  "o1",
+			"badge": map[string]interface{}{"id": "b1", "tag": map[string]interface{}{"label": "l1"}},
+		},
+	})
+	err = schema.ValidateRecord(goodRec)
+	if err != nil {
+		t.Fatalf("failed to validate record against resolved external ref. Error: %s", err)
+	}
+	badRec := Record.NewRecord("site", "0.0.1", "s02", map[string]interface{}{
+		"owner": map[string]interface{}{
+			"name":  "o1",
+			"badge": map[string]interface{}{"id": "b1", "tag": map[string]interface{}{"label": 1}},
+		},
+	})
+	err = schema.ValidateRecord(badRec)
+	if err == nil {
+		t.Fatalf("record with invalid value in external definition should fail validation")
+	}
+	_, err = Schema.LoadSchemaOpsWithFetcher(schemaRec, Schema.CachedFetcher(fetch, 0, 0))
+	if err != nil {
+		t.Fatalf("failed to reload schema with external ref. Error: %s", err)
+	}
+	if fetchCount["common"] != 2 {
+		t.Fatalf("expect new fetcher to fetch [common] again, got %v", fetchCount)
+	}
+}
+
+func TestParseExternalRef(t *testing.T) {
+	testList := []struct {
+		ref      string
+		schemaId string
+		defName  string
+		hasErr   bool
+	}{
+		{"common", "common", "", false},
+		{"common#", "common", "", false},
+		{"common#/definitions/owner", "common", "owner", false},
+		{"https://host/schema/common.json#/definitions/owner", "https://host/schema/common.json", "owner", false},
+		{"common#/properties/owner", "", "", true},
+		{"#/definitions/owner", "", "", true},
+	}
+	for _, test := range testList {
+		schemaId, defName, err := Schema.ParseExternalRef(test.ref)
+		if test.hasErr {
+			if err == nil {
+				t.Fatalf("ref [%s] should fail to parse", test.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("failed to parse ref [%s]. Error: %s", test.ref, err)
+		}
+		if schemaId != test.schemaId || defName != test.defName {
+			t.Fatalf("ref [%s] parsed as [%s][%s], expect [%s][%s]", test.ref, schemaId, defName, test.schemaId, test.defName)
+		}
+	}
+}
+
+func TestCachedFetcherBound(t *testing.T) {
+	fetchCount := map[string]int{}
+	fetch := func(schemaId string) (map[string]interface{}, error) {
+		fetchCount[schemaId]++
+		return map[string]interface{}{"name": schemaId}, nil
+	}
+	cached := Schema.CachedFetcher(fetch, 0, 2)
+	for _, schemaId := range []string{"a", "b", "a", "c", "a", "b"} {
+		_, err := cached(schemaId)
+		if err != nil {
+			t.Fatalf("failed to fetch [%s]. Error: %s", schemaId, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// [a] is dropped as the oldest when [c] is kept, [b] is dropped for [a] again
+	if fetchCount["a"] != 2 || fetchCount["b"] != 2 || fetchCount["c"] != 1 {
+		t.Fatalf("expect cache of 2 to drop oldest document, got %v", fetchCount)
+	}
+	expiring := Schema.CachedFetcher(fetch, 20*time.Millisecond, 0)
+	expiring("d")
+	expiring("d")
+	if fetchCount["d"] != 1 {
+		t.Fatalf("expect [d] fetched once before it expires, got %d", fetchCount["d"])
+	}
+	time.Sleep(30 * time.Millisecond)
+	expiring("d")
+	if fetchCount["d"] != 2 {
+		t.Fatalf("expect expired [d] fetched again, got %d", fetchCount["d"])
+	}
+}
@@ -0,0 +1,142 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaTest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlattenRecord(t *testing.T) {
+	schemaStr := `{
+		"name": "host",
+		"version": "0.0.1",
+		"properties": {
+			"name": {
+				"type": "string"
+			},
+			"location": {
+				"type": "object",
+				"$ref": "#/definitions/location"
+			},
+			"nics": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"$ref": "#/definitions/nic"
+				}
+			},
+			"tags": {
+				"type": "array",
+				"items": {
+					"type": "string"
+				}
+			},
+			"labels": {
+				"type": "map",
+				"items": {
+					"type": "string"
+				}
+			}
+		},
+		"definitions": {
+			"location": {
+				"name": "location",
+				"properties": {
+					"rack": {
+						"type": "string"
+					},
+					"slot": {
+						"type": "integer"
+					}
+				}
+			},
+			"nic": {
+				"name": "nic",
+				"key": "{bus}_{port}",
+				"properties": {
+					"bus": {
+						"type": "string"
+					},
+					"port": {
+						"type": "string"
+					},
+					"speed": {
+						"type": "integer"
+					}
+				}
+			}
+		}
+	}`
+	schema, err := LoadSchema(schemaStr)
+	if err != nil {
+		t.Fatalf("failed load schemaStr. Error:%s", err)
+	}
+	data := map[string]interface{}{
+		"name": "host01",
+		"location": map[string]interface{}{
+			"rack": "r01",
+			"slot": float64(3),
+		},
+		"nics": []interface{}{
+			map[string]interface{}{"bus": "pci0", "port": "1", "speed": float64(10)},
+			map[string]interface{}{"bus": "pci0", "port": "2", "speed": float64(25)},
+		},
+		"tags": []interface{}{"db"},
+		"labels": map[string]interface{}{
+			"env": "prod",
+		},
+	}
+	row, err := schema.Schema.FlattenRecord(data)
+	if err != nil {
+		t.Fatalf("failed to flatten record. Error:%s", err)
+	}
+	expected := map[string]interface{}{
+		"name":               "host01",
+		"location.rack":      "r01",
+		"location.slot":      float64(3),
+		"nics[pci0_1].bus":   "pci0",
+		"nics[pci0_1].port":  "1",
+		"nics[pci0_1].speed": float64(10),
+		"nics[pci0_2].bus":   "pci0",
+		"nics[pci0_2].port":  "2",
+		"nics[pci0_2].speed": float64(25),
+		"tags[db]":           "db",
+		"labels[env]":        "prod",
+	}
+	if !reflect.DeepEqual(row, expected) {
+		t.Fatalf("flattened row mismatch, expect [%v], got [%v]", expected, row)
+	}
+	columns := schema.Schema.FlatColumns()
+	expectedColumns := []string{"location.rack", "location.slot", "name"}
+	if !reflect.DeepEqual(columns, expectedColumns) {
+		t.Fatalf("flat columns mismatch, expect %v, got %v", expectedColumns, columns)
+	}
+	_, err = schema.Schema.FlattenRecord(map[string]interface{}{"unknown": "value"})
+	if err == nil {
+		t.Fatalf("expect error flattening undefined attribute")
+	}
+}
@@ -0,0 +1,219 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package SchemaTest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Schema/Record"
+)
+
+func TestSchemaRules(t *testing.T) {
+	schemaStr := `{
+		"name": "portRange",
+		"version": "0.0.1",
+		"properties": {
+			"protocol": {
+				"type": "string"
+			},
+			"startPort": {
+				"type": "integer"
+			},
+			"endPort": {
+				"type": "integer"
+			}
+		},
+		"rules": [
+			"startPort <= endPort",
+			"protocol == \"tcp\" or startPort > 1024"
+		]
+	}`
+	schema, err := LoadSchema(schemaStr)
+	if err != nil {
+		t.Fatalf("failed load schemaStr. Error:%s", err)
+	}
+	if len(schema.Schema.Rules) != 2 {
+		t.Fatalf("expect 2 rules, got [%d]", len(schema.Schema.Rules))
+	}
+	goodRecordStr := `{
+		"__id": "range01",
+		"__type": "portRange",
+		"__ver": "0.0.1",
+		"data": {
+			"protocol": "udp",
+			"startPort": 2000,
+			"endPort": 3000
+		}
+	}`
+	record, err := Record.LoadStr(goodRecordStr)
+	if err != nil {
+		t.Fatalf("failed to load good record str as record. Error:%s", err)
+	}
+	err = schema.ValidateRecord(record)
+	if err != nil {
+		t.Fatalf("failed to validate good record. Error:%s", err)
+	}
+	badRecordStr := `{
+		"__id": "range02",
+		"__type": "portRange",
+		"__ver": "0.0.1",
+		"data": {
+			"protocol": "tcp",
+			"startPort": 3000,
+			"endPort": 2000
+		}
+	}`
+	record, err = Record.LoadStr(badRecordStr)
+	if err != nil {
+		t.Fatalf("failed to load bad record str as record. Error:%s", err)
+	}
+	err = schema.ValidateRecord(record)
+	if err == nil {
+		t.Fatalf("failed to catch violated rule [startPort <= endPort]")
+	}
+	if !strings.Contains(err.Error(), "startPort <= endPort") {
+		t.Fatalf("error does not name the violated rule. Error:%s", err)
+	}
+	record.Data["startPort"] = 80
+	record.Data["endPort"] = 90
+	record.Data["protocol"] = "udp"
+	err = schema.ValidateRecord(record)
+	if err == nil {
+		t.Fatalf("failed to catch violated rule with [or]")
+	}
+}
+
+func TestSchemaRulesInvalid(t *testing.T) {
+	for _, rule := range []string{"startPort <=", "startPort => endPort", "startPort < endPort and", "startPort < missingAttr"} {
+		schemaStr := `{
+			"name": "portRange",
+			"version": "0.0.1",
+			"properties": {
+				"startPort": {
+					"type": "integer"
+				},
+				"endPort": {
+					"type": "integer"
+				}
+			},
+			"rules": ["` + rule + `"]
+		}`
+		_, err := LoadSchema(schemaStr)
+		if err == nil {
+			t.Fatalf("failed to catch invalid rule [%s]", rule)
+		}
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	schemaStr := `{
+		"name": "portRange",
+		"version": "0.0.1",
+		"properties": {
+			"protocol": {
+				"type": "string"
+			},
+			"startPort": {
+				"type": "integer"
+			},
+			"endPort": {
+				"type": "integer"
+			},
+			"legacyName": {
+				"type": "string",
+				"deprecated": true
+			}
+		},
+		"rules": [
+			"startPort <= endPort"
+		],
+		"warnRules": [
+			"startPort > 1024"
+		]
+	}`
+	schema, err := LoadSchema(schemaStr)
+	if err != nil {
+		t.Fatalf("failed load schemaStr. Error:%s", err)
+	}
+	if len(schema.Schema.WarnRules) != 1 {
+		t.Fatalf("expect 1 warnRule, got [%d]", len(schema.Schema.WarnRules))
+	}
+	warnRecordStr := `{
+		"__id": "range01",
+		"__type": "portRange",
+		"__ver": "0.0.1",
+		"data": {
+			"protocol": "tcp",
+			"startPort": 80,
+			"endPort": 443,
+			"legacyName": "web"
+		}
+	}`
+	record, err := Record.LoadStr(warnRecordStr)
+	if err != nil {
+		t.Fatalf("failed to load warn record str as record. Error:%s", err)
+	}
+	err = schema.ValidateRecord(record)
+	if err != nil {
+		t.Fatalf("record with only warnings should pass validation. Error:%s", err)
+	}
+	result := schema.Schema.Validate(record.Data, "")
+	if !result.Ok() {
+		t.Fatalf("record with only warnings should have no error, got %v", result.Errors)
+	}
+	if len(result.Warnings) != 2 {
+		t.Fatalf("expect 2 warnings, got %v", result.Warnings)
+	}
+	if !strings.Contains(result.Warnings[0], "startPort > 1024") || !strings.Contains(result.Warnings[1], "legacyName") {
+		t.Fatalf("unexpected warnings %v", result.Warnings)
+	}
+	errRecordStr := `{
+		"__id": "range02",
+		"__type": "portRange",
+		"__ver": "0.0.1",
+		"data": {
+			"protocol": "tcp",
+			"startPort": 3000,
+			"endPort": 2000
+		}
+	}`
+	record, err = Record.LoadStr(errRecordStr)
+	if err != nil {
+		t.Fatalf("failed to load err record str as record. Error:%s", err)
+	}
+	err = schema.ValidateRecord(record)
+	if err == nil {
+		t.Fatalf("record violating rule should fail validation")
+	}
+	result = schema.Schema.Validate(record.Data, "")
+	if result.Ok() || len(result.Errors) != 1 {
+		t.Fatalf("expect 1 error, got %v", result.Errors)
+	}
+	if len(result.Warnings) != 0 {
+		t.Fatalf("expect no warning, got %v", result.Warnings)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestCacheHeaders(t *testing.T) {
+	cfg := Http.Config{
+		Cache: Http.CacheConfig{
+			Default: Http.CachePolicy{MaxAge: 60},
+			Types: map[string]Http.CachePolicy{
+				"region": {MaxAge: 600},
+				"lease":  {NoStore: true},
+				"user":   {},
+			},
+		},
+	}
+	testList := map[string]string{
+		"region":  "public, max-age=600",
+		"lease":   Http.CacheNoStore,
+		"user":    "",
+		"machine": "public, max-age=60",
+	}
+	for dataType, expected := range testList {
+		w := httptest.NewRecorder()
+		Http.SetCacheHeaders(w, cfg, dataType)
+		if w.Header().Get(Http.HeaderCacheControl) != expected {
+			t.Fatalf("type [%s] header [%s]=[%s], expect [%s]", dataType, Http.HeaderCacheControl, w.Header().Get(Http.HeaderCacheControl), expected)
+		}
+	}
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	headers := Http.CacheHeaders(cfg.Cache.Policy("region"), now)
+	if headers[Http.HeaderExpires] != "Sat, 01 Jan 2022 00:10:00 GMT" {
+		t.Fatalf("invalid header [%s]=[%s]", Http.HeaderExpires, headers[Http.HeaderExpires])
+	}
+	headers = Http.CacheHeaders(cfg.Cache.Policy("lease"), now)
+	if headers[Http.HeaderExpires] != "0" {
+		t.Fatalf("invalid header [%s]=[%s] of no-store", Http.HeaderExpires, headers[Http.HeaderExpires])
+	}
+	w := httptest.NewRecorder()
+	Http.SetCacheHeaders(w, Http.Config{}, "region")
+	if len(w.Header()) != 0 {
+		t.Fatalf("no cache header expected without config, got %v", w.Header())
+	}
+}
+
+func TestNoCacheRequest(t *testing.T) {
+	testList := map[string]bool{
+		"":                    false,
+		"max-age=0":           false,
+		"no-cache":            true,
+		"max-age=0, No-Cache": true,
+	}
+	for value, expected := range testList {
+		req := httptest.NewRequest(http.MethodGet, "/region/r01", nil)
+		if value != "" {
+			req.Header.Set(Http.HeaderCacheControl, value)
+		}
+		if Http.NoCache(req) != expected {
+			t.Fatalf("[%s]=[%s] no-cache expect [%t]", Http.HeaderCacheControl, value, expected)
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "/region/r01", nil)
+	req.Header.Set(Http.HeaderPragma, Http.CacheNoCache)
+	if !Http.NoCache(req) {
+		t.Fatalf("[%s]=[%s] should bypass cache", Http.HeaderPragma, Http.CacheNoCache)
+	}
+}
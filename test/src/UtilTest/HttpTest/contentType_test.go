@@ -0,0 +1,100 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestContentType(t *testing.T) {
+	testList := []struct {
+		name     string
+		cfg      Http.Config
+		response func(w http.ResponseWriter, cfg Http.Config)
+		expected string
+	}{
+		{
+			name: "json",
+			response: func(w http.ResponseWriter, cfg Http.Config) {
+				Http.ResponseJson(w, map[string]interface{}{"name": "h01"}, http.StatusOK, cfg)
+			},
+			expected: "application/json; charset=utf-8",
+		},
+		{
+			name: "error",
+			response: func(w http.ResponseWriter, cfg Http.Config) {
+				Http.ResponseErr(w, fmt.Errorf("failed"), http.StatusBadRequest, cfg)
+			},
+			expected: "application/json; charset=utf-8",
+		},
+		{
+			name: "text",
+			response: func(w http.ResponseWriter, cfg Http.Config) {
+				Http.ResponseText(w, []byte("ok"), http.StatusOK, cfg)
+			},
+			expected: "text/plain; charset=utf-8",
+		},
+		{
+			name: "lines",
+			response: func(w http.ResponseWriter, cfg Http.Config) {
+				items := make(chan int, 1)
+				items <- 1
+				close(items)
+				Http.ResponseJsonLines(w, items, cfg)
+			},
+			expected: "application/x-ndjson; charset=utf-8",
+		},
+		{
+			name: "configured json",
+			cfg:  Http.Config{ContentType: "application/vnd.api+json", Charset: "iso-8859-1"},
+			response: func(w http.ResponseWriter, cfg Http.Config) {
+				Http.ResponseJson(w, []string{"h01"}, http.StatusOK, cfg)
+			},
+			expected: "application/vnd.api+json; charset=iso-8859-1",
+		},
+		{
+			name: "configured error",
+			cfg:  Http.Config{ContentType: "application/vnd.api+json"},
+			response: func(w http.ResponseWriter, cfg Http.Config) {
+				Http.ResponseErr(w, Http.NewHttpError("missing", http.StatusNotFound), http.StatusNotFound, cfg)
+			},
+			expected: "application/vnd.api+json; charset=utf-8",
+		},
+	}
+	for _, test := range testList {
+		w := httptest.NewRecorder()
+		test.response(w, test.cfg)
+		contentType := w.Header().Get(Http.HeaderContent)
+		if contentType != test.expected {
+			t.Fatalf("[%s] expect Content-Type [%s], got [%s]", test.name, test.expected, contentType)
+		}
+	}
+}
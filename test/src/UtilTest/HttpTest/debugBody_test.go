@@ -0,0 +1,99 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func debugBodyServe(cfg Http.Config, reqBody string) (string, string) {
+	logBuf := bytes.Buffer{}
+	logger := log.New(&logBuf, "", 0)
+	received := ""
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = string(body)
+		w.Write([]byte(`{"__id": "u01", "data": {"name": "alice", "password": "hunter2", "keys": [{"token": "abc"}]}}`))
+	})
+	sensitive := func() map[string]bool {
+		return map[string]bool{"password": true, "token": true}
+	}
+	req := httptest.NewRequest(http.MethodPut, "/user/u01", strings.NewReader(reqBody))
+	Http.DebugBodyHandler(handler, cfg, logger, sensitive).ServeHTTP(httptest.NewRecorder(), req)
+	return received, logBuf.String()
+}
+
+func TestDebugBodyLogged(t *testing.T) {
+	reqBody := `{"name": "alice", "password": "hunter2"}`
+	received, logText := debugBodyServe(Http.Config{DebugBody: Http.DebugBodyConfig{Enabled: true}}, reqBody)
+	if received != reqBody {
+		t.Fatalf("handler expect request body as sent, got [%s]", received)
+	}
+	if !strings.Contains(logText, `"name":"alice"`) {
+		t.Fatalf("expect bodies logged, got [%s]", logText)
+	}
+	if strings.Contains(logText, "hunter2") || strings.Contains(logText, "abc") {
+		t.Fatalf("expect sensitive attrs redacted, got [%s]", logText)
+	}
+	if strings.Count(logText, `"password":"***"`) != 2 || !strings.Contains(logText, `"token":"***"`) {
+		t.Fatalf("expect sensitive attrs logged as ***, got [%s]", logText)
+	}
+}
+
+func TestDebugBodyDisabled(t *testing.T) {
+	_, logText := debugBodyServe(Http.Config{}, `{"name": "alice"}`)
+	if logText != "" {
+		t.Fatalf("expect nothing logged when disabled, got [%s]", logText)
+	}
+}
+
+func TestDebugBodyFormat(t *testing.T) {
+	sensitive := map[string]bool{"password": true}
+	text := Http.DebugBody([]byte(`{"name": "`+strings.Repeat("a", 100)+`"}`), "/user/u01", sensitive, 20)
+	if !strings.HasPrefix(text, `{"name":"aaaaaaaaaaa...[cut,`) {
+		t.Fatalf("expect body cut at max size, got [%s]", text)
+	}
+	text = Http.DebugBody([]byte(`"hunter2"`), "/user/u01/password", sensitive, 100)
+	if text != Http.DebugBodyRedacted {
+		t.Fatalf("expect body on sensitive path redacted, got [%s]", text)
+	}
+	text = Http.DebugBody([]byte("{\"password\": \"a\"}\n{\"password\": \"b\"}\n"), "/user", sensitive, 100)
+	if text != "{\"password\":\"***\"}\n{\"password\":\"***\"}" {
+		t.Fatalf("expect JSON lines redacted line by line, got [%s]", text)
+	}
+	text = Http.DebugBody([]byte("name,password\nalice,hunter2"), "/user", sensitive, 100)
+	if strings.Contains(text, "hunter2") {
+		t.Fatalf("expect body not in JSON left out, got [%s]", text)
+	}
+}
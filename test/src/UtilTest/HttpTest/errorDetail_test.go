@@ -0,0 +1,88 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func responseError(t *testing.T, errorDetail string, err error) (*Http.HttpError, string) {
+	logBuf := bytes.Buffer{}
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+	w := httptest.NewRecorder()
+	Http.ResponseErr(w, err, http.StatusInternalServerError, Http.Config{ErrorDetail: errorDetail})
+	result := Http.HttpError{}
+	ex := json.Unmarshal(w.Body.Bytes(), &result)
+	if ex != nil {
+		t.Fatalf("failed to parse error response. Error: %s", ex)
+	}
+	if w.Code != result.Status {
+		t.Fatalf("response code [%d] not match error status [%d]", w.Code, result.Status)
+	}
+	return &result, logBuf.String()
+}
+
+func TestErrorDetail(t *testing.T) {
+	err := Http.WrapError(Http.NewHttpError("db table [data] connection refused", http.StatusInternalServerError), "failed to query record", http.StatusNotFound)
+	full, fullLog := responseError(t, "", err)
+	if full.Status != http.StatusNotFound || full.Message[0] != "failed to query record" || len(full.Context) == 0 {
+		t.Fatalf("full detail expect original error, got %v", full)
+	}
+	if full.CorrelationId != "" || fullLog != "" {
+		t.Fatalf("full detail should not set correlation id or log, got [%s] [%s]", full.CorrelationId, fullLog)
+	}
+	full, _ = responseError(t, Http.ErrorDetailFull, err)
+	if full.Message[0] != "failed to query record" {
+		t.Fatalf("full detail expect original message, got %v", full.Message)
+	}
+	safe, safeLog := responseError(t, Http.ErrorDetailSafe, err)
+	if safe.Status != http.StatusNotFound {
+		t.Fatalf("safe detail should keep status, got [%d]", safe.Status)
+	}
+	if len(safe.Message) != 1 || safe.Message[0] != http.StatusText(http.StatusNotFound) || len(safe.Context) != 0 {
+		t.Fatalf("safe detail expect generic message only, got %v", safe)
+	}
+	if safe.CorrelationId == "" {
+		t.Fatalf("safe detail missing correlation id")
+	}
+	if !strings.Contains(safeLog, safe.CorrelationId) || !strings.Contains(safeLog, "connection refused") {
+		t.Fatalf("log should keep detail with correlation id [%s], got [%s]", safe.CorrelationId, safeLog)
+	}
+	other, _ := responseError(t, Http.ErrorDetailSafe, err)
+	if other.CorrelationId == safe.CorrelationId {
+		t.Fatalf("correlation id should differ between responses")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func probe(t *testing.T, handler http.Handler, method string) (int, Http.HealthStatus) {
+	req := httptest.NewRequest(method, Http.PathHealth, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	status := Http.HealthStatus{}
+	if w.Code != http.StatusMethodNotAllowed {
+		err := json.Unmarshal(w.Body.Bytes(), &status)
+		if err != nil {
+			t.Fatalf("invalid probe body [%s]. Error: %s", w.Body.String(), err)
+		}
+	}
+	return w.Code, status
+}
+
+func TestHealthHandler(t *testing.T) {
+	started := time.Now().Add(-90 * time.Second)
+	code, status := probe(t, Http.HealthHandler("1.2.3", started, Http.Config{}), http.MethodGet)
+	if code != http.StatusOK || status.Status != Http.StatusOk {
+		t.Fatalf("expect health [%d] [%s], got [%d] [%s]", http.StatusOK, Http.StatusOk, code, status.Status)
+	}
+	if status.Version != "1.2.3" {
+		t.Fatalf("expect version [1.2.3], got [%s]", status.Version)
+	}
+	if status.Uptime < 90 {
+		t.Fatalf("expect uptime of at least 90 seconds, got [%d]", status.Uptime)
+	}
+	code, _ = probe(t, Http.HealthHandler("1.2.3", started, Http.Config{}), http.MethodPost)
+	if code != http.StatusMethodNotAllowed {
+		t.Fatalf("expect [%d] on POST to probe, got [%d]", http.StatusMethodNotAllowed, code)
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	var readyErr *Http.HttpError
+	ready := func() *Http.HttpError {
+		return readyErr
+	}
+	handler := Http.ReadyHandler("1.2.3", time.Now(), ready, Http.Config{})
+	code, status := probe(t, handler, http.MethodGet)
+	if code != http.StatusOK || status.Status != Http.StatusOk {
+		t.Fatalf("expect ready [%d] [%s], got [%d] [%s]", http.StatusOK, Http.StatusOk, code, status.Status)
+	}
+	readyErr = Http.NewHttpError("database is down", http.StatusServiceUnavailable)
+	code, status = probe(t, handler, http.MethodGet)
+	if code != http.StatusServiceUnavailable || status.Status != Http.StatusNotReady {
+		t.Fatalf("expect not ready [%d] [%s], got [%d] [%s]", http.StatusServiceUnavailable, Http.StatusNotReady, code, status.Status)
+	}
+	if len(status.Message) != 1 || status.Message[0] != "database is down" {
+		t.Fatalf("expect reason of not ready in message, got %v", status.Message)
+	}
+}
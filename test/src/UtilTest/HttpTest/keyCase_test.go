@@ -0,0 +1,83 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestKeyCaseNames(t *testing.T) {
+	snakeList := map[string]string{
+		"hostName":     "host_name",
+		"rackId":       "rack_id",
+		"HTTPPort":     "http_port",
+		"vmID":         "vm_id",
+		"ipV4Address":  "ip_v4_address",
+		"already_done": "already_done",
+	}
+	for key, expect := range snakeList {
+		if snake := Http.ToSnake(key); snake != expect {
+			t.Fatalf("expect [%s] in snake_case to be [%s], got [%s]", key, expect, snake)
+		}
+	}
+	camelList := map[string]string{
+		"host_name": "hostName",
+		"http_port": "httpPort",
+		"hostName":  "hostName",
+	}
+	for key, expect := range camelList {
+		if camel := Http.ToCamel(key); camel != expect {
+			t.Fatalf("expect [%s] in camelCase to be [%s], got [%s]", key, expect, camel)
+		}
+	}
+	if Http.WireKeyFunc(Http.Config{}) != nil {
+		t.Fatalf("expect no key func without KeyCase")
+	}
+}
+
+func TestKeyCaseOnWire(t *testing.T) {
+	// which keys are attributes is known by schema of record, request and response of Http keep every key as it is
+	cfg := Http.Config{KeyCase: Http.KeyCaseSnake}
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"__id": "h01", "data": {"host_name": "h01"}}`))
+	body, err := Http.LoadJsonRequest(req, cfg)
+	if err != nil {
+		t.Fatalf("failed to load request. Error: %s", err)
+	}
+	data := body.(map[string]interface{})["data"].(map[string]interface{})
+	if data["host_name"] != "h01" {
+		t.Fatalf("expect request keys kept, got %v", data)
+	}
+	w := httptest.NewRecorder()
+	Http.ResponseJson(w, map[string]interface{}{"typeName": map[string]interface{}{"contentMediaType": "x"}}, http.StatusOK, cfg)
+	if !strings.Contains(w.Body.String(), `"typeName"`) || !strings.Contains(w.Body.String(), `"contentMediaType"`) {
+		t.Fatalf("expect response keys kept, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,167 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestLimitHandler(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := Http.Config{Limit: Http.LimitConfig{MaxInFlight: 2, QueueWait: 50, RetryAfter: 3}}
+	server := httptest.NewServer(Http.LimitHandler(handler, cfg))
+	defer server.Close()
+	wg := sync.WaitGroup{}
+	for idx := 0; idx < 2; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/slow")
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-started
+	}
+	resp, err := http.Get(server.URL + "/data")
+	if err != nil {
+		t.Fatalf("failed to request saturated server. Error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expect [%d] over limit, got [%d]", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	if resp.Header.Get(Http.HeaderRetryAfter) != "3" {
+		t.Fatalf("expect [%s]=[3], got [%s]", Http.HeaderRetryAfter, resp.Header.Get(Http.HeaderRetryAfter))
+	}
+	for _, path := range []string{Http.PathHealth, Http.PathReady + "/db", Http.PathHealthAlias, Http.PathReadyAlias} {
+		resp, err = http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("failed to request [%s]. Error: %s", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expect exempt path [%s] served when saturated, got [%d]", path, resp.StatusCode)
+		}
+	}
+	close(release)
+	wg.Wait()
+	resp, err = http.Get(server.URL + "/data")
+	if err != nil {
+		t.Fatalf("failed to request after slots freed. Error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expect request served after slots freed, got [%d]", resp.StatusCode)
+	}
+}
+
+func TestLimitHandlerQueue(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := Http.Config{Limit: Http.LimitConfig{MaxInFlight: 1, QueueWait: 5000}}
+	server := httptest.NewServer(Http.LimitHandler(handler, cfg))
+	defer server.Close()
+	go func() {
+		resp, err := http.Get(server.URL + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	result := make(chan int)
+	go func() {
+		resp, err := http.Get(server.URL + "/data")
+		if err != nil {
+			result <- 0
+			return
+		}
+		resp.Body.Close()
+		result <- resp.StatusCode
+	}()
+	close(release)
+	if status := <-result; status != http.StatusOK {
+		t.Fatalf("expect queued request served once slot is free, got [%d]", status)
+	}
+}
+
+func TestLimitHandlerStream(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, Http.SuffixWatch) {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := Http.Config{Limit: Http.LimitConfig{MaxInFlight: 1, QueueWait: -1}}
+	server := httptest.NewServer(Http.LimitHandler(handler, cfg))
+	defer server.Close()
+	wg := sync.WaitGroup{}
+	for idx := 0; idx < 2; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/doc/d01" + Http.SuffixWatch)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-started
+	}
+	resp, err := http.Get(server.URL + "/data")
+	if err != nil {
+		t.Fatalf("failed to request with open watch. Error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expect open watch not to hold slot, got [%d]", resp.StatusCode)
+	}
+	close(release)
+	wg.Wait()
+}
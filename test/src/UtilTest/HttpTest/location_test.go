@@ -0,0 +1,61 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestResourcePath(t *testing.T) {
+	testList := []struct {
+		cfg      Http.Config
+		dataType string
+		dataId   string
+		expected string
+	}{
+		{Http.Config{}, "host", "h01", "/host/h01"},
+		{Http.Config{}, "host", "rack/h01", "/host/rack%2Fh01"},
+		{Http.Config{}, "host", "h 01", "/host/h%2001"},
+		{Http.Config{BasePath: "/tenant1/"}, "host", "h01", "/tenant1/host/h01"},
+		{Http.Config{BasePath: "api/v1"}, "host", "h01", "/api/v1/host/h01"},
+	}
+	for _, test := range testList {
+		location := Http.ResourcePath(test.cfg, test.dataType, test.dataId)
+		if location != test.expected {
+			t.Fatalf("expect Location [%s] of [%s/%s], got [%s]", test.expected, test.dataType, test.dataId, location)
+		}
+		parsed, err := url.Parse(location)
+		if err != nil {
+			t.Fatalf("Location [%s] is not a valid url. Error: %s", location, err)
+		}
+		if parsed.EscapedPath() != location {
+			t.Fatalf("Location [%s] changed after parse, got [%s]", location, parsed.EscapedPath())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	metrics := Http.NewMetrics("test", Http.MetricsConfig{Buckets: []float64{1, 0.1}})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+	dataType := func(r *http.Request) string {
+		if r.URL.Path == "/item" {
+			return "item"
+		}
+		return Http.MetricsUnknown
+	}
+	server := httptest.NewServer(Http.MetricsHandler(handler, metrics, dataType))
+	defer server.Close()
+	for _, req := range [][]string{{http.MethodGet, "/item"}, {http.MethodGet, "/item"}, {http.MethodPost, "/item"}, {http.MethodGet, "/missing"}, {"PURGE", "/item"}} {
+		request, _ := http.NewRequest(req[0], server.URL+req[1], nil)
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("failed to request [%s %s]. Error: %s", req[0], req[1], err)
+		}
+		resp.Body.Close()
+	}
+	w := httptest.NewRecorder()
+	metrics.Handler(Http.Config{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, Http.PathMetrics, nil))
+	if w.Code != http.StatusOK || w.Header().Get(Http.HeaderContent) != Http.ContentTypeMetrics {
+		t.Fatalf("expect metrics [%d] in [%s], got [%d] in [%s]", http.StatusOK, Http.ContentTypeMetrics, w.Code, w.Header().Get(Http.HeaderContent))
+	}
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{method="GET",dataType="item"} 2`,
+		`test_requests_total{method="GET",dataType="unknown"} 1`,
+		`test_requests_total{method="OTHER",dataType="item"} 1`,
+		"# TYPE test_request_duration_seconds histogram",
+		`test_request_duration_seconds_bucket{method="POST",dataType="item",le="0.1"} 1`,
+		`test_request_duration_seconds_bucket{method="POST",dataType="item",le="+Inf"} 1`,
+		`test_request_duration_seconds_count{method="GET",dataType="item"} 2`,
+		`test_responses_total{method="GET",dataType="item",code="200"} 2`,
+		`test_responses_total{method="GET",dataType="unknown",code="404"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expect line [%s] in metrics:\n%s", line, body)
+		}
+	}
+	if strings.Index(body, `le="0.1"`) > strings.Index(body, `le="1"`) {
+		t.Fatalf("expect buckets in ascending order:\n%s", body)
+	}
+}
+
+func TestMetricsObserve(t *testing.T) {
+	metrics := Http.NewMetrics("test", Http.MetricsConfig{})
+	metrics.Observe(http.MethodGet, "a\"b", http.StatusOK, 30*time.Millisecond)
+	w := httptest.NewRecorder()
+	metrics.Write(w)
+	body := w.Body.String()
+	for _, line := range []string{
+		`test_request_duration_seconds_bucket{method="GET",dataType="a\"b",le="0.025"} 0`,
+		`test_request_duration_seconds_bucket{method="GET",dataType="a\"b",le="0.05"} 1`,
+		`test_request_duration_seconds_bucket{method="GET",dataType="a\"b",le="10"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expect line [%s] in metrics:\n%s", line, body)
+		}
+	}
+	w = httptest.NewRecorder()
+	metrics.Handler(Http.Config{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, Http.PathMetrics, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expect [%d] on POST to metrics, got [%d]", http.StatusMethodNotAllowed, w.Code)
+	}
+}
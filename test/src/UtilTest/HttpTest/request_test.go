@@ -0,0 +1,65 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestLoadJsonRequestDuplicateKey(t *testing.T) {
+	body := `{"__id": "data01", "data": {"value": "01", "nested": {"value": "02"}, "value": "03"}}`
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	data, err := Http.LoadJsonRequest(req, Http.Config{})
+	if err != nil {
+		t.Fatalf("lenient mode should accept duplicate key. Error: %s", err)
+	}
+	value := data.(map[string]interface{})["data"].(map[string]interface{})["value"]
+	if value != "03" {
+		t.Fatalf("lenient mode should keep last value, got [%v]", value)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	_, err = Http.LoadJsonRequest(req, Http.Config{StrictJson: true})
+	if err == nil {
+		t.Fatalf("strict mode should reject duplicate key")
+	}
+	if err.Status != http.StatusBadRequest {
+		t.Fatalf("duplicate key return err.Code=[%d], expect err.Code=[%d]", err.Status, http.StatusBadRequest)
+	}
+	if !strings.Contains(err.Error(), "/data") {
+		t.Fatalf("error should tell where duplicate key is, got [%s]", err)
+	}
+	body = `{"list": [{"value": "01"}, {"value": "02"}], "value": "03"}`
+	req = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	_, err = Http.LoadJsonRequest(req, Http.Config{StrictJson: true})
+	if err != nil {
+		t.Fatalf("same key in different objects is not duplicate. Error: %s", err)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func serveWithSecurity(cfg Http.Config, isTls bool) *httptest.ResponseRecorder {
+	handler := Http.SecurityHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Http.ResponseText(w, []byte("ok"), http.StatusOK, cfg)
+	}), cfg)
+	req := httptest.NewRequest(http.MethodGet, "/schema", nil)
+	if isTls {
+		req.TLS = &tls.ConnectionState{}
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	w := serveWithSecurity(Http.Config{}, false)
+	for _, key := range []string{Http.HeaderContentTypeOptions, Http.HeaderFrameOptions, Http.HeaderCsp} {
+		if w.Header().Get(key) != Http.DefaultSecurityHeaders[key] {
+			t.Fatalf("header [%s]=[%s], expect [%s]", key, w.Header().Get(key), Http.DefaultSecurityHeaders[key])
+		}
+	}
+	if w.Header().Get(Http.HeaderHsts) != "" {
+		t.Fatalf("header [%s] should not be set without TLS", Http.HeaderHsts)
+	}
+	w = serveWithSecurity(Http.Config{}, true)
+	if w.Header().Get(Http.HeaderHsts) != Http.DefaultSecurityHeaders[Http.HeaderHsts] {
+		t.Fatalf("header [%s] missing on TLS request", Http.HeaderHsts)
+	}
+}
+
+func TestSecurityHeadersConfig(t *testing.T) {
+	cfg := Http.Config{
+		Security: map[string]string{
+			"x-frame-options": "SAMEORIGIN",
+			Http.HeaderCsp:    "",
+			Http.HeaderHsts:   "",
+			"Referrer-Policy": "no-referrer",
+		},
+	}
+	w := serveWithSecurity(cfg, true)
+	if w.Header().Get(Http.HeaderFrameOptions) != "SAMEORIGIN" {
+		t.Fatalf("failed to override header [%s], got [%s]", Http.HeaderFrameOptions, w.Header().Get(Http.HeaderFrameOptions))
+	}
+	if _, ok := w.Header()[Http.HeaderCsp]; ok {
+		t.Fatalf("header [%s] should be disabled", Http.HeaderCsp)
+	}
+	if _, ok := w.Header()[Http.HeaderHsts]; ok {
+		t.Fatalf("header [%s] should be disabled", Http.HeaderHsts)
+	}
+	if w.Header().Get("Referrer-Policy") != "no-referrer" {
+		t.Fatalf("failed to add custom security header")
+	}
+	if w.Header().Get(Http.HeaderContentTypeOptions) != "nosniff" {
+		t.Fatalf("default header [%s] should stay when not configured", Http.HeaderContentTypeOptions)
+	}
+}
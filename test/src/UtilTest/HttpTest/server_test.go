@@ -0,0 +1,322 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+func TestServerTimeoutDefault(t *testing.T) {
+	server := Http.NewServer(":0", nil, Http.Config{})
+	if server.ReadHeaderTimeout != Http.DefaultReadHeaderTimeout*time.Second {
+		t.Fatalf("expect default read header timeout, got [%s]", server.ReadHeaderTimeout)
+	}
+	if server.IdleTimeout != Http.DefaultIdleTimeout*time.Second {
+		t.Fatalf("expect default idle timeout, got [%s]", server.IdleTimeout)
+	}
+	if server.WriteTimeout != 0 {
+		t.Fatalf("expect no write timeout by default, got [%s]", server.WriteTimeout)
+	}
+	server = Http.NewServer(":0", nil, Http.Config{Timeout: Http.TimeoutConfig{Read: -1, Write: 5}})
+	if server.ReadTimeout != 0 || server.WriteTimeout != 5*time.Second {
+		t.Fatalf("expect configured timeouts, got read=[%s], write=[%s]", server.ReadTimeout, server.WriteTimeout)
+	}
+}
+
+func TestServerSlowHeader(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen. Error: %s", err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := Http.NewServer(listener.Addr().String(), handler, Http.Config{Timeout: Http.TimeoutConfig{ReadHeader: 1}})
+	go server.Serve(listener)
+	defer server.Close()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect. Error: %s", err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n"))
+	if err != nil {
+		t.Fatalf("failed to write partial header. Error: %s", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	for {
+		_, err = conn.Read(buf)
+		if err != nil {
+			break
+		}
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("slow header client is not timed out by server")
+	}
+}
+
+func TestGracefulServerStop(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen. Error: %s", err)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := Http.NewGracefulServer(listener.Addr().String(), handler, Http.Config{})
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Post("http://"+listener.Addr().String()+"/item", "application/json", nil)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-started
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stopped <- server.Stop(ctx)
+	}()
+	select {
+	case err = <-stopped:
+		t.Fatalf("expect Stop to wait for in-flight request, returned [%v]", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	if code := <-status; code != http.StatusCreated {
+		t.Fatalf("expect in-flight request to finish with [%d], got [%d]", http.StatusCreated, code)
+	}
+	if err = <-stopped; err != nil {
+		t.Fatalf("expect clean stop, got [%s]", err)
+	}
+	if err = <-served; err != nil {
+		t.Fatalf("expect Serve to return nil after Stop, got [%s]", err)
+	}
+	_, err = http.Get("http://" + listener.Addr().String() + "/item")
+	if err == nil {
+		t.Fatalf("expect no request served after stop")
+	}
+}
+
+func TestGracefulServerSignal(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen. Error: %s", err)
+	}
+	server := Http.NewGracefulServer(listener.Addr().String(), http.NotFoundHandler(), Http.Config{})
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener, syscall.SIGUSR1)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case err = <-served:
+		if err != nil {
+			t.Fatalf("expect clean stop on signal, got [%s]", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server did not stop on signal")
+	}
+}
+
+func TestGracefulServerErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen. Error: %s", err)
+	}
+	defer listener.Close()
+	server := Http.NewGracefulServer(listener.Addr().String(), http.NotFoundHandler(), Http.Config{})
+	if server.ShutdownTimeout != Http.DefaultShutdownTimeout*time.Second {
+		t.Fatalf("expect default shutdown timeout, got [%s]", server.ShutdownTimeout)
+	}
+	err = server.ListenAndServe()
+	if err == nil {
+		t.Fatalf("expect listen error on address in use")
+	}
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen. Error: %s", err)
+	}
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	server = Http.NewGracefulServer(busy.Addr().String(), handler, Http.Config{})
+	go server.Serve(busy)
+	go http.Get("http://" + busy.Addr().String() + "/slow")
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = server.Stop(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect deadline exceeded when request outlives shutdown, got [%v]", err)
+	}
+}
+
+// write self-signed certificate of 127.0.0.1 and its key as PEM files in dir
+func writeKeyPair(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key. Error: %s", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate. Error: %s", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key. Error: %s", err)
+	}
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return certFile, keyFile
+}
+
+func TestServerConfigTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir)
+	testList := map[string]struct {
+		cfg   Http.Config
+		valid bool
+	}{
+		"default":      {Http.Config{}, true},
+		"http":         {Http.Config{HttpType: Http.TypeHttp}, true},
+		"https":        {Http.Config{HttpType: Http.TypeHttps, CertFile: certFile, KeyFile: keyFile}, true},
+		"unknown":      {Http.Config{HttpType: "ftp"}, false},
+		"noCert":       {Http.Config{HttpType: Http.TypeHttps, KeyFile: keyFile}, false},
+		"missingKey":   {Http.Config{HttpType: Http.TypeHttps, CertFile: certFile, KeyFile: filepath.Join(dir, "none.pem")}, false},
+		"keyIsNotCert": {Http.Config{HttpType: Http.TypeHttps, CertFile: keyFile, KeyFile: keyFile}, false},
+	}
+	for name, test := range testList {
+		err := Http.CheckServerConfig(test.cfg)
+		if test.valid && err != nil {
+			t.Fatalf("expect config [%s] to be valid, got [%s]", name, err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("expect config [%s] to be invalid", name)
+		}
+	}
+	server := Http.NewGracefulServer("127.0.0.1:0", http.NotFoundHandler(), Http.Config{HttpType: Http.TypeHttps, CertFile: certFile})
+	err := server.ListenAndServe()
+	if err == nil {
+		t.Fatalf("expect https server without key file to fail before listening")
+	}
+}
+
+func TestGracefulServerTLS(t *testing.T) {
+	certFile, keyFile := writeKeyPair(t, t.TempDir())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen. Error: %s", err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := Http.Config{HttpType: Http.TypeHttps, CertFile: certFile, KeyFile: keyFile}
+	server := Http.NewGracefulServer(listener.Addr().String(), handler, cfg)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	certPem, _ := os.ReadFile(certFile)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPem)
+	client := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get("https://" + listener.Addr().String() + "/item")
+	if err != nil {
+		t.Fatalf("failed https request. Error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expect request over TLS, got status [%d]", resp.StatusCode)
+	}
+	resp, err = http.Get("http://" + listener.Addr().String() + "/item")
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Fatalf("expect plain http request to be refused by https server")
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = server.Stop(ctx)
+	if err != nil {
+		t.Fatalf("expect clean stop, got [%s]", err)
+	}
+	if err = <-served; err != nil {
+		t.Fatalf("expect Serve to return nil after Stop, got [%s]", err)
+	}
+}
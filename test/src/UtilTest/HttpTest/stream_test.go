@@ -0,0 +1,108 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package HttpErrorTest
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Http"
+)
+
+type streamLine struct {
+	Value interface{} `json:"value"`
+	Err   string      `json:"error,omitempty"`
+}
+
+func (l streamLine) StreamErr() error {
+	if l.Err == "" {
+		return nil
+	}
+	return errors.New(l.Err)
+}
+
+func readStream(t *testing.T, items []interface{}) (int, http.Header) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out := make(chan interface{})
+		go func() {
+			defer close(out)
+			for _, item := range items {
+				out <- item
+			}
+		}()
+		Http.ResponseJsonLines(w, out, Http.Config{})
+	}))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to request stream. Error: %s", err)
+	}
+	defer resp.Body.Close()
+	lines := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines++
+	}
+	// trailer is only there after body is read to the end
+	return lines, resp.Trailer
+}
+
+func TestJsonLinesTrailer(t *testing.T) {
+	lines, trailer := readStream(t, []interface{}{
+		streamLine{Value: 1},
+		streamLine{Value: "b"},
+	})
+	if lines != 2 || trailer.Get(Http.TrailerStreamStatus) != Http.StreamOk || trailer.Get(Http.TrailerStreamCount) != "2" {
+		t.Fatalf("expect [2] lines with trailer ok, got [%d] lines, trailer [%v]", lines, trailer)
+	}
+	if trailer.Get(Http.TrailerStreamError) != "" {
+		t.Fatalf("expect no error trailer on complete stream, got [%s]", trailer.Get(Http.TrailerStreamError))
+	}
+	// value that can not be encoded breaks the stream partway
+	lines, trailer = readStream(t, []interface{}{
+		streamLine{Value: 1},
+		streamLine{Value: make(chan int)},
+		streamLine{Value: 3},
+	})
+	if lines != 1 || trailer.Get(Http.TrailerStreamStatus) != Http.StreamFailed || trailer.Get(Http.TrailerStreamCount) != "1" {
+		t.Fatalf("expect [1] line with trailer failed, got [%d] lines, trailer [%v]", lines, trailer)
+	}
+	if trailer.Get(Http.TrailerStreamError) == "" {
+		t.Fatalf("expect error trailer on failed stream")
+	}
+	// item that reports failure of its source is written, then stream stops
+	lines, trailer = readStream(t, []interface{}{
+		streamLine{Value: 1},
+		streamLine{Err: "source is gone"},
+		streamLine{Value: 3},
+	})
+	if lines != 2 || trailer.Get(Http.TrailerStreamStatus) != Http.StreamFailed || trailer.Get(Http.TrailerStreamError) != "source is gone" {
+		t.Fatalf("expect [2] lines with trailer failed, got [%d] lines, trailer [%v]", lines, trailer)
+	}
+}
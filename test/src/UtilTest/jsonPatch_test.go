@@ -0,0 +1,83 @@
+/*
+************************************************************************************************************
+Copyright (c) 2022 Salesforce, Inc.
+All rights reserved.
+
+UniTAO was originally created in 2022 by Shai Herzog & Yi Huo as an
+Universal No-Coding Heterogeneous Infrastructure Maintenance & Inventory system that is holistically driven by open/community-developed semantic models/schemas.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>
+
+This copyright notice and license applies to all files in this directory or sub-directories, except when stated otherwise explicitly.
+************************************************************************************************************
+*/
+
+package UtilTest
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/salesforce/UniTAO/lib/Util/Json"
+)
+
+func TestJsonPatch(t *testing.T) {
+	doc := map[string]interface{}{}
+	json.Unmarshal([]byte(`{"name": "h01", "tags": ["a", "b", "c"], "spec": {"a/b": 1, "owner": "ops"}, "grid": [[1]]}`), &doc)
+	ops := []interface{}{}
+	json.Unmarshal([]byte(`[
+		{"op": "test", "path": "/tags/1", "value": "b"},
+		{"op": "add", "path": "/tags/0", "value": "first"},
+		{"op": "add", "path": "/tags/-", "value": "last"},
+		{"op": "remove", "path": "/tags/2"},
+		{"op": "move", "from": "/spec/owner", "path": "/owner"},
+		{"op": "copy", "from": "/tags/0", "path": "/spec/first"},
+		{"op": "replace", "path": "/spec/a~1b", "value": 2},
+		{"op": "add", "path": "/grid/0/-", "value": 2}
+	]`), &ops)
+	result, err := Json.ApplyPatch(doc, ops)
+	if err != nil {
+		t.Fatalf("failed to apply patch. Error: %s", err)
+	}
+	expected := map[string]interface{}{}
+	json.Unmarshal([]byte(`{"name": "h01", "owner": "ops", "tags": ["first", "a", "c", "last"], "spec": {"a/b": 2, "first": "first"}, "grid": [[1, 2]]}`), &expected)
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected patch result %v", result)
+	}
+	if len(doc["tags"].([]interface{})) != 3 || doc["spec"].(map[string]interface{})["owner"] != "ops" {
+		t.Fatalf("expect doc not changed by patch, got %v", doc)
+	}
+	_, err = Json.ApplyPatch(doc, []interface{}{
+		map[string]interface{}{"op": "remove", "path": "/name"},
+		map[string]interface{}{"op": "test", "path": "/tags/0", "value": "z"},
+	})
+	if !errors.Is(err, Json.ErrPatchTest) {
+		t.Fatalf("expect failed test op, got %v", err)
+	}
+	invalidList := []map[string]interface{}{
+		{"op": "remove", "path": "/missing"},
+		{"op": "add", "path": "/tags/9", "value": "x"},
+		{"op": "replace", "path": "tags", "value": "x"},
+		{"op": "move", "from": "/spec", "path": "/spec/inner"},
+		{"op": "unknown", "path": "/name"},
+	}
+	for _, op := range invalidList {
+		_, err = Json.ApplyPatch(doc, []interface{}{op})
+		if err == nil || errors.Is(err, Json.ErrPatchTest) {
+			t.Fatalf("expect op %v to fail, got %v", op, err)
+		}
+	}
+}
@@ -106,3 +106,101 @@ func TestParseStepPath(t *testing.T) {
 		t.Fatal("failed to parse two step path")
 	}
 }
+
+func TestNegativeIdx(t *testing.T) {
+	validList := map[string]int{
+		"-1":  -1,
+		"-2":  -2,
+		"-10": -10,
+	}
+	for key, expected := range validList {
+		pos, ok := Util.NegativeIdx(key)
+		if !ok || pos != expected {
+			t.Fatalf("expect idx [%s] at position [%d], got [%d, %t]", key, expected, pos, ok)
+		}
+	}
+	for _, key := range []string{"1", "-", "-0", "--1", "-+1", "01_01", "-a"} {
+		if _, ok := Util.NegativeIdx(key); ok {
+			t.Fatalf("idx [%s] should not be a negative position", key)
+		}
+	}
+	_, key, err := Util.ParseArrayPath("attrArray[-1]")
+	if err != nil || key != "-1" {
+		t.Fatalf("failed to parse negative idx, got [%s]. Error: %v", key, err)
+	}
+	_, _, err = Util.ParseArrayPath("attrArray[-0]")
+	if err == nil {
+		t.Fatalf("idx [-0] should fail to parse")
+	}
+}
+
+func TestEscapeKey(t *testing.T) {
+	testList := map[string]string{
+		"a/b":   "a%2Fb",
+		"a[0]":  "a%5B0%5D",
+		"a?b":   "a%3Fb",
+		"50%+1": "50%25%2B1",
+		"01_01": "01_01",
+		"a b=c": "a b=c",
+	}
+	for key, expected := range testList {
+		escaped := Util.EscapeKey(key)
+		if escaped != expected {
+			t.Fatalf("expect key [%s] escaped as [%s], got [%s]", key, expected, escaped)
+		}
+		unescaped, err := Util.UnescapeKey(escaped)
+		if err != nil || unescaped != key {
+			t.Fatalf("expect [%s] unescaped back to [%s], got [%s]. Error: %v", escaped, key, unescaped, err)
+		}
+	}
+	unescaped, err := Util.UnescapeKey("a+b")
+	if err != nil || unescaped != "a+b" {
+		t.Fatalf("expect raw + kept in [a+b], got [%s]. Error: %v", unescaped, err)
+	}
+	attrName, key, err := Util.ParseArrayPath("attrArray[a%2Fb%5D]")
+	if err != nil || attrName != "attrArray" || key != "a/b]" {
+		t.Fatalf("failed to parse escaped key, got [%s][%s]. Error: %v", attrName, key, err)
+	}
+}
+
+func TestFilterIdx(t *testing.T) {
+	attr, value, ok := Util.FilterIdx("key1=01")
+	if !ok || attr != "key1" || value != "01" {
+		t.Fatalf("expect filter [key1]=[01], got [%s]=[%s] %t", attr, value, ok)
+	}
+	if _, _, ok = Util.FilterIdx("01_01"); ok {
+		t.Fatalf("idx [01_01] should not be a filter")
+	}
+	_, key, err := Util.ParseArrayPath("attrArray[key1=01]")
+	if err != nil || key != "key1=01" {
+		t.Fatalf("failed to parse filter idx, got [%s]. Error: %v", key, err)
+	}
+	_, _, err = Util.ParseArrayPath("attrArray[=01]")
+	if err == nil {
+		t.Fatalf("filter idx without attribute should fail to parse")
+	}
+}
+
+func TestSliceRange(t *testing.T) {
+	testList := map[string][2]int{
+		"1:3":  {1, 3},
+		"2:":   {2, 5},
+		":2":   {0, 2},
+		":":    {0, 5},
+		"-2:":  {3, 5},
+		"3:1":  {3, 3},
+		"0:99": {0, 5},
+		"7:":   {5, 5},
+	}
+	for key, expected := range testList {
+		start, end, ok := Util.SliceRange(key, 5)
+		if !ok || start != expected[0] || end != expected[1] {
+			t.Fatalf("expect slice [%s] as [%d:%d] of length 5, got [%d:%d, %t]", key, expected[0], expected[1], start, end, ok)
+		}
+	}
+	for _, key := range []string{"01_01", "a:b", "1", "+1:2", "1:2:3"} {
+		if Util.IsSliceIdx(key) {
+			t.Fatalf("idx [%s] should not be a slice", key)
+		}
+	}
+}